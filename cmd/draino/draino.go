@@ -32,6 +32,10 @@ import (
 	"github.com/DataDog/compute-go/kubeclient"
 	"github.com/DataDog/compute-go/service"
 	"github.com/DataDog/compute-go/version"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
 	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 
@@ -52,7 +56,9 @@ import (
 	"github.com/planetlabs/draino/internal/limit"
 	"github.com/planetlabs/draino/internal/observability"
 	protector "github.com/planetlabs/draino/internal/protector"
+	"github.com/planetlabs/draino/internal/tracing"
 
+	corev1 "k8s.io/api/core/v1"
 	client "k8s.io/client-go/kubernetes"
 	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -199,16 +205,25 @@ func main() {
 
 		defer zlog.Sync() // nolint:errcheck // no check required on program exit
 
-		go launchTracerAndProfiler()
+		stopTracer, err := startTracer(options.tracingBackend)
+		if err != nil {
+			return err
+		}
+		defer stopTracer()
+		go launchProfiler()
 
 		// use a Go context so we can tell the leaderelection and other pieces when we want to step down
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
+		evictionEndpointMapping := kubernetes.NewEvictionEndpointMapping(nil)
 		globalConfig := kubernetes.GlobalConfig{
 			Context:                            ctx,
 			ConfigName:                         options.configName,
 			SuppliedConditions:                 options.suppliedConditions,
+			SuppliedConditionsStore:            kubernetes.NewSuppliedConditionsStore(options.suppliedConditions),
 			PVCManagementEnableIfNoEvictionUrl: options.pvcManagementByDefault,
+			DefaultPVCCleanup:                  options.defaultPVCCleanup,
+			EvictionEndpointResolver:           evictionEndpointMapping,
 		}
 
 		validationOptions := infraparameters.GetValidateAll()
@@ -252,12 +267,24 @@ func main() {
 			return err
 		}
 
-		eventRecorderForDrainerActivities, _ := kubernetes.BuildEventRecorderWithAggregationOnEventTypeAndMessage(zapr.NewLogger(zlog), cs, options.eventAggregationPeriod, options.logEvents)
-		drainerAPI := kubernetes.NewAPIDrainer(cs,
-			eventRecorderForDrainerActivities,
+		indexer, err := index.New(ctx, mgr.GetClient(), mgr.GetCache(), logger)
+		if err != nil {
+			return fmt.Errorf("error while initializing informer: %v\n", err)
+		}
+
+		pdbAnalyser := analyser.NewPDBAnalyser(ctx, mgr.GetLogger(), indexer, clock.RealClock{}, options.podWarmupDelayExtension)
+
+		drainPause := kubernetes.NewDrainPause()
+		var workloadUnavailability *kubernetes.WorkloadUnavailabilityCoordinator
+		if options.maxWorkloadUnavailablePct > 0 {
+			workloadUnavailability = kubernetes.NewWorkloadUnavailabilityCoordinator(store, options.maxWorkloadUnavailablePct)
+		}
+		drainerOptions := []kubernetes.APIDrainerOption{
 			kubernetes.MaxGracePeriod(options.minEvictionTimeout),
 			kubernetes.EvictionHeadroom(options.evictionHeadroom),
 			kubernetes.WithSkipDrain(options.skipDrain),
+			kubernetes.WithDeletionInsteadOfEviction(options.deleteInsteadOfEvict),
+			kubernetes.WithEvictPodsRecreatedOnNode(options.evictRecreatedPodsOnNode),
 			kubernetes.WithPodFilter(filtersDef.drainPodFilter),
 			kubernetes.WithStorageClassesAllowingDeletion(options.storageClassesAllowingVolumeDeletion),
 			kubernetes.WithMaxDrainAttemptsBeforeFail(options.maxDrainAttemptsBeforeFail),
@@ -265,12 +292,61 @@ func main() {
 			kubernetes.WithAPIDrainerLogger(zlog),
 			kubernetes.WithRuntimeObjectStore(store),
 			kubernetes.WithContainerRuntimeClient(mgr.GetClient()),
-		)
-
-		indexer, err := index.New(ctx, mgr.GetClient(), mgr.GetCache(), logger)
-		if err != nil {
-			return fmt.Errorf("error while initializing informer: %v\n", err)
+			kubernetes.WithControllerEvents(options.controllerEvents),
+			kubernetes.WithStructuredConditionsData(options.structuredConditionsData),
+			kubernetes.WithNamespaceAllowList(options.drainNamespaceAllowList),
+			kubernetes.WithExcludePendingPods(options.excludePendingPods),
+			kubernetes.WithPodNameExclusion(options.podNameExclusionsRegexp),
+			kubernetes.WithSkipControllerKinds(options.skipControllerGroupKinds),
+			kubernetes.WithRequirePDB(options.requirePDB),
+			kubernetes.WithFailFastOnBlockedPDB(options.failFastOnBlockedPDB),
+			kubernetes.WithAlternativePlacementCheck(options.checkAlternativePlacement),
+			kubernetes.WithDrainCompletionVerification(options.verifyDrainCompletion),
+			kubernetes.WithSkipPodsOnFilterError(options.skipPodsOnFilterError),
+			kubernetes.WithPodsToDrainCacheTTL(options.podsToDrainCacheTTL),
+			kubernetes.WithPodListPageSize(options.podListPageSize),
+			kubernetes.WithMetricNodeLabelTags(options.metricNodeLabelTags),
+			kubernetes.WithSkipPVCCleanupIfRemovedByOthers(options.skipPVCCleanupIfRemoved),
+			kubernetes.WithConfirmPodGoneBeforePVCCleanup(options.confirmPodGoneForPVC),
+			kubernetes.WithRespectPVCRetentionPolicy(options.respectPVCRetentionPolicy),
+			kubernetes.WithMaxPVCDeletionsPerDrain(options.maxPVCDeletionsPerDrain),
+			kubernetes.WithPVCCleanupParallelism(options.pvcCleanupParallelism),
+			kubernetes.WithPVCRecreateTimeout(options.pvcRecreateTimeout),
+			kubernetes.WithPVCDeletionTimeouts(options.pvcDeletionTimeoutsMap),
+			kubernetes.WithDeletionPollBounds(options.deletionPollFloor, options.deletionPollCeiling),
+			kubernetes.WithMaxPodDeletionsForPVCRecreate(options.maxPodDeletionsForPVC),
+			kubernetes.WithConditionsRecheckPeriod(options.conditionsRecheckPeriod),
+			kubernetes.WithEvictionAttemptEventsAggregation(options.evictionAttemptEvents),
+			kubernetes.WithNodeStabilityGate(options.nodeStabilityPeriod, options.nodeStabilityTimeout),
+			kubernetes.WithFailureCauseEventReasons(options.failureCauseReasonsMap),
+			kubernetes.WithDrainPlanEvent(options.drainPlanEvent),
+			kubernetes.WithEvictionDeleteOptions(options.evictionDeleteOptions),
+			kubernetes.WithEvictionEndpointDegradedThreshold(options.evictionEndpointDegraded),
+			kubernetes.WithEvictionEndpointMaxErrorBody(options.evictionEndpointMaxErrBody),
+			kubernetes.WithEvictionEndpointTimeout(options.evictionEndpointTimeout),
+			kubernetes.WithEvictionEndpointDryRun(options.evictionEndpointDryRun),
+			kubernetes.WithEvictionEndpoint500Retries(options.evictionEndpoint500Retries),
+			kubernetes.WithEvictionAPIRetriesOn500(options.evictionAPIMaxRetriesOn500, options.evictionAPIRetryOn500Wait),
+			kubernetes.WithPDBIndexer(indexer),
+			kubernetes.WithPDBWaitEstimator(pdbAnalyser),
+			kubernetes.WithEvictionEndpointResolver(evictionEndpointMapping),
+			kubernetes.WithWorkloadUnavailabilityCoordinator(workloadUnavailability),
+			kubernetes.WithDrainPause(drainPause),
+		}
+		if options.randomizeEvictionOrder {
+			drainerOptions = append(drainerOptions, kubernetes.WithRandomizedEvictionOrder(time.Now().UnixNano()))
+		}
+		if options.evictionBackoffJitterFunc != nil {
+			drainerOptions = append(drainerOptions, kubernetes.WithEvictionBackoffJitter(options.evictionBackoffJitterFunc, time.Now().UnixNano()))
+		}
+		if options.evictionEndpointAcceptAsync {
+			drainerOptions = append(drainerOptions, kubernetes.WithEvictionEndpointAcceptAsync(options.evictionEndpointStatusHdr, options.evictionEndpointStatusPoll))
+		}
+		if len(options.evictionEndpointCABundle) > 0 || !options.evictionEndpointInsecure {
+			drainerOptions = append(drainerOptions, kubernetes.WithEvictionEndpointTLS(options.evictionEndpointCABundle, options.evictionEndpointInsecure))
 		}
+		eventRecorderForDrainerActivities, _ := kubernetes.BuildEventRecorderWithAggregationOnEventTypeAndMessage(zapr.NewLogger(zlog), cs, options.eventAggregationPeriod, options.logEvents)
+		drainerAPI := kubernetes.NewAPIDrainer(cs, eventRecorderForDrainerActivities, drainerOptions...)
 
 		globalBlocker := kubernetes.NewGlobalBlocker(logger)
 		for p, f := range options.maxNotReadyNodesFunctions {
@@ -301,7 +377,7 @@ func main() {
 			return errRW
 		}
 
-		pvcProtector := protector.NewPVCProtector(store, zlog, globalConfig.PVCManagementEnableIfNoEvictionUrl)
+		pvcProtector := protector.NewPVCProtector(store, zlog, globalConfig.PVCCleanupDefault)
 		stabilityPeriodChecker := analyser.NewStabilityPeriodChecker(ctx, logger, mgr.GetClient(), nil, store, indexer, analyser.StabilityPeriodCheckerConfiguration{}, filtersDef.drainPodFilter)
 		filterFactory, err := filters.NewFactory(
 			filters.WithLogger(mgr.GetLogger()),
@@ -322,6 +398,26 @@ func main() {
 			return err
 		}
 
+		simulationPodFilter := kubernetes.NewPodFilters(filtersDef.drainPodFilter, kubernetes.PodHasNoEvictionEndpoint(store, evictionEndpointMapping))
+		simulationRateLimiter := limit.NewRateLimiter(clock.RealClock{}, cfg.KubeClientConfig.QPS*options.simulationRateLimitingRatio, int(float32(cfg.KubeClientConfig.Burst)*options.simulationRateLimitingRatio))
+		podTakingPDBBudget := analyser.PodTakingPDBBudgetIfNotReady
+		if options.simulationPDBTerminatingPodsTakingBudget {
+			podTakingPDBBudget = analyser.PodTakingPDBBudgetIfNotReadyOrTerminating
+		}
+		simulator := drain.NewDrainSimulator(context.Background(), mgr.GetClient(), indexer, simulationPodFilter, eventRecorder, simulationRateLimiter, logger, drain.DrainSimulatorOptions{
+			Concurrency:         options.simulationConcurrency,
+			AnnotateNode:        options.simulationAnnotateNode,
+			RateLimitByPodCount: options.simulationRateLimitByPodCount,
+			PodTakingPDBBudget:  podTakingPDBBudget,
+			NegativeCacheTTLs:   options.simulationNegativeCacheTTLsMap,
+		})
+		// The pre activities run again after a reset, the drain must be simulated again before the node becomes candidate
+		invalidateSimulation := func(ctx context.Context, node *corev1.Node, _ []string) {
+			if err := simulator.InvalidateNode(ctx, node); err != nil {
+				logger.Error(err, "cannot invalidate the drain simulation after the reset of the pre activities", "node", node.Name)
+			}
+		}
+
 		nodeReplacer := preprocessor.NewNodeReplacer(mgr.GetClient(), mgr.GetLogger())
 		drainRunnerFactory, err := drain_runner.NewFactory(
 			drain_runner.WithKubeClient(mgr.GetClient()),
@@ -330,7 +426,8 @@ func main() {
 			drain_runner.WithPreprocessors(
 				preprocessor.NewWaitTimePreprocessor(options.waitBeforeDraining),
 				preprocessor.NewNodeReplacementPreProcessor(mgr.GetClient(), options.preprovisioningActivatedByDefault, mgr.GetLogger()),
-				preprocessor.NewPreActivitiesPreProcessor(mgr.GetClient(), indexer, store, mgr.GetLogger(), eventRecorderForDrainRunnerActivities, clock.RealClock{}, options.preActivityDefaultTimeout),
+				preprocessor.NewPreActivitiesPreProcessor(mgr.GetClient(), indexer, store, mgr.GetLogger(), eventRecorderForDrainRunnerActivities, clock.RealClock{}, options.preActivityDefaultTimeout,
+					preprocessor.WithPreActivitiesResetCallback(invalidateSimulation)),
 			),
 			drain_runner.WithRerun(options.groupRunnerPeriod),
 			drain_runner.WithRetryWall(retryWall),
@@ -343,22 +440,20 @@ func main() {
 			drain_runner.WithBeforeReplacementDuration(options.durationBeforeReplacement),
 			drain_runner.WithNodeReplacer(nodeReplacer),
 			drain_runner.WithPVCProtector(pvcProtector),
+			drain_runner.WithDrainPause(drainPause),
 		)
 		if err != nil {
 			logger.Error(err, "failed to configure the drain_runner")
 			return err
 		}
 
-		simulationPodFilter := kubernetes.NewPodFilters(filtersDef.drainPodFilter, kubernetes.PodOrControllerHasNoneOfTheAnnotations(store, kubernetes.EvictionAPIURLAnnotationKey))
-		simulationRateLimiter := limit.NewRateLimiter(clock.RealClock{}, cfg.KubeClientConfig.QPS*options.simulationRateLimitingRatio, int(float32(cfg.KubeClientConfig.Burst)*options.simulationRateLimitingRatio))
-		simulator := drain.NewDrainSimulator(context.Background(), mgr.GetClient(), indexer, simulationPodFilter, eventRecorder, simulationRateLimiter, logger)
-		pdbAnalyser := analyser.NewPDBAnalyser(ctx, mgr.GetLogger(), indexer, clock.RealClock{}, options.podWarmupDelayExtension)
 		sorters := candidate_runner.NodeSorters{
 			sorters.NewAnnotationPrioritizer(store, indexer, eventRecorder, logger),
-			sorters.NewConditionComparator(globalConfig.SuppliedConditions),
+			sorters.NewConditionComparator(globalConfig.GetSuppliedConditions),
 			pdbAnalyser.CompareNode,
 		}
 
+		conditionsRateLimiter := limit.NewTypedRateLimiter(&clock.RealClock{}, kubernetes.GetRateLimitConfiguration(globalConfig.GetSuppliedConditions()), options.drainRateLimitQPS, options.drainRateLimitBurst)
 		drainCandidateRunnerFactory, err := candidate_runner.NewFactory(
 			candidate_runner.WithKubeClient(mgr.GetClient()),
 			candidate_runner.WithClock(&clock.RealClock{}),
@@ -372,7 +467,7 @@ func main() {
 			candidate_runner.WithNodeSorters(sorters),
 			candidate_runner.WithDryRun(options.dryRun),
 			candidate_runner.WithRetryWall(retryWall),
-			candidate_runner.WithRateLimiter(limit.NewTypedRateLimiter(&clock.RealClock{}, kubernetes.GetRateLimitConfiguration(globalConfig.SuppliedConditions), options.drainRateLimitQPS, options.drainRateLimitBurst)),
+			candidate_runner.WithRateLimiter(conditionsRateLimiter),
 			candidate_runner.WithGlobalConfig(globalConfig),
 		)
 		if err != nil {
@@ -421,7 +516,7 @@ func main() {
 			return errCli
 		}
 
-		scopeObserver := observability.NewScopeObserver(cs, globalConfig, store, options.scopeAnalysisPeriod, filtersDef.candidatePodFilter,
+		scopeObserver := observability.NewScopeObserver(cs, globalConfig, store, options.scopeAnalysisPeriod, options.scopeNodeUpdateWorkers, filtersDef.candidatePodFilter,
 			kubernetes.PodOrControllerHasAnyOfTheAnnotations(store, options.optInPodAnnotations...),
 			kubernetes.PodOrControllerHasAnyOfTheAnnotations(store, options.candidateProtectedPodAnnotations...),
 			filtersDef.nodeLabelFilter, zlog, retryWall, keyGetter, groupRegistry, filterFactory.BuildCandidateFilter())
@@ -438,6 +533,31 @@ func main() {
 			return kubernetes.Await(ctx, nodes, pods, statefulSets, deployments, persistentVolumes, persistentVolumeClaims)
 		}})
 
+		if options.conditionsConfigMapName != "" {
+			// The conditions are shared through the global configuration, only the rate limits derived from them have to be rebuilt
+			updateRateLimits := kubernetes.SuppliedConditionsSetterFunc(func(conditions []kubernetes.SuppliedCondition) {
+				conditionsRateLimiter.SetConfigurations(kubernetes.GetRateLimitConfiguration(conditions))
+			})
+			conditionsWatch := kubernetes.NewConditionsConfigMapWatch(ctx, cs, cfg.InfraParam.Namespace, options.conditionsConfigMapName, zlog, globalConfig.SuppliedConditionsStore, updateRateLimits)
+			mgr.Add(&RunOnce{fn: func(ctx context.Context) error {
+				return kubernetes.Await(ctx, conditionsWatch)
+			}})
+		}
+
+		if options.evictionEndpointMappingConfigMapName != "" {
+			mappingWatch := kubernetes.NewEvictionEndpointMappingConfigMapWatch(ctx, cs, cfg.InfraParam.Namespace, options.evictionEndpointMappingConfigMapName, zlog, evictionEndpointMapping)
+			mgr.Add(&RunOnce{fn: func(ctx context.Context) error {
+				return kubernetes.Await(ctx, mappingWatch)
+			}})
+		}
+
+		if options.drainPauseConfigMapName != "" {
+			pauseWatch := kubernetes.NewDrainPauseConfigMapWatch(ctx, cs, cfg.InfraParam.Namespace, options.drainPauseConfigMapName, zlog, drainPause)
+			mgr.Add(&RunOnce{fn: func(ctx context.Context) error {
+				return kubernetes.Await(ctx, pauseWatch)
+			}})
+		}
+
 		if err := mgr.Add(globalBlocker); err != nil {
 			logger.Error(err, "failed to setup global blocker with controller runtime")
 			return err
@@ -467,13 +587,31 @@ func main() {
 	}
 }
 
-// launchTracerAndProfiler will initialize and run the tracer and the endpoint for the profiler
-// the function is blocking launch it in a dedicated go-routine
-func launchTracerAndProfiler() {
+// startTracer initializes the tracer of the given backend and returns the function flushing and stopping it
+func startTracer(backend string) (func(), error) {
+	if backend == tracing.BackendOpenTelemetry {
+		// The endpoint and the headers of the exporter are read from the OTEL_EXPORTER_OTLP_* environment variables
+		exporter, err := otlptracehttp.New(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("cannot create the OpenTelemetry exporter: %w", err)
+		}
+		provider := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String("draino"))),
+		)
+		tracing.SetTracer(tracing.NewOpenTelemetryTracer(provider))
+		return func() { _ = provider.Shutdown(context.Background()) }, nil
+	}
 	tracer.Start(
 		tracer.WithService("draino"),
 	)
-	defer tracer.Stop()
+	tracing.SetTracer(tracing.NewDatadogTracer())
+	return tracer.Stop, nil
+}
+
+// launchProfiler will run the endpoint for the profiler
+// the function is blocking launch it in a dedicated go-routine
+func launchProfiler() {
 	mux := httptrace.NewServeMux()
 	http.ListenAndServe("localhost:8085", mux) // for go profiler
 }
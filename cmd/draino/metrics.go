@@ -51,13 +51,95 @@ func DrainoLegacyMetrics(ctx context.Context, options *Options, logger logr.Logg
 			Aggregation: view.Count(),
 			TagKeys:     []tag.Key{kubernetes.TagResult, kubernetes.TagReason, kubernetes.TagNodegroupName, kubernetes.TagNodegroupNamePrefix, kubernetes.TagNodegroupNamespace, kubernetes.TagTeam},
 		}
+		drainDuration = &view.View{
+			Name:        "drain_duration",
+			Measure:     kubernetes.MeasureDrainDuration,
+			Description: "Duration between the draining taint and the end of the drain.",
+			Aggregation: view.Distribution(60e3, 300e3, 600e3, 1800e3, 3600e3, 7200e3, 14400e3, 43200e3),
+			TagKeys:     []tag.Key{kubernetes.TagConfigName, kubernetes.TagResult, kubernetes.TagNodegroupName, kubernetes.TagNodegroupNamePrefix, kubernetes.TagNodegroupNamespace, kubernetes.TagTeam},
+		}
+		drainFailures = &view.View{
+			Name:        "drain_failures_total",
+			Measure:     kubernetes.MeasureDrainFailures,
+			Description: "Number of failed drains, by failure cause.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kubernetes.TagFailureCause, kubernetes.TagNodegroupName, kubernetes.TagNodegroupNamePrefix, kubernetes.TagNodegroupNamespace, kubernetes.TagTeam},
+		}
+		evictionAttempts = &view.View{
+			Name:        "eviction_attempts",
+			Measure:     kubernetes.MeasureEvictionAttempts,
+			Description: "Number of eviction attempts per pod eviction.",
+			Aggregation: view.Distribution(1, 2, 3, 5, 10, 20, 50),
+			TagKeys:     []tag.Key{kubernetes.TagResult, kubernetes.TagNodegroupName, kubernetes.TagNodegroupNamePrefix, kubernetes.TagNodegroupNamespace, kubernetes.TagTeam},
+		}
+		evictionEndpointLatency = &view.View{
+			Name:        "eviction_endpoint_latency",
+			Measure:     kubernetes.MeasureEvictionEndpointLatency,
+			Description: "Latency of the calls to the custom eviction endpoints.",
+			Aggregation: view.Distribution(50, 100, 250, 500, 1000, 2500, 5000, 10000, 20000),
+			TagKeys:     []tag.Key{kubernetes.TagEvictionEndpoint, kubernetes.TagResult, kubernetes.TagDegraded, kubernetes.TagNodegroupName, kubernetes.TagNodegroupNamePrefix, kubernetes.TagNodegroupNamespace, kubernetes.TagTeam},
+		}
+		podEvictionDuration = &view.View{
+			Name:        "pod_eviction_duration",
+			Measure:     kubernetes.MeasurePodEvictionDuration,
+			Description: "Duration between the first eviction call and the confirmation of the pod deletion, by eviction path and outcome.",
+			Aggregation: view.Distribution(1e3, 5e3, 15e3, 30e3, 60e3, 120e3, 300e3, 600e3, 1800e3),
+			TagKeys:     []tag.Key{kubernetes.TagNodeName, kubernetes.TagEvictionPath, kubernetes.TagResult, kubernetes.TagNodegroupName, kubernetes.TagNodegroupNamePrefix, kubernetes.TagNodegroupNamespace, kubernetes.TagTeam},
+		}
+		preActivityWait = &view.View{
+			Name:        "pre_activity_wait",
+			Measure:     kubernetes.MeasurePreActivityWait,
+			Description: "Duration between the drain-candidate taint and the end of the pre activities, by outcome.",
+			Aggregation: view.Distribution(60e3, 300e3, 600e3, 1800e3, 3600e3, 7200e3, 14400e3),
+			TagKeys:     []tag.Key{kubernetes.TagResult, kubernetes.TagNodegroupName, kubernetes.TagNodegroupNamePrefix, kubernetes.TagNodegroupNamespace, kubernetes.TagTeam},
+		}
+		pvcRecreateDuration = &view.View{
+			Name:        "pvc_recreate_duration",
+			Measure:     kubernetes.MeasurePVCRecreateDuration,
+			Description: "Duration waiting for the recreation of the PVCs deleted during the drains, by outcome.",
+			Aggregation: view.Distribution(1e3, 5e3, 15e3, 30e3, 60e3, 120e3, 180e3),
+			TagKeys:     []tag.Key{kubernetes.TagResult},
+		}
+		pvcsDeleted = &view.View{
+			Name:        "deleted_pvcs_total",
+			Measure:     kubernetes.MeasurePVCDeleted,
+			Description: "Number of PVCs deleted by the volume cleanup of the drains, by storage class.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kubernetes.TagStorageClass},
+		}
+		pvsDeleted = &view.View{
+			Name:        "deleted_pvs_total",
+			Measure:     kubernetes.MeasurePVDeleted,
+			Description: "Number of PVs deleted by the volume cleanup of the drains, by storage class.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kubernetes.TagStorageClass},
+		}
+		pdbsEvaluated = &view.View{
+			Name:        "pdbs_evaluated",
+			Measure:     kubernetes.MeasurePDBsEvaluated,
+			Description: "Number of PDBs evaluated per pod drain simulation, by namespace.",
+			Aggregation: view.Distribution(0, 1, 2, 3, 5, 10),
+			TagKeys:     []tag.Key{kubernetes.TagNamespace},
+		}
+		podsSkipped = &view.View{
+			Name:        "skipped_pods_total",
+			Measure:     kubernetes.MeasurePodsSkipped,
+			Description: "Number of pods skipped during drains, by filter reason.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kubernetes.TagReason},
+		}
 	)
 
+	// the metrics recorded by the drainer for a node have the node labels as tags
+	for _, v := range []*view.View{nodesReplacement, drainDuration, drainFailures, evictionAttempts, evictionEndpointLatency, podEvictionDuration} {
+		v.TagKeys = append(v.TagKeys, options.metricNodeLabelTagKeys...)
+	}
+
 	if options.noLegacyNodeHandler {
 		// removing: nodesDrained
-		kingpin.FatalIfError(view.Register(nodesDrainScheduled, nodesReplacement, nodesPreprovisioningLatency), "cannot create metrics")
+		kingpin.FatalIfError(view.Register(nodesDrainScheduled, nodesReplacement, nodesPreprovisioningLatency, podsSkipped, drainDuration, drainFailures, evictionAttempts, evictionEndpointLatency, podEvictionDuration, pdbsEvaluated, preActivityWait, pvcRecreateDuration, pvcsDeleted, pvsDeleted), "cannot create metrics")
 	} else {
-		kingpin.FatalIfError(view.Register(nodesDrained, nodesDrainScheduled, nodesReplacement, nodesPreprovisioningLatency), "cannot create metrics")
+		kingpin.FatalIfError(view.Register(nodesDrained, nodesDrainScheduled, nodesReplacement, nodesPreprovisioningLatency, podsSkipped, drainDuration, drainFailures, evictionAttempts, evictionEndpointLatency, podEvictionDuration, pdbsEvaluated, preActivityWait, pvcRecreateDuration, pvcsDeleted, pvsDeleted), "cannot create metrics")
 	}
 
 	promOptions := prometheus.Options{Namespace: kubernetes.Component, Registry: prom.NewRegistry()}
@@ -1,15 +1,24 @@
 package main
 
 import (
+	"crypto/x509"
 	"fmt"
+	"os"
+	"regexp"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/spf13/pflag"
+	"go.opencensus.io/tag"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/planetlabs/draino/internal/kubernetes"
+	"github.com/planetlabs/draino/internal/kubernetes/drain"
 	"github.com/planetlabs/draino/internal/kubernetes/index"
+	"github.com/planetlabs/draino/internal/tracing"
 )
 
 const (
@@ -23,11 +32,26 @@ type Options struct {
 	noLegacyNodeHandler         bool
 	debug                       bool
 	listen                      string
+	metricNodeLabelTags         []string
+	metricNodeLabelTagKeys      []tag.Key
 	kubecfg                     string
 	apiserver                   string
 	dryRun                      bool
 	minEvictionTimeout          time.Duration
 	evictionHeadroom            time.Duration
+	evictionEndpointDegraded    time.Duration
+	evictionEndpointMaxErrBody  int64
+	evictionAPIMaxRetriesOn500  int
+	evictionEndpointAcceptAsync bool
+	evictionEndpointCAFile      string
+	evictionEndpointCABundle    []byte
+	evictionEndpointInsecure    bool
+	evictionEndpointStatusHdr   string
+	evictionEndpointStatusPoll  time.Duration
+	evictionEndpointTimeout     time.Duration
+	evictionEndpoint500Retries  int
+	evictionEndpointDryRun      bool
+	evictionAPIRetryOn500Wait   time.Duration
 	drainBuffer                 time.Duration
 	drainBufferConfigMapName    string
 	schedulingRetryBackoffDelay time.Duration
@@ -36,7 +60,48 @@ type Options struct {
 
 	// Eviction filtering flags
 	skipDrain                 bool
+	deleteInsteadOfEvict      bool
+	evictRecreatedPodsOnNode  bool
 	doNotEvictPodControlledBy []string
+	drainNamespaceAllowList   []string
+	excludePendingPods        bool
+	podNameExclusions         []string
+	podNameExclusionsRegexp   []regexp.Regexp
+	skipControllerKinds       []string
+	skipControllerGroupKinds  []schema.GroupKind
+	requirePDB                bool
+	failFastOnBlockedPDB      bool
+	checkAlternativePlacement bool
+	verifyDrainCompletion     bool
+	skipPVCCleanupIfRemoved   bool
+	confirmPodGoneForPVC      bool
+	skipPodsOnFilterError     bool
+	podsToDrainCacheTTL       time.Duration
+	podListPageSize           int64
+	respectPVCRetentionPolicy bool
+	maxPVCDeletionsPerDrain   int
+	pvcCleanupParallelism     int
+	maxWorkloadUnavailablePct int
+	pvcRecreateTimeout        time.Duration
+	pvcDeletionTimeouts       []string
+	deletionPollFloor         time.Duration
+	deletionPollCeiling       time.Duration
+	pvcDeletionTimeoutsMap    map[string]time.Duration
+	maxPodDeletionsForPVC     int
+	conditionsRecheckPeriod   time.Duration
+	evictionAttemptEvents     time.Duration
+	nodeStabilityPeriod       time.Duration
+	nodeStabilityTimeout      time.Duration
+	evictionPropagationPolicy string
+	evictionGracePeriod       int64
+	evictionDeleteOptions     *meta.DeleteOptions
+	failureCauseReasons       []string
+	randomizeEvictionOrder    bool
+	evictionBackoffJitter     string
+	evictionBackoffJitterPct  float64
+	evictionBackoffJitterFunc kubernetes.EvictionBackoffJitter
+	drainPlanEvent            bool
+	failureCauseReasonsMap    map[kubernetes.FailureCause]string
 	evictLocalStoragePods     bool
 	protectedPodAnnotations   []string
 	drainGroupLabelKey        string
@@ -74,6 +139,8 @@ type Options struct {
 	// PV/PVC management
 	storageClassesAllowingVolumeDeletion []string
 	pvcManagementByDefault               bool
+	defaultPVCCleanupValue               string
+	defaultPVCCleanup                    *bool
 
 	// Drain runner rate limiting
 	drainRateLimitQPS   float32
@@ -83,23 +150,46 @@ type Options struct {
 
 	// Which ratio of the overall kube client rate limiting should be used by the drain simulation
 	simulationRateLimitingRatio float32
+	simulationConcurrency       int
+	simulationAnnotateNode      bool
+	// simulationRateLimitByPodCount reserves the simulation rate limiting budget of all the pods of a node at once
+	simulationRateLimitByPodCount bool
+	// simulationPDBTerminatingPodsTakingBudget considers that the terminating pods are not counted in the healthy pods of their PDB during the simulations
+	simulationPDBTerminatingPodsTakingBudget bool
+	// simulationNegativeCacheTTLs are the cache durations of the negative simulation results per reason category
+	simulationNegativeCacheTTLs    []string
+	simulationNegativeCacheTTLsMap map[drain.NegativeReasonCategory]time.Duration
 
 	// events generation
 	eventAggregationPeriod        time.Duration
 	excludedPodsPerNodeEstimation int
 	logEvents                     bool
+	controllerEvents              bool
+	structuredConditionsData      bool
 
 	configName          string
 	resetScopeLabel     bool
 	scopeAnalysisPeriod time.Duration
+	// scopeNodeUpdateWorkers is the number of workers of the scope observer patching the node labels concurrently
+	scopeNodeUpdateWorkers int
 
 	groupRunnerPeriod       time.Duration
 	podWarmupDelayExtension time.Duration
 
 	klogVerbosity int32
 
-	conditions         []string
-	suppliedConditions []kubernetes.SuppliedCondition
+	// Tracing backend, datadog or opentelemetry
+	tracingBackend string
+
+	conditions              []string
+	suppliedConditions      []kubernetes.SuppliedCondition
+	conditionsConfigMapName string
+
+	// evictionEndpointMappingConfigMapName is the configmap giving the eviction endpoints per namespace or controller
+	evictionEndpointMappingConfigMapName string
+
+	// drainPauseConfigMapName is the configmap pausing all the drains
+	drainPauseConfigMapName string
 }
 
 func optionsFromFlags() (*Options, *pflag.FlagSet) {
@@ -110,17 +200,34 @@ func optionsFromFlags() (*Options, *pflag.FlagSet) {
 	fs.BoolVar(&opt.debug, "debug", false, "Run with debug logging.")
 	fs.BoolVar(&opt.dryRun, "dry-run", false, "Emit an event without tainting or draining matching nodes.")
 	fs.BoolVar(&opt.skipDrain, "skip-drain", false, "Whether to skip draining nodes after tainting.")
+	fs.BoolVar(&opt.evictRecreatedPodsOnNode, "evict-pods-recreated-on-node", false, "Evict the pods recreated with the same name on the node while waiting for the deletion of an evicted pod, instead of considering the eviction complete.")
+	fs.BoolVar(&opt.deleteInsteadOfEvict, "delete-instead-of-evict", false, "Delete the pods instead of evicting them, the PDBs are not respected. Overridden per pod by the draino/use-delete annotation, ignored for the pods with a custom eviction endpoint.")
 	fs.BoolVar(&opt.evictLocalStoragePods, "evict-emptydir-pods", false, "Evict pods with local storage, i.e. with emptyDir volumes.")
 	fs.BoolVar(&opt.candidateLocalStoragePods, "candidate-emptydir-pods", true, "Evict pods with local storage, i.e. with emptyDir volumes.")
 	fs.BoolVar(&opt.preprovisioningActivatedByDefault, "preprovisioning-by-default", false, "Set this flag to activate pre-provisioning by default for all nodes")
 	fs.BoolVar(&opt.pvcManagementByDefault, "pvc-management-by-default", false, "PVC management is automatically activated for a workload that do not use eviction++")
+	fs.StringVar(&opt.defaultPVCCleanupValue, "default-pvc-cleanup", "", "PVC management of the workloads without the annotation "+kubernetes.PVCStorageClassCleanupAnnotationKey+", true or false, whether they use eviction++ or not. When empty, pvc-management-by-default applies.")
 	fs.BoolVar(&opt.resetScopeLabel, "reset-config-labels", false, "Reset the scope label on the nodes")
 	fs.BoolVar(&opt.noLegacyNodeHandler, "no-legacy-node-handler", false, "Deactivate draino legacy node handler")
 	fs.BoolVar(&opt.logEvents, "log-events", true, "Indicate if events sent to kubernetes should also be logged")
+	fs.BoolVar(&opt.controllerEvents, "controller-events", false, "Also emit eviction events on the controller (Deployment/StatefulSet) of the evicted pods")
+	fs.BoolVar(&opt.structuredConditionsData, "structured-conditions-data", false, "Annotate the eviction starting events of the pods and the custom eviction requests with the offending conditions of the node in JSON, under "+kubernetes.EvictionNodeConditionsJSONAnnotationKey)
 	fs.BoolVar(&opt.excludeStatefulSetOnNodeWithoutStorage, "exclude-sts-on-node-without-storage", true, "To ensure backward compatibility with draino v1, we have to exclude pod of STS running on node without local-storage")
 
 	fs.DurationVar(&opt.minEvictionTimeout, "min-eviction-timeout", kubernetes.DefaultMinEvictionTimeout, "Minimum time we wait to evict a pod. The pod terminationGracePeriod will be used if it is bigger.")
 	fs.DurationVar(&opt.evictionHeadroom, "eviction-headroom", kubernetes.DefaultEvictionOverhead, "Additional time to wait after a pod's termination grace period for it to have been deleted.")
+	fs.Int64Var(&opt.evictionEndpointMaxErrBody, "eviction-endpoint-max-error-body", kubernetes.DefaultEvictionEndpointMaxErrorBody, "Maximum number of bytes read from the error responses of the custom eviction endpoints.")
+	fs.IntVar(&opt.evictionAPIMaxRetriesOn500, "eviction-api-max-retries-on-500", kubernetes.DefaultEvictionAPIMaxRetriesOn500, "Number of retries of an eviction after a 500 of the Kubernetes eviction API that is not caused by overlapping PDBs.")
+	fs.DurationVar(&opt.evictionAPIRetryOn500Wait, "eviction-api-retry-on-500-backoff", kubernetes.DefaultEvictionAPIRetryOn500Backoff, "Wait before the first retry of an eviction after a 500 of the Kubernetes eviction API, doubled at each retry.")
+	fs.StringVar(&opt.evictionEndpointCAFile, "eviction-endpoint-ca-bundle", "", "Path of the PEM encoded CA bundle verifying the certificates of the https custom eviction endpoints. The verification cannot be skipped when it is set.")
+	fs.BoolVar(&opt.evictionEndpointInsecure, "eviction-endpoint-insecure-skip-verify", true, "Do not verify the certificates of the https custom eviction endpoints. When false and without CA bundle, the system CAs are used.")
+	fs.BoolVar(&opt.evictionEndpointAcceptAsync, "eviction-endpoint-accept-async", false, "Treat the 202 answers of the custom eviction endpoints as evictions pending an asynchronous processing, and wait for the pod deletion.")
+	fs.StringVar(&opt.evictionEndpointStatusHdr, "eviction-endpoint-status-url-header", "", "Header of the 202 answers of the custom eviction endpoints giving a status URL to poll until it answers 200. Ignored if empty.")
+	fs.DurationVar(&opt.evictionEndpointStatusPoll, "eviction-endpoint-status-poll-period", kubernetes.DefaultEvictionEndpointStatusPoll, "Period of the polling of the status URL of the evictions accepted asynchronously by a custom eviction endpoint.")
+	fs.DurationVar(&opt.evictionEndpointTimeout, "eviction-endpoint-timeout", kubernetes.DefaultEvictionEndpointTimeout, "Timeout of the calls to the custom eviction endpoints, overridden per pod by the draino/eviction-api-timeout annotation.")
+	fs.IntVar(&opt.evictionEndpoint500Retries, "eviction-endpoint-retries-on-500", kubernetes.DefaultEvictionEndpoint500Retries, "Number of retries of an eviction after a 500 of a custom eviction endpoint, overridden per pod by the draino/eviction-api-retries-on-500 annotation.")
+	fs.BoolVar(&opt.evictionEndpointDryRun, "eviction-endpoint-dry-run", false, "Only send dry-run evictions to the custom eviction endpoints, with the dryRun=All query parameter, and report their verdict without waiting for the pods. Overridden per pod by the draino/eviction-api-dry-run annotation.")
+	fs.DurationVar(&opt.evictionEndpointDegraded, "eviction-endpoint-degraded-threshold", 0, "Latency above which a call to a custom eviction endpoint is reported as degraded with a warning event. Disabled if 0.")
 	fs.DurationVar(&opt.drainBuffer, "drain-buffer", kubernetes.DefaultDrainBuffer, "Delay to respect between end of previous drain (success or error) and a new attempt within a drain-group.")
 	fs.StringVar(&opt.drainBufferConfigMapName, "drain-buffer-configmap-name", "", "The name of the configmap used to persist the drain-buffer values. Default will be draino-<config-name>-drain-buffer.")
 	fs.DurationVar(&opt.schedulingRetryBackoffDelay, "retry-backoff-delay", DefaultSchedulingRetryBackoffDelay, "Additional delay to add between retry schedules.")
@@ -130,6 +237,7 @@ func optionsFromFlags() (*Options, *pflag.FlagSet) {
 	fs.DurationVar(&opt.preprovisioningTimeout, "preprovisioning-timeout", DefaultPreprovisioningTimeout, "Timeout for a node to be preprovisioned before draining")
 	fs.DurationVar(&opt.preprovisioningCheckPeriod, "preprovisioning-check-period", DefaultPreprovisioningCheckPeriod, "Period to check if a node has been preprovisioned")
 	fs.DurationVar(&opt.scopeAnalysisPeriod, "scope-analysis-period", 5*time.Minute, "Period to run the scope analysis and generate metric")
+	fs.IntVar(&opt.scopeNodeUpdateWorkers, "scope-node-update-workers", 1, "Number of workers patching the scope label of the nodes concurrently. The patches of all the workers share the same client side rate limit.")
 	fs.DurationVar(&opt.groupRunnerPeriod, "group-runner-period", 10*time.Second, "Period for running the group runner")
 	fs.DurationVar(&opt.podWarmupDelayExtension, "pod-warmup-delay-extension", 30*time.Second, "Extra delay given to the pod to complete is warmup phase (all containers have passed their startProbes)")
 	fs.DurationVar(&opt.eventAggregationPeriod, "event-aggregation-period", 15*time.Minute, "Period for event generation on kubernetes object.")
@@ -146,14 +254,52 @@ func optionsFromFlags() (*Options, *pflag.FlagSet) {
 	fs.StringSliceVar(&opt.maxPendingPods, "max-pending-pods", []string{}, "Maximum number of Pending Pods in the cluster. When exceeding this value draino stop taking actions. (Value|Value%)")
 	fs.StringSliceVar(&opt.optInPodAnnotations, "opt-in-pod-annotation", []string{}, "Pod filtering out is ignored if the pod holds one of these annotations. In a way, this makes the pod directly eligible for draino eviction. May be specified multiple times. KEY[=VALUE]")
 	fs.StringSliceVar(&opt.shortLivedPodAnnotations, "short-lived-pod-annotation", []string{}, "Pod that have a short live, just like job; we prefer let them run till the end instead of evicting them; node is cordon. May be specified multiple times. KEY[=VALUE]")
+	fs.BoolVar(&opt.verifyDrainCompletion, "verify-drain-completion", false, "Fail the drain if evictable pods are still on the node once all the evictions are done.")
+	fs.BoolVar(&opt.skipPodsOnFilterError, "skip-pods-on-filter-error", false, "Leave on the node the pods that cannot be filtered, with an event, and drain the other pods. The drain fails if the filter returns an error, by default.")
+	fs.Int64Var(&opt.podListPageSize, "pod-list-page-size", kubernetes.DefaultPodListPageSize, "Maximum number of pods returned by each call listing the pods of a node to drain, the pages are gathered until the listing is complete. All the pods are listed at once if 0.")
+	fs.DurationVar(&opt.podsToDrainCacheTTL, "pods-to-drain-cache-ttl", 0, "Time during which the pods to drain listed for a node are reused by the next drains of the node, instead of listing and filtering the pods again. Disabled if 0.")
+	fs.BoolVar(&opt.confirmPodGoneForPVC, "confirm-pod-gone-before-pvc-cleanup", false, "Before deleting the PVCs of a pod removed by another actor, check that it is gone and not replaced by a pod with the same name. The cleanup is skipped if the name was reused.")
+	fs.BoolVar(&opt.skipPVCCleanupIfRemoved, "skip-pvc-cleanup-if-removed-by-others", false, "Do not delete the PVCs of a pod that was removed by another actor before draino could evict it.")
+	fs.BoolVar(&opt.respectPVCRetentionPolicy, "respect-pvc-retention-policy", false, "Do not delete the PVCs of a pod owned by a StatefulSet whose persistentVolumeClaimRetentionPolicy.whenDeleted is Retain. whenDeleted applies to the deletion of the StatefulSet, this flag maps it onto the eviction of its pods.")
+	fs.IntVar(&opt.maxWorkloadUnavailablePct, "max-workload-unavailable-percent", 0, "Maximum percentage of the pods of a workload evicted at once, across all the drains. The evictions breaching the cap wait for the others to complete, at least one eviction per workload is always allowed. Disabled if 0.")
+	fs.IntVar(&opt.maxPVCDeletionsPerDrain, "max-pvc-deletions-per-drain", 0, "Maximum number of PVCs deleted during the drain of a node. The drain fails when more PVCs should be deleted. No limit if 0.")
+	fs.IntVar(&opt.pvcCleanupParallelism, "pvc-cleanup-parallelism", kubernetes.DefaultPVCCleanupParallelism, "Maximum number of PVCs of an evicted pod, and of their PVs, deleted at once.")
+	fs.DurationVar(&opt.pvcRecreateTimeout, "pvc-recreate-timeout", kubernetes.DefaultPVCRecreateTimeout, "Time waiting for the recreation of a deleted PVC before failing the drain. Can be overridden with the annotation "+kubernetes.PVCRecreateTimeoutAnnotationKey)
+	fs.StringSliceVar(&opt.pvcDeletionTimeouts, "pvc-deletion-timeout", []string{}, "Time waiting for the deletion of the PVCs and PVs of a storage class before failing the eviction, formatted as <storage class>=<duration>. The other storage classes wait for 1m. May be specified multiple times.")
+	fs.DurationVar(&opt.deletionPollFloor, "deletion-poll-floor", kubernetes.DefaultDeletionPollFloor, "Minimum period of the checks of the pod and PV deletions. The period is a tenth of the deletion timeout, within the floor and the ceiling.")
+	fs.DurationVar(&opt.deletionPollCeiling, "deletion-poll-ceiling", kubernetes.DefaultDeletionPollCeiling, "Maximum period of the checks of the pod and PV deletions.")
+	fs.IntVar(&opt.maxPodDeletionsForPVC, "max-pod-deletions-for-pvc-recreate", 0, "Maximum number of times a pod is deleted to force the recreation of its deleted PVC. The drain fails when more deletions would be needed. No limit if 0.")
+	fs.DurationVar(&opt.conditionsRecheckPeriod, "drain-conditions-recheck-period", 0, "Period at which the conditions of a node are re-evaluated during its drain. The drain is aborted if the node has no offending condition anymore. Disabled if 0.")
+	fs.DurationVar(&opt.evictionAttemptEvents, "eviction-attempt-events-aggregation-period", 0, "Period of the node event summarizing the pods awaiting PDB budget during a drain. It replaces the node event emitted for each failed eviction attempt, the events of the pods are kept. Disabled if 0.")
+	fs.DurationVar(&opt.nodeStabilityPeriod, "node-stability-period", 0, "Time the node must stay ready, with unchanged conditions, before its drain is marked as completed. Disabled if 0.")
+	fs.DurationVar(&opt.nodeStabilityTimeout, "node-stability-timeout", 5*time.Minute, "Maximum time waiting for the stability of the node, the drain is marked as failed if the node is still flapping. Used with node-stability-period.")
+	fs.BoolVar(&opt.checkAlternativePlacement, "check-alternative-placement", false, "Fail the drain if any of the pods to evict cannot be placed on another node, unless it has the annotation "+kubernetes.EvictWithoutAlternativePlacementAnnotationKey+"=true.")
+	fs.BoolVar(&opt.requirePDB, "require-pdb", false, "Fail the drain if any of the pods to evict is not covered by a pod disruption budget.")
+	fs.BoolVar(&opt.failFastOnBlockedPDB, "fail-fast-on-blocked-pdb", false, "Stop retrying the eviction of a pod when one of its pod disruption budgets does not allow any disruption while all its pods are healthy.")
+	fs.StringVar(&opt.evictionPropagationPolicy, "eviction-propagation-policy", "", "Propagation policy sent with the eviction requests: Orphan, Background or Foreground. The default of the API server is used if empty. Can be overridden with the annotation "+kubernetes.EvictionPropagationPolicyAnnotationKey)
+	fs.Int64Var(&opt.evictionGracePeriod, "eviction-grace-period", -1, "Grace period in seconds sent with the eviction requests. The grace period of the pod is used if negative. Can be overridden with the annotation "+kubernetes.EvictionGracePeriodAnnotationKey)
+	fs.BoolVar(&opt.drainPlanEvent, "drain-plan-event", false, "Emit a node event listing the pods to evict, in the order their evictions start, before draining the node.")
+	fs.BoolVar(&opt.randomizeEvictionOrder, "randomize-eviction-order", false, "Start the evictions of the pods of a node in a random order, instead of the order of the listing.")
+	fs.StringVar(&opt.evictionBackoffJitter, "eviction-backoff-jitter", kubernetes.EvictionBackoffJitterNone, "Randomization of the waits between the eviction attempts of a pod: none, full or equal. See eviction-backoff-jitter-fraction.")
+	fs.Float64Var(&opt.evictionBackoffJitterPct, "eviction-backoff-jitter-fraction", 1, "Fraction of the waits between the eviction attempts of a pod that is randomized, between 0 and 1. The equal strategy randomizes half of it.")
+	fs.StringSliceVar(&opt.failureCauseReasons, "failure-cause-event-reason", []string{}, "Reason of the eviction failure events for a failure cause, the other failures keep the EvictionFailed reason. May be specified multiple times. CAUSE=REASON, e.g. pod_disruption_budget_blocked=EvictionBlockedByPDB")
+	fs.StringSliceVar(&opt.podNameExclusions, "exclude-pod-name", []string{}, "Do not evict the pods whose name matches this regular expression, the pods are left on the node. May be specified multiple times.")
+	fs.StringSliceVar(&opt.skipControllerKinds, "skip-controller-kind", []string{}, "Do not evict the pods owned, directly or not, by this kind, the pods are left on the node. Formatted as kind.group, e.g. GameServer.agones.dev or Deployment.apps. May be specified multiple times.")
+	fs.BoolVar(&opt.excludePendingPods, "exclude-pending-pods", false, "Do not evict the Pending pods, the pods are left on the node. By default they are evicted as the other pods.")
+	fs.StringSliceVar(&opt.drainNamespaceAllowList, "drain-namespace-allow-list", []string{}, "Only evict the pods of these namespaces, the other pods are left on the node. All namespaces are allowed if empty. May be specified multiple times.")
 	fs.StringSliceVar(&opt.storageClassesAllowingVolumeDeletion, "storage-class-allows-pv-deletion", []string{}, "Storage class for which persistent volume (and associated claim) deletion is allowed. May be specified multiple times.")
 
 	fs.StringVar(&opt.nodeLabelsExpr, "node-label-expr", "", "Nodes that match this expression will be eligible for tainting and draining.")
 	fs.StringVar(&opt.listen, "listen", ":10002", "Address at which to expose /metrics and /healthz.")
+	fs.StringSliceVar(&opt.metricNodeLabelTags, "metric-node-label-tag", []string{}, "Node label added as a tag, named after the label, to the drain and eviction metrics of the nodes. At most "+strconv.Itoa(kubernetes.MaxMetricNodeLabelTags)+" labels. May be specified multiple times.")
 	fs.StringVar(&opt.kubecfg, "kubeconfig", "", "Path to kubeconfig file. Leave unset to use in-cluster config.")
 	fs.StringVar(&opt.apiserver, "master", "", "Address of Kubernetes API server. Leave unset to use in-cluster config.")
 	fs.StringVar(&opt.drainGroupLabelKey, "drain-group-labels", "", "Comma separated list of label keys to be used to form draining groups. KEY1,KEY2,...")
 	fs.StringVar(&opt.configName, "config-name", "", "Name of the draino configuration")
+	fs.StringVar(&opt.tracingBackend, "tracing-backend", tracing.BackendDatadog, "Backend receiving the traces: "+tracing.BackendDatadog+" or "+tracing.BackendOpenTelemetry+". The OpenTelemetry exporter is configured with the standard OTEL_EXPORTER_OTLP_* environment variables.")
+	fs.StringVar(&opt.drainPauseConfigMapName, "drain-pause-configmap-name", "", "Name of a configmap, in draino namespace, pausing all the drains while its key '"+kubernetes.DrainPauseConfigMapKey+"' is true. The key '"+kubernetes.DrainPauseReasonConfigMapKey+"' may explain the pause. The drains resume when the configmap is deleted.")
+	fs.StringVar(&opt.evictionEndpointMappingConfigMapName, "eviction-endpoint-mapping-configmap-name", "", "Name of a configmap, in draino namespace, mapping namespaces or controllers to custom eviction endpoints. The key '"+kubernetes.EvictionEndpointMappingConfigMapKey+"' holds one <namespace>[/<kind>/<name>]=<url> entry per line. The annotation "+kubernetes.EvictionAPIURLAnnotationKey+" overrides the mapping.")
+	fs.StringVar(&opt.conditionsConfigMapName, "node-conditions-configmap-name", "", "Name of a configmap, in draino namespace, from which node conditions are reloaded at runtime. The key '"+kubernetes.ConditionsConfigMapKey+"' holds one condition per line.")
 
 	// We are using some values with json content, so don't use StringSlice: https://github.com/spf13/pflag/issues/370
 	fs.StringArrayVar(&opt.conditions, "node-conditions", nil, "Nodes for which any of these conditions are true will be tainted and drained.")
@@ -166,6 +312,11 @@ func optionsFromFlags() (*Options, *pflag.FlagSet) {
 	fs.Float32Var(&opt.drainRateLimitQPS, "drain-rate-limit-qps", kubernetes.DefaultDrainRateLimitQPS, "Maximum number of node drains per seconds per condition")
 	fs.IntVar(&opt.drainRateLimitBurst, "drain-rate-limit-burst", kubernetes.DefaultDrainRateLimitBurst, "Maximum number of parallel drains within a timeframe")
 	fs.Float32Var(&opt.simulationRateLimitingRatio, "drain-sim-rate-limit-ratio", 0.7, "Which ratio of the overall kube client rate limiting should be used by the drain simulation. 1.0 means that it will use the same.")
+	fs.BoolVar(&opt.simulationAnnotateNode, "drain-sim-annotate-node", false, "Write the result of the last drain simulation in the annotation "+drain.LastSimulationAnnotationKey+" of the node. This adds write load on the API server.")
+	fs.BoolVar(&opt.simulationRateLimitByPodCount, "drain-sim-rate-limit-by-pod-count", false, "Reserve the drain simulation rate limiting budget of all the pods of a node before simulating it, instead of one pod at a time. A node with more pods consumes more budget and its simulation is not interrupted half way.")
+	fs.BoolVar(&opt.simulationPDBTerminatingPodsTakingBudget, "drain-sim-pdb-exclude-terminating-pods", false, "During the drain simulations, do not count the terminating pods as healthy pods of their PDB: like the pods that are not ready, their eviction does not consume PDB budget.")
+	fs.StringSliceVar(&opt.simulationNegativeCacheTTLs, "drain-sim-negative-cache-ttl", []string{}, "Cache duration of the negative drain simulation results of a reason category, formatted as <category>=<duration>. The categories are overlapping-pdb, pdb-blocked and eviction-rejected, the others are cached for "+drain.NegativeCacheResTTL.String()+". May be specified multiple times.")
+	fs.IntVar(&opt.simulationConcurrency, "drain-sim-concurrency", 1, "Maximum number of pods of a node for which the drain is simulated in parallel. The simulation rate limiting still applies.")
 
 	return &opt, &fs
 }
@@ -221,9 +372,141 @@ func (o *Options) Validate() error {
 	if o.groupRunnerPeriod < time.Second {
 		return fmt.Errorf("group runner period should be at least 1s")
 	}
+	if o.maxWorkloadUnavailablePct < 0 || o.maxWorkloadUnavailablePct > 100 {
+		return fmt.Errorf("max workload unavailable percent should be between 0 and 100")
+	}
+	if o.podListPageSize < 0 {
+		return fmt.Errorf("pod list page size should not be negative")
+	}
+	if o.podsToDrainCacheTTL < 0 {
+		return fmt.Errorf("pods to drain cache ttl should not be negative")
+	}
+	if o.pvcCleanupParallelism < 1 {
+		return fmt.Errorf("pvc cleanup parallelism should be at least 1")
+	}
+	if o.maxPVCDeletionsPerDrain < 0 {
+		return fmt.Errorf("max pvc deletions per drain should not be negative")
+	}
+	if o.pvcRecreateTimeout <= 0 {
+		return fmt.Errorf("pvc recreate timeout should be positive")
+	}
+	if o.maxPodDeletionsForPVC < 0 {
+		return fmt.Errorf("max pod deletions for pvc recreate should not be negative")
+	}
+	if o.nodeStabilityPeriod < 0 {
+		return fmt.Errorf("node stability period should not be negative")
+	}
+	if o.nodeStabilityPeriod > 0 && o.nodeStabilityTimeout < o.nodeStabilityPeriod {
+		return fmt.Errorf("node stability timeout should be at least the node stability period")
+	}
+	if o.evictionAPIMaxRetriesOn500 < 0 {
+		return fmt.Errorf("eviction api max retries on 500 should not be negative")
+	}
+	if o.evictionAPIMaxRetriesOn500 > 0 && o.evictionAPIRetryOn500Wait <= 0 {
+		return fmt.Errorf("eviction api retry on 500 backoff should be positive")
+	}
+	if o.evictionEndpointAcceptAsync && o.evictionEndpointStatusHdr != "" && o.evictionEndpointStatusPoll <= 0 {
+		return fmt.Errorf("eviction endpoint status poll period should be positive")
+	}
+	if o.evictionEndpointTimeout <= 0 {
+		return fmt.Errorf("eviction endpoint timeout should be positive")
+	}
+	if o.evictionEndpoint500Retries < 0 {
+		return fmt.Errorf("eviction endpoint retries on 500 should not be negative")
+	}
+	if o.scopeNodeUpdateWorkers < 1 {
+		return fmt.Errorf("scope node update workers should be at least 1")
+	}
+	if o.deletionPollFloor <= 0 {
+		return fmt.Errorf("deletion poll floor should be positive")
+	}
+	if o.deletionPollCeiling < o.deletionPollFloor {
+		return fmt.Errorf("deletion poll ceiling should be at least the deletion poll floor")
+	}
 	if o.podWarmupDelayExtension < time.Second {
 		return fmt.Errorf("pod warmup delay extension should be at least 1s")
 	}
 
+	// Pod name exclusions
+	o.podNameExclusionsRegexp = nil
+	for _, expr := range o.podNameExclusions {
+		re, compileErr := regexp.Compile(expr)
+		if compileErr != nil {
+			return fmt.Errorf("cannot parse 'exclude-pod-name' argument %q, %v", expr, compileErr)
+		}
+		o.podNameExclusionsRegexp = append(o.podNameExclusionsRegexp, *re)
+	}
+
+	// Skipped controller kinds
+	o.skipControllerGroupKinds = nil
+	for _, kind := range o.skipControllerKinds {
+		groupKind := schema.ParseGroupKind(kind)
+		if groupKind.Kind == "" {
+			return fmt.Errorf("cannot parse 'skip-controller-kind' argument %q, expecting kind.group", kind)
+		}
+		o.skipControllerGroupKinds = append(o.skipControllerGroupKinds, groupKind)
+	}
+
+	// Node labels as metric tags
+	if o.metricNodeLabelTagKeys, err = kubernetes.MetricNodeLabelTagKeys(o.metricNodeLabelTags); err != nil {
+		return fmt.Errorf("cannot parse 'metric-node-label-tag' argument, %v", err)
+	}
+
+	// Tracing
+	backend, parseErr := tracing.ParseBackend(o.tracingBackend)
+	if parseErr != nil {
+		return fmt.Errorf("cannot parse 'tracing-backend' argument, %v", parseErr)
+	}
+	o.tracingBackend = backend
+
+	if o.defaultPVCCleanupValue != "" {
+		defaultPVCCleanup, parseErr := strconv.ParseBool(o.defaultPVCCleanupValue)
+		if parseErr != nil {
+			return fmt.Errorf("cannot parse 'default-pvc-cleanup' argument, %v", parseErr)
+		}
+		o.defaultPVCCleanup = &defaultPVCCleanup
+	}
+
+	if o.evictionEndpointCAFile != "" {
+		if o.evictionEndpointCABundle, err = os.ReadFile(o.evictionEndpointCAFile); err != nil {
+			return fmt.Errorf("cannot read 'eviction-endpoint-ca-bundle' file, %v", err)
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM(o.evictionEndpointCABundle) {
+			return fmt.Errorf("no valid PEM certificate in 'eviction-endpoint-ca-bundle' file %s", o.evictionEndpointCAFile)
+		}
+	}
+
+	// DeleteOptions sent with the evictions
+	if o.evictionPropagationPolicy != "" {
+		propagation, parseErr := kubernetes.ParseDeletionPropagation(o.evictionPropagationPolicy)
+		if parseErr != nil {
+			return fmt.Errorf("cannot parse 'eviction-propagation-policy' argument, %v", parseErr)
+		}
+		o.evictionDeleteOptions = &meta.DeleteOptions{PropagationPolicy: &propagation}
+	}
+	if o.evictionGracePeriod >= 0 {
+		if o.evictionDeleteOptions == nil {
+			o.evictionDeleteOptions = &meta.DeleteOptions{}
+		}
+		gracePeriod := o.evictionGracePeriod
+		o.evictionDeleteOptions.GracePeriodSeconds = &gracePeriod
+	}
+
+	if o.failureCauseReasonsMap, err = kubernetes.ParseFailureCauseEventReasons(o.failureCauseReasons); err != nil {
+		return fmt.Errorf("cannot parse 'failure-cause-event-reason' argument, %v", err)
+	}
+
+	if o.simulationNegativeCacheTTLsMap, err = drain.ParseNegativeCacheTTLs(o.simulationNegativeCacheTTLs); err != nil {
+		return fmt.Errorf("cannot parse 'drain-sim-negative-cache-ttl' argument, %v", err)
+	}
+
+	if o.pvcDeletionTimeoutsMap, err = kubernetes.ParsePVCDeletionTimeouts(o.pvcDeletionTimeouts); err != nil {
+		return fmt.Errorf("cannot parse 'pvc-deletion-timeout' argument, %v", err)
+	}
+
+	if o.evictionBackoffJitterFunc, err = kubernetes.ParseEvictionBackoffJitter(o.evictionBackoffJitter, o.evictionBackoffJitterPct); err != nil {
+		return fmt.Errorf("cannot parse 'eviction-backoff-jitter' arguments, %v", err)
+	}
+
 	return nil
 }
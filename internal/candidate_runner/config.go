@@ -33,7 +33,7 @@ type Config struct {
 	retryWall           drain.RetryWall
 	filter              filters.Filter
 	rateLimiter         limit.TypedRateLimiter
-	suppliedCondition   []kubernetes.SuppliedCondition
+	suppliedCondition   kubernetes.SuppliedConditionsGetter
 
 	// With defaults
 	clock                     clock.Clock
@@ -85,7 +85,7 @@ func (conf *Config) Validate() error {
 	if conf.rateLimiter == nil {
 		return errors.New("rate limiter is not set")
 	}
-	if len(conf.suppliedCondition) == 0 {
+	if conf.suppliedCondition == nil || len(conf.suppliedCondition()) == 0 {
 		return errors.New("global config is not set")
 	}
 
@@ -178,6 +178,6 @@ func WithRateLimiter(limiter limit.TypedRateLimiter) WithOption {
 
 func WithGlobalConfig(globalConfig kubernetes.GlobalConfig) WithOption {
 	return func(conf *Config) {
-		conf.suppliedCondition = globalConfig.SuppliedConditions
+		conf.suppliedCondition = globalConfig.GetSuppliedConditions
 	}
 }
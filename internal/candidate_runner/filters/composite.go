@@ -4,11 +4,11 @@ import (
 	"context"
 	"fmt"
 	"github.com/planetlabs/draino/internal/kubernetes"
+	"github.com/planetlabs/draino/internal/tracing"
 	"strings"
 
 	"github.com/DataDog/compute-go/logs"
 	"github.com/go-logr/logr"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -36,7 +36,7 @@ func (c *CompositeFilter) Name() string {
 }
 
 func (c *CompositeFilter) Filter(ctx context.Context, nodes []*v1.Node) (keep []*v1.Node) {
-	span, ctx := tracer.StartSpanFromContext(ctx, "FilterDrainCandidates")
+	span, ctx := tracing.StartSpanFromContext(ctx, "FilterDrainCandidates")
 	defer span.Finish()
 
 	var filteringStr []string
@@ -7,16 +7,15 @@ import (
 	v1 "k8s.io/api/core/v1"
 )
 
-func NewNodeWithConditionFilter(conditions []kubernetes.SuppliedCondition) Filter {
+func NewNodeWithConditionFilter(conditions kubernetes.SuppliedConditionsGetter) Filter {
 	return FilterFromFunctionWithReason(
 		"conditions",
 		func(ctx context.Context, n *v1.Node) (bool, string) {
-			badConditions := kubernetes.GetNodeOffendingConditions(n, conditions)
+			candidate, badConditions := kubernetes.IsNodeDrainCandidate(n, conditions())
 			if len(badConditions) == 0 {
 				return false, "no_condition"
 			}
-			badConditionsStr := kubernetes.GetConditionsTypes(badConditions)
-			if !kubernetes.AtLeastOneConditionAcceptedByTheNode(badConditionsStr, n) {
+			if !candidate {
 				return false, "no_allowed_condition"
 			}
 			return true, ""
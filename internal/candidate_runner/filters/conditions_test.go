@@ -119,7 +119,7 @@ func Test_NewNodeWithConditionFilter(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			f := NewNodeWithConditionFilter(tt.conditions)
+			f := NewNodeWithConditionFilter(func() []kubernetes.SuppliedCondition { return tt.conditions })
 
 			if got := f.Filter(context.Background(), tt.nodes); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewNodeWithConditionFilter.Filter = %v, want %v", got, tt.want)
@@ -67,7 +67,7 @@ func (conf *Config) Validate() error {
 	if conf.globalConfig.ConfigName == "" {
 		return errors.New("globalConfig.ConfigName is not set")
 	}
-	if len(conf.globalConfig.SuppliedConditions) == 0 {
+	if len(conf.globalConfig.GetSuppliedConditions()) == 0 {
 		return errors.New("globalConfig.SuppliedConditions is empty")
 	}
 	if conf.groupKeyGetter == nil {
@@ -25,7 +25,7 @@ func (factory *FilterFactory) BuildCandidateFilter() Filter {
 	}
 
 	f.filters = []Filter{
-		NewNodeWithConditionFilter(factory.conf.globalConfig.SuppliedConditions),
+		NewNodeWithConditionFilter(factory.conf.globalConfig.GetSuppliedConditions),
 		NewNodeWithLabelFilter(factory.conf.nodeLabelFilterFunc),
 		NewPodFilter(*factory.conf.logger, factory.conf.podFilterFunc, factory.conf.objectsStore),
 		NewRetryWallFilter(factory.conf.clock, factory.conf.retryWall),
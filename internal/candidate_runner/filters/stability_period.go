@@ -4,7 +4,7 @@ import (
 	"context"
 
 	"github.com/planetlabs/draino/internal/kubernetes/analyser"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"github.com/planetlabs/draino/internal/tracing"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/clock"
 )
@@ -13,7 +13,7 @@ func NewStabilityPeriodFilter(checker analyser.StabilityPeriodChecker, clock clo
 	return FilterFromFunction(
 		"stability_period",
 		func(ctx context.Context, n *corev1.Node) bool {
-			span, ctx := tracer.StartSpanFromContext(ctx, "StabilityPeriodFilter")
+			span, ctx := tracing.StartSpanFromContext(ctx, "StabilityPeriodFilter")
 			defer span.Finish()
 			return checker.StabilityPeriodAcceptsDrain(context.Background(), n, clock.Now())
 		},
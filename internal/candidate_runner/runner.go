@@ -7,13 +7,13 @@ import (
 	"time"
 
 	"github.com/DataDog/compute-go/logs"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 
 	"github.com/planetlabs/draino/internal/candidate_runner/filters"
 	"github.com/planetlabs/draino/internal/kubernetes/k8sclient"
 	"github.com/planetlabs/draino/internal/kubernetes/utils"
 	"github.com/planetlabs/draino/internal/limit"
 	"github.com/planetlabs/draino/internal/scheduler"
+	"github.com/planetlabs/draino/internal/tracing"
 
 	"github.com/go-logr/logr"
 	"github.com/planetlabs/draino/internal/groups"
@@ -51,7 +51,7 @@ type candidateRunner struct {
 	retryWall           drain.RetryWall
 	filter              filters.Filter
 	rateLimiter         limit.TypedRateLimiter
-	suppliedConditions  []kubernetes.SuppliedCondition
+	suppliedConditions  kubernetes.SuppliedConditionsGetter
 
 	maxSimultaneousCandidates int
 	dryRun                    bool
@@ -73,7 +73,7 @@ func (runner *candidateRunner) Run(info *groups.RunnerInfo) error {
 
 	// run an endless loop until there are no drain candidates left
 	wait.UntilWithContext(ctx, func(ctx context.Context) {
-		span, ctx := tracer.StartSpanFromContext(ctx, "EvaluateCandidates")
+		span, ctx := tracing.StartSpanFromContext(ctx, "EvaluateCandidates")
 		defer span.Finish()
 
 		start := runner.clock.Now()
@@ -186,7 +186,7 @@ func (runner *candidateRunner) Run(info *groups.RunnerInfo) error {
 // hasConditionRateLimitingCapacity will iterate over all the node's conditions and try to get a token from each rate limiter.
 // It will return true when it receives the first token and returns false if it cannot get any token.
 func (runner *candidateRunner) hasConditionRateLimitingCapacity(node *corev1.Node) bool {
-	conditions := kubernetes.GetNodeOffendingConditions(node, runner.suppliedConditions)
+	conditions := kubernetes.GetNodeOffendingConditions(node, runner.suppliedConditions())
 	for _, condition := range conditions {
 		if runner.rateLimiter.TryAccept(string(condition.Type)) {
 			return true
@@ -220,7 +220,7 @@ func (runner *candidateRunner) GetNodeIterator(nodes []*corev1.Node) scheduler.I
 
 // handleRetryFlagOnNodes checks if a node has the drain-failed retry annotation and if so it will reset the retry wall
 func (runner *candidateRunner) handleRetryFlagOnNodes(ctx context.Context, nodes []*corev1.Node) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "ResetRetries")
+	span, ctx := tracing.StartSpanFromContext(ctx, "ResetRetries")
 	defer span.Finish()
 
 	var errors []error
@@ -266,7 +266,7 @@ func (runner *candidateRunner) runCleanupWithContext(ctx context.Context, info *
 	time.Sleep(runner.runEvery / 2)
 	// run an endless loop until there are no drain candidates left
 	wait.UntilWithContext(ctx, func(ctx context.Context) {
-		span, ctx := tracer.StartSpanFromContext(ctx, "RunCleanupWithContext")
+		span, ctx := tracing.StartSpanFromContext(ctx, "RunCleanupWithContext")
 		defer span.Finish()
 
 		start := runner.clock.Now()
@@ -7,18 +7,19 @@ import (
 )
 
 type conditionsComparator struct {
-	knownConditions []kubernetes.SuppliedCondition
+	knownConditions kubernetes.SuppliedConditionsGetter
 }
 
-func NewConditionComparator(conditions []kubernetes.SuppliedCondition) func(n1, n2 *v1.Node) bool {
+func NewConditionComparator(conditions kubernetes.SuppliedConditionsGetter) func(n1, n2 *v1.Node) bool {
 	cc := &conditionsComparator{knownConditions: conditions}
 	return cc.CompareNodeConditionsPriorities
 }
 
 func (cc *conditionsComparator) CompareNodeConditionsPriorities(n1, n2 *v1.Node) bool {
+	knownConditions := cc.knownConditions()
 	return cc.compareConditionsPriorities(
-		kubernetes.GetNodeOffendingConditions(n1, cc.knownConditions),
-		kubernetes.GetNodeOffendingConditions(n2, cc.knownConditions))
+		kubernetes.GetNodeOffendingConditions(n1, knownConditions),
+		kubernetes.GetNodeOffendingConditions(n2, knownConditions))
 
 }
 
@@ -89,7 +89,7 @@ func Test_conditionsComparator_compareConditionsPriorities(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cc := &conditionsComparator{
-				knownConditions: knownConditions,
+				knownConditions: func() []kubernetes.SuppliedCondition { return knownConditions },
 			}
 			if got := cc.compareConditionsPriorities(tt.c1, tt.c2); got != tt.want {
 				t.Errorf("CompareNodeConditionsPriorities() = %v, want %v", got, tt.want)
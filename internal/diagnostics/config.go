@@ -31,7 +31,7 @@ type Config struct {
 	filter                 filters.Filter
 	keyGetter              groups.GroupKeyGetter
 	drainBuffer            drainbuffer.DrainBuffer
-	suppliedCondition      []kubernetes.SuppliedCondition
+	suppliedCondition      kubernetes.SuppliedConditionsGetter
 	stabilityPeriodChecker analyser.StabilityPeriodChecker
 
 	// With defaults
@@ -78,7 +78,7 @@ func (conf *Config) Validate() error {
 	if conf.drainBuffer == nil {
 		return errors.New("drainBuffer is not set")
 	}
-	if len(conf.suppliedCondition) == 0 {
+	if conf.suppliedCondition == nil || len(conf.suppliedCondition()) == 0 {
 		return errors.New("global config is not set")
 	}
 
@@ -135,7 +135,7 @@ func WithRetryWall(retryWall drain.RetryWall) WithOption {
 
 func WithGlobalConfig(globalConfig kubernetes.GlobalConfig) WithOption {
 	return func(conf *Config) {
-		conf.suppliedCondition = globalConfig.SuppliedConditions
+		conf.suppliedCondition = globalConfig.GetSuppliedConditions
 	}
 }
 
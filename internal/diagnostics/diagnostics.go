@@ -25,7 +25,7 @@ type Diagnostics struct {
 	clock               clock.Clock
 	retryWall           drain.RetryWall
 	filter              filters.Filter
-	suppliedConditions  []kubernetes.SuppliedCondition
+	suppliedConditions  kubernetes.SuppliedConditionsGetter
 	drainBuffer         drainbuffer.DrainBuffer
 	stabilityPeriod     analyser.StabilityPeriodChecker
 	nodeSorters         candidate_runner.NodeSorters
@@ -80,7 +80,7 @@ func (diag *Diagnostics) GetNodeDiagnostic(ctx context.Context, nodeName string)
 		DrainBufferAt:     drainBufferAt,
 		DrainBufferConfig: drainBufferConfig,
 		DrainSimulation:   dsr,
-		Conditions:        kubernetes.GetNodeOffendingConditions(&node, diag.suppliedConditions),
+		Conditions:        kubernetes.GetNodeOffendingConditions(&node, diag.suppliedConditions()),
 		StabilityPeriodOk: diag.stabilityPeriod.StabilityPeriodAcceptsDrain(ctx, &node, diag.clock.Now()),
 	}
 }
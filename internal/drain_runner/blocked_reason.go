@@ -0,0 +1,99 @@
+package drain_runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	preprocessor "github.com/planetlabs/draino/internal/drain_runner/pre_processor"
+	"github.com/planetlabs/draino/internal/kubernetes"
+	"github.com/planetlabs/draino/internal/kubernetes/k8sclient"
+)
+
+// BlockedReasonAnnotationKey is the node annotation holding the BlockedReason, in JSON, of the last blocker met while processing the node.
+// It is removed as soon as the drain of the node starts.
+const BlockedReasonAnnotationKey = "draino/blocked-reason"
+
+const (
+	BlockerPreProcessing = "pre-processing"
+	BlockerDrain         = "drain"
+)
+
+// BlockedReason summarizes why the node cannot be drained at the moment
+type BlockedReason struct {
+	// Blocker is the phase that is blocking the node, BlockerPreProcessing or BlockerDrain
+	Blocker string `json:"blocker"`
+	// Cause is the machine friendly reason: the preprocessor result or the drain failure cause
+	Cause     string      `json:"cause,omitempty"`
+	Message   string      `json:"message"`
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// GetBlockedReason returns the BlockedReason stored on the node, if any
+func GetBlockedReason(node *corev1.Node) (*BlockedReason, error) {
+	value, ok := node.GetAnnotations()[BlockedReasonAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	var reason BlockedReason
+	if err := json.Unmarshal([]byte(value), &reason); err != nil {
+		return nil, fmt.Errorf("cannot parse annotation %s: %w", BlockedReasonAnnotationKey, err)
+	}
+	return &reason, nil
+}
+
+// setBlockedReason writes the blocked reason annotation. The node is not patched if it already has the same blocker, cause and message,
+// this way the timestamp tells since when the node is blocked. Failures are only logged.
+// It returns the patched node, or the given one if nothing was patched, so that it can be used for further updates.
+func (runner *drainRunner) setBlockedReason(ctx context.Context, node *corev1.Node, blocker, cause, message string) *corev1.Node {
+	if current, err := GetBlockedReason(node); err == nil && current != nil &&
+		current.Blocker == blocker && current.Cause == cause && current.Message == message {
+		return node
+	}
+	value, err := json.Marshal(BlockedReason{Blocker: blocker, Cause: cause, Message: message, Timestamp: metav1.NewTime(runner.clock.Now())})
+	if err != nil {
+		runner.logger.Error(err, "cannot serialize blocked reason", "node", node.Name)
+		return node
+	}
+	var patch k8sclient.AnnotationPatch
+	patch.Metadata.Annotations = map[string]string{BlockedReasonAnnotationKey: string(value)}
+	return runner.patchBlockedReason(ctx, node, patch)
+}
+
+// setPreProcessingBlockedReason writes the blocked reason for preprocessors that are pending or that failed
+func (runner *drainRunner) setPreProcessingBlockedReason(ctx context.Context, node *corev1.Node, abortReason string, pending []string) *corev1.Node {
+	if abortReason != "" {
+		return runner.setBlockedReason(ctx, node, BlockerPreProcessing, abortReason, "pre-conditions failed: "+abortReason)
+	}
+	return runner.setBlockedReason(ctx, node, BlockerPreProcessing, string(preprocessor.PreProcessNotDoneReasonProcessing), "waiting for pre-activities: "+strings.Join(pending, ", "))
+}
+
+// setDrainBlockedReason writes the blocked reason for a drain failure, the cause is the failure cause of the error
+func (runner *drainRunner) setDrainBlockedReason(ctx context.Context, node *corev1.Node, drainErr error) *corev1.Node {
+	return runner.setBlockedReason(ctx, node, BlockerDrain, string(kubernetes.GetFailureCause(drainErr)), drainErr.Error())
+}
+
+// clearBlockedReason removes the blocked reason annotation, if the node has one. Failures are only logged.
+func (runner *drainRunner) clearBlockedReason(ctx context.Context, node *corev1.Node) *corev1.Node {
+	if _, ok := node.GetAnnotations()[BlockedReasonAnnotationKey]; !ok {
+		return node
+	}
+	var patch k8sclient.AnnotationDeletePatch
+	patch.Metadata.Annotations = map[string]interface{}{BlockedReasonAnnotationKey: nil}
+	return runner.patchBlockedReason(ctx, node, patch)
+}
+
+// patchBlockedReason works on a copy of the node, like k8sclient.PatchNodeCR, but returns it once patched
+func (runner *drainRunner) patchBlockedReason(ctx context.Context, node *corev1.Node, patch client.Patch) *corev1.Node {
+	nodeCopy := node.DeepCopy()
+	if err := runner.client.Patch(ctx, nodeCopy, patch); err != nil {
+		runner.logger.Error(err, "cannot patch blocked reason annotation", "node", node.Name)
+		return node
+	}
+	return nodeCopy
+}
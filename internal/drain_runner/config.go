@@ -33,7 +33,7 @@ type Config struct {
 	filter              filters.Filter
 	drainBuffer         drainbuffer.DrainBuffer
 	nodeReplacer        *preprocessor.NodeReplacer
-	suppliedCondition   []kubernetes.SuppliedCondition
+	suppliedCondition   kubernetes.SuppliedConditionsGetter
 	pvcProtector        protector.PVCProtector
 
 	// With defaults
@@ -43,6 +43,7 @@ type Config struct {
 
 	// Options
 	durationWithDrainedStatusBeforeReplacement time.Duration
+	drainPause                                 *kubernetes.DrainPause
 }
 
 // NewConfig returns a pointer to a new drain runner configuration
@@ -83,7 +84,7 @@ func (conf *Config) Validate() error {
 	if conf.nodeReplacer == nil {
 		return errors.New("node replacer should be set")
 	}
-	if len(conf.suppliedCondition) == 0 {
+	if conf.suppliedCondition == nil || len(conf.suppliedCondition()) == 0 {
 		return errors.New("global config is not set")
 	}
 	if conf.pvcProtector == nil {
@@ -170,7 +171,7 @@ func WithNodeReplacer(nodeReplacer *preprocessor.NodeReplacer) WithOption {
 
 func WithGlobalConfig(globalConfig kubernetes.GlobalConfig) WithOption {
 	return func(conf *Config) {
-		conf.suppliedCondition = globalConfig.SuppliedConditions
+		conf.suppliedCondition = globalConfig.GetSuppliedConditions
 	}
 }
 
@@ -180,6 +181,13 @@ func WithBeforeReplacementDuration(durationWithDrainedStatusBeforeReplacement ti
 	}
 }
 
+// WithDrainPause makes the runner leave the candidates untouched while the drains are paused
+func WithDrainPause(pause *kubernetes.DrainPause) WithOption {
+	return func(conf *Config) {
+		conf.drainPause = pause
+	}
+}
+
 func WithPVCProtector(pvcProtector protector.PVCProtector) WithOption {
 	return func(conf *Config) {
 		conf.pvcProtector = pvcProtector
@@ -40,6 +40,7 @@ func (factory *DrainRunnerFactory) build() *drainRunner {
 		suppliedConditions:  factory.conf.suppliedCondition,
 		preprocessors:       factory.conf.preprocessors,
 		pvcProtector:        factory.conf.pvcProtector,
+		drainPause:          factory.conf.drainPause,
 
 		durationWithDrainedStatusBeforeReplacement: factory.conf.durationWithDrainedStatusBeforeReplacement,
 	}
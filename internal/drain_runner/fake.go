@@ -37,6 +37,7 @@ type FakeOptions struct {
 
 	Drainer       kubernetes.Drainer
 	RetryStrategy drain.RetryStrategy
+	DrainPause    *kubernetes.DrainPause
 }
 
 func (opts *FakeOptions) ApplyDefaults() error {
@@ -120,6 +121,8 @@ func NewFakeRunner(opts *FakeOptions) (*drainRunner, error) {
 		filter:              opts.Filter,
 		drainBuffer:         opts.DrainBuffer,
 		nodeReplacer:        opts.NodeReplacer,
+		suppliedConditions:  func() []kubernetes.SuppliedCondition { return nil },
+		drainPause:          opts.DrainPause,
 
 		durationWithDrainedStatusBeforeReplacement: time.Hour,
 	}, nil
@@ -3,13 +3,16 @@ package pre_processor
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/DataDog/compute-go/logs"
 	"github.com/go-logr/logr"
+	"go.opencensus.io/tag"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/clock"
@@ -30,8 +33,14 @@ const (
 
 	PreActivityTimeoutAnnotationPrefix = "node-lifecycle.datadoghq.com/timeout-pre-activity-"
 
+	// outcomes of the pre activities, used to tag MeasurePreActivityWait
+	preActivityOutcomeDone    = "done"
+	preActivityOutcomeFailed  = "failed"
+	preActivityOutcomeTimeout = "timeout"
+
 	eventPreActivityBadConfiguration = "PreActivityBadConfiguration"
 	eventPreActivityFailed           = "PreActivityFailed"
+	eventPreActivityReset            = "PreActivityReset"
 )
 
 const PreProcessNotDoneReasonNotCandidate PreProcessNotDoneReason = "given node is not a candidate"
@@ -46,10 +55,30 @@ type PreActivitiesPreProcessor struct {
 	eventRecorder  kubernetes.EventRecorder
 	clock          clock.Clock
 	defaultTimeout time.Duration
+	resetCallbacks []PreActivitiesResetCallback
+
+	// waitRecorded keeps, per node, the drain-candidate time for which the pre activities wait was recorded, so that it is recorded once per transition
+	waitRecordedLock sync.Mutex
+	waitRecorded     map[string]time.Time
 }
 
-func NewPreActivitiesPreProcessor(client client.Client, podIndexer index.PodIndexer, store kubernetes.RuntimeObjectStore, logger logr.Logger, eventRecorder kubernetes.EventRecorder, clock clock.Clock, defaultTimeout time.Duration) DrainPreProcessor {
-	return &PreActivitiesPreProcessor{
+// PreActivitiesResetCallback is called when pre activities of the node are reset to PreActivityAnnotationNotStarted.
+// The activities are identified by the object holding them and their name, in the form "<object>/<activity>".
+type PreActivitiesResetCallback func(ctx context.Context, node *corev1.Node, activities []string)
+
+// PreActivitiesOption is used to pass an option to NewPreActivitiesPreProcessor
+type PreActivitiesOption func(pre *PreActivitiesPreProcessor)
+
+// WithPreActivitiesResetCallback registers a callback called after each reset of the pre activities of a node,
+// so that the controller knows that the activities will run again and that the drain has to be simulated again.
+func WithPreActivitiesResetCallback(callback PreActivitiesResetCallback) PreActivitiesOption {
+	return func(pre *PreActivitiesPreProcessor) {
+		pre.resetCallbacks = append(pre.resetCallbacks, callback)
+	}
+}
+
+func NewPreActivitiesPreProcessor(client client.Client, podIndexer index.PodIndexer, store kubernetes.RuntimeObjectStore, logger logr.Logger, eventRecorder kubernetes.EventRecorder, clock clock.Clock, defaultTimeout time.Duration, options ...PreActivitiesOption) DrainPreProcessor {
+	pre := &PreActivitiesPreProcessor{
 		client:         client,
 		podIndexer:     podIndexer,
 		store:          store,
@@ -57,7 +86,12 @@ func NewPreActivitiesPreProcessor(client client.Client, podIndexer index.PodInde
 		eventRecorder:  eventRecorder,
 		clock:          clock,
 		defaultTimeout: defaultTimeout,
+		waitRecorded:   map[string]time.Time{},
+	}
+	for _, option := range options {
+		option(pre)
 	}
+	return pre
 }
 
 func (_ *PreActivitiesPreProcessor) GetName() string {
@@ -93,6 +127,7 @@ func (pre *PreActivitiesPreProcessor) IsDone(ctx context.Context, node *corev1.N
 		case PreActivityAnnotationFailed:
 			logger.Info("pre activity failed")
 			pre.eventRecorder.NodeEventf(ctx, node, corev1.EventTypeWarning, eventPreActivityFailed, "pre activity '%s' failed", key)
+			pre.recordWait(ctx, node, taint, preActivityOutcomeFailed)
 			return false, PreProcessNotDoneReasonFailure, nil
 		default:
 			if taint.TimeAdded == nil {
@@ -102,6 +137,7 @@ func (pre *PreActivitiesPreProcessor) IsDone(ctx context.Context, node *corev1.N
 			if pre.clock.Now().Sub(candidateSince) > entry.timeout {
 				logger.Info("pre activity timed out")
 				pre.eventRecorder.NodeEventf(ctx, node, corev1.EventTypeWarning, eventPreActivityFailed, "pre activity '%s' timed out", key)
+				pre.recordWait(ctx, node, taint, preActivityOutcomeTimeout)
 				return false, PreProcessNotDoneReasonTimeout, nil
 			}
 			logger.V(logs.ZapDebug).Info("Waiting for pre activity to finish")
@@ -109,9 +145,30 @@ func (pre *PreActivitiesPreProcessor) IsDone(ctx context.Context, node *corev1.N
 		}
 	}
 
+	if len(activities) > 0 {
+		pre.recordWait(ctx, node, taint, preActivityOutcomeDone)
+	}
 	return true, "", nil
 }
 
+// recordWait records MeasurePreActivityWait the first time the pre activities of the node reach a terminal outcome for the current drain-candidate taint
+func (pre *PreActivitiesPreProcessor) recordWait(ctx context.Context, node *corev1.Node, taint *corev1.Taint, outcome string) {
+	if taint.TimeAdded == nil {
+		return
+	}
+	candidateSince := taint.TimeAdded.Time
+
+	pre.waitRecordedLock.Lock()
+	defer pre.waitRecordedLock.Unlock()
+	if recorded, ok := pre.waitRecorded[node.Name]; ok && recorded.Equal(candidateSince) {
+		return
+	}
+	pre.waitRecorded[node.Name] = candidateSince
+
+	tags, _ := tag.New(ctx, tag.Upsert(kubernetes.TagResult, outcome))
+	kubernetes.StatRecordForNode(tags, node, kubernetes.MeasurePreActivityWait.M(float64(pre.clock.Now().Sub(candidateSince).Milliseconds())))
+}
+
 func (pre *PreActivitiesPreProcessor) Reset(ctx context.Context, node *corev1.Node) error {
 	activities, err := pre.getActivities(ctx, node)
 	if err != nil {
@@ -119,7 +176,8 @@ func (pre *PreActivitiesPreProcessor) Reset(ctx context.Context, node *corev1.No
 	}
 
 	errors := []error{}
-	for _, item := range activities {
+	reset := []string{}
+	for key, item := range activities {
 		converted, ok := item.sourceObject.(client.Object)
 		if !ok {
 			errors = append(errors, fmt.Errorf("cannot cast source object"))
@@ -132,6 +190,24 @@ func (pre *PreActivitiesPreProcessor) Reset(ctx context.Context, node *corev1.No
 		// In case the node is already gone, we don't care anymore.
 		if err != nil && !apierrors.IsNotFound(err) {
 			errors = append(errors, err)
+			continue
+		}
+		if err == nil && item.state != PreActivityAnnotationNotStarted {
+			reset = append(reset, key)
+		}
+	}
+
+	// the activities will run again, their next outcome has to be recorded
+	pre.waitRecordedLock.Lock()
+	delete(pre.waitRecorded, node.Name)
+	pre.waitRecordedLock.Unlock()
+
+	if len(reset) > 0 {
+		sort.Strings(reset)
+		pre.logger.Info("pre activities reset", "node", node.Name, "activities", reset)
+		pre.eventRecorder.NodeEventf(ctx, node, corev1.EventTypeNormal, eventPreActivityReset, "pre activities reset, they will run again: %s", strings.Join(reset, ", "))
+		for _, callback := range pre.resetCallbacks {
+			callback(ctx, node, reset)
 		}
 	}
 
@@ -10,6 +10,8 @@ import (
 	"github.com/planetlabs/draino/internal/kubernetes/index"
 	"github.com/planetlabs/draino/internal/kubernetes/k8sclient"
 	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -286,6 +288,76 @@ func TestPreActivitiesPreProcessor(t *testing.T) {
 	}
 }
 
+func TestPreActivitiesPreProcessor_WaitMetric(t *testing.T) {
+	waitView := &view.View{
+		Name:        "test_pre_activity_wait",
+		Measure:     kubernetes.MeasurePreActivityWait,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{kubernetes.TagResult},
+	}
+	assert.NoError(t, view.Register(waitView))
+	defer view.Unregister(waitView)
+
+	countFor := func(outcome string) int64 {
+		rows, err := view.RetrieveData(waitView.Name)
+		assert.NoError(t, err)
+		for _, r := range rows {
+			for _, tg := range r.Tags {
+				if tg.Key == kubernetes.TagResult && tg.Value == outcome {
+					return r.Data.(*view.CountData).Value
+				}
+			}
+		}
+		return 0
+	}
+
+	candidateSince := time.Now().Add(-10 * time.Minute)
+	tests := []struct {
+		Name            string
+		State           string
+		ExpectedOutcome string
+	}{
+		{Name: "done", State: PreActivityAnnotationDone, ExpectedOutcome: preActivityOutcomeDone},
+		{Name: "failed", State: PreActivityAnnotationFailed, ExpectedOutcome: preActivityOutcomeFailed},
+		{Name: "timeout", State: PreActivityAnnotationProcessing, ExpectedOutcome: preActivityOutcomeTimeout},
+	}
+
+	logger := logr.Discard()
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			node := createPreActivityNode(createPreActivityNodeOptions{
+				NLATaintSince: candidateSince,
+				preActivities: map[string]string{PreActivityAnnotationPrefix + "foobar": tt.State},
+			})
+			wrapper, err := k8sclient.NewFakeClient(k8sclient.FakeConf{Objects: []runtime.Object{node}})
+			assert.NoError(t, err, "failed to create fake clients")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			idx, err := index.New(ctx, wrapper.GetManagerClient(), wrapper.GetCache(), logger)
+			assert.NoError(t, err, "failed to craete indexer")
+
+			store, closeStore := kubernetes.RunStoreForTest(ctx, fake.NewSimpleClientset(node))
+			defer closeStore()
+
+			ch := make(chan struct{})
+			defer close(ch)
+			wrapper.Start(ch)
+
+			recorder := kubernetes.NewEventRecorder(record.NewFakeRecorder(1000))
+			preProcessor := NewPreActivitiesPreProcessor(wrapper.GetManagerClient(), idx, store, logger, recorder, clock.RealClock{}, time.Minute)
+
+			// the outcome is evaluated at each loop, it must be recorded only once
+			for i := 0; i < 2; i++ {
+				_, _, err := preProcessor.IsDone(ctx, node)
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, int64(1), countFor(tt.ExpectedOutcome))
+		})
+	}
+}
+
 // ATTENTION - Unfortunately, this test cannot cover controller annotations.
 // The problem is that the metadata search algorithm is using the runtime object store to get the ctrl of a given pod, whereas the pre-activity pre processor is using the controller-runtime client.
 // Unfortunately, it's not possible to have a shared object store for both, so the runtime client cannot update the objects of the store.
@@ -307,6 +379,8 @@ func TestPreActivitiesPreProcessor_Reset(t *testing.T) {
 		Name    string
 		Node    *corev1.Node
 		Objects []runtime.Object
+		// ExpectedReset are the activities given to the reset callback, nil if it must not be called
+		ExpectedReset []string
 	}{
 		{
 			Name: "Should reset node annotation",
@@ -315,6 +389,7 @@ func TestPreActivitiesPreProcessor_Reset(t *testing.T) {
 					PreActivityAnnotationPrefix + "foo": PreActivityAnnotationFailed,
 				},
 			}),
+			ExpectedReset: []string{"test-node/foo"},
 		},
 		{
 			Name: "Should reset multiple node annotations with different states",
@@ -325,6 +400,7 @@ func TestPreActivitiesPreProcessor_Reset(t *testing.T) {
 					PreActivityAnnotationPrefix + "third":  PreActivityAnnotationNotStarted,
 				},
 			}),
+			ExpectedReset: []string{"test-node/foo", "test-node/second"},
 		},
 		{
 			Name: "Should reset pod annotations",
@@ -333,7 +409,16 @@ func TestPreActivitiesPreProcessor_Reset(t *testing.T) {
 					PreActivityAnnotationPrefix + "foo": PreActivityAnnotationFailed,
 				},
 			}),
-			Objects: []runtime.Object{podWithKey},
+			Objects:       []runtime.Object{podWithKey},
+			ExpectedReset: []string{"default/with-key/pod", "test-node/foo"},
+		},
+		{
+			Name: "Should not signal a reset if no activity started",
+			Node: createPreActivityNode(createPreActivityNodeOptions{
+				preActivities: map[string]string{
+					PreActivityAnnotationPrefix + "foo": PreActivityAnnotationNotStarted,
+				},
+			}),
 		},
 	}
 
@@ -361,9 +446,22 @@ func TestPreActivitiesPreProcessor_Reset(t *testing.T) {
 			defer close(ch)
 			wrapper.Start(ch)
 
-			preProcessor := NewPreActivitiesPreProcessor(wrapper.GetManagerClient(), idx, store, logger, recorder, clock.RealClock{}, time.Minute)
+			var reset []string
+			callbacks := 0
+			preProcessor := NewPreActivitiesPreProcessor(wrapper.GetManagerClient(), idx, store, logger, recorder, clock.RealClock{}, time.Minute,
+				WithPreActivitiesResetCallback(func(_ context.Context, node *corev1.Node, activities []string) {
+					assert.Equal(t, tt.Node.Name, node.Name)
+					callbacks++
+					reset = activities
+				}))
 			err = preProcessor.Reset(ctx, tt.Node)
 			assert.NoError(t, err, "failed to reset pre activities")
+			if tt.ExpectedReset == nil {
+				assert.Equal(t, 0, callbacks, "the reset callback must not be called")
+			} else {
+				assert.Equal(t, 1, callbacks, "the reset callback must be called once")
+				assert.Equal(t, tt.ExpectedReset, reset)
+			}
 
 			var node corev1.Node
 			err = wrapper.GetManagerClient().Get(ctx, types.NamespacedName{Name: tt.Node.Name}, &node)
@@ -2,14 +2,13 @@ package drain_runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
-
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -27,6 +26,7 @@ import (
 	"github.com/planetlabs/draino/internal/kubernetes/k8sclient"
 	"github.com/planetlabs/draino/internal/metrics"
 	"github.com/planetlabs/draino/internal/protector"
+	"github.com/planetlabs/draino/internal/tracing"
 )
 
 // DrainTimeout how long is it acceptable for a drain to run
@@ -48,10 +48,11 @@ type drainRunner struct {
 	eventRecorder       kubernetes.EventRecorder
 	filter              filters.Filter
 	drainBuffer         drainbuffer.DrainBuffer
-	suppliedConditions  []kubernetes.SuppliedCondition
+	suppliedConditions  kubernetes.SuppliedConditionsGetter
 	nodeReplacer        *preprocessor.NodeReplacer
 	pvcProtector        protector.PVCProtector
 	preprocessors       []preprocessor.DrainPreProcessor
+	drainPause          *kubernetes.DrainPause
 
 	durationWithDrainedStatusBeforeReplacement time.Duration
 }
@@ -68,7 +69,7 @@ func (runner *drainRunner) Run(info *groups.RunnerInfo) error {
 			return
 		}
 
-		span, ctx := tracer.StartSpanFromContext(ctx, "DrainCandidate")
+		span, ctx := tracing.StartSpanFromContext(ctx, "DrainCandidate")
 		defer span.Finish()
 
 		var drainInfo DataInfo
@@ -99,7 +100,7 @@ func (runner *drainRunner) Run(info *groups.RunnerInfo) error {
 // Here we are searching for such cases, and we are sending them back to the pool by removing the taint.
 // These nodes might become candidate again in a near future.
 func (runner *drainRunner) handleLeftOverDraining(ctx context.Context, info *groups.RunnerInfo) {
-	span, ctx := tracer.StartSpanFromContext(ctx, "ResetStuckDrainAttempts")
+	span, ctx := tracing.StartSpanFromContext(ctx, "ResetStuckDrainAttempts")
 	defer span.Finish()
 
 	draining, _, err := runner.getNodesForNLATaint(ctx, info.Key, []k8sclient.DrainTaintValue{k8sclient.TaintDraining})
@@ -121,14 +122,14 @@ func (runner *drainRunner) handleLeftOverDraining(ctx context.Context, info *gro
 			runner.logger.Error(err, "Failed to remove taint on node left over in 'draining'", "node", n.Name)
 			return
 		}
-		CounterDrainedNodes(n, DrainedNodeResultFailed, kubernetes.GetNodeOffendingConditions(n, runner.suppliedConditions), "stuck_in_draining")
+		CounterDrainedNodes(n, DrainedNodeResultFailed, kubernetes.GetNodeOffendingConditions(n, runner.suppliedConditions()), "stuck_in_draining")
 	}
 }
 
 // handlePendingDrainedNodes searches for all drained nodes and triggers a node replacement if they are drained for too long.
 // This might happen in cases where we've reached the min-size of our node group, so the CA cannot shutdown the node.
 func (runner *drainRunner) handlePendingDrainedNodes(ctx context.Context, info *groups.RunnerInfo) {
-	span, ctx := tracer.StartSpanFromContext(ctx, "ReplacePendingDrainedNodes")
+	span, ctx := tracing.StartSpanFromContext(ctx, "ReplacePendingDrainedNodes")
 	defer span.Finish()
 
 	drained, _, err := runner.getNodesForNLATaint(ctx, info.Key, []k8sclient.DrainTaintValue{k8sclient.TaintDrained})
@@ -196,11 +197,19 @@ func (runner *drainRunner) handleGroup(ctx context.Context, info *groups.RunnerI
 // This function concentrates the taint management on the node for the drain_runner.
 // During the pre-activities resolution phase the node keeps its `drain_candidate` taint.
 func (runner *drainRunner) handleCandidate(ctx context.Context, info *groups.RunnerInfo, candidate *corev1.Node) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "HandleDrainCandidate")
+	span, ctx := tracing.StartSpanFromContext(ctx, "HandleDrainCandidate")
 	defer span.Finish()
 
 	loggerForNode := runner.logger.WithValues("node", candidate.Name)
 
+	// While the drains are paused the node keeps its candidate status as is, it is drained once the drains are resumed
+	if runner.drainPause != nil {
+		if paused, reason := runner.drainPause.IsPaused(); paused {
+			loggerForNode.Info("drains are paused, leaving the node in the candidates", "reason", reason)
+			return nil
+		}
+	}
+
 	// Check if the node is still candidate before processing
 	filterOutput := runner.filter.FilterNode(ctx, candidate)
 	if !filterOutput.Keep {
@@ -212,11 +221,12 @@ func (runner *drainRunner) handleCandidate(ctx context.Context, info *groups.Run
 
 	// Checking pre-activities
 	kubernetes.LogrForVerboseNode(runner.logger, candidate, "Node is candidate for drain, checking pre-activities")
-	allPreprocessorsDone, shouldAbort, reason := runner.checkPreprocessors(ctx, candidate, info.Key)
+	allPreprocessorsDone, shouldAbort, reason, pending := runner.checkPreprocessors(ctx, candidate, info.Key)
 	if shouldAbort {
+		candidate = runner.setPreProcessingBlockedReason(ctx, candidate, reason, nil)
 		runner.eventRecorder.NodeEventf(ctx, candidate, core.EventTypeWarning, kubernetes.EventReasonDrainFailed, "Error while waiting for pre conditions: %s", reason)
 		runner.resetPreProcessors(ctx, candidate, info.Key)
-		CounterDrainedNodes(candidate, DrainedNodeResultFailed, kubernetes.GetNodeOffendingConditions(candidate, runner.suppliedConditions), "pre-processing")
+		CounterDrainedNodes(candidate, DrainedNodeResultFailed, kubernetes.GetNodeOffendingConditions(candidate, runner.suppliedConditions()), "pre-processing")
 		newNode, err := runner.updateRetryWallOnCandidate(ctx, candidate, fmt.Sprintf("pre-conditions failed %s", reason), info.Key)
 		if err != nil {
 			return err
@@ -226,10 +236,14 @@ func (runner *drainRunner) handleCandidate(ctx context.Context, info *groups.Run
 	}
 	if !allPreprocessorsDone {
 		loggerForNode.Info("waiting for preprocessors to be done before draining", "node", candidate.Name)
+		if len(pending) > 0 {
+			runner.setPreProcessingBlockedReason(ctx, candidate, "", pending)
+		}
 		return nil
 	}
 
 	loggerForNode.Info("start draining")
+	candidate = runner.clearBlockedReason(ctx, candidate)
 	// Draining a node is a blocking operation. This makes sure that one drain does not affect the other by taking PDB budget.
 	candidate, err := k8sclient.AddNLATaint(ctx, runner.client, candidate, runner.clock.Now(), k8sclient.TaintDraining)
 	if err != nil {
@@ -243,22 +257,37 @@ func (runner *drainRunner) handleCandidate(ctx context.Context, info *groups.Run
 	if errRefresh != nil {
 		if apierrors.IsNotFound(errRefresh) {
 			loggerForNode.Info("node has been deleted while we were waiting for the drain to complete")
-			CounterDrainedNodes(candidate, DrainedNodeResultSucceeded, kubernetes.GetNodeOffendingConditions(candidate, runner.suppliedConditions), "node_deleted")
+			CounterDrainedNodes(candidate, DrainedNodeResultSucceeded, kubernetes.GetNodeOffendingConditions(candidate, runner.suppliedConditions()), "node_deleted")
 			return nil
 		}
 		loggerForNode.Error(errRefresh, "failed to refresh node after drain")
-		CounterDrainedNodes(candidate, DrainedNodeResultFailed, kubernetes.GetNodeOffendingConditions(candidate, runner.suppliedConditions), "node_refresh")
+		CounterDrainedNodes(candidate, DrainedNodeResultFailed, kubernetes.GetNodeOffendingConditions(candidate, runner.suppliedConditions()), "node_refresh")
 		return errRefresh
 	}
+	if errors.As(err, &kubernetes.DrainPausedError{}) {
+		// The drains are globally paused, this is not a failure of the node: it goes back to the candidates
+		// without consuming a retry, it will be drained once the drains are resumed.
+		loggerForNode.Info("drains are paused, putting the node back in the candidates", "reason", err.Error())
+		_, errTaint := k8sclient.AddNLATaint(ctx, runner.client, candidate, runner.clock.Now(), k8sclient.TaintDrainCandidate)
+		return errTaint
+	}
+	if errors.As(err, &kubernetes.ConditionsResolvedError{}) {
+		// The node does not need to be drained anymore, this is not a failure: the candidate status is removed without any retry wall
+		loggerForNode.Info("drain aborted, the node has no offending condition anymore, removing candidate status")
+		runner.resetPreProcessors(ctx, candidate, info.Key)
+		_, errTaint := k8sclient.RemoveNLATaint(ctx, runner.client, candidate)
+		return errTaint
+	}
 	if err != nil {
 		failureCause := kubernetes.GetFailureCause(err)
 		if failureCause == "" {
 			loggerForNode.Error(err, "error doesn't map to a failure cause")
 			failureCause = "undefined"
 		}
-		CounterDrainedNodes(candidate, DrainedNodeResultFailed, kubernetes.GetNodeOffendingConditions(candidate, runner.suppliedConditions), failureCause)
+		CounterDrainedNodes(candidate, DrainedNodeResultFailed, kubernetes.GetNodeOffendingConditions(candidate, runner.suppliedConditions()), failureCause)
 		loggerForNode.Error(err, "failed to drain node", "failure_cause", failureCause)
 		runner.eventRecorder.NodeEventf(ctx, candidate, core.EventTypeWarning, kubernetes.EventReasonDrainFailed, "Drain failed: %v", err)
+		candidate = runner.setDrainBlockedReason(ctx, candidate, err)
 		runner.resetPreProcessors(ctx, candidate, info.Key)
 		updatedNode, errRetryWall := runner.updateRetryWallOnCandidate(ctx, candidate, err.Error(), info.Key)
 		if errRetryWall != nil {
@@ -275,14 +304,15 @@ func (runner *drainRunner) handleCandidate(ctx context.Context, info *groups.Run
 		loggerForNode.Error(err, "Failed to add 'drained' taint")
 		return err
 	}
-	CounterDrainedNodes(candidate, DrainedNodeResultSucceeded, kubernetes.GetNodeOffendingConditions(candidate, runner.suppliedConditions), "")
+	CounterDrainedNodes(candidate, DrainedNodeResultSucceeded, kubernetes.GetNodeOffendingConditions(candidate, runner.suppliedConditions()), "")
 	runner.eventRecorder.NodeEventf(ctx, candidate, core.EventTypeNormal, kubernetes.EventReasonDrainSucceeded, "Drained node")
 	runner.logger.Info("successfully drained node", "node", candidate.Name)
 	return nil
 }
 
-func (runner *drainRunner) checkPreprocessors(ctx context.Context, candidate *corev1.Node, groupKey groups.GroupKey) (allDone bool, shouldAbort bool, abortReason string) {
-	span, ctx := tracer.StartSpanFromContext(ctx, "CheckDrainPreprocessors")
+// checkPreprocessors returns the names of the preprocessors that are still pending, the ones that failed during the evaluation are not listed
+func (runner *drainRunner) checkPreprocessors(ctx context.Context, candidate *corev1.Node, groupKey groups.GroupKey) (allDone bool, shouldAbort bool, abortReason string, pending []string) {
+	span, ctx := tracing.StartSpanFromContext(ctx, "CheckDrainPreprocessors")
 	defer span.Finish()
 
 	allDone = true
@@ -304,6 +334,7 @@ func (runner *drainRunner) checkPreprocessors(ctx context.Context, candidate *co
 		if !done {
 			runner.logger.Info("preprocessor still pending", "node", candidate.Name, "preprocessor", pre.GetName())
 			allDone = false
+			pending = append(pending, pre.GetName())
 		}
 	}
 	return
@@ -311,7 +342,7 @@ func (runner *drainRunner) checkPreprocessors(ctx context.Context, candidate *co
 
 // resetPreProcessors will iterate over all pre processors and call the reset function.
 func (runner *drainRunner) resetPreProcessors(ctx context.Context, candidate *corev1.Node, groupKey groups.GroupKey) {
-	span, ctx := tracer.StartSpanFromContext(ctx, "ResetPreProcessors")
+	span, ctx := tracing.StartSpanFromContext(ctx, "ResetPreProcessors")
 	defer span.Finish()
 
 	for _, pre := range runner.preprocessors {
@@ -350,6 +381,10 @@ func (runner *drainRunner) drainCandidate(ctx context.Context, info *groups.Runn
 	kubernetes.LogrForVerboseNode(runner.logger, candidate, "drainBuffer configuration", "drainBuffer", drainBuffer)
 
 	err = runner.drainer.Drain(drainContext, candidate)
+	if errors.As(err, &kubernetes.DrainPausedError{}) {
+		// the drain did not start, it does not count as an attempt
+		return err
+	}
 	// We can ignore the error as it's only fired when the drain buffer is not initialized.
 	// This cannot happen as the main loop of the drain runner will be blocked in that case.
 	_ = runner.drainBuffer.StoreDrainAttempt(info.Key, drainBuffer)
@@ -362,7 +397,7 @@ func (runner *drainRunner) drainCandidate(ctx context.Context, info *groups.Runn
 }
 
 func (runner *drainRunner) updateRetryWallOnCandidate(ctx context.Context, candidate *corev1.Node, reason string, groupKey groups.GroupKey) (*corev1.Node, error) {
-	span, ctx := tracer.StartSpanFromContext(ctx, "ResetFailedCandidate")
+	span, ctx := tracing.StartSpanFromContext(ctx, "ResetFailedCandidate")
 	defer span.Finish()
 
 	newNode, err := runner.retryWall.SetNewRetryWallTimestamp(ctx, candidate, reason, runner.clock.Now())
@@ -424,7 +459,7 @@ func (runner *drainRunner) handlePVCProtection(ctx context.Context, info *groups
 				continue
 			}
 			runner.eventRecorder.NodeEventf(ctx, node, core.EventTypeWarning, kubernetes.EventReasonPendingPodWithLocalPV, "Pod "+pods[0].Namespace+"/"+pods[0].Name+" needs that node due to local PV, removing taint from the node")
-			CounterDrainedNodes(node, DrainedNodeResultFailed, kubernetes.GetNodeOffendingConditions(node, runner.suppliedConditions), "pvc_protection")
+			CounterDrainedNodes(node, DrainedNodeResultFailed, kubernetes.GetNodeOffendingConditions(node, runner.suppliedConditions()), "pvc_protection")
 		}
 	}
 }
@@ -80,6 +80,23 @@ func TestDrainRunner(t *testing.T) {
 			ShoulHaveTaint:  false,
 			ExpectedRetries: 1,
 		},
+		{
+			Name:            "Should put the node back in the candidates while the drains are paused",
+			Key:             "my-key",
+			Node:            createNode("my-key", k8sclient.TaintDrainCandidate),
+			Drainer:         &errorDrainer{err: kubernetes.DrainPausedError{NodeName: "foo-node"}},
+			ShoulHaveTaint:  true,
+			ExpectedTaint:   k8sclient.TaintDrainCandidate,
+			ExpectedRetries: 0,
+		},
+		{
+			Name:            "Should remove the candidate status without retry if the conditions are resolved",
+			Key:             "my-key",
+			Node:            createNode("my-key", k8sclient.TaintDrainCandidate),
+			Drainer:         &errorDrainer{err: kubernetes.ConditionsResolvedError{NodeName: "foo-node"}},
+			ShoulHaveTaint:  false,
+			ExpectedRetries: 0,
+		},
 		{
 			Name:            "Should ignore node without taint",
 			Key:             "my-key",
@@ -201,3 +218,180 @@ func createNode(key string, taintVal k8sclient.DrainTaintValue) *corev1.Node {
 		},
 	}
 }
+
+func TestDrainRunner_DrainPause(t *testing.T) {
+	testLogger := zapr.NewLogger(zap.NewNop())
+	node := createNode("my-key", k8sclient.TaintDrainCandidate)
+	wrapper, err := k8sclient.NewFakeClient(k8sclient.FakeConf{
+		Objects: []runtime.Object{node},
+		Indexes: []k8sclient.WithIndex{
+			func(_ client.Client, cache cachecr.Cache) error {
+				return groups.InitSchedulingGroupIndexer(cache, groups.NewGroupKeyFromNodeMetadata(nil, testLogger, kubernetes.NoopEventRecorder{}, nil, nil, []string{"key"}, nil, ""))
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	ch := make(chan struct{})
+	defer close(ch)
+	pause := kubernetes.NewDrainPause()
+	pause.SetPaused(true, "maintenance")
+	drainer := &countingDrainer{}
+	runner, err := NewFakeRunner(&FakeOptions{
+		Chan:          ch,
+		ClientWrapper: wrapper,
+		Drainer:       drainer,
+		DrainPause:    pause,
+	})
+	assert.NoError(t, err, "failed to create fake drain runner")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	runner.handleGroup(ctx, &groups.RunnerInfo{Context: ctx, Key: "my-key"})
+	assert.NoError(t, ctx.Err(), "context reached deadline")
+
+	// the candidate is left untouched and the drain is not attempted
+	var got corev1.Node
+	assert.NoError(t, wrapper.GetManagerClient().Get(context.Background(), types.NamespacedName{Name: node.Name}, &got))
+	taint, exist := k8sclient.GetNLATaint(&got)
+	if assert.True(t, exist) {
+		assert.Equal(t, k8sclient.TaintDrainCandidate, taint.Value)
+		initialTaint, _ := k8sclient.GetNLATaint(node)
+		assert.Equal(t, initialTaint.TimeAdded.Unix(), taint.TimeAdded.Unix())
+	}
+	assert.Zero(t, drainer.calls)
+	nextDrain, err := runner.drainBuffer.NextDrain("my-key")
+	assert.NoError(t, err)
+	assert.True(t, nextDrain.IsZero(), "no drain attempt must be stored")
+}
+
+type countingDrainer struct {
+	kubernetes.NoopDrainer
+	calls int
+}
+
+func (d *countingDrainer) Drain(ctx context.Context, n *v1.Node) error {
+	d.calls++
+	return nil
+}
+
+type errorDrainer struct {
+	kubernetes.NoopDrainer
+	err error
+}
+
+func (d *errorDrainer) Drain(ctx context.Context, n *v1.Node) error { return d.err }
+
+type abortingPreprocessor struct {
+	reason preprocessor.PreProcessNotDoneReason
+}
+
+func (_ *abortingPreprocessor) GetName() string {
+	return "abortingPreprocessor"
+}
+
+func (p *abortingPreprocessor) IsDone(ctx context.Context, node *corev1.Node) (bool, preprocessor.PreProcessNotDoneReason, error) {
+	return false, p.reason, nil
+}
+
+func (p *abortingPreprocessor) Reset(ctx context.Context, node *corev1.Node) error {
+	return nil
+}
+
+func TestDrainRunner_BlockedReason(t *testing.T) {
+	withBlockedReason := func(node *corev1.Node) *corev1.Node {
+		node.Annotations = map[string]string{BlockedReasonAnnotationKey: `{"blocker":"drain","cause":"pod_eviction_timeout_kubeapi","message":"timed out","timestamp":"2022-01-01T00:00:00Z"}`}
+		return node
+	}
+	tests := []struct {
+		Name          string
+		Node          *corev1.Node
+		Preprocessors []preprocessor.DrainPreProcessor
+		Drainer       kubernetes.Drainer
+
+		ExpectedReason *BlockedReason
+	}{
+		{
+			Name:           "Pending pre-activity",
+			Node:           createNode("my-key", k8sclient.TaintDrainCandidate),
+			Preprocessors:  []preprocessor.DrainPreProcessor{&testPreprocessor{isDone: false}},
+			Drainer:        &kubernetes.NoopDrainer{},
+			ExpectedReason: &BlockedReason{Blocker: BlockerPreProcessing, Cause: "processing", Message: "waiting for pre-activities: testPreprocessor"},
+		},
+		{
+			Name:           "Failed pre-activity",
+			Node:           createNode("my-key", k8sclient.TaintDrainCandidate),
+			Preprocessors:  []preprocessor.DrainPreProcessor{&abortingPreprocessor{reason: preprocessor.PreProcessNotDoneReasonTimeout}},
+			Drainer:        &kubernetes.NoopDrainer{},
+			ExpectedReason: &BlockedReason{Blocker: BlockerPreProcessing, Cause: "timeout", Message: "pre-conditions failed: timeout"},
+		},
+		{
+			Name:           "Drain blocked by PDB",
+			Node:           createNode("my-key", k8sclient.TaintDrainCandidate),
+			Drainer:        &errorDrainer{err: kubernetes.OverlappingDisruptionBudgetsError{}},
+			ExpectedReason: &BlockedReason{Blocker: BlockerDrain, Cause: string(kubernetes.OverlappingPodDisruptionBudgets), Message: "overlapping pod disruption budgets"},
+		},
+		{
+			Name:           "Drain blocked by eviction endpoint error",
+			Node:           withBlockedReason(createNode("my-key", k8sclient.TaintDrainCandidate)),
+			Drainer:        &errorDrainer{err: kubernetes.EvictionEndpointError{StatusCode: 500}},
+			ExpectedReason: &BlockedReason{Blocker: BlockerDrain, Cause: "eviction_endpoint_500", Message: "eviction endpoint error: code=500"},
+		},
+		{
+			Name:    "Resolved conditions do not block the node",
+			Node:    withBlockedReason(createNode("my-key", k8sclient.TaintDrainCandidate)),
+			Drainer: &errorDrainer{err: kubernetes.ConditionsResolvedError{}},
+		},
+		{
+			Name:    "Successful drain clears the reason",
+			Node:    withBlockedReason(createNode("my-key", k8sclient.TaintDrainCandidate)),
+			Drainer: &kubernetes.NoopDrainer{},
+		},
+	}
+	testLogger := zapr.NewLogger(zap.NewNop())
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			wrapper, err := k8sclient.NewFakeClient(k8sclient.FakeConf{
+				Objects: []runtime.Object{tt.Node},
+				Indexes: []k8sclient.WithIndex{
+					func(_ client.Client, cache cachecr.Cache) error {
+						return groups.InitSchedulingGroupIndexer(cache, groups.NewGroupKeyFromNodeMetadata(nil, testLogger, kubernetes.NoopEventRecorder{}, nil, nil, []string{"key"}, nil, ""))
+					},
+				},
+			})
+			assert.NoError(t, err)
+
+			ch := make(chan struct{})
+			defer close(ch)
+			runner, err := NewFakeRunner(&FakeOptions{
+				Chan:          ch,
+				ClientWrapper: wrapper,
+				Preprocessors: tt.Preprocessors,
+				Drainer:       tt.Drainer,
+			})
+			assert.NoError(t, err, "failed to create fake drain runner")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			runner.handleGroup(ctx, &groups.RunnerInfo{Context: ctx, Key: "my-key"})
+			assert.NoError(t, ctx.Err(), "context reached deadline")
+
+			var node corev1.Node
+			err = wrapper.GetManagerClient().Get(context.Background(), types.NamespacedName{Name: tt.Node.Name}, &node)
+			assert.NoError(t, err)
+
+			reason, err := GetBlockedReason(&node)
+			assert.NoError(t, err)
+			if tt.ExpectedReason == nil {
+				assert.Nil(t, reason)
+				return
+			}
+			if assert.NotNil(t, reason) {
+				assert.False(t, reason.Timestamp.IsZero())
+				reason.Timestamp = metav1.Time{}
+				assert.Equal(t, tt.ExpectedReason, reason)
+			}
+		})
+	}
+}
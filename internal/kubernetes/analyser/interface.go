@@ -2,6 +2,7 @@ package analyser
 
 import (
 	"context"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
@@ -21,4 +22,8 @@ type PDBAnalyser interface {
 
 	// CompareNode return true if the node n1 should be drained in priority compared to node n2, because of budget being taking there
 	CompareNode(n1, n2 *corev1.Node) bool
+
+	// EstimatePDBWait returns how long the pods warming up on the given node are still going to take the disruption budgets.
+	// The pods blocking the budgets for another reason are ignored, their wait cannot be estimated.
+	EstimatePDBWait(ctx context.Context, nodeName string) (time.Duration, error)
 }
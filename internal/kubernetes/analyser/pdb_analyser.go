@@ -117,6 +117,46 @@ func (a *pdbAnalyserImpl) BlockingPodsOnNode(ctx context.Context, nodeName strin
 	return blockingPods, nil
 }
 
+func (a *pdbAnalyserImpl) EstimatePDBWait(ctx context.Context, nodeName string) (time.Duration, error) {
+	blockingPods, err := a.BlockingPodsOnNode(ctx, nodeName)
+	if err != nil {
+		return 0, err
+	}
+	var max time.Duration
+	for _, b := range blockingPods {
+		if !a.isWarmingUpPods(b) {
+			continue
+		}
+		if d := a.remainingWarmUpDelay(b.Pod); d > max {
+			max = d
+		}
+	}
+	return max, nil
+}
+
+// remainingWarmUpDelay returns the time left before all the containers of the pod are warmed up.
+// The containers that are not running yet are given their full warm-up delay.
+func (a *pdbAnalyserImpl) remainingWarmUpDelay(pod *corev1.Pod) (max time.Duration) {
+	now := a.clock.Now()
+	started := make(map[string]time.Time, len(pod.Status.ContainerStatuses))
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Terminated == nil && cs.State.Running != nil {
+			started[cs.Name] = cs.State.Running.StartedAt.Time
+		}
+	}
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		d := getContainerWarmUpDelay(c) + a.podWarmupDelayExtension
+		if startedAt, ok := started[c.Name]; ok {
+			d = startedAt.Add(d).Sub(now)
+		}
+		if d > max {
+			max = d
+		}
+	}
+	return max
+}
+
 func (a *pdbAnalyserImpl) removeTransientBlockingStates(b []BlockingPod) []BlockingPod {
 	result := make([]BlockingPod, 0, len(b))
 	for _, p := range b {
@@ -246,11 +286,29 @@ func getMaxProbeDelay(probes []*corev1.Probe) (max time.Duration) {
 	return
 }
 
+// PodTakingPDBBudgetFunc tells if the pod is not counted in the healthy pods of its PDB, its eviction does not consume any more budget
+type PodTakingPDBBudgetFunc func(pod *corev1.Pod) bool
+
+// PodTakingPDBBudgetIfNotReady is the default PodTakingPDBBudgetFunc, the pods that are not ready are taking budget
+func PodTakingPDBBudgetIfNotReady(pod *corev1.Pod) bool {
+	return !utils.IsPodReady(pod)
+}
+
+// PodTakingPDBBudgetIfNotReadyOrTerminating also considers that the terminating pods are taking budget, even if they are still ready
+func PodTakingPDBBudgetIfNotReadyOrTerminating(pod *corev1.Pod) bool {
+	return pod.DeletionTimestamp != nil || !utils.IsPodReady(pod)
+}
+
 func IsPDBBlockedByPod(ctx context.Context, pod *corev1.Pod, pdb *policyv1.PodDisruptionBudget) bool {
+	return IsPDBBlockedByPodWithBudgetFunc(ctx, pod, pdb, PodTakingPDBBudgetIfNotReady)
+}
+
+// IsPDBBlockedByPodWithBudgetFunc is IsPDBBlockedByPod with a custom way of telling if the pod is already taking budget from the PDB
+func IsPDBBlockedByPodWithBudgetFunc(ctx context.Context, pod *corev1.Pod, pdb *policyv1.PodDisruptionBudget, takingBudget PodTakingPDBBudgetFunc) bool {
 	// If the pod is not ready it's already taking budget from the PDB
 	// If the remaining budget is still positive or zero, it's fine
 	var podTakingBudget int32 = 0
-	if !utils.IsPodReady(pod) {
+	if takingBudget(pod) {
 		podTakingBudget = 1
 	}
 
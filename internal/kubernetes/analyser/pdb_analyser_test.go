@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"k8s.io/utils/clock"
+	testingclock "k8s.io/utils/clock/testing"
 
 	"github.com/go-logr/zapr"
 	"go.uber.org/zap"
@@ -214,6 +215,43 @@ func TestPDBAnalyser_IsPDBBlocked(t *testing.T) {
 	}
 }
 
+func TestPDBAnalyser_IsPDBBlockedByPodWithBudgetFunc(t *testing.T) {
+	terminatingPod := createPodWithStatus(true)
+	terminatingPod.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	tests := []struct {
+		Name         string
+		IsBlocked    bool
+		Pod          *corev1.Pod
+		TakingBudget PodTakingPDBBudgetFunc
+	}{
+		{
+			Name:         "Terminating pod counted as healthy by default",
+			IsBlocked:    true,
+			Pod:          terminatingPod,
+			TakingBudget: PodTakingPDBBudgetIfNotReady,
+		},
+		{
+			Name:         "Terminating pod excluded from the healthy pods",
+			IsBlocked:    false,
+			Pod:          terminatingPod,
+			TakingBudget: PodTakingPDBBudgetIfNotReadyOrTerminating,
+		},
+		{
+			Name:         "Running pod still blocked when excluding the terminating pods",
+			IsBlocked:    true,
+			Pod:          createPodWithStatus(true),
+			TakingBudget: PodTakingPDBBudgetIfNotReadyOrTerminating,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			res := IsPDBBlockedByPodWithBudgetFunc(context.Background(), tt.Pod, createPDBWithStatus(1, 1), tt.TakingBudget)
+			assert.Equal(t, tt.IsBlocked, res)
+		})
+	}
+}
+
 func createNode(name string) *corev1.Node {
 	return &corev1.Node{
 		TypeMeta: metav1.TypeMeta{
@@ -289,3 +327,42 @@ func createPDBWithStatus(des, healthy int32) *policyv1.PodDisruptionBudget {
 func createPodWithStatus(isReady bool) *corev1.Pod {
 	return createPod("test", "test", "test", isReady, map[string]string{})
 }
+
+func TestPDBAnalyser_RemainingWarmUpDelay(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	probe := &corev1.Probe{InitialDelaySeconds: 60, PeriodSeconds: 10, SuccessThreshold: 1} // 70s
+	tests := []struct {
+		Name     string
+		Status   []corev1.ContainerStatus
+		Expected time.Duration
+	}{
+		{
+			Name:     "container not running yet gets the full delay",
+			Expected: 70*time.Second + time.Second,
+		},
+		{
+			Name: "running container gets the time left",
+			Status: []corev1.ContainerStatus{
+				{Name: "c", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(now.Add(-30 * time.Second))}}},
+			},
+			Expected: 40*time.Second + time.Second,
+		},
+		{
+			Name: "warmed up container",
+			Status: []corev1.ContainerStatus{
+				{Name: "c", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(now.Add(-5 * time.Minute))}}},
+			},
+			Expected: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			a := &pdbAnalyserImpl{clock: testingclock.NewFakeClock(now), podWarmupDelayExtension: time.Second}
+			pod := &corev1.Pod{
+				Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "c", StartupProbe: probe}}},
+				Status: corev1.PodStatus{ContainerStatuses: tt.Status},
+			}
+			assert.Equal(t, tt.Expected, a.remainingWarmUpDelay(pod))
+		})
+	}
+}
@@ -55,6 +55,16 @@ func GetNodeOffendingConditions(n *core.Node, suppliedConditions []SuppliedCondi
 	return conditions
 }
 
+// IsNodeDrainCandidate tells if the node matches at least one of the supplied conditions that is accepted by the node.
+// The offending conditions are always returned, even if none of them is accepted by the node.
+func IsNodeDrainCandidate(n *core.Node, suppliedConditions []SuppliedCondition) (bool, []SuppliedCondition) {
+	conditions := GetNodeOffendingConditions(n, suppliedConditions)
+	if len(conditions) == 0 {
+		return false, nil
+	}
+	return AtLeastOneConditionAcceptedByTheNode(GetConditionsTypes(conditions), n), conditions
+}
+
 func IsOverdue(n *core.Node, suppliedCondition SuppliedCondition) bool {
 	for _, nodeCondition := range n.Status.Conditions {
 		if suppliedCondition.Type == nodeCondition.Type &&
@@ -66,6 +76,22 @@ func IsOverdue(n *core.Node, suppliedCondition SuppliedCondition) bool {
 	return false
 }
 
+// OffendingCondition is the structured description of a node condition that triggered the drain
+type OffendingCondition struct {
+	Type   core.NodeConditionType `json:"type"`
+	Status core.ConditionStatus   `json:"status"`
+}
+
+// FormatOffendingConditions serializes the conditions in JSON, so that they can be parsed without splitting strings
+func FormatOffendingConditions(conditions []SuppliedCondition) (string, error) {
+	offending := make([]OffendingCondition, len(conditions))
+	for i := range conditions {
+		offending[i] = OffendingCondition{Type: conditions[i].Type, Status: conditions[i].Status}
+	}
+	b, err := json.Marshal(offending)
+	return string(b), err
+}
+
 func GetConditionsTypes(conditions []SuppliedCondition) []string {
 	result := make([]string, len(conditions))
 	for i := range conditions {
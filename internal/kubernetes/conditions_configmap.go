@@ -0,0 +1,164 @@
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ConditionsConfigMapKey is the key of the configmap data holding the conditions.
+// There is one condition per line, using the same format as the --node-conditions flag.
+const ConditionsConfigMapKey = "conditions"
+
+// SuppliedConditionsSetter is implemented by the components that accept a new list of conditions at runtime
+type SuppliedConditionsSetter interface {
+	SetSuppliedConditions(conditions []SuppliedCondition)
+}
+
+// SuppliedConditionsSetterFunc adapts a function to the SuppliedConditionsSetter interface
+type SuppliedConditionsSetterFunc func(conditions []SuppliedCondition)
+
+func (f SuppliedConditionsSetterFunc) SetSuppliedConditions(conditions []SuppliedCondition) {
+	f(conditions)
+}
+
+// SuppliedConditionsGetter returns the current conditions for which draino is triggered
+type SuppliedConditionsGetter func() []SuppliedCondition
+
+// SuppliedConditionsStore holds the conditions for which draino is triggered. It is shared, through the GlobalConfig,
+// by all the components so that the conditions reloaded at runtime apply to all of them.
+type SuppliedConditionsStore struct {
+	sync.RWMutex
+	conditions []SuppliedCondition
+}
+
+var _ SuppliedConditionsSetter = &SuppliedConditionsStore{}
+
+// NewSuppliedConditionsStore returns a store holding the given conditions
+func NewSuppliedConditionsStore(conditions []SuppliedCondition) *SuppliedConditionsStore {
+	return &SuppliedConditionsStore{conditions: conditions}
+}
+
+// GetSuppliedConditions returns the current conditions
+func (s *SuppliedConditionsStore) GetSuppliedConditions() []SuppliedCondition {
+	s.RLock()
+	defer s.RUnlock()
+	return s.conditions
+}
+
+// SetSuppliedConditions replaces the conditions
+func (s *SuppliedConditionsStore) SetSuppliedConditions(conditions []SuppliedCondition) {
+	s.Lock()
+	defer s.Unlock()
+	s.conditions = conditions
+}
+
+// ConditionsConfigMapWatch watches a configmap and pushes the conditions it contains to the registered setters
+type ConditionsConfigMapWatch struct {
+	cache.SharedInformer
+	name    string
+	logger  *zap.Logger
+	setters []SuppliedConditionsSetter
+}
+
+// NewConditionsConfigMapWatch creates a watch on the configmap namespace/name. Each time the configmap is
+// created or updated, the conditions are parsed and given to the setters.
+func NewConditionsConfigMapWatch(ctx context.Context, c kubernetes.Interface, namespace, name string, logger *zap.Logger, setters ...SuppliedConditionsSetter) *ConditionsConfigMapWatch {
+	w := &ConditionsConfigMapWatch{
+		SharedInformer: newConfigMapInformer(ctx, c, namespace, name),
+		name:           name,
+		logger:         logger.With(zap.String("configmap", namespace+"/"+name)),
+		setters:        setters,
+	}
+	w.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: w.onChange,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// The resyncs of the informer give the same configmap again: reloading it would reset the rate limiters
+			if conditionsConfigMapUnchanged(oldObj, newObj) {
+				return
+			}
+			w.onChange(newObj)
+		},
+	})
+	return w
+}
+
+// conditionsConfigMapUnchanged returns true if the update does not change the resourceVersion or the conditions of the configmap
+func conditionsConfigMapUnchanged(oldObj, newObj interface{}) bool {
+	oldCM, ok := oldObj.(*core.ConfigMap)
+	if !ok {
+		return false
+	}
+	newCM, ok := newObj.(*core.ConfigMap)
+	if !ok {
+		return false
+	}
+	if oldCM.ResourceVersion != "" && oldCM.ResourceVersion == newCM.ResourceVersion {
+		return true
+	}
+	return oldCM.Data[ConditionsConfigMapKey] == newCM.Data[ConditionsConfigMapKey]
+}
+
+// newConfigMapInformer returns an informer on the single configmap namespace/name
+func newConfigMapInformer(ctx context.Context, c kubernetes.Interface, namespace, name string) cache.SharedInformer {
+	nameSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(o meta.ListOptions) (runtime.Object, error) {
+			o.FieldSelector = nameSelector
+			return c.CoreV1().ConfigMaps(namespace).List(ctx, o)
+		},
+		WatchFunc: func(o meta.ListOptions) (watch.Interface, error) {
+			o.FieldSelector = nameSelector
+			return c.CoreV1().ConfigMaps(namespace).Watch(ctx, o)
+		},
+	}
+	return cache.NewSharedInformer(lw, &core.ConfigMap{}, 30*time.Minute)
+}
+
+func (w *ConditionsConfigMapWatch) Start(ctx context.Context) {
+	w.Run(ctx.Done())
+}
+
+func (w *ConditionsConfigMapWatch) onChange(obj interface{}) {
+	cm, ok := obj.(*core.ConfigMap)
+	if !ok || cm.Name != w.name {
+		return
+	}
+	conditions, err := ParseConditionsFromConfigMap(cm)
+	if err != nil {
+		w.logger.Error("Ignoring conditions update, the configmap content is not valid", zap.Error(err))
+		return
+	}
+	if len(conditions) == 0 {
+		w.logger.Warn("Ignoring conditions update, the configmap does not contain any condition")
+		return
+	}
+	w.logger.Info("Reloading conditions from configmap", zap.Strings("conditions", GetConditionsTypes(conditions)))
+	for _, s := range w.setters {
+		s.SetSuppliedConditions(conditions)
+	}
+}
+
+// ParseConditionsFromConfigMap parses the conditions stored under ConditionsConfigMapKey
+func ParseConditionsFromConfigMap(cm *core.ConfigMap) ([]SuppliedCondition, error) {
+	var conditions []string
+	for _, line := range strings.Split(cm.Data[ConditionsConfigMapKey], "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			conditions = append(conditions, line)
+		}
+	}
+	// Sanitize user input, same as what is done for the flag
+	sort.Strings(conditions)
+	return ParseConditions(conditions)
+}
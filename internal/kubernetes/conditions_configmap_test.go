@@ -0,0 +1,91 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestConditionsConfigMapWatch(t *testing.T) {
+	node := &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: nodeName},
+		Status: core.NodeStatus{Conditions: []core.NodeCondition{
+			{Type: "Cool", Status: core.ConditionTrue},
+			{Type: "Rad", Status: core.ConditionTrue},
+		}},
+	}
+	cm := &core.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{Name: "draino-conditions", Namespace: "draino"},
+		Data:       map[string]string{ConditionsConfigMapKey: "Cool\n"},
+	}
+	initialConditions, err := ParseConditions([]string{"Other"})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	kclient := fake.NewSimpleClientset(cm)
+	d := NewAPIDrainer(kclient, NewEventRecorder(&record.FakeRecorder{}), WithGlobalConfig(GlobalConfig{SuppliedConditions: initialConditions}))
+	assert.Empty(t, d.GetNodeOffendingConditions(node))
+
+	watch := NewConditionsConfigMapWatch(ctx, kclient, cm.Namespace, cm.Name, zap.NewNop(), d)
+	go watch.Start(ctx)
+
+	offendingTypes := func() []string { return GetConditionsTypes(d.GetNodeOffendingConditions(node)) }
+	waitForTypes := func(expected []string) {
+		err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			return assert.ObjectsAreEqual(expected, offendingTypes()), nil
+		})
+		assert.NoError(t, err, "expected %v, got %v", expected, offendingTypes())
+	}
+	waitForTypes([]string{"Cool"})
+
+	// Update the configmap: new conditions must be used
+	cm.Data[ConditionsConfigMapKey] = "Rad={\"delay\":\"0s\"}\nCool\n"
+	_, err = kclient.CoreV1().ConfigMaps(cm.Namespace).Update(ctx, cm, meta.UpdateOptions{})
+	assert.NoError(t, err)
+	waitForTypes([]string{"Cool", "Rad"})
+
+	// Invalid content is ignored, previous conditions are kept
+	cm.Data[ConditionsConfigMapKey] = "Rad={not json"
+	_, err = kclient.CoreV1().ConfigMaps(cm.Namespace).Update(ctx, cm, meta.UpdateOptions{})
+	assert.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, []string{"Cool", "Rad"}, offendingTypes())
+}
+
+func TestSuppliedConditionsStore_SharedByGlobalConfig(t *testing.T) {
+	initialConditions, err := ParseConditions([]string{"Other"})
+	assert.NoError(t, err)
+	newConditions, err := ParseConditions([]string{"Cool"})
+	assert.NoError(t, err)
+
+	globalConfig := GlobalConfig{SuppliedConditions: initialConditions, SuppliedConditionsStore: NewSuppliedConditionsStore(initialConditions)}
+	d := NewAPIDrainer(fake.NewSimpleClientset(), NewEventRecorder(&record.FakeRecorder{}), WithGlobalConfig(globalConfig))
+	getter := SuppliedConditionsGetter(globalConfig.GetSuppliedConditions)
+
+	// The copies of the global configuration see the conditions set on the drainer, and the other way around
+	d.SetSuppliedConditions(newConditions)
+	assert.Equal(t, []string{"Cool"}, GetConditionsTypes(getter()))
+	globalConfig.SuppliedConditionsStore.SetSuppliedConditions(initialConditions)
+	assert.Equal(t, []string{"Other"}, GetConditionsTypes(d.getSuppliedConditions()))
+}
+
+func TestConditionsConfigMapUnchanged(t *testing.T) {
+	cm := func(resourceVersion, conditions string) *core.ConfigMap {
+		return &core.ConfigMap{
+			ObjectMeta: meta.ObjectMeta{Name: "draino-conditions", ResourceVersion: resourceVersion},
+			Data:       map[string]string{ConditionsConfigMapKey: conditions},
+		}
+	}
+	assert.True(t, conditionsConfigMapUnchanged(cm("1", "Cool"), cm("1", "Cool")), "resync")
+	assert.True(t, conditionsConfigMapUnchanged(cm("1", "Cool"), cm("2", "Cool")), "same conditions")
+	assert.False(t, conditionsConfigMapUnchanged(cm("1", "Cool"), cm("2", "Rad")), "new conditions")
+}
@@ -150,3 +150,70 @@ func TestOffendingConditions(t *testing.T) {
 		})
 	}
 }
+
+func TestIsNodeDrainCandidate(t *testing.T) {
+	cases := []struct {
+		name               string
+		obj                *core.Node
+		conditions         []string
+		expectedCandidate  bool
+		expectedConditions []string
+	}{
+		{
+			name: "MultipleMatchingConditions",
+			obj: &core.Node{
+				ObjectMeta: meta.ObjectMeta{Name: nodeName},
+				Status: core.NodeStatus{Conditions: []core.NodeCondition{
+					{Type: "Cool", Status: core.ConditionTrue},
+					{Type: "Rad", Status: core.ConditionTrue},
+					{Type: "Other", Status: core.ConditionFalse},
+				}},
+			},
+			conditions:         []string{"Cool", "Other", "Rad"},
+			expectedCandidate:  true,
+			expectedConditions: []string{"Cool", "Rad"},
+		},
+		{
+			name: "NoMatchingCondition",
+			obj: &core.Node{
+				ObjectMeta: meta.ObjectMeta{Name: nodeName},
+				Status: core.NodeStatus{Conditions: []core.NodeCondition{
+					{Type: "Cool", Status: core.ConditionFalse},
+				}},
+			},
+			conditions:         []string{"Cool", "Rad"},
+			expectedCandidate:  false,
+			expectedConditions: []string{},
+		},
+		{
+			name: "MatchingConditionNotAllowedByTheNode",
+			obj: &core.Node{
+				ObjectMeta: meta.ObjectMeta{Name: nodeName, Annotations: map[string]string{allowedConditionAnnotationKey: "Rad"}},
+				Status: core.NodeStatus{Conditions: []core.NodeCondition{
+					{Type: "Cool", Status: core.ConditionTrue},
+				}},
+			},
+			conditions:         []string{"Cool", "Rad"},
+			expectedCandidate:  false,
+			expectedConditions: []string{"Cool"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			suppliedConditions, err := ParseConditions(tc.conditions)
+			if err != nil {
+				t.Errorf(err.Error())
+				return
+			}
+
+			candidate, badConditions := IsNodeDrainCandidate(tc.obj, suppliedConditions)
+			if candidate != tc.expectedCandidate {
+				t.Errorf("IsNodeDrainCandidate(tc.obj): want %v, got %v", tc.expectedCandidate, candidate)
+			}
+			if types := GetConditionsTypes(badConditions); !reflect.DeepEqual(types, tc.expectedConditions) {
+				t.Errorf("IsNodeDrainCandidate(tc.obj) conditions: want %#v, got %#v", tc.expectedConditions, types)
+			}
+		})
+	}
+}
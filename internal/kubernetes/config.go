@@ -1,6 +1,11 @@
 package kubernetes
 
-import "context"
+import (
+	"context"
+	"time"
+
+	core "k8s.io/api/core/v1"
+)
 
 type GlobalConfig struct {
 	// Main context
@@ -10,8 +15,62 @@ type GlobalConfig struct {
 	ConfigName string
 
 	// PVCManagementEnableIfNoEvictionUrl PVC management is enabled by default if there is no evictionURL defined
+	// It is ignored when DefaultPVCCleanup is set.
 	PVCManagementEnableIfNoEvictionUrl bool
 
+	// DefaultPVCCleanup, when set, is the PVC management of the pods without the PVCStorageClassCleanupAnnotationKey annotation,
+	// whether they use an evictionURL or not
+	DefaultPVCCleanup *bool
+
+	// EvictionEndpointResolver gives the custom eviction endpoint of the pods that are not annotated with EvictionAPIURLAnnotationKey
+	EvictionEndpointResolver EvictionEndpointResolver
+
 	// SuppliedConditions List of conditions that the controller should react on
 	SuppliedConditions []SuppliedCondition
+
+	// SuppliedConditionsStore, when set, holds the conditions in place of SuppliedConditions so that they can be reloaded at runtime.
+	// The components must read the conditions with GetSuppliedConditions.
+	SuppliedConditionsStore *SuppliedConditionsStore
+
+	// MinEvictionTimeout, EvictionHeadroom and PVCRecreateTimeout are the defaults of the drainer timings, a zero value keeps the drainer default.
+	// They are overridden by the MaxGracePeriod, EvictionHeadroom and WithPVCRecreateTimeout options, whatever the order of the options.
+	MinEvictionTimeout time.Duration
+	EvictionHeadroom   time.Duration
+	PVCRecreateTimeout time.Duration
+}
+
+// GetSuppliedConditions returns the current conditions that the controller should react on
+func (c GlobalConfig) GetSuppliedConditions() []SuppliedCondition {
+	if c.SuppliedConditionsStore != nil {
+		return c.SuppliedConditionsStore.GetSuppliedConditions()
+	}
+	return c.SuppliedConditions
+}
+
+// PVCCleanupDefaultFunc returns the PVC management of a pod that does not have the PVCStorageClassCleanupAnnotationKey annotation
+type PVCCleanupDefaultFunc func(p *core.Pod, store RuntimeObjectStore) bool
+
+// PVCCleanupDefault returns the PVC management of a pod that does not have the PVCStorageClassCleanupAnnotationKey annotation:
+// DefaultPVCCleanup if it is set, else the PVCManagementEnableIfNoEvictionUrl behavior.
+func (c GlobalConfig) PVCCleanupDefault(p *core.Pod, store RuntimeObjectStore) bool {
+	if c.DefaultPVCCleanup != nil {
+		return *c.DefaultPVCCleanup
+	}
+	return PVCCleanupDefaultIfNoEvictionURL(c.PVCManagementEnableIfNoEvictionUrl, c.EvictionEndpointResolver)(p, store)
+}
+
+// PVCCleanupDefaultIfNoEvictionURL returns the default enabling the PVC management, if enabled is set, only for the pods without evictionURL,
+// whether it comes from the annotation or from the resolver
+func PVCCleanupDefaultIfNoEvictionURL(enabled bool, resolver EvictionEndpointResolver) PVCCleanupDefaultFunc {
+	return func(p *core.Pod, store RuntimeObjectStore) bool {
+		if !enabled {
+			return false
+		}
+		return !HasEvictionEndpoint(p, store, resolver)
+	}
+}
+
+// IsPVCCleanupEnabled returns the PVC management of the pod: its annotation, or the default of the configuration
+func (c GlobalConfig) IsPVCCleanupEnabled(p *core.Pod, store RuntimeObjectStore) bool {
+	return PVCStorageClassCleanupEnabled(p, store, c.PVCCleanupDefault(p, store))
 }
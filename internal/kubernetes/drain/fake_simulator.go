@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/planetlabs/draino/internal/kubernetes"
+	"github.com/planetlabs/draino/internal/kubernetes/analyser"
 	"github.com/planetlabs/draino/internal/kubernetes/index"
 	"github.com/planetlabs/draino/internal/kubernetes/k8sclient"
 	"github.com/planetlabs/draino/internal/kubernetes/utils"
@@ -20,6 +21,14 @@ type FakeSimulatorOptions struct {
 	CacheTTL        *time.Duration
 	RateLimiter     limit.RateLimiter
 	Clock           clock.Clock
+	Concurrency     int
+	AnnotateNode    bool
+	// RateLimitByPodCount reserves the rate limiting budget of all the pods of a node before simulating it
+	RateLimitByPodCount bool
+	// PodTakingPDBBudget defaults to analyser.PodTakingPDBBudgetIfNotReady
+	PodTakingPDBBudget analyser.PodTakingPDBBudgetFunc
+	// NegativeCacheTTLs overrides NegativeCacheResTTL for some categories of negative results
+	NegativeCacheTTLs map[NegativeReasonCategory]time.Duration
 
 	Objects   []runtime.Object
 	PodFilter kubernetes.PodFilterFunc
@@ -38,6 +47,12 @@ func (opts *FakeSimulatorOptions) applyDefaults() {
 	if opts.RateLimiter == nil {
 		opts.RateLimiter = limit.NewRateLimiter(opts.Clock, 100, 100)
 	}
+	if opts.Concurrency == 0 {
+		opts.Concurrency = 1
+	}
+	if opts.PodTakingPDBBudget == nil {
+		opts.PodTakingPDBBudget = analyser.PodTakingPDBBudgetIfNotReady
+	}
 }
 
 func NewFakeDrainSimulator(opts *FakeSimulatorOptions) (DrainSimulator, error) {
@@ -56,14 +71,19 @@ func NewFakeDrainSimulator(opts *FakeSimulatorOptions) (DrainSimulator, error) {
 	wrapper.Start(opts.Chan)
 
 	simulator := &drainSimulatorImpl{
-		podIndexer:     fakeIndexer,
-		pdbIndexer:     fakeIndexer,
-		client:         wrapper.GetManagerClient(),
-		podResultCache: utils.NewTTLCache[simulationResult](*opts.CacheTTL, *opts.CleanupDuration),
-		skipPodFilter:  opts.PodFilter,
-		eventRecorder:  kubernetes.NoopEventRecorder{},
-		rateLimiter:    opts.RateLimiter,
-		logger:         logr.Discard(),
+		podIndexer:          fakeIndexer,
+		pdbIndexer:          fakeIndexer,
+		client:              wrapper.GetManagerClient(),
+		podResultCache:      utils.NewTTLCache[simulationResult](*opts.CacheTTL, *opts.CleanupDuration),
+		skipPodFilter:       opts.PodFilter,
+		eventRecorder:       kubernetes.NoopEventRecorder{},
+		rateLimiter:         opts.RateLimiter,
+		concurrency:         opts.Concurrency,
+		annotateNode:        opts.AnnotateNode,
+		rateLimitByPodCount: opts.RateLimitByPodCount,
+		podTakingPDBBudget:  opts.PodTakingPDBBudget,
+		negativeCacheTTLs:   opts.NegativeCacheTTLs,
+		logger:              logr.Discard(),
 	}
 
 	return simulator, nil
@@ -2,8 +2,12 @@ package drain
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/planetlabs/draino/internal/kubernetes/k8sclient"
@@ -15,9 +19,10 @@ import (
 	"github.com/planetlabs/draino/internal/kubernetes/index"
 	"github.com/planetlabs/draino/internal/kubernetes/utils"
 	"github.com/planetlabs/draino/internal/limit"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"github.com/planetlabs/draino/internal/tracing"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
 	corev1 "k8s.io/api/core/v1"
-	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -29,8 +34,60 @@ const (
 
 	eventDrainSimulationFailed    = "DrainSimulationFailed"
 	eventEvictionSimulationFailed = "EvictionSimulationFailed"
+
+	// LastSimulationAnnotationKey holds the LastSimulation of the node, as json
+	LastSimulationAnnotationKey = "draino/last-simulation"
+
+	LastSimulationPassed = "passed"
+	LastSimulationFailed = "failed"
 )
 
+// NegativeReasonCategory groups the reasons of the negative simulation results, each category can be cached for a different duration
+type NegativeReasonCategory string
+
+const (
+	// NegativeReasonOverlappingPDB is structural, it lasts until the PDBs are changed
+	NegativeReasonOverlappingPDB NegativeReasonCategory = "overlapping-pdb"
+	// NegativeReasonPDBBlocked is usually transient, the budget comes back once the pods of the workload are ready
+	NegativeReasonPDBBlocked NegativeReasonCategory = "pdb-blocked"
+	// NegativeReasonEvictionRejected is the rejection of the dry-run eviction by the API server or an admission webhook
+	NegativeReasonEvictionRejected NegativeReasonCategory = "eviction-rejected"
+)
+
+// ParseNegativeCacheTTLs parses the cache durations of the negative results, each entry formatted as <category>=<duration>.
+// The categories that are not listed are cached for NegativeCacheResTTL.
+func ParseNegativeCacheTTLs(entries []string) (map[NegativeReasonCategory]time.Duration, error) {
+	ttls := map[NegativeReasonCategory]time.Duration{}
+	for _, entry := range entries {
+		category, value, found := strings.Cut(entry, "=")
+		category, value = strings.TrimSpace(category), strings.TrimSpace(value)
+		if !found {
+			return nil, fmt.Errorf("invalid negative cache ttl '%s', expecting <category>=<duration>", entry)
+		}
+		switch c := NegativeReasonCategory(category); c {
+		case NegativeReasonOverlappingPDB, NegativeReasonPDBBlocked, NegativeReasonEvictionRejected:
+		default:
+			return nil, fmt.Errorf("unknown negative reason category '%s', expecting %s, %s or %s", category, NegativeReasonOverlappingPDB, NegativeReasonPDBBlocked, NegativeReasonEvictionRejected)
+		}
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid negative cache ttl '%s' for '%s', expecting a positive duration", value, category)
+		}
+		if _, exist := ttls[NegativeReasonCategory(category)]; exist {
+			return nil, fmt.Errorf("duplicated negative cache ttl for '%s'", category)
+		}
+		ttls[NegativeReasonCategory(category)] = ttl
+	}
+	return ttls, nil
+}
+
+// LastSimulation is the verdict of the last drain simulation of a node, written in the LastSimulationAnnotationKey annotation
+type LastSimulation struct {
+	Result    string      `json:"result"`
+	Timestamp metav1.Time `json:"timestamp"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
 type DrainSimulator interface {
 	// SimulateDrain will simulate a drain for the given node.
 	// This means that it will perform an eviction simulation of all pods running on the node.
@@ -38,6 +95,18 @@ type DrainSimulator interface {
 	// SimulatePodDrain will simulate a drain of the given pod.
 	// Before calling the API server it will make sure that some of the obvious problems are not given.
 	SimulatePodDrain(context.Context, *corev1.Pod) (canEvict bool, reason string, err error)
+	// DumpCache returns the simulation results that are currently cached, for debugging purposes.
+	DumpCache() []SimCacheEntry
+	// InvalidateNode removes the cached simulation results of the pods of the node, the next simulation of the node calls the API server again.
+	InvalidateNode(context.Context, *corev1.Node) error
+}
+
+// SimCacheEntry is a simulation result stored in the cache of the simulator
+type SimCacheEntry struct {
+	PodUID string
+	Result bool
+	Reason string
+	Expiry time.Time
 }
 
 type drainSimulatorImpl struct {
@@ -50,6 +119,17 @@ type drainSimulatorImpl struct {
 	// skipPodFilter will be used to evaluate if pods running on a node should go through the eviction simulation
 	skipPodFilter  kubernetes.PodFilterFunc
 	podResultCache utils.TTLCache[simulationResult]
+	// concurrency is the maximum number of pods of a node that are simulated in parallel
+	concurrency int
+	// annotateNode writes the result of each node simulation in the LastSimulationAnnotationKey annotation of the node
+	annotateNode bool
+	// rateLimitByPodCount reserves the rate limiting budget of all the pods to simulate before simulating a node,
+	// instead of taking one token per pod simulation, so that the simulation of a big node is not stopped half way.
+	rateLimitByPodCount bool
+	// podTakingPDBBudget tells if a pod is already taking budget from its PDB when checking if the PDB blocks its eviction
+	podTakingPDBBudget analyser.PodTakingPDBBudgetFunc
+	// negativeCacheTTLs overrides NegativeCacheResTTL for some categories of negative results
+	negativeCacheTTLs map[NegativeReasonCategory]time.Duration
 }
 
 type simulationResult struct {
@@ -60,23 +140,44 @@ type simulationResult struct {
 
 var _ DrainSimulator = &drainSimulatorImpl{}
 
-func NewDrainSimulator(
-	ctx context.Context,
-	client client.Client,
-	indexer *index.Indexer,
-	skipPodFilter kubernetes.PodFilterFunc,
-	eventRecorder kubernetes.EventRecorder,
-	rateLimiter limit.RateLimiter,
-	logger logr.Logger,
-) DrainSimulator {
+// DrainSimulatorOptions are the settings of the simulator created by NewDrainSimulator, the zero value is usable
+type DrainSimulatorOptions struct {
+	// Concurrency is the maximum number of pods of a node that are simulated in parallel, at least one
+	Concurrency int
+	// AnnotateNode writes the result of each node simulation in the LastSimulationAnnotationKey annotation of the node
+	AnnotateNode bool
+	// RateLimitByPodCount reserves the rate limiting budget of all the pods of a node before simulating it
+	RateLimitByPodCount bool
+	// PodTakingPDBBudget defaults to analyser.PodTakingPDBBudgetIfNotReady
+	PodTakingPDBBudget analyser.PodTakingPDBBudgetFunc
+	// NegativeCacheTTLs overrides NegativeCacheResTTL for some categories of negative results
+	NegativeCacheTTLs map[NegativeReasonCategory]time.Duration
+}
+
+func (opts *DrainSimulatorOptions) applyDefaults() {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.PodTakingPDBBudget == nil {
+		opts.PodTakingPDBBudget = analyser.PodTakingPDBBudgetIfNotReady
+	}
+}
+
+func NewDrainSimulator(ctx context.Context, client client.Client, indexer *index.Indexer, skipPodFilter kubernetes.PodFilterFunc, eventRecorder kubernetes.EventRecorder, rateLimiter limit.RateLimiter, logger logr.Logger, opts DrainSimulatorOptions) DrainSimulator {
+	opts.applyDefaults()
 	simulator := &drainSimulatorImpl{
-		podIndexer:    indexer,
-		pdbIndexer:    indexer,
-		client:        client,
-		skipPodFilter: skipPodFilter,
-		eventRecorder: eventRecorder,
-		rateLimiter:   rateLimiter,
-		logger:        logger.WithName("EvictionSimulator"),
+		podIndexer:          indexer,
+		pdbIndexer:          indexer,
+		client:              client,
+		skipPodFilter:       skipPodFilter,
+		eventRecorder:       eventRecorder,
+		rateLimiter:         rateLimiter,
+		concurrency:         opts.Concurrency,
+		annotateNode:        opts.AnnotateNode,
+		rateLimitByPodCount: opts.RateLimitByPodCount,
+		podTakingPDBBudget:  opts.PodTakingPDBBudget,
+		negativeCacheTTLs:   opts.NegativeCacheTTLs,
+		logger:              logger.WithName("EvictionSimulator"),
 
 		// TODO think about using alternative solutions like a MRU cache
 		podResultCache: utils.NewTTLCache[simulationResult](3*time.Minute, 10*time.Second),
@@ -88,9 +189,38 @@ func NewDrainSimulator(
 }
 
 func (sim *drainSimulatorImpl) SimulateDrain(ctx context.Context, node *corev1.Node) (bool, []string, []error) {
-	span, ctx := tracer.StartSpanFromContext(ctx, "SimulateNodeDrain")
+	span, ctx := tracing.StartSpanFromContext(ctx, "SimulateNodeDrain")
 	defer span.Finish()
 
+	canEvict, reasons, errs := sim.simulateDrain(ctx, node)
+	if sim.annotateNode {
+		sim.writeLastSimulation(ctx, node, canEvict, reasons, errs)
+	}
+	return canEvict, reasons, errs
+}
+
+// writeLastSimulation patches the LastSimulationAnnotationKey annotation of the node. Failures are only logged, they do not change the simulation result.
+func (sim *drainSimulatorImpl) writeLastSimulation(ctx context.Context, node *corev1.Node, canEvict bool, reasons []string, errs []error) {
+	last := LastSimulation{Result: LastSimulationPassed, Timestamp: metav1.Now()}
+	if !canEvict {
+		last.Result = LastSimulationFailed
+	}
+	if len(reasons) > 0 {
+		last.Reason = reasons[0]
+	} else if len(errs) > 0 {
+		last.Reason = errs[0].Error()
+	}
+	value, err := json.Marshal(last)
+	if err != nil {
+		sim.logger.Error(err, "cannot serialize last simulation", "node", node.GetName())
+		return
+	}
+	if err := k8sclient.PatchNodeAnnotationKeyCR(ctx, sim.client, node, LastSimulationAnnotationKey, string(value)); err != nil {
+		sim.logger.Error(err, "cannot annotate node with last simulation", "node", node.GetName())
+	}
+}
+
+func (sim *drainSimulatorImpl) simulateDrain(ctx context.Context, node *corev1.Node) (bool, []string, []error) {
 	pods, err := sim.podIndexer.GetPodsByNode(ctx, node.GetName())
 	if err != nil {
 		return false, nil, []error{err}
@@ -113,14 +243,27 @@ func (sim *drainSimulatorImpl) SimulateDrain(ctx context.Context, node *corev1.N
 		return false, reasons, errors
 	}
 
-	for _, pod := range pods {
-		// TODO add suceeded/failed pod drain simulation count metric
-		canEvict, reason, err := sim.SimulatePodDrain(ctx, pod)
-		if err != nil {
-			return false, nil, []error{err}
+	if sim.rateLimitByPodCount {
+		var toSimulate int
+		for _, pod := range pods {
+			if _, exist := sim.podResultCache.Get(createCacheKey(pod), time.Now()); !exist {
+				toSimulate++
+			}
+		}
+		if toSimulate > 0 && !sim.rateLimiter.TryAcceptN(toSimulate) {
+			sim.logger.V(logs.ZapDebug).Info("Drain simulation aborted due to rate limiting.", "node", node.GetName(), "pods", toSimulate)
+			return false, nil, []error{&k8sclient.ClientSideRateLimit{}}
 		}
-		if !canEvict {
-			reasons = append(reasons, fmt.Sprintf("Cannot drain pod '%s/%s', because: %v", pod.GetNamespace(), pod.GetName(), reason))
+		ctx = withReservedRateLimit(ctx)
+	}
+
+	// TODO add suceeded/failed pod drain simulation count metric
+	for i, res := range sim.simulatePodsDrain(ctx, pods) {
+		if res.err != nil {
+			return false, nil, []error{res.err}
+		}
+		if !res.result {
+			reasons = append(reasons, fmt.Sprintf("Cannot drain pod '%s/%s', because: %v", pods[i].GetNamespace(), pods[i].GetName(), res.reason))
 		}
 	}
 
@@ -133,8 +276,53 @@ func (sim *drainSimulatorImpl) SimulateDrain(ctx context.Context, node *corev1.N
 	return true, nil, nil
 }
 
+type reservedRateLimitKey struct{}
+
+// withReservedRateLimit marks the context of pod simulations for which the rate limiting token was already taken by the node simulation
+func withReservedRateLimit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, reservedRateLimitKey{}, true)
+}
+
+func hasReservedRateLimit(ctx context.Context) bool {
+	reserved, _ := ctx.Value(reservedRateLimitKey{}).(bool)
+	return reserved
+}
+
+// simulatePodsDrain runs the pod simulations with a bounded concurrency and returns the results in the order of the pods.
+// Once a simulation returns an error, the pods that are not started yet are not simulated anymore.
+func (sim *drainSimulatorImpl) simulatePodsDrain(ctx context.Context, pods []*corev1.Pod) []simulationResult {
+	concurrency := sim.concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]simulationResult, len(pods))
+	sem := make(chan struct{}, concurrency)
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for i := range pods {
+		sem <- struct{}{}
+		if failed.Load() {
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			canEvict, reason, err := sim.SimulatePodDrain(ctx, pods[i])
+			if err != nil {
+				failed.Store(true)
+			}
+			results[i] = simulationResult{result: canEvict, reason: reason, err: err}
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
 func (sim *drainSimulatorImpl) SimulatePodDrain(ctx context.Context, pod *corev1.Pod) (bool, string, error) {
-	span, ctx := tracer.StartSpanFromContext(ctx, "SimulatePodDrain")
+	span, ctx := tracing.StartSpanFromContext(ctx, "SimulatePodDrain")
 	defer span.Finish()
 
 	if res, exist := sim.podResultCache.Get(createCacheKey(pod), time.Now()); exist {
@@ -147,7 +335,7 @@ func (sim *drainSimulatorImpl) SimulatePodDrain(ctx context.Context, pod *corev1
 	}
 	if !passes {
 		// If the pod does not pass the filter, it means that it will be accepted by default
-		sim.writePodCache(pod, true, reason, nil)
+		sim.writePodCache(pod, true, "", reason, nil)
 		return true, reason, nil
 	}
 
@@ -158,9 +346,10 @@ func (sim *drainSimulatorImpl) SimulatePodDrain(ctx context.Context, pod *corev1
 
 	// If there is more than one PDB associated to the given pod, the eviction will fail for sure due to the APIServer behaviour.
 	podKey := index.GeneratePodIndexKey(pod.GetName(), pod.GetNamespace())
+	recordPDBsEvaluated(ctx, pod.GetNamespace(), len(pdbs[podKey]))
 	if len(pdbs[podKey]) > 1 {
 		reason = fmt.Sprintf("Pod has more than one associated PDB: %s", strings.Join(utils.GetPDBNames(pdbs[podKey]), ";"))
-		sim.writePodCache(pod, false, reason, nil)
+		sim.writePodCache(pod, false, NegativeReasonOverlappingPDB, reason, nil)
 		sim.eventRecorder.PodEventf(ctx, pod, corev1.EventTypeWarning, eventEvictionSimulationFailed, reason)
 		return false, reason, nil
 	}
@@ -168,15 +357,15 @@ func (sim *drainSimulatorImpl) SimulatePodDrain(ctx context.Context, pod *corev1
 	// If there is a matching PDB, check if it would allow disruptions
 	if len(pdbs[podKey]) == 1 {
 		pdb := pdbs[podKey][0]
-		if analyser.IsPDBBlockedByPod(ctx, pod, pdb) {
+		if analyser.IsPDBBlockedByPodWithBudgetFunc(ctx, pod, pdb, sim.podTakingPDBBudget) {
 			reason = fmt.Sprintf("PDB '%s' does not allow any disruptions", pdb.GetName())
-			sim.writePodCache(pod, false, reason, nil)
+			sim.writePodCache(pod, false, NegativeReasonPDBBlocked, reason, nil)
 			sim.eventRecorder.PodEventf(ctx, pod, corev1.EventTypeWarning, eventEvictionSimulationFailed, reason)
 			return false, reason, nil
 		}
 	}
 
-	if !sim.rateLimiter.TryAccept() {
+	if !hasReservedRateLimit(ctx) && !sim.rateLimiter.TryAccept() {
 		sim.logger.V(logs.ZapDebug).Info("Drain simulation aborted due to rate limiting.")
 		return false, "", &k8sclient.ClientSideRateLimit{}
 	}
@@ -193,29 +382,26 @@ func (sim *drainSimulatorImpl) SimulatePodDrain(ctx context.Context, pod *corev1
 		if apierrors.IsTooManyRequests(err) {
 			err = nil
 		}
-		sim.writePodCache(pod, false, reason, err)
+		sim.writePodCache(pod, false, NegativeReasonEvictionRejected, reason, err)
 		sim.eventRecorder.PodEventf(ctx, pod, corev1.EventTypeWarning, eventEvictionSimulationFailed, reason)
 		return false, reason, err
 	}
 
-	sim.writePodCache(pod, true, "", nil)
+	sim.writePodCache(pod, true, "", "", nil)
 	return true, "", nil
 }
 
+// recordPDBsEvaluated records the number of PDBs evaluated by the simulation of a pod of the namespace
+func recordPDBsEvaluated(ctx context.Context, namespace string, count int) {
+	tags, _ := tag.New(ctx, tag.Upsert(kubernetes.TagNamespace, namespace))
+	stats.Record(tags, kubernetes.MeasurePDBsEvaluated.M(int64(count)))
+}
+
 func (sim *drainSimulatorImpl) simulateAPIEviction(ctx context.Context, pod *corev1.Pod) (bool, error) {
-	span, ctx := tracer.StartSpanFromContext(ctx, "SimulatePodEviction")
+	span, ctx := tracing.StartSpanFromContext(ctx, "SimulatePodEviction")
 	defer span.Finish()
 
-	eviction := &policyv1.Eviction{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      pod.GetName(),
-			Namespace: pod.GetNamespace(),
-		},
-		DeleteOptions: &metav1.DeleteOptions{
-			DryRun: []string{"All"},
-		},
-	}
-	err := sim.client.SubResource("eviction").Create(ctx, pod, eviction)
+	err := sim.client.SubResource("eviction").Create(ctx, pod, kubernetes.NewDryRunEviction(pod))
 	if err != nil {
 		sim.logger.V(logs.ZapDebug).Info("Error returned by simulation eviction", "pod", pod.Namespace+"/"+pod.Name, "err", err, "IsTooManyReq", apierrors.IsTooManyRequests(err), "IsForbidden", apierrors.IsForbidden(err), "Reason", apierrors.ReasonForError(err))
 		return false, fmt.Errorf("Cannot evict pod '%s/%s': %w", pod.Namespace, pod.Name, err)
@@ -224,14 +410,38 @@ func (sim *drainSimulatorImpl) simulateAPIEviction(ctx context.Context, pod *cor
 	return true, nil
 }
 
-func (sim *drainSimulatorImpl) writePodCache(pod *corev1.Pod, result bool, reason string, err error) {
+// writePodCache caches the result of a pod simulation. The negative results are cached for the duration configured for their category, NegativeCacheResTTL by default.
+func (sim *drainSimulatorImpl) writePodCache(pod *corev1.Pod, result bool, category NegativeReasonCategory, reason string, err error) {
 	ttl := NegativeCacheResTTL
 	if result {
 		ttl = PositiveCacheResTTL
+	} else if categoryTTL, ok := sim.negativeCacheTTLs[category]; ok {
+		ttl = categoryTTL
 	}
 	sim.podResultCache.AddCustomTTL(createCacheKey(pod), simulationResult{result: result, reason: reason, err: err}, ttl)
 }
 
+func (sim *drainSimulatorImpl) DumpCache() []SimCacheEntry {
+	entries := sim.podResultCache.Entries(time.Now())
+	res := make([]SimCacheEntry, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, SimCacheEntry{PodUID: e.Key, Result: e.Value.result, Reason: e.Value.reason, Expiry: e.Until})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].PodUID < res[j].PodUID })
+	return res
+}
+
+func (sim *drainSimulatorImpl) InvalidateNode(ctx context.Context, node *corev1.Node) error {
+	pods, err := sim.podIndexer.GetPodsByNode(ctx, node.GetName())
+	if err != nil {
+		return fmt.Errorf("cannot get pods of node %s: %w", node.GetName(), err)
+	}
+	for _, pod := range pods {
+		sim.podResultCache.Delete(createCacheKey(pod))
+	}
+	return nil
+}
+
 func createCacheKey(pod *corev1.Pod) string {
 	return string(pod.UID)
 }
@@ -2,17 +2,28 @@ package drain
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
 
 	"github.com/planetlabs/draino/internal/kubernetes"
+	"github.com/planetlabs/draino/internal/kubernetes/analyser"
+	"github.com/planetlabs/draino/internal/kubernetes/k8sclient"
+	"github.com/planetlabs/draino/internal/limit"
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	testingclock "k8s.io/utils/clock/testing"
 )
 
 func TestSimulator_SimulateDrain(t *testing.T) {
@@ -153,6 +164,448 @@ func TestSimulator_SimulateDrain(t *testing.T) {
 	}
 }
 
+func TestSimulator_SimulateDrain_Concurrency(t *testing.T) {
+	node := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "foo-node"}}
+	blockedLabels := map[string]string{"app": "blocked"}
+	objects := []runtime.Object{
+		&node,
+		createPDB(createPDBOpts{Name: "blocked-pdb", Labels: blockedLabels, Des: 2, Healthy: 1}),
+	}
+	for i := 0; i < 10; i++ {
+		labels := map[string]string{"app": "free"}
+		if i%3 == 0 {
+			labels = blockedLabels
+		}
+		pod := createPod(createPodOpts{Name: fmt.Sprintf("pod-%d", i), Labels: labels, NodeName: node.Name})
+		pod.UID = types.UID(pod.Name)
+		objects = append(objects, pod)
+	}
+
+	simulate := func(concurrency int, filter kubernetes.PodFilterFunc) (bool, []string) {
+		ch := make(chan struct{})
+		defer close(ch)
+		simulator, err := NewFakeDrainSimulator(&FakeSimulatorOptions{Chan: ch, Objects: objects, PodFilter: filter, Concurrency: concurrency})
+		assert.NoError(t, err)
+		drainable, reasons, errs := simulator.SimulateDrain(context.Background(), &node)
+		assert.Empty(t, errs)
+		sort.Strings(reasons)
+		return drainable, reasons
+	}
+
+	// the fake client does not support evictions: only the pods covered by the PDB are going through the simulation
+	onlyBlockedPods := func(p corev1.Pod) (bool, string, error) {
+		return p.Labels["app"] == "blocked", "", nil
+	}
+
+	t.Run("Should give the same result as the serial simulation", func(t *testing.T) {
+		serialDrainable, serialReasons := simulate(1, onlyBlockedPods)
+		assert.False(t, serialDrainable)
+		assert.Len(t, serialReasons, 4)
+
+		drainable, reasons := simulate(4, onlyBlockedPods)
+		assert.Equal(t, serialDrainable, drainable)
+		assert.Equal(t, serialReasons, reasons)
+	})
+
+	t.Run("Should bound the number of parallel pod simulations", func(t *testing.T) {
+		var lock sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		trackingFilter := func(p corev1.Pod) (bool, string, error) {
+			lock.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			lock.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			lock.Lock()
+			inFlight--
+			lock.Unlock()
+			// filtered out pods are accepted without further simulation
+			return false, "", nil
+		}
+
+		drainable, _ := simulate(3, trackingFilter)
+		assert.True(t, drainable)
+		assert.LessOrEqual(t, maxInFlight, 3)
+		assert.Greater(t, maxInFlight, 1)
+	})
+}
+
+// denyingRateLimiter stops the simulations right before the eviction dry run, that is not supported by the fake client
+type denyingRateLimiter struct{}
+
+func (denyingRateLimiter) TryAccept() bool       { return false }
+func (denyingRateLimiter) TryAcceptN(n int) bool { return false }
+
+func TestSimulator_SimulatePodDrain_PodTakingPDBBudget(t *testing.T) {
+	testLabels := map[string]string{"app": "foo"}
+	terminatingPod := createPod(createPodOpts{Name: "foo-pod", Labels: testLabels, NodeName: "foo-node"})
+	terminatingPod.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	terminatingPod.Finalizers = []string{"test/finalizer"}
+	blockedReason := "PDB 'foo-pdb' does not allow any disruptions"
+
+	tests := []struct {
+		Name          string
+		TakingBudget  analyser.PodTakingPDBBudgetFunc
+		ExpectBlocked bool
+	}{
+		{
+			Name:          "Should count the terminating pods in the PDB by default",
+			ExpectBlocked: true,
+		},
+		{
+			Name:          "Should not count the terminating pods in the PDB when excluded",
+			TakingBudget:  analyser.PodTakingPDBBudgetIfNotReadyOrTerminating,
+			ExpectBlocked: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			ch := make(chan struct{})
+			defer close(ch)
+			simulator, err := NewFakeDrainSimulator(&FakeSimulatorOptions{
+				Chan:               ch,
+				Objects:            []runtime.Object{terminatingPod.DeepCopy(), createPDB(createPDBOpts{Name: "foo-pdb", Labels: testLabels, Des: 2, Healthy: 2})},
+				PodFilter:          noopPodFilter,
+				RateLimiter:        denyingRateLimiter{},
+				PodTakingPDBBudget: tt.TakingBudget,
+			})
+			assert.NoError(t, err)
+
+			canEvict, reason, err := simulator.SimulatePodDrain(context.Background(), terminatingPod)
+			assert.False(t, canEvict)
+			if tt.ExpectBlocked {
+				assert.Equal(t, blockedReason, reason)
+				assert.NoError(t, err)
+			} else {
+				// the pod went through the PDB check and was stopped by the rate limiter
+				assert.Empty(t, reason)
+				assert.Equal(t, &k8sclient.ClientSideRateLimit{}, err)
+			}
+		})
+	}
+}
+
+func TestSimulator_SimulatePodDrain_PDBsEvaluatedMetric(t *testing.T) {
+	evaluatedView := &view.View{
+		Name:        "test_pdbs_evaluated",
+		Measure:     kubernetes.MeasurePDBsEvaluated,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{kubernetes.TagNamespace},
+	}
+	assert.NoError(t, view.Register(evaluatedView))
+	defer view.Unregister(evaluatedView)
+
+	inNamespace := func(namespace string, obj metav1.Object) runtime.Object {
+		obj.SetNamespace(namespace)
+		return obj.(runtime.Object)
+	}
+	fooLabels, barLabels := map[string]string{"app": "foo"}, map[string]string{"app": "bar"}
+	overlapped := createPod(createPodOpts{Name: "overlapped-pod", Labels: fooLabels, NodeName: "foo-node"})
+	covered := inNamespace("other", createPod(createPodOpts{Name: "covered-pod", Labels: barLabels, NodeName: "foo-node"})).(*corev1.Pod)
+	uncovered := inNamespace("third", createPod(createPodOpts{Name: "uncovered-pod", Labels: fooLabels, NodeName: "foo-node"})).(*corev1.Pod)
+
+	ch := make(chan struct{})
+	defer close(ch)
+	simulator, err := NewFakeDrainSimulator(&FakeSimulatorOptions{
+		Chan: ch,
+		Objects: []runtime.Object{
+			overlapped, covered, uncovered,
+			createPDB(createPDBOpts{Name: "foo-pdb1", Labels: fooLabels, Des: 2, Healthy: 3}),
+			createPDB(createPDBOpts{Name: "foo-pdb2", Labels: fooLabels, Des: 2, Healthy: 3}),
+			inNamespace("other", createPDB(createPDBOpts{Name: "bar-pdb", Labels: barLabels, Des: 2, Healthy: 3})),
+			inNamespace("other", createPDB(createPDBOpts{Name: "foo-pdb", Labels: fooLabels, Des: 2, Healthy: 3})),
+		},
+		PodFilter:   noopPodFilter,
+		RateLimiter: denyingRateLimiter{},
+	})
+	assert.NoError(t, err)
+
+	for _, pod := range []*corev1.Pod{overlapped, covered, uncovered} {
+		// the results are cached per pod UID
+		pod.UID = types.UID(pod.Name)
+		_, _, _ = simulator.SimulatePodDrain(context.Background(), pod)
+	}
+
+	rows, err := view.RetrieveData(evaluatedView.Name)
+	assert.NoError(t, err)
+	evaluated := map[string]float64{}
+	for _, r := range rows {
+		evaluated[r.Tags[0].Value] = r.Data.(*view.SumData).Value
+	}
+	assert.Equal(t, map[string]float64{"default": 2, "other": 1, "third": 0}, evaluated)
+}
+
+func TestSimulator_DumpCache(t *testing.T) {
+	ch := make(chan struct{})
+	defer close(ch)
+	simulator, err := NewFakeDrainSimulator(&FakeSimulatorOptions{Chan: ch, PodFilter: noopPodFilter})
+	assert.NoError(t, err)
+	assert.Empty(t, simulator.DumpCache())
+
+	okPod := createPod(createPodOpts{Name: "ok-pod", NodeName: "foo-node"})
+	okPod.UID = "uid-ok"
+	blockedPod := createPod(createPodOpts{Name: "blocked-pod", NodeName: "foo-node"})
+	blockedPod.UID = "uid-blocked"
+
+	before := time.Now()
+	impl := simulator.(*drainSimulatorImpl)
+	impl.writePodCache(okPod, true, "", "", nil)
+	impl.writePodCache(blockedPod, false, NegativeReasonPDBBlocked, "PDB 'foo-pdb' does not allow any disruptions", nil)
+
+	dump := simulator.DumpCache()
+	if assert.Len(t, dump, 2) {
+		assert.Equal(t, "uid-blocked", dump[0].PodUID)
+		assert.False(t, dump[0].Result)
+		assert.Equal(t, "PDB 'foo-pdb' does not allow any disruptions", dump[0].Reason)
+		assert.WithinDuration(t, before.Add(NegativeCacheResTTL), dump[0].Expiry, time.Second)
+
+		assert.Equal(t, "uid-ok", dump[1].PodUID)
+		assert.True(t, dump[1].Result)
+		assert.Empty(t, dump[1].Reason)
+		assert.WithinDuration(t, before.Add(PositiveCacheResTTL), dump[1].Expiry, time.Second)
+	}
+}
+
+func TestSimulator_NegativeCacheTTLs(t *testing.T) {
+	ch := make(chan struct{})
+	defer close(ch)
+	simulator, err := NewFakeDrainSimulator(&FakeSimulatorOptions{Chan: ch, PodFilter: noopPodFilter, NegativeCacheTTLs: map[NegativeReasonCategory]time.Duration{
+		NegativeReasonOverlappingPDB: time.Hour,
+		NegativeReasonPDBBlocked:     30 * time.Second,
+	}})
+	assert.NoError(t, err)
+
+	pods := map[NegativeReasonCategory]*corev1.Pod{}
+	for _, category := range []NegativeReasonCategory{NegativeReasonOverlappingPDB, NegativeReasonPDBBlocked, NegativeReasonEvictionRejected} {
+		pods[category] = createPod(createPodOpts{Name: string(category), NodeName: "foo-node"})
+		pods[category].UID = types.UID(category)
+	}
+	before := time.Now()
+	impl := simulator.(*drainSimulatorImpl)
+	for category, pod := range pods {
+		impl.writePodCache(pod, false, category, "blocked", nil)
+	}
+
+	expiries := map[string]time.Time{}
+	for _, e := range simulator.DumpCache() {
+		expiries[e.PodUID] = e.Expiry
+	}
+	assert.WithinDuration(t, before.Add(time.Hour), expiries[string(NegativeReasonOverlappingPDB)], time.Second)
+	assert.WithinDuration(t, before.Add(30*time.Second), expiries[string(NegativeReasonPDBBlocked)], time.Second)
+	// the categories that are not configured keep the default TTL
+	assert.WithinDuration(t, before.Add(NegativeCacheResTTL), expiries[string(NegativeReasonEvictionRejected)], time.Second)
+}
+
+func TestParseNegativeCacheTTLs(t *testing.T) {
+	tests := []struct {
+		name      string
+		entries   []string
+		expected  map[NegativeReasonCategory]time.Duration
+		expectErr bool
+	}{
+		{
+			name:     "valid entries",
+			entries:  []string{"overlapping-pdb=1h", " pdb-blocked = 30s "},
+			expected: map[NegativeReasonCategory]time.Duration{NegativeReasonOverlappingPDB: time.Hour, NegativeReasonPDBBlocked: 30 * time.Second},
+		},
+		{
+			name:     "no entry",
+			expected: map[NegativeReasonCategory]time.Duration{},
+		},
+		{
+			name:      "unknown category",
+			entries:   []string{"no-budget=1m"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid duration",
+			entries:   []string{"pdb-blocked=soon"},
+			expectErr: true,
+		},
+		{
+			name:      "missing duration",
+			entries:   []string{"pdb-blocked"},
+			expectErr: true,
+		},
+		{
+			name:      "duplicated category",
+			entries:   []string{"pdb-blocked=1m", "pdb-blocked=2m"},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ttls, err := ParseNegativeCacheTTLs(tt.entries)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ttls)
+		})
+	}
+}
+
+func TestSimulator_InvalidateNode(t *testing.T) {
+	podOnNode := createPod(createPodOpts{Name: "pod", NodeName: "foo-node"})
+	podOnNode.UID = "uid-pod"
+	podOnOtherNode := createPod(createPodOpts{Name: "other-pod", NodeName: "bar-node"})
+	podOnOtherNode.UID = "uid-other-pod"
+
+	ch := make(chan struct{})
+	defer close(ch)
+	simulator, err := NewFakeDrainSimulator(&FakeSimulatorOptions{Chan: ch, PodFilter: noopPodFilter, Objects: []runtime.Object{podOnNode, podOnOtherNode}})
+	assert.NoError(t, err)
+
+	impl := simulator.(*drainSimulatorImpl)
+	impl.writePodCache(podOnNode, false, NegativeReasonPDBBlocked, "PDB 'foo-pdb' does not allow any disruptions", nil)
+	impl.writePodCache(podOnOtherNode, true, "", "", nil)
+
+	assert.NoError(t, simulator.InvalidateNode(context.Background(), &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "foo-node"}}))
+	dump := simulator.DumpCache()
+	if assert.Len(t, dump, 1) {
+		assert.Equal(t, "uid-other-pod", dump[0].PodUID)
+	}
+}
+
+// recordingRateLimiter records the number of tokens requested by each call
+type recordingRateLimiter struct {
+	sync.Mutex
+	requested []int
+}
+
+func (r *recordingRateLimiter) TryAccept() bool {
+	return r.TryAcceptN(1)
+}
+
+func (r *recordingRateLimiter) TryAcceptN(n int) bool {
+	r.Lock()
+	defer r.Unlock()
+	r.requested = append(r.requested, n)
+	return true
+}
+
+func TestSimulator_SimulateDrain_RateLimitByPodCount(t *testing.T) {
+	nodeWithPods := func(name string, count int) (*corev1.Node, []runtime.Object) {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		objects := []runtime.Object{node}
+		for i := 0; i < count; i++ {
+			pod := createPod(createPodOpts{Name: fmt.Sprintf("%s-pod-%d", name, i), NodeName: name})
+			pod.UID = types.UID(pod.Name)
+			objects = append(objects, pod)
+		}
+		return node, objects
+	}
+	// the pods are not going through the eviction dry run, only the reservation takes tokens
+	filterAll := func(p corev1.Pod) (bool, string, error) { return false, "", nil }
+
+	smallNode, smallObjects := nodeWithPods("small-node", 5)
+	bigNode, bigObjects := nodeWithPods("big-node", 20)
+	objects := append(smallObjects, bigObjects...)
+
+	t.Run("Should reserve one token per pod of the node", func(t *testing.T) {
+		ch := make(chan struct{})
+		defer close(ch)
+		limiter := &recordingRateLimiter{}
+		simulator, err := NewFakeDrainSimulator(&FakeSimulatorOptions{Chan: ch, Objects: objects, PodFilter: filterAll, RateLimiter: limiter, RateLimitByPodCount: true})
+		assert.NoError(t, err)
+
+		for _, node := range []*corev1.Node{smallNode, bigNode} {
+			drainable, _, errs := simulator.SimulateDrain(context.Background(), node)
+			assert.True(t, drainable)
+			assert.Empty(t, errs)
+		}
+		// the results are cached, the pods are not going to be simulated again
+		simulator.SimulateDrain(context.Background(), smallNode)
+		assert.Equal(t, []int{5, 20}, limiter.requested)
+	})
+
+	t.Run("Should not simulate the node if the budget of all its pods is not available", func(t *testing.T) {
+		ch := make(chan struct{})
+		defer close(ch)
+		limiter := limit.NewRateLimiter(testingclock.NewFakeClock(time.Now()), 1, 10)
+		simulator, err := NewFakeDrainSimulator(&FakeSimulatorOptions{Chan: ch, Objects: objects, PodFilter: filterAll, RateLimiter: limiter, RateLimitByPodCount: true})
+		assert.NoError(t, err)
+
+		drainable, _, errs := simulator.SimulateDrain(context.Background(), smallNode)
+		assert.True(t, drainable)
+		assert.Empty(t, errs)
+
+		// the reservation is capped to the burst, but only 5 tokens are left
+		drainable, _, errs = simulator.SimulateDrain(context.Background(), bigNode)
+		assert.False(t, drainable)
+		assert.Equal(t, []error{&k8sclient.ClientSideRateLimit{}}, errs)
+		assert.Len(t, simulator.DumpCache(), 5, "only the pods of the small node are simulated")
+	})
+}
+
+func TestSimulator_SimulateDrain_AnnotateNode(t *testing.T) {
+	testLabels := map[string]string{
+		"app": "foo",
+	}
+	tests := []struct {
+		Name           string
+		AnnotateNode   bool
+		Objects        []runtime.Object
+		ExpectedResult string
+		ExpectedReason string
+	}{
+		{
+			Name:           "Should annotate the node when the drain simulation passes",
+			AnnotateNode:   true,
+			ExpectedResult: LastSimulationPassed,
+		},
+		{
+			Name:         "Should annotate the node with the reason when the drain simulation fails",
+			AnnotateNode: true,
+			Objects: []runtime.Object{
+				createPod(createPodOpts{Name: "foo-pod", Labels: testLabels, NodeName: "foo-node"}),
+				createPDB(createPDBOpts{Name: "foo-pdb", Labels: testLabels, Des: 2, Healthy: 1}),
+			},
+			ExpectedResult: LastSimulationFailed,
+			ExpectedReason: "Cannot drain pod 'default/foo-pod', because: PDB 'foo-pdb' does not allow any disruptions",
+		},
+		{
+			Name:         "Should not annotate the node by default",
+			AnnotateNode: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			ch := make(chan struct{})
+			defer close(ch)
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "foo-node"}}
+			simulator, err := NewFakeDrainSimulator(&FakeSimulatorOptions{
+				Chan:         ch,
+				Objects:      append(tt.Objects, node),
+				PodFilter:    noopPodFilter,
+				AnnotateNode: tt.AnnotateNode,
+			})
+			assert.NoError(t, err)
+
+			before := time.Now().Truncate(time.Second)
+			simulator.SimulateDrain(context.Background(), node)
+
+			var updated corev1.Node
+			assert.NoError(t, simulator.(*drainSimulatorImpl).client.Get(context.Background(), types.NamespacedName{Name: node.Name}, &updated))
+			value, found := updated.Annotations[LastSimulationAnnotationKey]
+			if !tt.AnnotateNode {
+				assert.False(t, found)
+				return
+			}
+			assert.True(t, found)
+			var last LastSimulation
+			assert.NoError(t, json.Unmarshal([]byte(value), &last))
+			assert.Equal(t, tt.ExpectedResult, last.Result)
+			assert.Equal(t, tt.ExpectedReason, last.Reason)
+			assert.False(t, last.Timestamp.Time.Before(before))
+		})
+	}
+}
+
 type createPodOpts struct {
 	Name       string
 	NodeName   string
@@ -0,0 +1,101 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	core "k8s.io/api/core/v1"
+)
+
+// activeDrain is a drain in progress, cancelled by CancelDrain
+type activeDrain struct {
+	cancel context.CancelFunc
+	// cancelled is closed by CancelDrain, the drain stops waiting for its evictions and returns a DrainCancelledError
+	cancelled chan struct{}
+	once      sync.Once
+}
+
+func (a *activeDrain) abort() {
+	a.once.Do(func() {
+		close(a.cancelled)
+		a.cancel()
+	})
+}
+
+func (a *activeDrain) isCancelled() bool {
+	select {
+	case <-a.cancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+type activeDrainKey struct{}
+
+// activeDrains tracks the drains in progress per node, a node can be drained by several calls at once
+type activeDrains struct {
+	sync.Mutex
+	drains map[string]map[*activeDrain]struct{}
+}
+
+func newActiveDrains() *activeDrains {
+	return &activeDrains{drains: map[string]map[*activeDrain]struct{}{}}
+}
+
+// start registers a drain of the node, the returned context is cancelled with the drain. The drain must be unregistered with stop.
+func (a *activeDrains) start(ctx context.Context, nodeName string) (context.Context, *activeDrain) {
+	ctx, cancel := context.WithCancel(ctx)
+	drain := &activeDrain{cancel: cancel, cancelled: make(chan struct{})}
+	a.Lock()
+	defer a.Unlock()
+	if a.drains[nodeName] == nil {
+		a.drains[nodeName] = map[*activeDrain]struct{}{}
+	}
+	a.drains[nodeName][drain] = struct{}{}
+	return context.WithValue(ctx, activeDrainKey{}, drain), drain
+}
+
+func (a *activeDrains) stop(nodeName string, drain *activeDrain) {
+	a.Lock()
+	defer a.Unlock()
+	delete(a.drains[nodeName], drain)
+	if len(a.drains[nodeName]) == 0 {
+		delete(a.drains, nodeName)
+	}
+	drain.cancel()
+}
+
+// cancel aborts all the drains of the node, it returns false if there was none
+func (a *activeDrains) cancel(nodeName string) bool {
+	a.Lock()
+	defer a.Unlock()
+	for drain := range a.drains[nodeName] {
+		drain.abort()
+	}
+	return len(a.drains[nodeName]) > 0
+}
+
+// drainCancelled returns the channel closed when the drain of the context is cancelled, nil if the drain cannot be cancelled
+func drainCancelled(ctx context.Context) <-chan struct{} {
+	if drain, ok := ctx.Value(activeDrainKey{}).(*activeDrain); ok {
+		return drain.cancelled
+	}
+	return nil
+}
+
+// CancelDrain aborts the drains of the node started with Drain or DrainPods that are still in progress, they return a DrainCancelledError.
+// The pending evictions are aborted, the pods already evicted are not brought back. It returns false if no drain of the node was in progress.
+func (d *APIDrainer) CancelDrain(node *core.Node) bool {
+	return d.activeDrains.cancel(node.GetName())
+}
+
+// DrainCancelledError is returned by the drains cancelled with CancelDrain
+type DrainCancelledError struct {
+	NodeName string
+}
+
+func (e DrainCancelledError) Error() string {
+	return fmt.Sprintf("drain of node %s cancelled", e.NodeName)
+}
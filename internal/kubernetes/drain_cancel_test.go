@@ -0,0 +1,59 @@
+package kubernetes
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	crfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/planetlabs/draino/internal/kubernetes/k8sclient"
+)
+
+func TestAPIDrainer_CancelDrain(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}}}
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+	c := fake.NewSimpleClientset(node, pod)
+	var evictions int32
+	c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		atomic.AddInt32(&evictions, 1)
+		// the eviction is retried after 10s, the cancellation must not wait for it
+		return true, nil, apierrors.NewTooManyRequests("blocked by the disruption budget", 10)
+	})
+	d := NewAPIDrainer(c, &NoopEventRecorder{}, WithContainerRuntimeClient(crfake.NewClientBuilder().Build()))
+
+	assert.False(t, d.CancelDrain(node), "no drain in progress")
+
+	result := make(chan error, 1)
+	go func() { result <- d.Drain(context.Background(), node) }()
+	assert.NoError(t, wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return atomic.LoadInt32(&evictions) > 0, nil
+	}))
+
+	assert.True(t, d.CancelDrain(node))
+	select {
+	case err := <-result:
+		assert.Equal(t, DrainCancelledError{NodeName: nodeName}, err)
+		assert.Equal(t, DrainCancelled, GetFailureCause(err))
+	case <-time.After(2 * time.Second):
+		t.Fatal("the drain was not aborted")
+	}
+	assert.False(t, d.CancelDrain(node), "the cancelled drain is not tracked anymore")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&evictions))
+}
@@ -0,0 +1,56 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NewDryRunEviction returns the eviction of the pod that is only evaluated by the apiserver: the PDBs and the admission webhooks
+// are checked, but the pod is not evicted
+func NewDryRunEviction(pod *core.Pod) *policy.Eviction {
+	return &policy.Eviction{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      pod.GetName(),
+			Namespace: pod.GetNamespace(),
+		},
+		DeleteOptions: &meta.DeleteOptions{
+			DryRun: []string{meta.DryRunAll},
+		},
+	}
+}
+
+// DrainDryRunBlockedError is returned by the drains in dry-run mode, see WithDrainDryRun, when the eviction of some pods would be rejected
+type DrainDryRunBlockedError struct {
+	NodeName string
+	Pods     []string
+}
+
+func (e DrainDryRunBlockedError) Error() string {
+	return fmt.Sprintf("the drain of node %s would be blocked by pods: %s", e.NodeName, strings.Join(e.Pods, ", "))
+}
+
+// dryRunEvictions runs the dry-run eviction of each pod against the kubernetes API, including the pods evicted by an eviction endpoint,
+// and returns a DrainDryRunBlockedError listing the pods whose eviction is rejected
+func (d *APIDrainer) dryRunEvictions(ctx context.Context, n *core.Node, pods []*core.Pod) error {
+	var blocked []string
+	for _, pod := range pods {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := d.c.CoreV1().Pods(pod.GetNamespace()).EvictV1(ctx, NewDryRunEviction(pod)); err != nil {
+			TracedLoggerForNode(ctx, n, d.l).Info("Dry run eviction rejected", zap.String("pod", pod.GetName()), zap.String("pod_namespace", pod.GetNamespace()), zap.Error(err))
+			blocked = append(blocked, fmt.Sprintf("%s/%s (%v)", pod.GetNamespace(), pod.GetName(), err))
+		}
+	}
+	if len(blocked) > 0 {
+		return DrainDryRunBlockedError{NodeName: n.GetName(), Pods: blocked}
+	}
+	TracedLoggerForNode(ctx, n, d.l).Info("Dry run drain, all the evictions would be accepted", zap.Int("pods", len(pods)))
+	return nil
+}
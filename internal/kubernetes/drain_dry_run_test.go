@@ -0,0 +1,78 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	crfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/planetlabs/draino/internal/kubernetes/k8sclient"
+)
+
+func TestAPIDrainer_DrainDryRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		blocked     map[string]bool
+		expectedErr error
+	}{
+		{
+			name: "all the evictions accepted",
+		},
+		{
+			name:        "eviction rejected by a PDB",
+			blocked:     map[string]bool{"pod-b": true},
+			expectedErr: DrainDryRunBlockedError{NodeName: nodeName, Pods: []string{"ns/pod-b (blocked by the disruption budget)"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: []core.Taint{{
+				Key:    k8sclient.DrainoTaintKey,
+				Value:  k8sclient.TaintDraining,
+				Effect: core.TaintEffectNoSchedule,
+			}}}}
+			objects := []runtime.Object{node}
+			for _, name := range []string{"pod-a", "pod-b"} {
+				objects = append(objects, &core.Pod{ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}})
+			}
+			c := fake.NewSimpleClientset(objects...)
+			var dryRuns []string
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+				if !assert.NotNil(t, eviction.DeleteOptions) || !assert.Equal(t, []string{meta.DryRunAll}, eviction.DeleteOptions.DryRun, "only dry-run evictions are expected") {
+					return true, nil, nil
+				}
+				dryRuns = append(dryRuns, eviction.GetName())
+				if tt.blocked[eviction.GetName()] {
+					return true, nil, apierrors.NewTooManyRequests("blocked by the disruption budget", 10)
+				}
+				return true, nil, nil
+			})
+			d := NewAPIDrainer(c, &NoopEventRecorder{}, WithContainerRuntimeClient(crfake.NewClientBuilder().Build()), WithDrainDryRun(true))
+
+			err := d.Drain(context.Background(), node)
+			assert.Equal(t, tt.expectedErr, err)
+			if tt.expectedErr != nil {
+				assert.Equal(t, DrainDryRunBlocked, GetFailureCause(err))
+			}
+			assert.ElementsMatch(t, []string{"pod-a", "pod-b"}, dryRuns)
+			pods, err := c.CoreV1().Pods("ns").List(context.Background(), meta.ListOptions{})
+			assert.NoError(t, err)
+			assert.Len(t, pods.Items, 2, "the pods must stay on the node")
+			for _, a := range c.Actions() {
+				assert.NotEqual(t, "delete", a.GetVerb())
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	core "k8s.io/api/core/v1"
+)
+
+// DrainOverrides replaces some of the settings of an APIDrainer for a single call to DrainWithOptions.
+// The zero value of each field keeps the setting of the drainer.
+type DrainOverrides struct {
+	// StorageClassesAllowingPVDeletion replaces the storage classes given to WithStorageClassesAllowingDeletion when it is not nil
+	StorageClassesAllowingPVDeletion []string
+	// MinEvictionTimeout replaces the value given to MaxGracePeriod when it is positive
+	MinEvictionTimeout time.Duration
+	// EvictionHeadroom replaces the value given to EvictionHeadroom when it is positive
+	EvictionHeadroom time.Duration
+	// DryRun runs the dry-run eviction of the pods to drain instead of evicting them, like WithDrainDryRun. A drainer configured
+	// with WithDrainDryRun always runs the dry run, whatever the value of the override.
+	DryRun bool
+}
+
+type drainOverridesKey struct{}
+
+// resolvedDrainOverrides are the overrides, stored in the context of the drain, in the form used by the drainer
+type resolvedDrainOverrides struct {
+	storageClassesAllowingPVDeletion map[string]struct{}
+	minEvictionTimeout               time.Duration
+	evictionHeadroom                 time.Duration
+	dryRun                           bool
+}
+
+func withDrainOverrides(ctx context.Context, overrides DrainOverrides) context.Context {
+	resolved := &resolvedDrainOverrides{
+		minEvictionTimeout: overrides.MinEvictionTimeout,
+		evictionHeadroom:   overrides.EvictionHeadroom,
+		dryRun:             overrides.DryRun,
+	}
+	if overrides.StorageClassesAllowingPVDeletion != nil {
+		resolved.storageClassesAllowingPVDeletion = map[string]struct{}{}
+		for _, sc := range overrides.StorageClassesAllowingPVDeletion {
+			resolved.storageClassesAllowingPVDeletion[sc] = struct{}{}
+		}
+	}
+	return context.WithValue(ctx, drainOverridesKey{}, resolved)
+}
+
+func getDrainOverrides(ctx context.Context) *resolvedDrainOverrides {
+	if o, ok := ctx.Value(drainOverridesKey{}).(*resolvedDrainOverrides); ok {
+		return o
+	}
+	return &resolvedDrainOverrides{}
+}
+
+// DrainWithOptions drains the node like Drain, with some settings of the drainer replaced for this call only
+func (d *APIDrainer) DrainWithOptions(ctx context.Context, node *core.Node, overrides DrainOverrides) error {
+	return d.Drain(withDrainOverrides(ctx, overrides), node)
+}
+
+func (d *APIDrainer) getStorageClassesAllowingPVDeletion(ctx context.Context) map[string]struct{} {
+	if o := getDrainOverrides(ctx); o.storageClassesAllowingPVDeletion != nil {
+		return o.storageClassesAllowingPVDeletion
+	}
+	return d.storageClassesAllowingPVDeletion
+}
+
+func (d *APIDrainer) getMinEvictionTimeout(ctx context.Context) time.Duration {
+	if o := getDrainOverrides(ctx); o.minEvictionTimeout > 0 {
+		return o.minEvictionTimeout
+	}
+	return d.minEvictionTimeout
+}
+
+func (d *APIDrainer) getEvictionHeadroom(ctx context.Context) time.Duration {
+	if o := getDrainOverrides(ctx); o.evictionHeadroom > 0 {
+		return o.evictionHeadroom
+	}
+	return d.evictionHeadroom
+}
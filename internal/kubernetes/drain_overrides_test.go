@@ -0,0 +1,132 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	crfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/planetlabs/draino/internal/kubernetes/k8sclient"
+)
+
+func TestAPIDrainer_DrainOverrides(t *testing.T) {
+	storageClass := "local"
+	pvc := &core.PersistentVolumeClaim{
+		ObjectMeta: meta.ObjectMeta{Name: "data", Namespace: "ns"},
+		Spec:       core.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+	}
+	pod := &core.Pod{
+		ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", Annotations: map[string]string{PVCStorageClassCleanupAnnotationKey: PVCStorageClassCleanupAnnotationTrueValue}},
+		Spec: core.PodSpec{Volumes: []core.Volume{{
+			Name:         "data",
+			VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+		}}},
+	}
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+
+	tests := []struct {
+		name                   string
+		overrides              *DrainOverrides
+		expectedPVCs           []string
+		expectedMinEviction    time.Duration
+		expectedEvictionBuffer time.Duration
+	}{
+		{
+			name:                   "no override",
+			expectedPVCs:           []string{},
+			expectedMinEviction:    time.Minute,
+			expectedEvictionBuffer: 10 * time.Second,
+		},
+		{
+			name:                   "empty overrides",
+			overrides:              &DrainOverrides{},
+			expectedPVCs:           []string{},
+			expectedMinEviction:    time.Minute,
+			expectedEvictionBuffer: 10 * time.Second,
+		},
+		{
+			name: "overrides",
+			overrides: &DrainOverrides{
+				StorageClassesAllowingPVDeletion: []string{storageClass},
+				MinEvictionTimeout:               5 * time.Minute,
+				EvictionHeadroom:                 time.Second,
+			},
+			expectedPVCs:           []string{"data"},
+			expectedMinEviction:    5 * time.Minute,
+			expectedEvictionBuffer: time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewAPIDrainer(fake.NewSimpleClientset(pvc), &NoopEventRecorder{},
+				WithStorageClassesAllowingDeletion([]string{"other"}),
+				MaxGracePeriod(time.Minute),
+				EvictionHeadroom(10*time.Second),
+			)
+			ctx := context.Background()
+			if tt.overrides != nil {
+				ctx = withDrainOverrides(ctx, *tt.overrides)
+			}
+
+			pvcs, err := d.getInScopePVCs(ctx, node, pod)
+			assert.NoError(t, err)
+			names := []string{}
+			for _, p := range pvcs {
+				names = append(names, p.GetName())
+			}
+			assert.Equal(t, tt.expectedPVCs, names)
+			assert.Equal(t, tt.expectedMinEviction, d.getMinEvictionTimeout(ctx))
+			assert.Equal(t, tt.expectedEvictionBuffer, d.getEvictionHeadroom(ctx))
+
+			// The defaults of the drainer are not mutated
+			assert.Equal(t, map[string]struct{}{"other": {}}, d.storageClassesAllowingPVDeletion)
+			assert.Equal(t, time.Minute, d.minEvictionTimeout)
+			assert.Equal(t, 10*time.Second, d.evictionHeadroom)
+		})
+	}
+}
+
+func TestAPIDrainer_DrainWithOptions_DryRun(t *testing.T) {
+	taintDraining := []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: taintDraining}}
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+
+	c := fake.NewSimpleClientset(node, pod)
+	evictions, dryRunEvictions := 0, 0
+	c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+		if eviction.DeleteOptions != nil && len(eviction.DeleteOptions.DryRun) > 0 {
+			dryRunEvictions++
+			return true, nil, nil
+		}
+		evictions++
+		return true, nil, c.Tracker().Delete(core.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+	})
+	d := NewAPIDrainer(c, &NoopEventRecorder{}, WithContainerRuntimeClient(crfake.NewClientBuilder().Build()))
+
+	// the override runs the same dry-run evictions as WithDrainDryRun
+	assert.NoError(t, d.DrainWithOptions(context.Background(), node, DrainOverrides{DryRun: true}))
+	assert.Equal(t, 0, evictions)
+	assert.Equal(t, 1, dryRunEvictions)
+	_, err := c.CoreV1().Pods("ns").Get(context.Background(), podName, meta.GetOptions{})
+	assert.NoError(t, err, "the pod must not be evicted during a dry run")
+
+	// The next drain without override evicts the pod
+	assert.NoError(t, d.Drain(context.Background(), node))
+	assert.Equal(t, 1, evictions)
+}
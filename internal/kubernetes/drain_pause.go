@@ -0,0 +1,128 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// DrainPauseConfigMapKey is the key of the configmap data pausing all the drains when set to true
+	DrainPauseConfigMapKey = "paused"
+	// DrainPauseReasonConfigMapKey is the optional key of the configmap data explaining the pause, it is reported in the DrainPausedError
+	DrainPauseReasonConfigMapKey = "reason"
+)
+
+// DrainPause is the global switch pausing all the drains, shared by all the drainers of the process
+type DrainPause struct {
+	sync.RWMutex
+	paused bool
+	reason string
+}
+
+// NewDrainPause returns a switch that does not pause the drains
+func NewDrainPause() *DrainPause {
+	return &DrainPause{}
+}
+
+// SetPaused pauses or resumes the drains
+func (p *DrainPause) SetPaused(paused bool, reason string) {
+	p.Lock()
+	defer p.Unlock()
+	p.paused, p.reason = paused, reason
+}
+
+// IsPaused tells if the drains are paused, and why
+func (p *DrainPause) IsPaused() (bool, string) {
+	p.RLock()
+	defer p.RUnlock()
+	return p.paused, p.reason
+}
+
+// ParseDrainPause reads the pause stored under DrainPauseConfigMapKey, a missing key means that the drains are not paused
+func ParseDrainPause(cm *core.ConfigMap) (paused bool, reason string, err error) {
+	value, ok := cm.Data[DrainPauseConfigMapKey]
+	if !ok || strings.TrimSpace(value) == "" {
+		return false, "", nil
+	}
+	if paused, err = strconv.ParseBool(strings.TrimSpace(value)); err != nil {
+		return false, "", fmt.Errorf("invalid value '%s' for '%s', expecting true or false", value, DrainPauseConfigMapKey)
+	}
+	return paused, strings.TrimSpace(cm.Data[DrainPauseReasonConfigMapKey]), nil
+}
+
+// DrainPauseConfigMapWatch watches a configmap and pauses the drains while it says so. The drains are resumed when the configmap is deleted.
+type DrainPauseConfigMapWatch struct {
+	cache.SharedInformer
+	name   string
+	logger *zap.Logger
+	pause  *DrainPause
+}
+
+// NewDrainPauseConfigMapWatch creates a watch on the configmap namespace/name driving the pause
+func NewDrainPauseConfigMapWatch(ctx context.Context, c kubernetes.Interface, namespace, name string, logger *zap.Logger, pause *DrainPause) *DrainPauseConfigMapWatch {
+	w := &DrainPauseConfigMapWatch{
+		SharedInformer: newConfigMapInformer(ctx, c, namespace, name),
+		name:           name,
+		logger:         logger.With(zap.String("configmap", namespace+"/"+name)),
+		pause:          pause,
+	}
+	w.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    w.onChange,
+		UpdateFunc: func(_, newObj interface{}) { w.onChange(newObj) },
+		DeleteFunc: w.onDelete,
+	})
+	return w
+}
+
+func (w *DrainPauseConfigMapWatch) Start(ctx context.Context) {
+	w.Run(ctx.Done())
+}
+
+func (w *DrainPauseConfigMapWatch) onChange(obj interface{}) {
+	cm, ok := obj.(*core.ConfigMap)
+	if !ok || cm.Name != w.name {
+		return
+	}
+	paused, reason, err := ParseDrainPause(cm)
+	if err != nil {
+		w.logger.Error("Ignoring drain pause update, the configmap content is not valid", zap.Error(err))
+		return
+	}
+	if wasPaused, _ := w.pause.IsPaused(); wasPaused != paused {
+		w.logger.Info("Drain pause changed", zap.Bool("paused", paused), zap.String("reason", reason))
+	}
+	w.pause.SetPaused(paused, reason)
+}
+
+func (w *DrainPauseConfigMapWatch) onDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if cm, ok := obj.(*core.ConfigMap); !ok || cm.Name != w.name {
+		return
+	}
+	w.logger.Info("Drain pause configmap deleted, resuming the drains")
+	w.pause.SetPaused(false, "")
+}
+
+// DrainPausedError is returned by the drains started while the drains are paused, the drain should be retried once they are resumed
+type DrainPausedError struct {
+	NodeName string
+	Reason   string
+}
+
+func (e DrainPausedError) Error() string {
+	msg := fmt.Sprintf("drain of node %s not started, the drains are paused", e.NodeName)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
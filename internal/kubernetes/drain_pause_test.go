@@ -0,0 +1,107 @@
+package kubernetes
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	crfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/planetlabs/draino/internal/kubernetes/k8sclient"
+)
+
+func TestDrainPauseConfigMapWatch(t *testing.T) {
+	cm := &core.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{Name: "draino-pause", Namespace: "draino"},
+		Data:       map[string]string{DrainPauseConfigMapKey: "true", DrainPauseReasonConfigMapKey: "cluster upgrade"},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	kclient := fake.NewSimpleClientset(cm)
+	pause := NewDrainPause()
+	watch := NewDrainPauseConfigMapWatch(ctx, kclient, cm.Namespace, cm.Name, zap.NewNop(), pause)
+	go watch.Start(ctx)
+
+	waitForPause := func(expected bool, expectedReason string) {
+		err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			paused, reason := pause.IsPaused()
+			return paused == expected && reason == expectedReason, nil
+		})
+		assert.NoError(t, err)
+	}
+	waitForPause(true, "cluster upgrade")
+
+	// Invalid content is ignored, the drains stay paused
+	cm.Data[DrainPauseConfigMapKey] = "maybe"
+	_, err := kclient.CoreV1().ConfigMaps(cm.Namespace).Update(ctx, cm, meta.UpdateOptions{})
+	assert.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	paused, _ := pause.IsPaused()
+	assert.True(t, paused)
+
+	cm.Data = map[string]string{DrainPauseConfigMapKey: "false"}
+	_, err = kclient.CoreV1().ConfigMaps(cm.Namespace).Update(ctx, cm, meta.UpdateOptions{})
+	assert.NoError(t, err)
+	waitForPause(false, "")
+
+	cm.Data = map[string]string{DrainPauseConfigMapKey: "true"}
+	_, err = kclient.CoreV1().ConfigMaps(cm.Namespace).Update(ctx, cm, meta.UpdateOptions{})
+	assert.NoError(t, err)
+	waitForPause(true, "")
+
+	// Deleting the configmap resumes the drains
+	assert.NoError(t, kclient.CoreV1().ConfigMaps(cm.Namespace).Delete(ctx, cm.Name, meta.DeleteOptions{}))
+	waitForPause(false, "")
+}
+
+func TestDrain_Paused(t *testing.T) {
+	tests := []struct {
+		name      string
+		paused    bool
+		expectErr bool
+	}{
+		{name: "paused", paused: true, expectErr: true},
+		{name: "not paused", paused: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: []core.Taint{{
+				Key:    k8sclient.DrainoTaintKey,
+				Value:  k8sclient.TaintDraining,
+				Effect: core.TaintEffectNoSchedule,
+			}}}}
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+			c := fake.NewSimpleClientset(node, pod)
+			var evictions int32
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				atomic.AddInt32(&evictions, 1)
+				return true, nil, c.Tracker().Delete(core.SchemeGroupVersion.WithResource("pods"), "ns", podName)
+			})
+			pause := NewDrainPause()
+			pause.SetPaused(tt.paused, "maintenance")
+			d := NewAPIDrainer(c, &NoopEventRecorder{}, WithContainerRuntimeClient(crfake.NewClientBuilder().Build()), WithDrainPause(pause))
+
+			err := d.Drain(context.Background(), node)
+			if !tt.expectErr {
+				assert.NoError(t, err)
+				assert.Equal(t, int32(1), atomic.LoadInt32(&evictions))
+				return
+			}
+			assert.Equal(t, DrainPausedError{NodeName: nodeName, Reason: "maintenance"}, err)
+			assert.Equal(t, DrainPaused, GetFailureCause(err))
+			assert.Zero(t, atomic.LoadInt32(&evictions), "no pod must be evicted while the drains are paused")
+		})
+	}
+}
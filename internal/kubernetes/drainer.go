@@ -20,31 +20,43 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
+	"math/rand"
 	"net/http"
 	url2 "net/url"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/planetlabs/draino/internal/kubernetes/index"
 	"github.com/planetlabs/draino/internal/kubernetes/k8sclient"
+	"github.com/planetlabs/draino/internal/kubernetes/utils"
+	"github.com/planetlabs/draino/internal/tracing"
 
 	"github.com/DataDog/go-service-authn/pkg/serviceauthentication/authnclient"
+	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
 	"go.uber.org/zap"
-	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	appsv1 "k8s.io/api/apps/v1"
 	core "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	policy "k8s.io/api/policy/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
@@ -65,6 +77,16 @@ const (
 	DefaultEvictionOverhead             = 30 * time.Second
 	DefaultPVCRecreateTimeout           = 3 * time.Minute
 	DefaultPodDeletePeriodWaitingForPVC = 10 * time.Second
+	DefaultEvictionEndpointMaxErrorBody = 4 * 1024
+	DefaultEvictionAPIMaxRetriesOn500   = 3
+	DefaultEvictionAPIRetryOn500Backoff = 5 * time.Second
+	DefaultEvictionEndpointStatusPoll   = 10 * time.Second
+	DefaultEvictionEndpointTimeout      = 20 * time.Second
+	DefaultEvictionEndpoint500Retries   = 4
+	DefaultDeletionPollFloor            = 6 * time.Second
+	DefaultDeletionPollCeiling          = 2 * time.Minute
+	DefaultPVCCleanupParallelism        = 4
+	DefaultPodListPageSize              = 500
 	awaitPVCDeletionTimeout             = time.Minute
 
 	KindDaemonSet   = "DaemonSet"
@@ -74,9 +96,17 @@ const (
 	DefaultSkipDrain          = false
 
 	EvictionNodeConditionsAnnotationKey        = "draino/node-conditions"
+	EvictionNodeConditionsJSONAnnotationKey    = "draino/node-conditions-json"
 	PVCStorageClassCleanupAnnotationKey        = "draino/delete-pvc-and-pv"
 	PVCStorageClassCleanupAnnotationTrueValue  = "true"
 	PVCStorageClassCleanupAnnotationFalseValue = "false"
+	PVCCleanupDisabledNodeAnnotationKey        = "draino/disable-pvc-cleanup"
+
+	// PVCForceCleanupAnnotationKey, set to "true" on a pod or on one of its PVCs, cleans up the PVC even if its storage class does not allow the deletion
+	PVCForceCleanupAnnotationKey = "draino/force-pvc-cleanup"
+
+	// PVCRecreateTimeoutAnnotationKey, set on a pod or its controller, overrides the time waiting for the recreation of its deleted PVCs (e.g. "10m")
+	PVCRecreateTimeoutAnnotationKey = "draino/pvc-recreate-timeout"
 
 	CompletedStr = "Completed"
 	FailedStr    = "Failed"
@@ -91,10 +121,78 @@ const (
 	eventReasonEvictionSucceeded     = "EvictionSucceeded"
 	eventReasonEvictionFailed        = "EvictionFailed"
 	eventReasonEvictionAttemptFailed = "EvictionAttemptFailed"
+	// eventReasonEvictionAttemptsFailed is the periodic summary replacing the EvictionAttemptFailed events of the node when they are aggregated
+	eventReasonEvictionAttemptsFailed = "EvictionAttemptsFailed"
+	// eventReasonDrainFinalFailed is reported once, when the drain of the node failed for the last allowed attempt and is not retried anymore
+	eventReasonDrainFinalFailed = "DrainFinalFailed"
+	// eventReasonDrainPlan lists the pods about to be evicted from the node, in the order their evictions start
+	eventReasonDrainPlan = "DrainPlan"
 
 	eventReasonBadValueForAnnotation = "BadValueForAnnotation"
 
+	eventReasonEvictionEndpointDegraded = "EvictionEndpointDegraded"
+	// eventReasonEvictionAccepted is reported when a custom eviction endpoint answers 202 and processes the eviction asynchronously
+	eventReasonEvictionAccepted = "EvictionAccepted"
+	// eventReasonEvictionDryRun reports the verdict of a custom eviction endpoint to a dry-run eviction
+	eventReasonEvictionDryRun = "EvictionDryRun"
+
+	eventReasonPVCCleanupSkipped = "PVCCleanupSkipped"
+	eventReasonPVCRecreated      = "PVCRecreated"
+	eventReasonPVCCleanupForced  = "PVCCleanupForced"
+
+	// outcomes of the wait for the PVC recreation, used to tag MeasurePVCRecreateDuration
+	pvcRecreateResultRecreated = "recreated"
+	pvcRecreateResultTimeout   = "timeout"
+	pvcRecreateResultMaxPodDel = "max_pod_deletions"
+	pvcRecreateResultError     = "error"
+
+	// eviction paths and outcomes of the evictions, used to tag MeasurePodEvictionDuration
+	evictionPathKubernetes     = "kubernetes"
+	evictionPathOperator       = "operator"
+	podEvictionResultSucceeded = "succeeded"
+	podEvictionResultTimeout   = "timeout"
+	podEvictionResultAborted   = "aborted"
+	podEvictionResultFailed    = "failed"
+
+	eventReasonNamespaceNotAllowed = "NamespaceNotAllowed"
+	eventReasonPodNameExcluded     = "PodNameExcluded"
+	eventReasonPodFilterError      = "PodFilterError"
+	eventReasonPendingPodSkipped   = "PendingPodSkipped"
+	eventReasonPendingPodIncluded  = "PendingPodIncluded"
+	eventReasonControllerSkipped   = "ControllerKindSkipped"
+
+	podSkippedReasonNamespaceNotAllowed = "namespace-not-allowed"
+	podSkippedReasonPodNameExcluded     = "pod-name-excluded"
+	podSkippedReasonFilterError         = "filter-error"
+	podSkippedReasonPendingPod          = "pending-pod"
+	podSkippedReasonControllerKind      = "controller-kind-skipped"
+
 	EvictionAPIURLAnnotationKey = "draino/eviction-api-url"
+	// EvictionAPITimeoutAnnotationKey, set on a pod or its controller, overrides the timeout of the calls to its custom eviction endpoint (e.g. "45s")
+	EvictionAPITimeoutAnnotationKey = "draino/eviction-api-timeout"
+	// EvictionAPI500RetriesAnnotationKey, set on a pod or its controller, overrides the number of retries after a 500 of its custom eviction endpoint
+	EvictionAPI500RetriesAnnotationKey = "draino/eviction-api-retries-on-500"
+
+	EvictionPropagationPolicyAnnotationKey = "draino/eviction-propagation-policy"
+	EvictionGracePeriodAnnotationKey       = "draino/eviction-grace-period-seconds"
+
+	// MinGracePeriodAnnotationKey, set on a pod or its controller, is a floor of the grace period given to the pod on eviction (e.g. "2m")
+	MinGracePeriodAnnotationKey = "draino/min-grace-period"
+
+	// EvictionNonBlockingAnnotationKey, set to "true" on a pod, lets the drain succeed even if the eviction of the pod fails
+	EvictionNonBlockingAnnotationKey = "draino/eviction-non-blocking"
+
+	// UseDeleteAnnotationKey, set to "true" or "false" on a pod or its controller, overrides WithDeletionInsteadOfEviction for the pod
+	UseDeleteAnnotationKey = "draino/use-delete"
+
+	// EvictionAPIDryRunAnnotationKey, set to "true" or "false" on a pod or its controller, overrides WithEvictionEndpointDryRun for the pod
+	EvictionAPIDryRunAnnotationKey = "draino/eviction-api-dry-run"
+	// EvictionEndpointDryRunQueryParam is added to the URL of the custom eviction endpoint for the dry-run calls, with the value "All"
+	EvictionEndpointDryRunQueryParam = "dryRun"
+
+	eventReasonNonBlockingEvictionFailed = "NonBlockingEvictionFailed"
+	// eventReasonPodRecreatedOnNode is reported when the pod is recreated with the same name on the node being drained, see WithEvictPodsRecreatedOnNode
+	eventReasonPodRecreatedOnNode = "PodRecreatedOnNode"
 )
 
 type nodeMutatorFn func(*core.Node)
@@ -119,6 +217,15 @@ func (e EvictionEndpointError) Error() string {
 	return msg
 }
 
+// EvictionEndpointDryRunRejectedError is returned when a custom eviction endpoint rejects a dry-run eviction, see WithEvictionEndpointDryRun
+type EvictionEndpointDryRunRejectedError struct {
+	StatusCode int
+}
+
+func (e EvictionEndpointDryRunRejectedError) Error() string {
+	return fmt.Sprintf("eviction endpoint rejected the dry run eviction: code=%d", e.StatusCode)
+}
+
 type AudienceNotFoundError struct {
 	Audience string
 }
@@ -156,6 +263,9 @@ func (e PodEvictionTimeoutError) Error() string {
 	return msg
 }
 
+// overlappingDisruptionBudgetsMessage is part of the message of the 500 returned by the eviction API when a pod matches more than one PDB
+const overlappingDisruptionBudgetsMessage = "more than one PodDisruptionBudget"
+
 type OverlappingDisruptionBudgetsError struct {
 }
 
@@ -163,13 +273,72 @@ func (e OverlappingDisruptionBudgetsError) Error() string {
 	return "overlapping pod disruption budgets"
 }
 
+type PodDisruptionBudgetBlockedError struct {
+	PodName string
+	PDBs    []string
+}
+
+func (e PodDisruptionBudgetBlockedError) Error() string {
+	return fmt.Sprintf("pod %s cannot be evicted, its pod disruption budgets do not allow any disruption while all their pods are healthy: %s", e.PodName, strings.Join(e.PDBs, ", "))
+}
+
+type PodsWithoutPDBError struct {
+	NodeName string
+	Pods     []string
+}
+
+func (e PodsWithoutPDBError) Error() string {
+	return fmt.Sprintf("pods on node %s are not protected by any pod disruption budget: %s", e.NodeName, strings.Join(e.Pods, ", "))
+}
+
+type PodsWithoutAlternativePlacementError struct {
+	NodeName string
+	Pods     []string
+}
+
+func (e PodsWithoutAlternativePlacementError) Error() string {
+	return fmt.Sprintf("pods on node %s cannot be placed on any other node, use the annotation %s to evict them anyway: %s", e.NodeName, EvictWithoutAlternativePlacementAnnotationKey, strings.Join(e.Pods, ", "))
+}
+
+type ConditionsResolvedError struct {
+	NodeName string
+}
+
+func (e ConditionsResolvedError) Error() string {
+	return fmt.Sprintf("drain of node %s aborted, the node has no offending condition anymore", e.NodeName)
+}
+
+type PodsRemainingAfterDrainError struct {
+	NodeName string
+	Pods     []string
+}
+
+func (e PodsRemainingAfterDrainError) Error() string {
+	return fmt.Sprintf("pods are still on node %s after the drain: %s", e.NodeName, strings.Join(e.Pods, ", "))
+}
+
 type PodDeletionTimeoutError struct {
+	// PreStopHookRunning is set when the pod has a PreStop hook and was still within its termination grace period:
+	// the pod is slow to terminate rather than stuck.
+	PreStopHookRunning bool
 }
 
 func (e PodDeletionTimeoutError) Error() string {
+	if e.PreStopHookRunning {
+		return "timed out waiting for pod to be deleted (PreStop hook still running within the termination grace period)"
+	}
 	return "timed out waiting for pod to be deleted (stuck terminating, check finalizers)"
 }
 
+// podRecreatedOnNodeError is returned by awaitDeletion when the pod was replaced by a pod with the same name on the same node, the eviction sequence evicts the new pod
+type podRecreatedOnNodeError struct {
+	pod *core.Pod
+}
+
+func (e podRecreatedOnNodeError) Error() string {
+	return fmt.Sprintf("pod %s/%s recreated on node %s with uid %s", e.pod.GetNamespace(), e.pod.GetName(), e.pod.Spec.NodeName, e.pod.GetUID())
+}
+
 type VolumeCleanupError struct {
 	Err error
 }
@@ -182,11 +351,52 @@ func (e VolumeCleanupError) Unwrap() error {
 	return e.Err
 }
 
+type MaxPVCDeletionsExceededError struct {
+	NodeName string
+	PVCName  string
+	Max      int
+}
+
+func (e MaxPVCDeletionsExceededError) Error() string {
+	return fmt.Sprintf("cannot delete pvc %s, the drain of node %s already deleted the maximum of %d pvc(s), manual intervention required", e.PVCName, e.NodeName, e.Max)
+}
+
+type PVCRecreateTimeoutError struct {
+	PodName      string
+	PVCName      string
+	Timeout      time.Duration
+	PodDeletions int
+}
+
+func (e PVCRecreateTimeoutError) Error() string {
+	return fmt.Sprintf("pvc %s was not recreated after %s, pod %s was deleted %d time(s) to force the recreation", e.PVCName, e.Timeout, e.PodName, e.PodDeletions)
+}
+
+type MaxPodDeletionsForPVCRecreateExceededError struct {
+	PodName string
+	PVCName string
+	Max     int
+}
+
+func (e MaxPodDeletionsForPVCRecreateExceededError) Error() string {
+	return fmt.Sprintf("pvc %s was not recreated, pod %s was already deleted the maximum of %d time(s) to force the recreation, manual intervention required", e.PVCName, e.PodName, e.Max)
+}
+
+type NodeNotStableError struct {
+	NodeName string
+	Timeout  time.Duration
+}
+
+func (e NodeNotStableError) Error() string {
+	return fmt.Sprintf("node %s did not stay ready with stable conditions within %s, the drain is not marked as completed", e.NodeName, e.Timeout)
+}
+
 // A Drainer drains nodes.
 type Drainer interface {
 	// Drain the supplied node. Evicts the node of all but mirror and DaemonSet pods.
 	Drain(ctx context.Context, n *core.Node) error
 	MarkDrain(ctx context.Context, n *core.Node, when, finish time.Time, failed bool, failCount int32) error
+	MarkDrainWithReason(ctx context.Context, n *core.Node, when, finish time.Time, failed bool, failCount int32, failureReason string) error
 	MarkDrainDelete(ctx context.Context, n *core.Node) error
 	GetPodsToDrain(ctx context.Context, node string, podStore PodStore) ([]*core.Pod, error)
 	GetMaxDrainAttemptsBeforeFail(ctx context.Context, n *core.Node) int32
@@ -237,6 +447,11 @@ func (d *NoopDrainer) MarkDrain(ctx context.Context, n *core.Node, when, finish
 	return nil
 }
 
+// MarkDrainWithReason does nothing.
+func (d *NoopDrainer) MarkDrainWithReason(ctx context.Context, n *core.Node, when, finish time.Time, failed bool, failCount int32, failureReason string) error {
+	return nil
+}
+
 // MarkDrainDelete does nothing.
 func (d *NoopDrainer) MarkDrainDelete(ctx context.Context, n *core.Node) error {
 	return nil
@@ -262,6 +477,7 @@ func (d *NoopDrainer) GetReplacementStatus(ctx context.Context, n *core.Node) (N
 }
 
 var _ DrainerInstance = &APIDrainer{}
+var _ SuppliedConditionsSetter = &APIDrainer{}
 
 // APIDrainer drains Kubernetes nodes via the Kubernetes API.
 type APIDrainer struct {
@@ -276,11 +492,212 @@ type APIDrainer struct {
 	minEvictionTimeout         time.Duration
 	evictionHeadroom           time.Duration
 	skipDrain                  bool
+	drainDryRun                bool
+	skipTaintCheck             bool
 	maxDrainAttemptsBeforeFail int32
 
 	globalConfig GlobalConfig
+	// timingsFromOptions records the timings set by an option, they take precedence over the ones of the globalConfig
+	timingsFromOptions struct{ minEvictionTimeout, evictionHeadroom, pvcRecreateTimeout bool }
+	// globalConfigLock protects the supplied conditions that can be reloaded at runtime
+	globalConfigLock sync.RWMutex
 
 	storageClassesAllowingPVDeletion map[string]struct{}
+
+	controllerEvents bool
+
+	// structuredConditionsData adds the offending conditions of the node, in JSON, to the eviction starting events of the pods and to the custom eviction requests
+	structuredConditionsData bool
+
+	evictionRequestTransformer EvictionRequestTransformer
+
+	// verifyDrainCompletion checks that no evictable pod is left on the node once all the evictions are done
+	verifyDrainCompletion bool
+
+	// skipPodsOnFilterError leaves on the node the pods for which the filter returns an error, instead of failing the listing of the pods to drain
+	skipPodsOnFilterError bool
+
+	// metricNodeLabelTags are the tags named after the node labels added to the metrics recorded for a node, see WithMetricNodeLabelTags
+	metricNodeLabelTags []tag.Key
+
+	// podListPageSize is the maximum number of pods returned by each call listing the pods of a node with the API, 0 lists them at once
+	podListPageSize int64
+
+	// podsToDrainCache keeps the result of GetPodsToDrain per node name for a short time, nil if disabled
+	podsToDrainCache utils.TTLCache[[]*core.Pod]
+
+	// skipPVCCleanupIfRemovedByOthers does not clean up the PVCs of the pods that were deleted by another actor during the eviction sequence
+	skipPVCCleanupIfRemovedByOthers bool
+	// confirmPodGoneBeforePVCCleanup checks with the API that the pods removed by another actor are gone, and not replaced by a pod with the same name, before cleaning up their PVCs
+	confirmPodGoneBeforePVCCleanup bool
+
+	// respectPVCRetentionPolicy does not clean up the PVCs of the pods whose StatefulSet retains its PVCs when it is deleted
+	respectPVCRetentionPolicy bool
+
+	// maxPVCDeletionsPerDrain is the maximum number of PVCs deleted during a single drain, 0 means no limit
+	maxPVCDeletionsPerDrain int
+	// pvcCleanupParallelism is the maximum number of PVCs of a pod cleaned up at once, see forEachPVC
+	pvcCleanupParallelism int
+
+	// deletionPollFloor and deletionPollCeiling bound the period of the checks of the pod and PV deletions
+	deletionPollFloor   time.Duration
+	deletionPollCeiling time.Duration
+
+	// pvcDeletionTimeouts are the times waiting for the deletion of the PVCs and PVs per storage class, the classes that are not listed
+	// wait for awaitPVCDeletionTimeout
+	pvcDeletionTimeouts map[string]time.Duration
+
+	// pvcRecreateTimeout is the time waiting for the recreation of a deleted PVC, it can be overridden per pod with PVCRecreateTimeoutAnnotationKey
+	pvcRecreateTimeout time.Duration
+
+	// maxPodDeletionsForPVCRecreate is the maximum number of times a pod is deleted to force the recreation of one of its PVCs, 0 means no limit
+	maxPodDeletionsForPVCRecreate int
+
+	// conditionsRecheckPeriod is the period at which the offending conditions of the node are re-evaluated during the drain, 0 disables it
+	conditionsRecheckPeriod time.Duration
+	// evictionAttemptEventsPeriod is the period of the summary of the failed eviction attempts of a drain, 0 emits one node event per attempt
+	evictionAttemptEventsPeriod time.Duration
+
+	// nodeStabilityPeriod is the time the node must stay ready, with unchanged conditions, before a drain is marked as completed. 0 disables the gate.
+	nodeStabilityPeriod time.Duration
+	// nodeStabilityTimeout bounds the wait for the stability of the node, the drain is marked as failed when it expires
+	nodeStabilityTimeout time.Duration
+
+	// evictionOrder shuffles the pods before starting their evictions when it is set, it is protected by evictionOrderLock
+	evictionOrder     *rand.Rand
+	evictionOrderLock sync.Mutex
+	// evictionBackoffJitter randomizes the waits between the eviction attempts of a pod when it is set, evictionBackoffRand is protected by evictionBackoffRandLock
+	evictionBackoffJitter   EvictionBackoffJitter
+	evictionBackoffRand     *rand.Rand
+	evictionBackoffRandLock sync.Mutex
+	// drainPlanEvent emits a node event listing the pods to evict before starting the evictions
+	drainPlanEvent bool
+
+	// failureCauseEventReasons replaces the reason of the eviction failure events, per failure cause of the error
+	failureCauseEventReasons map[FailureCause]string
+
+	// drainSummaryCallback is called once at the end of each drain
+	drainSummaryCallback func(DrainSummary)
+
+	// evictionEndpointMaxErrorBody is the maximum number of bytes read from the error responses of the custom eviction endpoints
+	evictionEndpointMaxErrorBody int64
+
+	// evictionEndpointTimeout is the timeout of the calls to the custom eviction endpoints, the EvictionAPITimeoutAnnotationKey annotation takes precedence
+	evictionEndpointTimeout time.Duration
+	// evictionEndpoint500Retries is the number of retries of an eviction after a 500 of a custom eviction endpoint, the EvictionAPI500RetriesAnnotationKey
+	// annotation takes precedence
+	evictionEndpoint500Retries int
+
+	// evictionEndpointTLSConfig is the TLS configuration of the calls to the https custom eviction endpoints, when it is nil
+	// the server certificate is not verified
+	evictionEndpointTLSConfig *tls.Config
+
+	// evictionEndpointRoundTripper replaces the default transport of the calls to the custom eviction endpoints when it is set
+	evictionEndpointRoundTripper http.RoundTripper
+
+	// evictionEndpointResolver gives the custom eviction endpoint of the pods that are not annotated, it is optional
+	evictionEndpointResolver EvictionEndpointResolver
+
+	// evictionEndpointDegradedThreshold is the latency above which a call to a custom eviction endpoint is reported as degraded, 0 disables it
+	evictionEndpointDegradedThreshold time.Duration
+	// evictionEndpointAcceptAsync treats the 202 answers of the custom eviction endpoints as accepted evictions pending an asynchronous processing.
+	// When evictionEndpointStatusURLHeader is set and found in the 202 answer, the status URL it gives is polled every evictionEndpointStatusPoll
+	evictionEndpointAcceptAsync     bool
+	evictionEndpointStatusURLHeader string
+	evictionEndpointStatusPoll      time.Duration
+	// evictionAPIMaxRetriesOn500 is the number of retries of an eviction after a transient 500 of the Kubernetes eviction API,
+	// the first retry waits for evictionAPIRetryOn500Backoff and the wait doubles at each retry
+	evictionAPIMaxRetriesOn500   int
+	evictionAPIRetryOn500Backoff time.Duration
+	// evictionEndpointStats keeps the recent calls to the custom eviction endpoints, see EvictionEndpointStats
+	evictionEndpointStats *evictionEndpointStats
+
+	// evictionDeleteOptions are sent with the eviction requests, they can be overridden per pod with annotations
+	evictionDeleteOptions *meta.DeleteOptions
+
+	// checkAlternativePlacement fails the drain if one of the pods to evict cannot be placed on any other node
+	checkAlternativePlacement bool
+
+	// deleteInsteadOfEvict deletes the pods with the Kubernetes API rather than evicting them, the PDBs are not respected.
+	// The UseDeleteAnnotationKey annotation takes precedence.
+	deleteInsteadOfEvict bool
+
+	// evictRecreatedPodsOnNode evicts the pods recreated with the same name on the node while waiting for the deletion of the evicted pod
+	evictRecreatedPodsOnNode bool
+
+	// evictionEndpointDryRun only sends dry-run evictions to the custom eviction endpoints, the EvictionAPIDryRunAnnotationKey annotation takes precedence
+	evictionEndpointDryRun bool
+
+	// requirePDB fails the drain if one of the pods to evict is not covered by a PDB
+	requirePDB bool
+	pdbIndexer index.PDBIndexer
+
+	// pdbWaitEstimator adds the time spent waiting for the disruption budgets to EstimateDrainDuration, it is optional
+	pdbWaitEstimator PDBWaitEstimator
+
+	// workloadUnavailability defers the evictions that would make too many pods of a workload unavailable at once, it is optional
+	workloadUnavailability *WorkloadUnavailabilityCoordinator
+
+	// drainPause is the global switch checked before starting a drain, it is optional
+	drainPause *DrainPause
+	// activeDrains tracks the drains in progress, see CancelDrain
+	activeDrains *activeDrains
+
+	// failFastOnBlockedPDB stops retrying the eviction of a pod when one of its PDBs is permanently blocked
+	failFastOnBlockedPDB bool
+
+	// namespaceAllowList restricts the drain to the pods of these namespaces, nil means all namespaces are allowed
+	namespaceAllowList map[string]struct{}
+
+	// podNameExclusions are the expressions matched against the pod names, the matching pods are not evicted
+	podNameExclusions []regexp.Regexp
+
+	// skipControllerKinds are the kinds of owners whose pods are not evicted, they are handled by another system
+	skipControllerKinds map[schema.GroupKind]struct{}
+
+	// excludePendingPods leaves the Pending pods on the node instead of evicting them
+	excludePendingPods bool
+}
+
+// DrainSummary describes the result of a drain, it is given to the callback set with WithDrainSummaryCallback
+type DrainSummary struct {
+	NodeName string
+	Duration time.Duration
+	// Pods contains one entry per eviction that completed before the end of the drain.
+	// The evictions aborted after a failure are not listed.
+	Pods         []PodEvictionSummary
+	TotalRetries int
+	// AlreadyDrained is set when the node had no pod to evict, the drain succeeded without doing anything
+	AlreadyDrained bool
+	Err            error
+}
+
+// PodEvictionSummary describes the eviction of one pod during a drain
+type PodEvictionSummary struct {
+	Namespace string
+	Name      string
+	// EvictionEndpoint is the custom endpoint used for the eviction, empty if the kubernetes API was used
+	EvictionEndpoint string
+	Duration         time.Duration
+	Retries          int
+	PVCsDeleted      []string
+	// RemovedByOtherActor is set when the pod was deleted without any eviction call of the drainer being accepted
+	RemovedByOtherActor bool
+	// HasPreStopHook is set when one of the containers of the pod has a PreStop hook, its deletion can take the whole grace period
+	HasPreStopHook bool
+	// NonBlocking is set when the pod has the EvictionNonBlockingAnnotationKey annotation, a failure of its eviction does not fail the drain
+	NonBlocking bool
+	Err         error
+
+	attempts int
+}
+
+// EvictionRequestTransformer builds the body and the content-type of the request sent to a custom eviction endpoint
+type EvictionRequestTransformer func(eviction *policy.Eviction) (body []byte, contentType string, err error)
+
+// DefaultEvictionRequestTransformer sends the policy.Eviction serialized in JSON
+func DefaultEvictionRequestTransformer(eviction *policy.Eviction) ([]byte, string, error) {
+	return GetEvictionJsonPayload(eviction).Bytes(), "application/json", nil
 }
 
 // APIDrainerOption configures an APIDrainer.
@@ -292,6 +709,7 @@ type APIDrainerOption func(d *APIDrainer)
 func MaxGracePeriod(m time.Duration) APIDrainerOption {
 	return func(d *APIDrainer) {
 		d.minEvictionTimeout = m
+		d.timingsFromOptions.minEvictionTimeout = true
 	}
 }
 
@@ -300,6 +718,7 @@ func MaxGracePeriod(m time.Duration) APIDrainerOption {
 func EvictionHeadroom(h time.Duration) APIDrainerOption {
 	return func(d *APIDrainer) {
 		d.evictionHeadroom = h
+		d.timingsFromOptions.evictionHeadroom = true
 	}
 }
 
@@ -318,6 +737,15 @@ func WithSkipDrain(b bool) APIDrainerOption {
 	}
 }
 
+// WithDrainDryRun configures an APIDrainer to only run the dry-run eviction of the pods to drain. Drain does not evict any pod, it returns
+// a DrainDryRunBlockedError listing the pods whose eviction would be rejected, or nil if the node could be drained.
+// DrainOverrides.DryRun runs the same dry run for a single drain, it cannot disable the dry run configured here.
+func WithDrainDryRun(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.drainDryRun = b
+	}
+}
+
 // WithAPIDrainerLogger configures a APIDrainer to use the supplied
 // logger.
 func WithAPIDrainerLogger(l *zap.Logger) APIDrainerOption {
@@ -350,376 +778,1746 @@ func WithRuntimeObjectStore(store RuntimeObjectStore) APIDrainerOption {
 	}
 }
 
-// WithGlobalConfig give the list of conditions for which draino is triggered
+// WithGlobalConfig give the list of conditions for which draino is triggered, and the default timings of the drainer
 func WithGlobalConfig(globalConfig GlobalConfig) APIDrainerOption {
 	return func(d *APIDrainer) {
 		d.globalConfig = globalConfig
 	}
 }
 
-func WithContainerRuntimeClient(client client.Client) APIDrainerOption {
+// WithPodNameExclusion configures an APIDrainer to leave on the node the pods whose name matches one of the expressions.
+// It is applied after the pod filter.
+func WithPodNameExclusion(exclusions []regexp.Regexp) APIDrainerOption {
 	return func(d *APIDrainer) {
-		d.crClient = client
+		d.podNameExclusions = exclusions
 	}
 }
 
-// NewAPIDrainer returns a Drainer that drains nodes
-func NewAPIDrainer(c kubernetes.Interface, eventRecorder EventRecorder, ao ...APIDrainerOption) *APIDrainer {
-	d := &APIDrainer{
-		c:                  c,
-		l:                  zap.NewNop(),
-		filter:             NewPodFilters(),
-		minEvictionTimeout: DefaultMinEvictionTimeout,
-		evictionHeadroom:   DefaultEvictionOverhead,
-		skipDrain:          DefaultSkipDrain,
-		eventRecorder:      eventRecorder,
+// WithExcludePendingPods configures an APIDrainer to leave the Pending pods (not scheduled yet, or pulling their images) on the node.
+// By default they are evicted as the other pods.
+func WithExcludePendingPods(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.excludePendingPods = b
 	}
-	for _, o := range ao {
-		o(d)
+}
+
+// WithStructuredConditionsData configures an APIDrainer to annotate the eviction starting events of the pods and the custom eviction requests
+// with the offending conditions of the node in JSON, under EvictionNodeConditionsJSONAnnotationKey
+func WithStructuredConditionsData(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.structuredConditionsData = b
 	}
-	return d
 }
 
-func GetNodeRetryMaxAttempt(n *core.Node) (customValue int32, usedDefault bool, err error) {
-	if maxStr, ok := n.Annotations[CustomRetryMaxAttemptAnnotation]; ok {
-		maxValue, err := strconv.Atoi(maxStr)
-		if err != nil {
-			return 0, true, fmt.Errorf(CustomRetryMaxAttemptAnnotation+" can't convert value. Ignoring the user value '%s' and using default instead. Error: %w", maxStr, err)
+// WithControllerEvents configures an APIDrainer to also emit the eviction events on the controller of the pod
+func WithControllerEvents(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.controllerEvents = b
+	}
+}
+
+// WithNamespaceAllowList configures an APIDrainer to only evict pods of the given namespaces. An empty list allows all namespaces.
+func WithNamespaceAllowList(namespaces []string) APIDrainerOption {
+	return func(d *APIDrainer) {
+		if len(namespaces) == 0 {
+			d.namespaceAllowList = nil
+			return
 		}
-		if maxValue < 1 { // to disable retry the user should use annotation draino/drain-retry=false
-			return 0, true, fmt.Errorf(CustomRetryMaxAttemptAnnotation+" has a zero or negative value. Ignoring the value '%s' and using default instead.", maxStr)
+		d.namespaceAllowList = map[string]struct{}{}
+		for _, ns := range namespaces {
+			d.namespaceAllowList[ns] = struct{}{}
 		}
-		if maxValue > 100 { // it does not make sense to have bigger value. User should play with `retry-delay` parameter at some point to increase the retry period
-			return 100, false, fmt.Errorf(CustomRetryMaxAttemptAnnotation+" has a too big value '%s'. Ignoring the value and using 100 instead.", maxStr)
+	}
+}
+
+// WithSkipControllerKinds configures an APIDrainer to leave on the node the pods owned by one of the kinds, e.g. a custom resource
+// whose pods are drained by its own operator. All the owners of the chain of GetOwnerChain are considered, not only the controller of the pod.
+func WithSkipControllerKinds(kinds []schema.GroupKind) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.skipControllerKinds = map[schema.GroupKind]struct{}{}
+		for _, kind := range kinds {
+			d.skipControllerKinds[kind] = struct{}{}
 		}
-		return int32(maxValue), false, nil
 	}
-	return 0, true, nil
 }
 
-func (d *APIDrainer) GetMaxDrainAttemptsBeforeFail(ctx context.Context, n *core.Node) int32 {
-	customValue, useDefault, err := GetNodeRetryMaxAttempt(n)
-	if err != nil {
-		d.l.Warn(err.Error(), zap.String("node", n.Name))
-		d.eventRecorder.NodeEventf(ctx, n, core.EventTypeWarning, eventReasonBadValueForAnnotation, err.Error())
+// WithEvictionRequestTransformer configures the way the request sent to custom eviction endpoints is built.
+// A nil transformer falls back to DefaultEvictionRequestTransformer.
+func WithEvictionRequestTransformer(t EvictionRequestTransformer) APIDrainerOption {
+	return func(d *APIDrainer) {
+		if t == nil {
+			t = DefaultEvictionRequestTransformer
+		}
+		d.evictionRequestTransformer = t
 	}
-	if useDefault {
-		return d.maxDrainAttemptsBeforeFail
+}
+
+// WithSkipTaintCheck configures an APIDrainer to drain nodes that do not have the draining taint.
+// It is intended for library use, when the caller has already validated the node.
+func WithSkipTaintCheck(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.skipTaintCheck = b
 	}
-	return customValue
 }
 
-func (d *APIDrainer) ResetRetryAnnotation(ctx context.Context, n *core.Node) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "ResetRetryAnnotation")
-	defer span.Finish()
+// WithEvictionEndpointMaxErrorBody configures the maximum number of bytes of the error responses of the custom eviction endpoints that are kept for the logs
+func WithEvictionEndpointMaxErrorBody(maxBytes int64) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.evictionEndpointMaxErrorBody = maxBytes
+	}
+}
 
-	if _, ok := n.Labels[NodeLabelKeyReplaceRequest]; ok {
-		k8sclient.PatchDeleteNodeLabelKey(ctx, d.c, n.Name, NodeLabelKeyReplaceRequest)
+// WithEvictionEndpointTimeout configures the timeout of the calls to the custom eviction endpoints
+func WithEvictionEndpointTimeout(timeout time.Duration) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.evictionEndpointTimeout = timeout
 	}
+}
 
-	// Till we are done with the annotation migration to the new key we have to deal with the 2 keys. Later we can remove that first block.
-	if n.Annotations[drainRetryAnnotationKey] == drainRetryFailedAnnotationValue {
-		k8sclient.PatchNodeAnnotationKey(ctx, d.c, n.Name, drainRetryAnnotationKey, drainRetryAnnotationValue)
+// WithEvictionEndpoint500Retries configures how many times an eviction is retried after a 500 of a custom eviction endpoint, 0 retries fails the eviction at once
+func WithEvictionEndpoint500Retries(retries int) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.evictionEndpoint500Retries = retries
 	}
-	return k8sclient.PatchDeleteNodeAnnotationKey(ctx, d.c, n.Name, drainRetryFailedAnnotationKey)
 }
 
-// MarkDrainDelete removes the condition on the node to mark the current drain schedule.
-func (d *APIDrainer) MarkDrainDelete(ctx context.Context, n *core.Node) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "MarkDrainDelete")
-	defer span.Finish()
+// WithDeletionInsteadOfEviction makes the drainer delete the pods that have no custom eviction endpoint instead of evicting them.
+// The deletion does not respect the PDBs, the pod can still choose with the UseDeleteAnnotationKey annotation.
+func WithDeletionInsteadOfEviction(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.deleteInsteadOfEvict = b
+	}
+}
 
-	if err := RetryWithTimeout(
-		func() error {
-			nodeName := n.Name
-			// Refresh the node object
-			freshNode, err := d.c.CoreV1().Nodes().Get(ctx, nodeName, meta.GetOptions{})
-			if err != nil {
-				if !apierrors.IsNotFound(err) {
-					return err
-				}
-				return nil
-			}
-			newConditions := []core.NodeCondition{}
-			for _, condition := range freshNode.Status.Conditions {
-				if string(condition.Type) == ConditionDrainedScheduled {
-					continue
-				}
-				newConditions = append(newConditions, condition)
-			}
-			if len(newConditions) == len(freshNode.Status.Conditions) {
-				return nil
-			}
-			freshNode.Status.Conditions = newConditions
-			if _, err := d.c.CoreV1().Nodes().UpdateStatus(ctx, freshNode, meta.UpdateOptions{FieldManager: "draino"}); err != nil {
-				return err
-			}
-			return nil
-		},
-		SetConditionRetryPeriod,
-		SetConditionTimeout,
-	); err != nil {
-		return err
+// WithEvictPodsRecreatedOnNode configures an APIDrainer to evict the pod recreated with the same name on the same node while waiting for the
+// deletion of an evicted pod, e.g. by a StatefulSet whose pods tolerate the taint of the drain, instead of considering the eviction complete.
+// The pods recreated on another node still complete the eviction, the wait goes on while the recreated pod is not scheduled yet.
+// A recreated pod still not scheduled at the end of the grace period completes the eviction: it may wait for the cleanup of its PVCs.
+// The evictions go on until the eviction timeout of the pod.
+func WithEvictPodsRecreatedOnNode(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.evictRecreatedPodsOnNode = b
 	}
-	return nil
 }
 
-// MarkDrain set a condition on the node to mark that the drain is scheduled. (retry internally in case of failure)
-func (d *APIDrainer) MarkDrain(ctx context.Context, n *core.Node, when, finish time.Time, failed bool, failCount int32) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "MarkDrain")
-	defer span.Finish()
+// WithEvictionEndpointDryRun makes the drainer only send dry-run evictions to the custom eviction endpoints: the EvictionEndpointDryRunQueryParam
+// parameter is added to the URL and the DeleteOptions of the payload have DryRun set. The endpoint is called once per pod, its verdict is reported
+// with an event and the pod is not waited for. An endpoint must honor the parameter, Draino cannot prevent the eviction otherwise.
+// The pod can still choose with the EvictionAPIDryRunAnnotationKey annotation.
+func WithEvictionEndpointDryRun(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.evictionEndpointDryRun = b
+	}
+}
 
-	span.SetTag("node", n.GetName())
-	span.SetTag("when", when)
-	span.SetTag("finish", finish)
-	span.SetTag("failCount", failCount)
-	span.SetTag("failed", failed)
+// WithEvictionEndpointResolver configures the resolver consulted for the pods without the EvictionAPIURLAnnotationKey annotation.
+// The annotation on the pod or its controller always overrides the resolver.
+func WithEvictionEndpointResolver(r EvictionEndpointResolver) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.evictionEndpointResolver = r
+	}
+}
 
-	if err := RetryWithTimeout(
-		func() error {
-			nodeName := n.Name
-			// Refresh the node object
-			freshNode, err := d.c.CoreV1().Nodes().Get(ctx, nodeName, meta.GetOptions{})
-			if err != nil {
-				if !apierrors.IsNotFound(err) {
-					return err
-				}
-				return nil
-			}
+// WithEvictionEndpointTLS configures the verification of the certificates of the https custom eviction endpoints. When caBundle is set,
+// the certificates must be signed by one of its PEM encoded CAs, whatever insecure. Else the system CAs are used, unless insecure
+// skips the verification. If the bundle has no valid certificate, no endpoint can be verified. Without this option, the certificates are not verified.
+func WithEvictionEndpointTLS(caBundle []byte, insecure bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		if len(caBundle) == 0 {
+			d.evictionEndpointTLSConfig = &tls.Config{InsecureSkipVerify: insecure}
+			return
+		}
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(caBundle)
+		d.evictionEndpointTLSConfig = &tls.Config{RootCAs: pool}
+	}
+}
 
-			msgSuffix := ""
-			conditionStatus := core.ConditionTrue
-			if !finish.IsZero() {
-				if failed {
-					msgSuffix = fmt.Sprintf(" | %s: %s", FailedStr, finish.Format(time.RFC3339))
-					if failCount >= d.GetMaxDrainAttemptsBeforeFail(ctx, n) {
-						freshNode.Annotations[drainRetryFailedAnnotationKey] = drainRetryFailedAnnotationValue
-					}
-				} else {
-					msgSuffix = fmt.Sprintf(" | %s: %s", CompletedStr, finish.Format(time.RFC3339))
-				}
-				conditionStatus = core.ConditionFalse
-			}
+// WithEvictionEndpointRoundTripper configures the base transport of the calls to the custom eviction endpoints, in place of
+// the default transport chosen from the scheme of the URL. The token layer is still added on top of it when the URL has a token audience.
+func WithEvictionEndpointRoundTripper(roundTripper http.RoundTripper) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.evictionEndpointRoundTripper = roundTripper
+	}
+}
 
-			// Create or update the condition associated to the monitor
-			now := meta.Time{Time: time.Now()}
-			conditionUpdated := false
-			msgPrefix := fmt.Sprintf("[%d] | ", failCount)
-			for i, condition := range freshNode.Status.Conditions {
-				if string(condition.Type) != ConditionDrainedScheduled {
-					continue
-				}
-				freshNode.Status.Conditions[i].LastHeartbeatTime = now
-				freshNode.Status.Conditions[i].Message = msgPrefix + "Drain activity scheduled " + when.Format(time.RFC3339) + msgSuffix
-				freshNode.Status.Conditions[i].Status = conditionStatus
-				conditionUpdated = true
+// WithEvictionEndpointDegradedThreshold configures an APIDrainer to warn when a call to a custom eviction endpoint takes longer than the threshold
+func WithEvictionEndpointDegradedThreshold(threshold time.Duration) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.evictionEndpointDegradedThreshold = threshold
+	}
+}
+
+// WithEvictionEndpointAcceptAsync configures an APIDrainer to accept the 202 answers of the custom eviction endpoints: the eviction is pending
+// and the drainer waits for the deletion of the pod. If statusURLHeader is not empty and found in the answer, the status URL it contains
+// is polled every pollPeriod until it answers 200 (eviction processed); 202 means still pending and any other answer fails the eviction.
+func WithEvictionEndpointAcceptAsync(statusURLHeader string, pollPeriod time.Duration) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.evictionEndpointAcceptAsync = true
+		d.evictionEndpointStatusURLHeader = statusURLHeader
+		d.evictionEndpointStatusPoll = pollPeriod
+	}
+}
+
+// WithEvictionAPIRetriesOn500 configures how many times an eviction is retried after a 500 of the Kubernetes eviction API that is not
+// caused by overlapping PDBs, and the wait before the first retry. The wait doubles at each retry, 0 retries fails the eviction at once.
+func WithEvictionAPIRetriesOn500(maxRetries int, backoff time.Duration) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.evictionAPIMaxRetriesOn500 = maxRetries
+		d.evictionAPIRetryOn500Backoff = backoff
+	}
+}
+
+// WithEvictionDeleteOptions configures the DeleteOptions (propagation policy, grace period) sent with the eviction requests
+func WithEvictionDeleteOptions(o *meta.DeleteOptions) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.evictionDeleteOptions = o
+	}
+}
+
+// WithDrainSummaryCallback configures an APIDrainer to call the function with a summary of each drain once it is done
+func WithDrainSummaryCallback(f func(DrainSummary)) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.drainSummaryCallback = f
+	}
+}
+
+// WithDrainCompletionVerification configures an APIDrainer to fail the drain if evictable pods are still on the node after all the evictions
+func WithDrainCompletionVerification(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.verifyDrainCompletion = b
+	}
+}
+
+// WithSkipPodsOnFilterError configures an APIDrainer to leave on the node the pods for which the pod filter returns an error,
+// with an event, and to drain the other pods. By default the error of the filter fails the drain.
+func WithSkipPodsOnFilterError(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.skipPodsOnFilterError = b
+	}
+}
+
+// WithMetricNodeLabelTags configures an APIDrainer to add the given node labels as tags, named after the labels, to the metrics it records for
+// a node: the drain durations and failures, the eviction attempts and durations, the eviction endpoint latency and the replacement requests.
+// The views must list the tags, see MetricNodeLabelTagKeys. The invalid labels are ignored and at most MaxMetricNodeLabelTags are kept.
+func WithMetricNodeLabelTags(labels []string) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.metricNodeLabelTags = nil
+		for _, label := range labels {
+			if len(d.metricNodeLabelTags) == MaxMetricNodeLabelTags {
+				break
+			}
+			if keys, err := MetricNodeLabelTagKeys([]string{label}); err == nil {
+				d.metricNodeLabelTags = append(d.metricNodeLabelTags, keys...)
+			}
+		}
+	}
+}
+
+// WithPodListPageSize configures how many pods are returned by each call listing the pods of a node with the API, when no pod store is used.
+// The pages are gathered until the listing is complete. 0 lists all the pods of the node at once.
+func WithPodListPageSize(size int64) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.podListPageSize = size
+	}
+}
+
+// WithPodsToDrainCacheTTL configures an APIDrainer to reuse the pods returned by GetPodsToDrain for a node during the ttl, instead of
+// listing and filtering the pods again. The verification of the drain completion always lists the pods. Disabled if the ttl is not positive.
+func WithPodsToDrainCacheTTL(ttl time.Duration) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.podsToDrainCache = nil
+		if ttl > 0 {
+			d.podsToDrainCache = utils.NewTTLCache[[]*core.Pod](ttl, ttl)
+		}
+	}
+}
+
+// WithSkipPVCCleanupIfRemovedByOthers configures an APIDrainer to not delete the PVCs of a pod that was removed by another actor
+// before any of the eviction calls of the drainer was accepted, the cleanup is left to that actor.
+func WithSkipPVCCleanupIfRemovedByOthers(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.skipPVCCleanupIfRemovedByOthers = b
+	}
+}
+
+// WithConfirmPodGoneBeforePVCCleanup configures an APIDrainer to fetch again a pod that was removed by another actor before deleting its PVCs.
+// The cleanup is skipped if a pod with the same name but another UID was created in the meantime, it may be using the PVCs.
+func WithConfirmPodGoneBeforePVCCleanup(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.confirmPodGoneBeforePVCCleanup = b
+	}
+}
+
+// WithRespectPVCRetentionPolicy configures an APIDrainer to not delete the PVCs of a pod owned by a StatefulSet whose
+// persistentVolumeClaimRetentionPolicy.whenDeleted is Retain. The whenDeleted scope applies to the deletion of the StatefulSet,
+// Kubernetes does not define a policy for the eviction of its pods: this option maps the StatefulSet deletion retention onto the eviction.
+func WithRespectPVCRetentionPolicy(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.respectPVCRetentionPolicy = b
+	}
+}
+
+// WithMaxPVCDeletionsPerDrain configures an APIDrainer to delete at most max PVCs during a single drain.
+// The drain fails with a MaxPVCDeletionsExceededError when more PVCs should be deleted. A zero value means no limit.
+func WithMaxPVCDeletionsPerDrain(max int) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.maxPVCDeletionsPerDrain = max
+	}
+}
+
+// WithPVCCleanupParallelism configures how many PVCs of a pod, and then their PVs, are deleted at once. The pod is also deleted to force
+// the recreation of the PVCs by their StatefulSet while waiting for several PVCs at once. 1 processes the PVCs one after the other.
+func WithPVCCleanupParallelism(parallelism int) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.pvcCleanupParallelism = parallelism
+	}
+}
+
+// WithDeletionPollBounds configures the minimum and maximum periods of the checks of the pod and PV deletions. The period is a tenth
+// of the deletion timeout within these bounds: a lower floor catches the deletions faster, a higher one makes fewer API calls.
+func WithDeletionPollBounds(floor, ceiling time.Duration) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.deletionPollFloor = floor
+		d.deletionPollCeiling = ceiling
+	}
+}
+
+// WithPVCDeletionTimeouts configures the time an APIDrainer waits for the deletion of the PVCs and PVs of a storage class, keyed by
+// storage class name. The storage classes that are not listed keep the default of one minute.
+func WithPVCDeletionTimeouts(timeouts map[string]time.Duration) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.pvcDeletionTimeouts = timeouts
+	}
+}
+
+// ParsePVCDeletionTimeouts parses the PVC deletion timeouts formatted as <storage class>=<duration>
+func ParsePVCDeletionTimeouts(entries []string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	for _, entry := range entries {
+		class, value, found := strings.Cut(entry, "=")
+		class, value = strings.TrimSpace(class), strings.TrimSpace(value)
+		if !found || class == "" || value == "" {
+			return nil, fmt.Errorf("invalid pvc deletion timeout '%s', expecting <storage class>=<duration>", entry)
+		}
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid pvc deletion timeout '%s' for storage class '%s', expecting a positive duration", value, class)
+		}
+		if _, exist := timeouts[class]; exist {
+			return nil, fmt.Errorf("duplicated pvc deletion timeout for storage class '%s'", class)
+		}
+		timeouts[class] = timeout
+	}
+	return timeouts, nil
+}
+
+// WithPVCRecreateTimeout configures the time an APIDrainer waits for the recreation of a deleted PVC.
+// The drain fails with a PVCRecreateTimeoutError when the PVC is not recreated in time.
+func WithPVCRecreateTimeout(timeout time.Duration) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.pvcRecreateTimeout = timeout
+		d.timingsFromOptions.pvcRecreateTimeout = true
+	}
+}
+
+// WithMaxPodDeletionsForPVCRecreate configures an APIDrainer to delete a pod at most max times to force the recreation of one of its PVCs.
+// The drain fails with a MaxPodDeletionsForPVCRecreateExceededError when more deletions would be needed. A zero value means no limit.
+func WithMaxPodDeletionsForPVCRecreate(max int) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.maxPodDeletionsForPVCRecreate = max
+	}
+}
+
+// WithConditionsRecheckPeriod configures an APIDrainer to re-evaluate the offending conditions of the node periodically during the drain.
+// If none of them is present anymore the drain is aborted with a ConditionsResolvedError. A zero period disables the re-evaluation.
+func WithConditionsRecheckPeriod(period time.Duration) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.conditionsRecheckPeriod = period
+	}
+}
+
+// WithEvictionAttemptEventsAggregation configures an APIDrainer to replace the EvictionAttemptFailed events of the node, one per
+// failed attempt, by a summary of the pods awaiting PDB budget emitted at the given period. The events of the pods are not changed.
+// A zero period disables the aggregation.
+func WithEvictionAttemptEventsAggregation(period time.Duration) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.evictionAttemptEventsPeriod = period
+	}
+}
+
+// WithNodeStabilityGate configures an APIDrainer to wait, at the end of a successful drain, for the node to stay ready with unchanged
+// conditions during the stability period. If the node is still flapping after the timeout, Drain returns a NodeNotStableError
+// and the drain runner handles it as a failed drain. A zero period disables the gate.
+func WithNodeStabilityGate(period, timeout time.Duration) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.nodeStabilityPeriod = period
+		d.nodeStabilityTimeout = timeout
+	}
+}
+
+// WithRandomizedEvictionOrder configures an APIDrainer to start the evictions of the pods of a node in a random order, so that the same
+// pod is not always the first one evicted. The seed makes the sequence of orders reproducible.
+func WithRandomizedEvictionOrder(seed int64) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.evictionOrder = rand.New(rand.NewSource(seed))
+	}
+}
+
+// WithEvictionBackoffJitter configures an APIDrainer to randomize the waits between the attempts to evict a pod, see ParseEvictionBackoffJitter.
+// The seed makes the sequence of waits reproducible. Without this option, all the pods rejected at the same time are retried at the same time.
+// The Retry-After proposed by the API server is not jittered, it is never shortened.
+func WithEvictionBackoffJitter(jitter EvictionBackoffJitter, seed int64) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.evictionBackoffJitter = jitter
+		d.evictionBackoffRand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// WithDrainPlanEvent configures an APIDrainer to emit a node event with the pods to evict, in the order their evictions start,
+// before starting the evictions of a node. The event is emitted for the dry runs as well.
+func WithDrainPlanEvent(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.drainPlanEvent = b
+	}
+}
+
+// WithFailureCauseEventReasons configures the reasons of the eviction failure events per failure cause, as returned by GetFailureCause.
+// The failures whose cause is not mapped keep the EvictionFailed reason.
+func WithFailureCauseEventReasons(reasons map[FailureCause]string) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.failureCauseEventReasons = reasons
+	}
+}
+
+// WithAlternativePlacementCheck configures an APIDrainer to fail the drain if one of the pods to evict cannot be placed on any other node,
+// according to its node selector, node affinity and tolerations. The pods having the EvictWithoutAlternativePlacementAnnotationKey annotation are evicted anyway.
+// It requires WithRuntimeObjectStore.
+func WithAlternativePlacementCheck(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.checkAlternativePlacement = b
+	}
+}
+
+// WithRequirePDB configures an APIDrainer to fail the drain if any of the pods to evict has no matching PDB. It requires WithPDBIndexer.
+func WithRequirePDB(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.requirePDB = b
+	}
+}
+
+// WithFailFastOnBlockedPDB configures an APIDrainer to stop retrying the eviction of a pod, instead of waiting for the eviction timeout,
+// when one of its PDBs does not allow any disruption while all the pods it covers are healthy. It requires WithPDBIndexer.
+func WithFailFastOnBlockedPDB(b bool) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.failFastOnBlockedPDB = b
+	}
+}
+
+// PDBWaitEstimator estimates how long the evictions are going to wait for the disruption budgets taken by the pods of a node
+type PDBWaitEstimator interface {
+	EstimatePDBWait(ctx context.Context, nodeName string) (time.Duration, error)
+}
+
+// WithPDBWaitEstimator configures the estimation of the PDB wait used by EstimateDrainDuration
+func WithPDBWaitEstimator(e PDBWaitEstimator) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.pdbWaitEstimator = e
+	}
+}
+
+// WithWorkloadUnavailabilityCoordinator configures an APIDrainer to reserve each eviction with the coordinator, shared by all the drains,
+// so that a maximum percentage of the pods of a workload are evicted at once. The evictions breaching the cap wait for the others to complete.
+func WithWorkloadUnavailabilityCoordinator(c *WorkloadUnavailabilityCoordinator) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.workloadUnavailability = c
+	}
+}
+
+// WithDrainPause configures an APIDrainer to not start any drain while the switch pauses the drains. The drains started
+// in the meantime fail with a DrainPausedError without evicting any pod, the drains already running are not interrupted.
+func WithDrainPause(p *DrainPause) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.drainPause = p
+	}
+}
+
+// WithPDBIndexer configures the indexer used to find the PDBs associated with the pods
+func WithPDBIndexer(indexer index.PDBIndexer) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.pdbIndexer = indexer
+	}
+}
+
+func WithContainerRuntimeClient(client client.Client) APIDrainerOption {
+	return func(d *APIDrainer) {
+		d.crClient = client
+	}
+}
+
+// NewAPIDrainer returns a Drainer that drains nodes
+func NewAPIDrainer(c kubernetes.Interface, eventRecorder EventRecorder, ao ...APIDrainerOption) *APIDrainer {
+	d := &APIDrainer{
+		c:                  c,
+		l:                  zap.NewNop(),
+		filter:             NewPodFilters(),
+		minEvictionTimeout: DefaultMinEvictionTimeout,
+		evictionHeadroom:   DefaultEvictionOverhead,
+		skipDrain:          DefaultSkipDrain,
+		eventRecorder:      eventRecorder,
+
+		evictionRequestTransformer:   DefaultEvictionRequestTransformer,
+		evictionEndpointMaxErrorBody: DefaultEvictionEndpointMaxErrorBody,
+		evictionEndpointTimeout:      DefaultEvictionEndpointTimeout,
+		evictionEndpoint500Retries:   DefaultEvictionEndpoint500Retries,
+		pvcRecreateTimeout:           DefaultPVCRecreateTimeout,
+		deletionPollFloor:            DefaultDeletionPollFloor,
+		deletionPollCeiling:          DefaultDeletionPollCeiling,
+		evictionEndpointStats:        newEvictionEndpointStats(),
+		activeDrains:                 newActiveDrains(),
+		pvcCleanupParallelism:        DefaultPVCCleanupParallelism,
+		podListPageSize:              DefaultPodListPageSize,
+		evictionAPIMaxRetriesOn500:   DefaultEvictionAPIMaxRetriesOn500,
+		evictionAPIRetryOn500Backoff: DefaultEvictionAPIRetryOn500Backoff,
+	}
+	for _, o := range ao {
+		o(d)
+	}
+	d.applyGlobalConfigTimings()
+	return d
+}
+
+// applyGlobalConfigTimings uses the timings of the globalConfig that are set and not overridden by an option
+func (d *APIDrainer) applyGlobalConfigTimings() {
+	if d.globalConfig.MinEvictionTimeout > 0 && !d.timingsFromOptions.minEvictionTimeout {
+		d.minEvictionTimeout = d.globalConfig.MinEvictionTimeout
+	}
+	if d.globalConfig.EvictionHeadroom > 0 && !d.timingsFromOptions.evictionHeadroom {
+		d.evictionHeadroom = d.globalConfig.EvictionHeadroom
+	}
+	if d.globalConfig.PVCRecreateTimeout > 0 && !d.timingsFromOptions.pvcRecreateTimeout {
+		d.pvcRecreateTimeout = d.globalConfig.PVCRecreateTimeout
+	}
+}
+
+// SetSuppliedConditions replaces the conditions for which draino is triggered, in the SuppliedConditionsStore of the global configuration if any
+func (d *APIDrainer) SetSuppliedConditions(conditions []SuppliedCondition) {
+	d.globalConfigLock.Lock()
+	defer d.globalConfigLock.Unlock()
+	if d.globalConfig.SuppliedConditionsStore != nil {
+		d.globalConfig.SuppliedConditionsStore.SetSuppliedConditions(conditions)
+		return
+	}
+	d.globalConfig.SuppliedConditions = conditions
+}
+
+func (d *APIDrainer) getSuppliedConditions() []SuppliedCondition {
+	d.globalConfigLock.RLock()
+	defer d.globalConfigLock.RUnlock()
+	return d.globalConfig.GetSuppliedConditions()
+}
+
+// GetNodeOffendingConditions returns the conditions of the node that match the current supplied conditions
+func (d *APIDrainer) GetNodeOffendingConditions(n *core.Node) []SuppliedCondition {
+	return GetNodeOffendingConditions(n, d.getSuppliedConditions())
+}
+
+// IsNodeDrainCandidate tells if the node matches any of the current supplied conditions, and which ones
+func (d *APIDrainer) IsNodeDrainCandidate(n *core.Node) (bool, []SuppliedCondition) {
+	return IsNodeDrainCandidate(n, d.getSuppliedConditions())
+}
+
+// PreviewConditionImpact returns the sorted names of the nodes of the store that have at least one of the given conditions, whether the
+// nodes accept them or not. It is read-only, meant to plan a change of the supplied conditions: the conditions of the drainer are not replaced.
+// It requires WithRuntimeObjectStore.
+func (d *APIDrainer) PreviewConditionImpact(ctx context.Context, conditions []SuppliedCondition) (matchingNodes []string, err error) {
+	if d.runtimeObjectStore == nil {
+		return nil, errors.New("cannot preview the conditions, no runtime object store configured")
+	}
+	if !d.runtimeObjectStore.Nodes().HasSynced() {
+		return nil, errors.New("cannot preview the conditions, the node store is not synced")
+	}
+	for _, n := range d.runtimeObjectStore.Nodes().ListNodes() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if len(GetNodeOffendingConditions(n, conditions)) > 0 {
+			matchingNodes = append(matchingNodes, n.Name)
+		}
+	}
+	sort.Strings(matchingNodes)
+	return matchingNodes, nil
+}
+
+func GetNodeRetryMaxAttempt(n *core.Node) (customValue int32, usedDefault bool, err error) {
+	if maxStr, ok := n.Annotations[CustomRetryMaxAttemptAnnotation]; ok {
+		maxValue, err := ParseRetryMaxAttempt(maxStr)
+		return maxValue, maxValue == 0, err
+	}
+	return 0, true, nil
+}
+
+// ParseRetryMaxAttempt validates the value of the CustomRetryMaxAttemptAnnotation annotation.
+// It returns 0 if the default should be used instead, and 100 if the value is too big. The error explains why the value was not used as is.
+func ParseRetryMaxAttempt(value string) (int32, error) {
+	maxValue, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf(CustomRetryMaxAttemptAnnotation+" can't convert value. Ignoring the user value '%s' and using default instead. Error: %w", value, err)
+	}
+	if maxValue < 1 { // to disable retry the user should use annotation draino/drain-retry=false
+		return 0, fmt.Errorf(CustomRetryMaxAttemptAnnotation+" has a zero or negative value. Ignoring the value '%s' and using default instead.", value)
+	}
+	if maxValue > 100 { // it does not make sense to have bigger value. User should play with `retry-delay` parameter at some point to increase the retry period
+		return 100, fmt.Errorf(CustomRetryMaxAttemptAnnotation+" has a too big value '%s'. Ignoring the value and using 100 instead.", value)
+	}
+	return int32(maxValue), nil
+}
+
+func (d *APIDrainer) GetMaxDrainAttemptsBeforeFail(ctx context.Context, n *core.Node) int32 {
+	customValue, useDefault, err := GetNodeRetryMaxAttempt(n)
+	if err != nil {
+		TracedLogger(ctx, d.l).Warn(err.Error(), zap.String("node", n.Name))
+		d.eventRecorder.NodeEventf(ctx, n, core.EventTypeWarning, eventReasonBadValueForAnnotation, err.Error())
+	}
+	if useDefault {
+		return d.maxDrainAttemptsBeforeFail
+	}
+	return customValue
+}
+
+func (d *APIDrainer) ResetRetryAnnotation(ctx context.Context, n *core.Node) error {
+	span, ctx := tracing.StartSpanFromContext(ctx, "ResetRetryAnnotation")
+	defer span.Finish()
+
+	if _, ok := n.Labels[NodeLabelKeyReplaceRequest]; ok {
+		k8sclient.PatchDeleteNodeLabelKey(ctx, d.c, n.Name, NodeLabelKeyReplaceRequest)
+	}
+
+	// Till we are done with the annotation migration to the new key we have to deal with the 2 keys. Later we can remove that first block.
+	if n.Annotations[drainRetryAnnotationKey] == drainRetryFailedAnnotationValue {
+		k8sclient.PatchNodeAnnotationKey(ctx, d.c, n.Name, drainRetryAnnotationKey, drainRetryAnnotationValue)
+	}
+	return k8sclient.PatchDeleteNodeAnnotationKey(ctx, d.c, n.Name, drainRetryFailedAnnotationKey)
+}
+
+// ResetRetryAnnotationForNodes applies ResetRetryAnnotation to all the nodes matching the selector, to recover at once after a systemic failure.
+// The result of each node is returned by node name, a nil error meaning that the node was reset. The error is set only if the nodes cannot be listed.
+func (d *APIDrainer) ResetRetryAnnotationForNodes(ctx context.Context, selector labels.Selector) (map[string]error, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx, "ResetRetryAnnotationForNodes")
+	defer span.Finish()
+	span.SetTag("selector", selector.String())
+
+	nodes, err := d.c.CoreV1().Nodes().List(ctx, meta.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list nodes matching %s: %w", selector.String(), err)
+	}
+	results := make(map[string]error, len(nodes.Items))
+	for i := range nodes.Items {
+		n := &nodes.Items[i]
+		results[n.Name] = d.ResetRetryAnnotation(ctx, n)
+		if results[n.Name] != nil {
+			TracedLogger(ctx, d.l).Error("Cannot reset the retry annotation", zap.String("node", n.Name), zap.Error(results[n.Name]))
+		}
+	}
+	TracedLogger(ctx, d.l).Info("Reset the retry annotation of the nodes", zap.String("selector", selector.String()), zap.Int("nodes", len(results)))
+	return results, nil
+}
+
+// MarkDrainDelete removes the condition on the node to mark the current drain schedule.
+func (d *APIDrainer) MarkDrainDelete(ctx context.Context, n *core.Node) error {
+	span, ctx := tracing.StartSpanFromContext(ctx, "MarkDrainDelete")
+	defer span.Finish()
+
+	if err := RetryWithTimeout(
+		func() error {
+			nodeName := n.Name
+			// Refresh the node object
+			freshNode, err := d.c.CoreV1().Nodes().Get(ctx, nodeName, meta.GetOptions{})
+			if err != nil {
+				if !apierrors.IsNotFound(err) {
+					return err
+				}
+				return nil
+			}
+			newConditions := []core.NodeCondition{}
+			for _, condition := range freshNode.Status.Conditions {
+				if string(condition.Type) == ConditionDrainedScheduled {
+					continue
+				}
+				newConditions = append(newConditions, condition)
+			}
+			if len(newConditions) == len(freshNode.Status.Conditions) {
+				return nil
+			}
+			freshNode.Status.Conditions = newConditions
+			if _, err := d.c.CoreV1().Nodes().UpdateStatus(ctx, freshNode, meta.UpdateOptions{FieldManager: "draino"}); err != nil {
+				return err
+			}
+			return nil
+		},
+		SetConditionRetryPeriod,
+		SetConditionTimeout,
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarkDrain set a condition on the node to mark that the drain is scheduled. (retry internally in case of failure)
+func (d *APIDrainer) MarkDrain(ctx context.Context, n *core.Node, when, finish time.Time, failed bool, failCount int32) error {
+	return d.MarkDrainWithReason(ctx, n, when, finish, failed, failCount, "")
+}
+
+// MarkDrainWithReason is MarkDrain recording, when the drain failed, the failure reason (e.g. the FailureCause of the drain error)
+// in the condition message. GetDrainConditionStatus reads it back in DrainConditionStatus.FailureReason.
+func (d *APIDrainer) MarkDrainWithReason(ctx context.Context, n *core.Node, when, finish time.Time, failed bool, failCount int32, failureReason string) error {
+	span, ctx := tracing.StartSpanFromContext(ctx, "MarkDrain")
+	defer span.Finish()
+
+	span.SetTag("node", n.GetName())
+	span.SetTag("when", when)
+	span.SetTag("finish", finish)
+	span.SetTag("failCount", failCount)
+	span.SetTag("failed", failed)
+	span.SetTag("failureReason", failureReason)
+
+	finalFailed := false
+	if err := RetryWithTimeout(
+		func() error {
+			nodeName := n.Name
+			// Refresh the node object
+			freshNode, err := d.c.CoreV1().Nodes().Get(ctx, nodeName, meta.GetOptions{})
+			if err != nil {
+				if !apierrors.IsNotFound(err) {
+					return err
+				}
+				return nil
+			}
+
+			conditionStatus := core.ConditionTrue
+			if !finish.IsZero() {
+				if failed && failCount >= d.GetMaxDrainAttemptsBeforeFail(ctx, n) {
+					// the node may already be marked by a previous call, only the transition is reported
+					finalFailed = freshNode.Annotations[drainRetryFailedAnnotationKey] != drainRetryFailedAnnotationValue
+					if freshNode.Annotations == nil {
+						freshNode.Annotations = map[string]string{}
+					}
+					freshNode.Annotations[drainRetryFailedAnnotationKey] = drainRetryFailedAnnotationValue
+				}
+				conditionStatus = core.ConditionFalse
+			}
+
+			// Create or update the condition associated to the monitor
+			now := meta.Time{Time: time.Now()}
+			conditionUpdated := false
+			message := FormatDrainConditionMessageWithReason(failCount, when, finish, failed, failureReason)
+			for i, condition := range freshNode.Status.Conditions {
+				if string(condition.Type) != ConditionDrainedScheduled {
+					continue
+				}
+				freshNode.Status.Conditions[i].LastHeartbeatTime = now
+				freshNode.Status.Conditions[i].Message = message
+				freshNode.Status.Conditions[i].Status = conditionStatus
+				conditionUpdated = true
+			}
+			if !conditionUpdated { // There was no condition found, let's create one
+				freshNode.Status.Conditions = append(freshNode.Status.Conditions,
+					core.NodeCondition{
+						Type:               ConditionDrainedScheduled,
+						Status:             conditionStatus,
+						LastHeartbeatTime:  now,
+						LastTransitionTime: now,
+						Reason:             "Draino",
+						Message:            message,
+					},
+				)
+			}
+			if _, err := d.c.CoreV1().Nodes().UpdateStatus(ctx, freshNode, meta.UpdateOptions{FieldManager: "draino"}); err != nil {
+				return err
+			}
+			return nil
+		},
+		SetConditionRetryPeriod,
+		SetConditionTimeout,
+	); err != nil {
+		return err
+	}
+	if finalFailed {
+		msg := fmt.Sprintf("Drain failed %d time(s), the maximum number of attempts is reached and the drain is not retried", failCount)
+		if failureReason != "" {
+			msg += ": " + failureReason
+		}
+		d.eventRecorder.NodeEventf(ctx, n, core.EventTypeWarning, eventReasonDrainFinalFailed, "%s", msg)
+	}
+	if !finish.IsZero() {
+		d.recordDrainDuration(ctx, n, finish, failed)
+	}
+	return nil
+}
+
+// awaitNodeStability waits until the node stays ready, with the same condition statuses, during nodeStabilityPeriod.
+// It returns a NodeNotStableError if this does not happen within nodeStabilityTimeout. A node that is not found is considered stable.
+func (d *APIDrainer) awaitNodeStability(ctx context.Context, n *core.Node) error {
+	pollPeriod := d.nodeStabilityPeriod / 5 // let's observe the node a few times during the stability period
+	if pollPeriod > 30*time.Second {
+		pollPeriod = 30 * time.Second
+	}
+
+	var state string
+	var stableSince time.Time
+	err := wait.PollImmediateWithContext(ctx, pollPeriod, d.nodeStabilityTimeout, func(ctx context.Context) (bool, error) {
+		fresh, err := d.c.CoreV1().Nodes().Get(ctx, n.GetName(), meta.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			TracedLoggerForNode(ctx, n, d.l).Info("Cannot get node to check its stability", zap.Error(err))
+			return false, nil
+		}
+		ready, _ := GetReadinessState(fresh)
+		if current := getNodeStabilityState(fresh, ready); current != state {
+			state, stableSince = current, time.Now()
+		}
+		return ready && time.Since(stableSince) >= d.nodeStabilityPeriod, nil
+	})
+	// the poll reports the cancellation of the context as a timeout
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return fmt.Errorf("stopped waiting for the stability of node %s: %w", n.GetName(), ctxErr)
+	}
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return NodeNotStableError{NodeName: n.GetName(), Timeout: d.nodeStabilityTimeout}
+	}
+	return err
+}
+
+// getNodeStabilityState renders the readiness and the condition statuses of the node, the condition set by draino is ignored
+func getNodeStabilityState(n *core.Node, ready bool) string {
+	states := []string{strconv.FormatBool(ready)}
+	for _, condition := range n.Status.Conditions {
+		if string(condition.Type) == ConditionDrainedScheduled {
+			continue
+		}
+		states = append(states, string(condition.Type)+"="+string(condition.Status))
+	}
+	sort.Strings(states[1:])
+	return strings.Join(states, ",")
+}
+
+// recordDrainDuration records the time spent between the addition of the draining taint and the end of the drain
+func (d *APIDrainer) recordDrainDuration(ctx context.Context, n *core.Node, finish time.Time, failed bool) {
+	taint, ok := k8sclient.GetNLATaint(n)
+	if !ok || taint.TimeAdded == nil {
+		return
+	}
+	result := "succeeded"
+	if failed {
+		result = "failed"
+	}
+	tags, _ := tag.New(ctx, tag.Upsert(TagConfigName, d.globalConfig.ConfigName), tag.Upsert(TagResult, result))
+	d.statRecordForNode(tags, n, MeasureDrainDuration.M(float64(finish.Sub(taint.TimeAdded.Time).Milliseconds())))
+}
+
+const (
+	drainConditionScheduledStr     = "Drain activity scheduled"
+	drainConditionFailureReasonStr = "FailureReason"
+)
+
+// FormatDrainConditionMessage renders the message of the drain condition, e.g.
+// [1] | Drain activity scheduled 2020-03-20T15:50:34+01:00 | Failed: 2020-03-20T15:55:50+01:00
+// The finish date is only rendered when it is set. ParseDrainConditionMessage reads it back.
+func FormatDrainConditionMessage(failCount int32, when, finish time.Time, failed bool) string {
+	return FormatDrainConditionMessageWithReason(failCount, when, finish, failed, "")
+}
+
+// FormatDrainConditionMessageWithReason renders the message of the drain condition with the reason of a failed drain, e.g.
+// [1] | Drain activity scheduled 2020-03-20T15:50:34+01:00 | Failed: 2020-03-20T15:55:50+01:00 | FailureReason: volume_cleanup
+// The reason is only rendered for a finished failed drain. ParseDrainConditionFailureReason reads it back.
+func FormatDrainConditionMessageWithReason(failCount int32, when, finish time.Time, failed bool, failureReason string) string {
+	msg := fmt.Sprintf("[%d] | %s %s", failCount, drainConditionScheduledStr, when.Format(time.RFC3339))
+	if finish.IsZero() {
+		return msg
+	}
+	result := CompletedStr
+	if failed {
+		result = FailedStr
+	}
+	msg = fmt.Sprintf("%s | %s: %s", msg, result, finish.Format(time.RFC3339))
+	// the separator of the parts cannot be part of the reason
+	if failureReason = strings.TrimSpace(strings.ReplaceAll(failureReason, "|", " ")); failed && failureReason != "" {
+		msg = fmt.Sprintf("%s | %s: %s", msg, drainConditionFailureReasonStr, failureReason)
+	}
+	return msg
+}
+
+// ParseDrainConditionFailureReason returns the failure reason of a message rendered by FormatDrainConditionMessageWithReason,
+// it is empty if the message has none.
+func ParseDrainConditionFailureReason(msg string) string {
+	for _, part := range strings.Split(msg, " | ") {
+		if strings.HasPrefix(part, drainConditionFailureReasonStr+":") {
+			return strings.TrimSpace(strings.TrimPrefix(part, drainConditionFailureReasonStr+":"))
+		}
+	}
+	return ""
+}
+
+// ParseDrainConditionMessage parses a message rendered by FormatDrainConditionMessage.
+// The fail count prefix and the dates are optional, to support the messages written by the older versions of draino.
+// The dates have the precision of RFC3339, the sub-second part is lost by the formatting.
+func ParseDrainConditionMessage(msg string) (failCount int32, when, finish time.Time, failed bool, err error) {
+	parts := strings.Split(msg, " | ")
+	if len(parts[0]) > 0 && parts[0][0] == '[' { // Detect new prefix format
+		if _, err := fmt.Sscanf(parts[0], "[%d]", &failCount); err != nil {
+			return 0, time.Time{}, time.Time{}, false, fmt.Errorf("cannot parse failedCount: %w", err)
+		}
+		parts = parts[1:]
+	}
+	for _, part := range parts {
+		switch {
+		case strings.HasPrefix(part, drainConditionScheduledStr):
+			if value := strings.TrimSpace(strings.TrimPrefix(part, drainConditionScheduledStr)); value != "" {
+				if when, err = time.Parse(time.RFC3339, value); err != nil {
+					return 0, time.Time{}, time.Time{}, false, fmt.Errorf("cannot parse scheduled date: %w", err)
+				}
 			}
-			if !conditionUpdated { // There was no condition found, let's create one
-				freshNode.Status.Conditions = append(freshNode.Status.Conditions,
-					core.NodeCondition{
-						Type:               ConditionDrainedScheduled,
-						Status:             conditionStatus,
-						LastHeartbeatTime:  now,
-						LastTransitionTime: now,
-						Reason:             "Draino",
-						Message:            msgPrefix + "Drain activity scheduled " + when.Format(time.RFC3339) + msgSuffix,
-					},
-				)
+		case strings.HasPrefix(part, CompletedStr+":"), strings.HasPrefix(part, FailedStr+":"):
+			result, value, _ := strings.Cut(part, ":")
+			if finish, err = time.Parse(time.RFC3339, strings.TrimSpace(value)); err != nil {
+				return 0, time.Time{}, time.Time{}, false, fmt.Errorf("cannot parse %s date: %w", strings.ToLower(result), err)
 			}
-			if _, err := d.c.CoreV1().Nodes().UpdateStatus(ctx, freshNode, meta.UpdateOptions{FieldManager: "draino"}); err != nil {
-				return err
+			failed = result == FailedStr
+		}
+	}
+	return failCount, when, finish, failed, nil
+}
+
+type DrainConditionStatus struct {
+	Marked         bool
+	Completed      bool
+	Failed         bool
+	FailedCount    int32
+	LastTransition time.Time
+	// FailureReason is the reason recorded by MarkDrainWithReason for a failed drain, it is empty if none was recorded
+	FailureReason string
+}
+
+func GetDrainConditionStatus(n *core.Node) (DrainConditionStatus, error) {
+	var drainStatus DrainConditionStatus
+	for _, condition := range n.Status.Conditions {
+		if string(condition.Type) != ConditionDrainedScheduled {
+			continue
+		}
+		drainStatus.Marked = true
+		drainStatus.LastTransition = condition.LastTransitionTime.Time
+		failCount, _, finish, failed, err := ParseDrainConditionMessage(condition.Message)
+		if err != nil {
+			return drainStatus, fmt.Errorf("cannot parse drain condition on node %s: %w", n.GetName(), err)
+		}
+		drainStatus.FailedCount = failCount
+
+		if condition.Status == core.ConditionFalse {
+			if !finish.IsZero() {
+				drainStatus.Completed = !failed
+				drainStatus.Failed = failed
+				if failed {
+					drainStatus.FailureReason = ParseDrainConditionFailureReason(condition.Message)
+				}
+				return drainStatus, nil
 			}
-			return nil
-		},
-		SetConditionRetryPeriod,
-		SetConditionTimeout,
-	); err != nil {
+		} else if condition.Status == core.ConditionTrue {
+			return drainStatus, nil
+		}
+		return drainStatus, errors.New("failed to read " + ConditionDrainedScheduled + " condition")
+	}
+	return drainStatus, nil
+}
+
+// Drain the supplied node. Evicts the node of all but mirror and DaemonSet pods.
+// A DrainPausedError is returned while the drains are paused, see WithDrainPause.
+func (d *APIDrainer) Drain(ctx context.Context, node *core.Node) error {
+	span, ctx := tracing.StartSpanFromContext(ctx, "Drain")
+	defer span.Finish()
+
+	return d.runDrain(ctx, node, nil)
+}
+
+// DrainPods evicts the given pods from the supplied node, instead of the pods found by GetPodsToDrain. The safety checks of the drain
+// still apply: the draining taint, the PDB and placement checks, and the eviction sequence waiting for the PDBs. The other pods are left on the node.
+// All the pods must be scheduled on the node.
+func (d *APIDrainer) DrainPods(ctx context.Context, node *core.Node, pods []*core.Pod) error {
+	span, ctx := tracing.StartSpanFromContext(ctx, "DrainPods")
+	defer span.Finish()
+
+	for _, pod := range pods {
+		if pod.Spec.NodeName != node.GetName() {
+			return fmt.Errorf("cannot drain pod %s/%s, it is not scheduled on node %s", pod.GetNamespace(), pod.GetName(), node.GetName())
+		}
+	}
+	if pods == nil {
+		pods = []*core.Pod{}
+	}
+	return d.runDrain(ctx, node, pods)
+}
+
+// runDrain drains the node, evicting the given pods or, if nil, the pods found by GetPodsToDrain.
+// It reports the failures and the summary of the drain.
+func (d *APIDrainer) runDrain(ctx context.Context, node *core.Node, pods []*core.Pod) error {
+	if d.drainPause != nil {
+		if paused, reason := d.drainPause.IsPaused(); paused {
+			TracedLogger(ctx, d.l).Info("Drain not started, the drains are paused", zap.String("node", node.GetName()), zap.String("reason", reason))
+			return DrainPausedError{NodeName: node.GetName(), Reason: reason}
+		}
+	}
+	var summary *DrainSummary
+	if d.drainSummaryCallback != nil {
+		summary = &DrainSummary{NodeName: node.GetName()}
+	}
+	ctx, drain := d.activeDrains.start(ctx, node.GetName())
+	defer d.activeDrains.stop(node.GetName(), drain)
+	start := time.Now()
+	err := d.drain(withPVCDeletionCounter(ctx), node, pods, summary)
+	// Some pods may have been evicted, a retry must list the pods again rather than replaying the cached ones
+	if d.podsToDrainCache != nil {
+		d.podsToDrainCache.Delete(node.GetName())
+	}
+	if err == nil && d.nodeStabilityPeriod > 0 {
+		if err = d.awaitNodeStability(ctx, node); err != nil {
+			TracedLoggerForNode(ctx, node, d.l).Warn("Drain failed, the node is not stable", zap.Error(err))
+		}
+	}
+	if err != nil && drain.isCancelled() {
+		TracedLogger(ctx, d.l).Info("Drain cancelled", zap.String("node", node.GetName()), zap.Error(err))
+		err = DrainCancelledError{NodeName: node.GetName()}
+	}
+	if err != nil {
+		d.recordDrainFailure(ctx, node, err)
+	}
+	if summary != nil {
+		summary.Duration = time.Since(start)
+		summary.Err = err
+		d.drainSummaryCallback(*summary)
+	}
+	return err
+}
+
+// reserveWorkloadUnavailability waits until the eviction of the pod does not breach the cap of its workload, if a coordinator is configured
+func (d *APIDrainer) reserveWorkloadUnavailability(ctx context.Context, pod *core.Pod, abort <-chan struct{}) (func(), error) {
+	if d.workloadUnavailability == nil {
+		return func() {}, nil
+	}
+	span, ctx := tracing.StartSpanFromContext(ctx, "reserveWorkloadUnavailability")
+	defer span.Finish()
+	return d.workloadUnavailability.Reserve(ctx, pod, abort, d.getMinEvictionTimeoutWithEvictionHeadRoom(ctx, pod))
+}
+
+// recordEvictionAttempts records the number of eviction calls done for a pod, for the successful evictions as well, to capture the PDB contention
+func (d *APIDrainer) recordEvictionAttempts(ctx context.Context, n *core.Node, attempts int, err error) {
+	result := "succeeded"
+	if err != nil {
+		result = "failed"
+	}
+	tags, _ := tag.New(ctx, tag.Upsert(TagResult, result))
+	d.statRecordForNode(tags, n, MeasureEvictionAttempts.M(int64(attempts)))
+}
+
+// recordDrainFailure counts the failed drain, tagged with its failure cause
+func (d *APIDrainer) recordDrainFailure(ctx context.Context, n *core.Node, err error) {
+	cause := GetFailureCause(err)
+	if cause == "" {
+		cause = "unknown"
+	}
+	tags, _ := tag.New(ctx, tag.Upsert(TagFailureCause, string(cause)))
+	d.statRecordForNode(tags, n, MeasureDrainFailures.M(1))
+}
+
+// drain evicts the given pods, or the pods found by GetPodsToDrain if nil. The completion is only verified for the latter.
+func (d *APIDrainer) drain(ctx context.Context, node *core.Node, pods []*core.Pod, summary *DrainSummary) error {
+	// Do nothing if draining is not enabled.
+	if d.skipDrain {
+		TracedLoggerForNode(ctx, node, d.l).Debug("Skipping drain because draining is disabled")
+		return nil
+	}
+
+	// retrieve a fresh version of the node
+	n, err := d.c.CoreV1().Nodes().Get(ctx, node.Name, meta.GetOptions{})
+	if err != nil {
 		return err
 	}
+
+	taint, hasNLATaint := k8sclient.GetNLATaint(n)
+	drainCandidate := hasNLATaint && taint.Value == k8sclient.TaintDraining
+
+	if !drainCandidate && !d.skipTaintCheck {
+		TracedLoggerForNode(ctx, node, d.l).Info("Aborting drain because the node is not drain-candidate")
+		return NodeHasNotDrainingTaintError{NodeName: node.Name}
+	}
+
+	discovered := pods == nil
+	if discovered {
+		if pods, err = d.GetPodsToDrain(ctx, n.GetName(), nil); err != nil {
+			return fmt.Errorf("cannot get pods for node %s: %w", n.GetName(), err)
+		}
+	}
+	if len(pods) == 0 && discovered {
+		TracedLoggerForNode(ctx, n, d.l).Info("Node already drained, no pod to evict")
+		if summary != nil {
+			summary.AlreadyDrained = true
+		}
+	}
+
+	if d.requirePDB {
+		if err := d.checkPodsHavePDB(ctx, n, pods); err != nil {
+			return err
+		}
+	}
+
+	if d.checkAlternativePlacement {
+		if err := d.checkPodsHaveAlternativePlacement(n, pods); err != nil {
+			return err
+		}
+	}
+
+	pods = d.shuffleEvictionOrder(pods)
+	if d.drainPlanEvent && len(pods) > 0 {
+		d.reportDrainPlan(ctx, n, pods)
+	}
+
+	if d.drainDryRun || getDrainOverrides(ctx).dryRun {
+		return d.dryRunEvictions(ctx, n, pods)
+	}
+
+	var summaryTick <-chan time.Time
+	if d.evictionAttemptEventsPeriod > 0 {
+		ctx = withEvictionAttemptAggregator(ctx)
+		ticker := time.NewTicker(d.evictionAttemptEventsPeriod)
+		defer ticker.Stop()
+		summaryTick = ticker.C
+	}
+
+	conditionsAnnotations := d.getStructuredConditionsAnnotations(n)
+	abort := make(chan struct{})
+	// One slot per pod: the evictions still running when the drain returns early must not block on the channel
+	results := make(chan PodEvictionSummary, len(pods))
+	for i := range pods {
+		pod := pods[i]
+		go func() {
+			start := time.Now()
+			podSummary := PodEvictionSummary{Namespace: pod.GetNamespace(), Name: pod.GetName(), HasPreStopHook: utils.HasPreStopHook(pod), NonBlocking: isEvictionNonBlocking(pod)}
+			d.eventRecorder.NodeEventf(ctx, n, core.EventTypeNormal, eventReasonEvictionStarting, "Evicting pod %s/%s to drain node", pod.Namespace, pod.Name)
+			if chain := GetOwnerChain(pod, d.runtimeObjectStore); len(chain) > 0 {
+				d.eventRecorder.PodAnnotatedEventf(ctx, pod, conditionsAnnotations, core.EventTypeNormal, eventReasonEvictionStarting, "Evicting pod to drain node %s, owners: %s", n.Name, FormatOwnerChain(chain))
+			} else {
+				d.eventRecorder.PodAnnotatedEventf(ctx, pod, conditionsAnnotations, core.EventTypeNormal, eventReasonEvictionStarting, "Evicting pod to drain node %s", n.Name)
+			}
+			d.controllerEventf(ctx, pod, core.EventTypeNormal, eventReasonEvictionStarting, "Evicting pod %s/%s to drain node %s", pod.Namespace, pod.Name, n.Name)
+			release, err := d.reserveWorkloadUnavailability(ctx, pod, abort)
+			if err == nil {
+				err = d.evict(ctx, n, pod, abort, &podSummary)
+				release()
+			}
+			setEvictionAwaitingBudget(ctx, pod, false)
+			podSummary.Duration = time.Since(start)
+			d.recordEvictionAttempts(ctx, n, podSummary.attempts, err)
+			if err != nil {
+				reason := d.getEvictionFailedReason(err)
+				d.eventRecorder.NodeEventf(ctx, n, core.EventTypeWarning, reason, "Eviction failed for pod %s/%s: %v", pod.Namespace, pod.Name, err)
+				d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, reason, "Eviction failed: %v", err)
+				d.controllerEventf(ctx, pod, core.EventTypeWarning, reason, "Eviction failed for pod %s/%s: %v", pod.Namespace, pod.Name, err)
+				podSummary.Err = fmt.Errorf("cannot evict pod %s/%s: %w", pod.GetNamespace(), pod.GetName(), err)
+				results <- podSummary
+				return
+			}
+			d.eventRecorder.NodeEventf(ctx, n, core.EventTypeNormal, eventReasonEvictionSucceeded, "Pod %s/%s evicted from node", pod.Namespace, pod.Name)
+			d.eventRecorder.PodEventf(ctx, pod, core.EventTypeNormal, eventReasonEvictionSucceeded, "Pod evicted from node %s", n.Name)
+			d.controllerEventf(ctx, pod, core.EventTypeNormal, eventReasonEvictionSucceeded, "Pod %s/%s evicted from node %s", pod.Namespace, pod.Name, n.Name)
+			results <- podSummary // the for range pods below expects to receive one value per pod from the results channel
+		}()
+	}
+	// This will _eventually_ abort evictions. Evictions may spend up to
+	// d.deleteTimeout() in d.awaitDeletion(), or 5 seconds in backoff before
+	// noticing they've been aborted.
+	//
+	// Note(adrienjt): In addition, they may also spend up to:
+	// - 1min/PVC awaiting PVC deletions,
+	// - 1min/PV awaiting PV deletions,
+	// - and DefaultPVCRecreateTimeout per PVC
+	defer close(abort)
+
+	// The re-evaluation only makes sense if the node had offending conditions when the drain started
+	var recheck <-chan time.Time
+	if d.conditionsRecheckPeriod > 0 && len(d.GetNodeOffendingConditions(n)) > 0 {
+		ticker := time.NewTicker(d.conditionsRecheckPeriod)
+		defer ticker.Stop()
+		recheck = ticker.C
+	}
+
+	// pods of the node that were not evicted but that do not fail the drain
+	nonBlockingFailures := map[string]struct{}{}
+	cancelled := drainCancelled(ctx)
+	for received := 0; received < len(pods); {
+		var res PodEvictionSummary
+		select {
+		case <-cancelled:
+			return DrainCancelledError{NodeName: n.GetName()}
+		case <-recheck:
+			if err := d.checkConditionsStillOffending(ctx, n); err != nil {
+				return err
+			}
+			continue
+		case <-summaryTick:
+			d.reportEvictionAttemptsFailed(ctx, n)
+			continue
+		case res = <-results:
+			received++
+		}
+		if summary != nil {
+			summary.Pods = append(summary.Pods, res)
+			summary.TotalRetries += res.Retries
+		}
+		if res.Err != nil && res.NonBlocking {
+			TracedLoggerForNode(ctx, n, d.l).Warn("Eviction of non-blocking pod failed, continuing the drain", zap.String("pod", res.Namespace+"/"+res.Name), zap.Error(res.Err))
+			d.eventRecorder.NodeEventf(ctx, n, core.EventTypeWarning, eventReasonNonBlockingEvictionFailed, "Eviction failed for non-blocking pod %s/%s, the drain continues: %v", res.Namespace, res.Name, res.Err)
+			nonBlockingFailures[res.Namespace+"/"+res.Name] = struct{}{}
+			continue
+		}
+		if res.Err != nil {
+			return fmt.Errorf("cannot evict all pods: %w", res.Err)
+			// all remaining evictions are aborted and their errors ignored (aborted or otherwise)
+			// TODO(adrienjt): capture missing errors?
+			// They are registered as events on pods.
+		}
+	}
+
+	if d.verifyDrainCompletion && discovered {
+		return d.checkNoPodLeft(ctx, n, nonBlockingFailures)
+	}
 	return nil
 }
 
-type DrainConditionStatus struct {
-	Marked         bool
-	Completed      bool
-	Failed         bool
-	FailedCount    int32
-	LastTransition time.Time
-}
+// shuffleEvictionOrder returns a shuffled copy of the pods if the eviction order is randomized, else the pods unchanged
+func (d *APIDrainer) shuffleEvictionOrder(pods []*core.Pod) []*core.Pod {
+	if d.evictionOrder == nil {
+		return pods
+	}
+	shuffled := make([]*core.Pod, len(pods))
+	copy(shuffled, pods)
+	d.evictionOrderLock.Lock()
+	defer d.evictionOrderLock.Unlock()
+	d.evictionOrder.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	return shuffled
+}
+
+// getEvictionFailedReason returns the reason of the eviction failure events: the one configured for the failure cause of the error, else EvictionFailed
+func (d *APIDrainer) getEvictionFailedReason(err error) string {
+	if reason, ok := d.failureCauseEventReasons[GetFailureCause(err)]; ok {
+		return reason
+	}
+	return eventReasonEvictionFailed
+}
+
+// checkConditionsStillOffending returns a ConditionsResolvedError if a fresh version of the node has no offending condition anymore.
+// If the node cannot be fetched the drain continues, the check is done again at the next period.
+func (d *APIDrainer) checkConditionsStillOffending(ctx context.Context, n *core.Node) error {
+	fresh, err := d.c.CoreV1().Nodes().Get(ctx, n.GetName(), meta.GetOptions{})
+	if err != nil {
+		TracedLoggerForNode(ctx, n, d.l).Info("Cannot get node to re-evaluate its conditions", zap.Error(err))
+		return nil
+	}
+	if len(d.GetNodeOffendingConditions(fresh)) > 0 {
+		return nil
+	}
+	TracedLoggerForNode(ctx, n, d.l).Info("Aborting drain because the node has no offending condition anymore")
+	return ConditionsResolvedError{NodeName: n.GetName()}
+}
+
+// checkNoPodLeft lists the pods of the node again and returns a PodsRemainingAfterDrainError if some evictable pods are still there.
+// This catches the pods that were scheduled on the node during the drain. The ignored pods, given as namespace/name, are not reported.
+func (d *APIDrainer) checkNoPodLeft(ctx context.Context, n *core.Node, ignored map[string]struct{}) error {
+	pods, err := d.getPodsToDrain(ctx, n.GetName(), nil, false)
+	if err != nil {
+		return fmt.Errorf("cannot verify drain completion for node %s: %w", n.GetName(), err)
+	}
+	var remaining []string
+	for _, p := range pods {
+		if p.DeletionTimestamp != nil {
+			continue
+		}
+		if _, ok := ignored[p.GetNamespace()+"/"+p.GetName()]; ok {
+			continue
+		}
+		remaining = append(remaining, p.GetNamespace()+"/"+p.GetName())
+	}
+	if len(remaining) > 0 {
+		return PodsRemainingAfterDrainError{NodeName: n.GetName(), Pods: remaining}
+	}
+	return nil
+}
+
+// isEvictionNonBlocking tells if the pod has the EvictionNonBlockingAnnotationKey annotation set to "true"
+func isEvictionNonBlocking(pod *core.Pod) bool {
+	return pod.GetAnnotations()[EvictionNonBlockingAnnotationKey] == "true"
+}
+
+// controllerEventf emits the event on the controller of the pod if the option is activated and the controller can be found in the store
+func (d *APIDrainer) controllerEventf(ctx context.Context, pod *core.Pod, eventType, reason, messageFmt string, args ...interface{}) {
+	if !d.controllerEvents || d.runtimeObjectStore == nil {
+		return
+	}
+	if ctrl, found := GetControllerForPod(pod, d.runtimeObjectStore); found {
+		d.eventRecorder.ControllerEventf(ctx, ctrl, eventType, reason, messageFmt, args...)
+	}
+}
+
+// checkPodsHavePDB returns a PodsWithoutPDBError listing the pods that are not covered by any PDB
+func (d *APIDrainer) checkPodsHavePDB(ctx context.Context, n *core.Node, pods []*core.Pod) error {
+	if d.pdbIndexer == nil {
+		return errors.New("cannot check pod disruption budgets, no pdb indexer configured")
+	}
+	pdbs, err := d.pdbIndexer.GetPDBsForPods(ctx, pods)
+	if err != nil {
+		return fmt.Errorf("cannot get pod disruption budgets for node %s: %w", n.GetName(), err)
+	}
+	var unprotected []string
+	for _, p := range pods {
+		if len(pdbs[index.GeneratePodIndexKey(p.GetName(), p.GetNamespace())]) == 0 {
+			unprotected = append(unprotected, p.GetNamespace()+"/"+p.GetName())
+		}
+	}
+	if len(unprotected) > 0 {
+		return PodsWithoutPDBError{NodeName: n.GetName(), Pods: unprotected}
+	}
+	return nil
+}
+
+// checkPodsHaveAlternativePlacement returns a PodsWithoutAlternativePlacementError listing the pods that cannot be placed on any other node
+func (d *APIDrainer) checkPodsHaveAlternativePlacement(n *core.Node, pods []*core.Pod) error {
+	if d.runtimeObjectStore == nil {
+		return errors.New("cannot check alternative placement, no runtime object store configured")
+	}
+	if stuck := podsWithoutAlternativePlacement(pods, d.runtimeObjectStore.Nodes().ListNodes(), d.runtimeObjectStore); len(stuck) > 0 {
+		return PodsWithoutAlternativePlacementError{NodeName: n.GetName(), Pods: stuck}
+	}
+	return nil
+}
+
+func (d *APIDrainer) GetPodsToDrain(ctx context.Context, node string, podStore PodStore) ([]*core.Pod, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx, "GetPodsToDrain")
+	defer span.Finish()
+
+	if d.podsToDrainCache == nil {
+		return d.getPodsToDrain(ctx, node, podStore, true)
+	}
+	now := time.Now()
+	if pods, ok := d.podsToDrainCache.Get(node, now); ok {
+		TracedLogger(ctx, d.l).Debug("Reusing the cached pods to drain", zap.String("node", node), zap.Int("pods", len(pods)))
+		return append(make([]*core.Pod, 0, len(pods)), pods...), nil
+	}
+	pods, err := d.getPodsToDrain(ctx, node, podStore, true)
+	if err != nil {
+		return nil, err
+	}
+	// the entries of the nodes that are not drained anymore are removed with the misses
+	d.podsToDrainCache.Cleanup(now)
+	d.podsToDrainCache.Add(node, append(make([]*core.Pod, 0, len(pods)), pods...))
+	return pods, nil
+}
+
+// GetEvictablePodsOnCandidateNodes returns the pods that would be evicted if all the nodes that are drain candidates for the supplied conditions were drained now.
+// It uses the same filter as GetPodsToDrain but does not report the skipped pods. This is meant for estimations, nothing is evicted.
+func (d *APIDrainer) GetEvictablePodsOnCandidateNodes(ctx context.Context, nodes []*core.Node, suppliedConditions []SuppliedCondition, podStore PodStore) ([]*core.Pod, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx, "GetEvictablePodsOnCandidateNodes")
+	defer span.Finish()
+
+	var evictable []*core.Pod
+	for _, n := range nodes {
+		if candidate, _ := IsNodeDrainCandidate(n, suppliedConditions); !candidate {
+			continue
+		}
+		pods, err := d.getPodsToDrain(ctx, n.GetName(), podStore, false)
+		if err != nil {
+			return nil, err
+		}
+		evictable = append(evictable, pods...)
+	}
+	return evictable, nil
+}
+
+// getPodsToDrain lists the pods of the node that pass the filters. The skipped pods are reported with events and metrics only if reportSkipped is set.
+func (d *APIDrainer) getPodsToDrain(ctx context.Context, node string, podStore PodStore, reportSkipped bool) ([]*core.Pod, error) {
 
-func GetDrainConditionStatus(n *core.Node) (DrainConditionStatus, error) {
-	var drainStatus DrainConditionStatus
-	for _, condition := range n.Status.Conditions {
-		if string(condition.Type) != ConditionDrainedScheduled {
+	var err error
+	var pods []*core.Pod
+	if podStore != nil {
+		if pods, err = podStore.ListPodsForNode(node); err != nil {
+			return nil, err
+		}
+	} else if pods, err = d.listPodsForNode(ctx, node); err != nil {
+		return nil, err
+	}
+
+	include := make([]*core.Pod, 0, len(pods))
+	for _, p := range pods {
+		if !d.isNamespaceAllowed(p.GetNamespace()) {
+			if reportSkipped {
+				d.eventRecorder.PodEventf(ctx, p, core.EventTypeNormal, eventReasonNamespaceNotAllowed, "Pod left on node %s, namespace %s is not in the drain allow list", node, p.GetNamespace())
+				recordPodSkipped(ctx, podSkippedReasonNamespaceNotAllowed)
+			}
 			continue
 		}
-		drainStatus.Marked = true
-		drainStatus.LastTransition = condition.LastTransitionTime.Time
-		drainStatus.FailedCount = 0
-		msg := strings.Split(condition.Message, " | ")
-		msgPrefix := msg[0]
-		if len(msgPrefix) > 0 && msgPrefix[0] == '[' { // Detect new prefix format
-			_, err := fmt.Sscanf(msgPrefix, "[%d]", &drainStatus.FailedCount)
-			if err != nil {
-				return drainStatus, fmt.Errorf("cannot parse failedCount on node%s: %w", n.GetName(), err)
+		passes, reason, err := d.filter(*p)
+		if err != nil {
+			if !d.skipPodsOnFilterError {
+				return nil, fmt.Errorf("cannot filter pods: %w", err)
 			}
+			TracedLogger(ctx, d.l).Warn("Skipping pod, the filter returned an error", zap.String("node", node), zap.String("pod", p.Namespace+"/"+p.Name), zap.Error(err))
+			if reportSkipped {
+				d.eventRecorder.PodEventf(ctx, p, core.EventTypeWarning, eventReasonPodFilterError, "Pod left on node %s, it cannot be filtered: %v", node, err)
+				recordPodSkipped(ctx, podSkippedReasonFilterError)
+			}
+			continue
 		}
-
-		if condition.Status == core.ConditionFalse {
-			if strings.Contains(condition.Message, CompletedStr) {
-				drainStatus.Completed = true
-				return drainStatus, nil
+		if !passes {
+			if reportSkipped {
+				recordPodSkipped(ctx, reason)
 			}
-			if strings.Contains(condition.Message, FailedStr) {
-				// [1] | Drain activity scheduled 2020-03-20T15:50:34+01:00 | Failed: 2020-03-20T15:55:50+01:00
-				drainStatus.Failed = true
-				return drainStatus, nil
+			continue
+		}
+		if expr := d.matchPodNameExclusion(p.GetName()); expr != "" {
+			if reportSkipped {
+				d.eventRecorder.PodEventf(ctx, p, core.EventTypeNormal, eventReasonPodNameExcluded, "Pod left on node %s, its name matches the exclusion %s", node, expr)
+				recordPodSkipped(ctx, podSkippedReasonPodNameExcluded)
 			}
-		} else if condition.Status == core.ConditionTrue {
-			return drainStatus, nil
+			continue
 		}
-		return drainStatus, errors.New("failed to read " + ConditionDrainedScheduled + " condition")
+		if owner, skipped := d.matchSkippedControllerKind(p); skipped {
+			if reportSkipped {
+				d.eventRecorder.PodEventf(ctx, p, core.EventTypeNormal, eventReasonControllerSkipped, "Pod left on node %s, it is owned by %s", node, owner)
+				recordPodSkipped(ctx, podSkippedReasonControllerKind)
+			}
+			continue
+		}
+		if p.Status.Phase == core.PodPending {
+			if d.excludePendingPods {
+				if reportSkipped {
+					d.eventRecorder.PodEventf(ctx, p, core.EventTypeNormal, eventReasonPendingPodSkipped, "Pod left on node %s, it is Pending", node)
+					recordPodSkipped(ctx, podSkippedReasonPendingPod)
+				}
+				continue
+			}
+			if reportSkipped {
+				d.eventRecorder.PodEventf(ctx, p, core.EventTypeNormal, eventReasonPendingPodIncluded, "Pod is Pending, it is evicted with the other pods of node %s", node)
+			}
+		}
+		include = append(include, p)
 	}
-	return drainStatus, nil
+	return include, nil
 }
 
-// Drain the supplied node. Evicts the node of all but mirror and DaemonSet pods.
-func (d *APIDrainer) Drain(ctx context.Context, node *core.Node) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "Drain")
-	defer span.Finish()
+// listPodsForNode lists the pods of the node with the API, by pages of podListPageSize pods
+func (d *APIDrainer) listPodsForNode(ctx context.Context, node string) ([]*core.Pod, error) {
+	opts := meta.ListOptions{
+		FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": node}).String(),
+		Limit:         d.podListPageSize,
+	}
+	var pods []*core.Pod
+	for {
+		l, err := d.c.CoreV1().Pods(meta.NamespaceAll).List(ctx, opts)
+		if err != nil {
+			// an expired continue token fails the listing, the pods are listed again with the next attempt of the drain
+			return nil, fmt.Errorf("cannot get pods for node %s: %w", node, err)
+		}
+		for i := range l.Items {
+			pods = append(pods, &l.Items[i])
+		}
+		if l.Continue == "" {
+			return pods, nil
+		}
+		opts.Continue = l.Continue
+	}
+}
 
-	// Do nothing if draining is not enabled.
-	if d.skipDrain {
-		TracedLoggerForNode(ctx, node, d.l).Debug("Skipping drain because draining is disabled")
-		return nil
+// matchPodNameExclusion returns the first exclusion expression matching the pod name, or an empty string
+func (d *APIDrainer) matchPodNameExclusion(name string) string {
+	for i := range d.podNameExclusions {
+		if d.podNameExclusions[i].MatchString(name) {
+			return d.podNameExclusions[i].String()
+		}
 	}
+	return ""
+}
 
-	// retrieve a fresh version of the node
-	n, err := d.c.CoreV1().Nodes().Get(ctx, node.Name, meta.GetOptions{})
-	if err != nil {
-		return err
+// matchSkippedControllerKind returns the first owner of the pod whose kind is skipped
+func (d *APIDrainer) matchSkippedControllerKind(pod *core.Pod) (ObjectRef, bool) {
+	if len(d.skipControllerKinds) == 0 {
+		return ObjectRef{}, false
+	}
+	chain, groupKinds := getOwnerChainWithGroupKinds(pod, d.runtimeObjectStore)
+	for i := range chain {
+		if _, ok := d.skipControllerKinds[groupKinds[i]]; ok {
+			return chain[i], true
+		}
 	}
+	return ObjectRef{}, false
+}
 
-	taint, hasNLATaint := k8sclient.GetNLATaint(n)
-	drainCandidate := hasNLATaint && taint.Value == k8sclient.TaintDraining
+func recordPodSkipped(ctx context.Context, reason string) {
+	tags, _ := tag.New(ctx, tag.Upsert(TagReason, reason))
+	stats.Record(tags, MeasurePodsSkipped.M(1))
+}
 
-	if !drainCandidate {
-		TracedLoggerForNode(ctx, node, d.l).Info("Aborting drain because the node is not drain-candidate")
-		return NodeHasNotDrainingTaintError{NodeName: node.Name}
+func (d *APIDrainer) isNamespaceAllowed(namespace string) bool {
+	if d.namespaceAllowList == nil {
+		return true
+	}
+	_, ok := d.namespaceAllowList[namespace]
+	return ok
+}
+
+func (d *APIDrainer) evict(ctx context.Context, node *core.Node, pod *core.Pod, abort <-chan struct{}, summary *PodEvictionSummary) error {
+	evictionAPIURL, ok := d.getEvictionAPIURL(pod)
+	if ok {
+		summary.EvictionEndpoint = evictionAPIURL
+		return d.evictWithOperatorAPI(ctx, evictionAPIURL, node, pod, abort, summary)
 	}
+	return d.evictWithKubernetesAPI(ctx, node, pod, abort, summary)
+}
+
+// getEvictionAPIURL returns the custom eviction endpoint of the pod: the annotation of the pod or its controller, else the one given by the resolver
+func (d *APIDrainer) getEvictionAPIURL(pod *core.Pod) (string, bool) {
+	return GetEvictionEndpoint(pod, d.runtimeObjectStore, d.evictionEndpointResolver)
+}
 
-	pods, err := d.GetPodsToDrain(ctx, n.GetName(), nil)
+// useDeletion tells if the pod is deleted rather than evicted, the UseDeleteAnnotationKey annotation of the pod or its controller takes precedence
+// over the drainer configuration. Invalid annotation values are reported and ignored.
+func (d *APIDrainer) useDeletion(ctx context.Context, pod *core.Pod) bool {
+	value, ok := GetAnnotationFromPodOrController(UseDeleteAnnotationKey, pod, d.runtimeObjectStore)
+	if !ok {
+		return d.deleteInsteadOfEvict
+	}
+	useDelete, err := strconv.ParseBool(value)
 	if err != nil {
-		return fmt.Errorf("cannot get pods for node %s: %w", n.GetName(), err)
+		TracedLogger(ctx, d.l).Warn("Ignoring use delete annotation", zap.String("pod", pod.Namespace+"/"+pod.Name), zap.String("value", value))
+		d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, eventReasonBadValueForAnnotation, "Ignoring %s annotation, '%s' is not true or false", UseDeleteAnnotationKey, value)
+		return d.deleteInsteadOfEvict
 	}
+	return useDelete
+}
 
-	abort := make(chan struct{})
-	errs := make(chan error, 1)
-	for i := range pods {
-		pod := pods[i]
-		go func() {
-			d.eventRecorder.NodeEventf(ctx, n, core.EventTypeNormal, eventReasonEvictionStarting, "Evicting pod %s/%s to drain node", pod.Namespace, pod.Name)
-			d.eventRecorder.PodEventf(ctx, pod, core.EventTypeNormal, eventReasonEvictionStarting, "Evicting pod to drain node %s", n.Name)
-			if err := d.evict(ctx, n, pod, abort); err != nil {
-				d.eventRecorder.NodeEventf(ctx, n, core.EventTypeWarning, eventReasonEvictionFailed, "Eviction failed for pod %s/%s: %v", pod.Namespace, pod.Name, err)
-				d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, eventReasonEvictionFailed, "Eviction failed: %v", err)
-				errs <- fmt.Errorf("cannot evict pod %s/%s: %w", pod.GetNamespace(), pod.GetName(), err)
-				return
-			}
-			d.eventRecorder.NodeEventf(ctx, n, core.EventTypeNormal, eventReasonEvictionSucceeded, "Pod %s/%s evicted from node", pod.Namespace, pod.Name)
-			d.eventRecorder.PodEventf(ctx, pod, core.EventTypeNormal, eventReasonEvictionSucceeded, "Pod evicted from node %s", n.Name)
-			errs <- nil // the for range pods below expects to receive one value per pod from the errs channel
-		}()
+// useEvictionEndpointDryRun tells if only dry-run evictions are sent to the custom eviction endpoint of the pod, the EvictionAPIDryRunAnnotationKey
+// annotation of the pod or its controller takes precedence over the drainer configuration. Invalid annotation values are reported and ignored.
+func (d *APIDrainer) useEvictionEndpointDryRun(ctx context.Context, pod *core.Pod) bool {
+	value, ok := GetAnnotationFromPodOrController(EvictionAPIDryRunAnnotationKey, pod, d.runtimeObjectStore)
+	if !ok {
+		return d.evictionEndpointDryRun
 	}
-	// This will _eventually_ abort evictions. Evictions may spend up to
-	// d.deleteTimeout() in d.awaitDeletion(), or 5 seconds in backoff before
-	// noticing they've been aborted.
-	//
-	// Note(adrienjt): In addition, they may also spend up to:
-	// - 1min/PVC awaiting PVC deletions,
-	// - 1min/PV awaiting PV deletions,
-	// - and DefaultPVCRecreateTimeout per PVC
-	defer close(abort)
+	dryRun, err := strconv.ParseBool(value)
+	if err != nil {
+		TracedLogger(ctx, d.l).Warn("Ignoring eviction api dry run annotation", zap.String("pod", pod.Namespace+"/"+pod.Name), zap.String("value", value))
+		d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, eventReasonBadValueForAnnotation, "Ignoring %s annotation, '%s' is not true or false", EvictionAPIDryRunAnnotationKey, value)
+		return d.evictionEndpointDryRun
+	}
+	return dryRun
+}
 
-	for range pods {
-		if err := <-errs; err != nil {
-			return fmt.Errorf("cannot evict all pods: %w", err)
-			// all remaining evictions are aborted and their errors ignored (aborted or otherwise)
-			// TODO(adrienjt): capture missing errors?
-			// They are registered as events on pods.
-		}
+// getEvictionEndpointTimeout returns the timeout of the calls to the custom eviction endpoint of the pod, the EvictionAPITimeoutAnnotationKey annotation
+// of the pod or its controller takes precedence. Invalid annotation values are reported and ignored.
+func (d *APIDrainer) getEvictionEndpointTimeout(ctx context.Context, pod *core.Pod) time.Duration {
+	value, ok := GetAnnotationFromPodOrController(EvictionAPITimeoutAnnotationKey, pod, d.runtimeObjectStore)
+	if !ok {
+		return d.evictionEndpointTimeout
 	}
-	return nil
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		TracedLogger(ctx, d.l).Warn("Ignoring eviction api timeout annotation", zap.String("pod", pod.Namespace+"/"+pod.Name), zap.String("value", value))
+		d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, eventReasonBadValueForAnnotation, "Ignoring %s annotation, '%s' is not a positive duration", EvictionAPITimeoutAnnotationKey, value)
+		return d.evictionEndpointTimeout
+	}
+	return timeout
 }
 
-func (d *APIDrainer) GetPodsToDrain(ctx context.Context, node string, podStore PodStore) ([]*core.Pod, error) {
-	span, ctx := tracer.StartSpanFromContext(ctx, "GetPodsToDrain")
-	defer span.Finish()
+// getEvictionEndpoint500Retries returns the number of retries after a 500 of the custom eviction endpoint of the pod, the EvictionAPI500RetriesAnnotationKey
+// annotation of the pod or its controller takes precedence. Invalid annotation values are reported and ignored.
+func (d *APIDrainer) getEvictionEndpoint500Retries(ctx context.Context, pod *core.Pod) int {
+	value, ok := GetAnnotationFromPodOrController(EvictionAPI500RetriesAnnotationKey, pod, d.runtimeObjectStore)
+	if !ok {
+		return d.evictionEndpoint500Retries
+	}
+	retries, err := strconv.Atoi(value)
+	if err != nil || retries < 0 {
+		TracedLogger(ctx, d.l).Warn("Ignoring eviction api retries on 500 annotation", zap.String("pod", pod.Namespace+"/"+pod.Name), zap.String("value", value))
+		d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, eventReasonBadValueForAnnotation, "Ignoring %s annotation, '%s' is not a positive integer or 0", EvictionAPI500RetriesAnnotationKey, value)
+		return d.evictionEndpoint500Retries
+	}
+	return retries
+}
 
-	var err error
-	var pods []*core.Pod
-	if podStore != nil {
-		if pods, err = podStore.ListPodsForNode(node); err != nil {
-			return nil, err
-		}
-	} else {
-		l, err := d.c.CoreV1().Pods(meta.NamespaceAll).List(ctx, meta.ListOptions{
-			FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": node}).String(),
-		})
+// getEvictionDeleteOptions returns the DeleteOptions to use for the eviction of the pod: the global ones, overridden by the annotations of the pod or its controller.
+// Invalid annotation values are reported and ignored. It returns nil if nothing is configured.
+func (d *APIDrainer) getEvictionDeleteOptions(ctx context.Context, pod *core.Pod) *meta.DeleteOptions {
+	var opts *meta.DeleteOptions
+	if d.evictionDeleteOptions != nil {
+		opts = d.evictionDeleteOptions.DeepCopy()
+	}
+	if value, ok := GetAnnotationFromPodOrController(EvictionPropagationPolicyAnnotationKey, pod, d.runtimeObjectStore); ok {
+		propagation, err := ParseDeletionPropagation(value)
 		if err != nil {
-			return nil, fmt.Errorf("cannot get pods for node %s: %w", node, err)
+			TracedLogger(ctx, d.l).Warn("Ignoring eviction propagation policy annotation", zap.String("pod", pod.Namespace+"/"+pod.Name), zap.Error(err))
+			d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, eventReasonBadValueForAnnotation, "Ignoring %s annotation: %v", EvictionPropagationPolicyAnnotationKey, err)
+		} else {
+			if opts == nil {
+				opts = &meta.DeleteOptions{}
+			}
+			opts.PropagationPolicy = &propagation
 		}
-		for i := range l.Items {
-			pods = append(pods, &l.Items[i])
+	}
+	if value, ok := GetAnnotationFromPodOrController(EvictionGracePeriodAnnotationKey, pod, d.runtimeObjectStore); ok {
+		gracePeriod, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || gracePeriod < 0 {
+			TracedLogger(ctx, d.l).Warn("Ignoring eviction grace period annotation", zap.String("pod", pod.Namespace+"/"+pod.Name), zap.String("value", value))
+			d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, eventReasonBadValueForAnnotation, "Ignoring %s annotation, '%s' is not a non-negative number of seconds", EvictionGracePeriodAnnotationKey, value)
+		} else {
+			if opts == nil {
+				opts = &meta.DeleteOptions{}
+			}
+			opts.GracePeriodSeconds = &gracePeriod
 		}
 	}
-
-	include := make([]*core.Pod, 0, len(pods))
-	for _, p := range pods {
-		passes, _, err := d.filter(*p)
-		if err != nil {
-			return nil, fmt.Errorf("cannot filter pods: %w", err)
+	if minGracePeriod := d.getMinGracePeriod(ctx, pod); minGracePeriod > 0 {
+		gracePeriod := getPodTerminationGracePeriodSeconds(pod)
+		if opts != nil && opts.GracePeriodSeconds != nil {
+			gracePeriod = *opts.GracePeriodSeconds
 		}
-		if passes {
-			include = append(include, p)
+		if minSeconds := int64(math.Ceil(minGracePeriod.Seconds())); gracePeriod < minSeconds {
+			if opts == nil {
+				opts = &meta.DeleteOptions{}
+			}
+			opts.GracePeriodSeconds = &minSeconds
 		}
 	}
-	return include, nil
+	return opts
 }
 
-func (d *APIDrainer) evict(ctx context.Context, node *core.Node, pod *core.Pod, abort <-chan struct{}) error {
-	evictionAPIURL, ok := GetAnnotationFromPodOrController(EvictionAPIURLAnnotationKey, pod, d.runtimeObjectStore)
-	if ok {
-		return d.evictWithOperatorAPI(ctx, evictionAPIURL, node, pod, abort)
+// getMinGracePeriod returns the floor of the grace period given by the MinGracePeriodAnnotationKey annotation of the pod or its controller, 0 if there is none.
+// An invalid annotation is reported with a warning event, it is meant for the delete options of the eviction: the timeouts and the estimations use lookupMinGracePeriod.
+func (d *APIDrainer) getMinGracePeriod(ctx context.Context, pod *core.Pod) time.Duration {
+	minGracePeriod, invalidValue := d.lookupMinGracePeriod(pod)
+	if invalidValue != "" {
+		TracedLogger(ctx, d.l).Warn("Ignoring min grace period annotation", zap.String("pod", pod.Namespace+"/"+pod.Name), zap.String("value", invalidValue))
+		d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, eventReasonBadValueForAnnotation, "Ignoring %s annotation, '%s' is not a positive duration", MinGracePeriodAnnotationKey, invalidValue)
+	}
+	return minGracePeriod
+}
+
+// lookupMinGracePeriod returns the floor of the grace period without reporting anything. An invalid annotation is ignored and
+// returned as invalidValue.
+func (d *APIDrainer) lookupMinGracePeriod(pod *core.Pod) (minGracePeriod time.Duration, invalidValue string) {
+	value, ok := GetAnnotationFromPodOrController(MinGracePeriodAnnotationKey, pod, d.runtimeObjectStore)
+	if !ok {
+		return 0, ""
 	}
-	return d.evictWithKubernetesAPI(ctx, node, pod, abort)
+	minGracePeriod, err := time.ParseDuration(value)
+	if err != nil || minGracePeriod <= 0 {
+		return 0, value
+	}
+	return minGracePeriod, ""
 }
 
-func (d *APIDrainer) getGracePeriodWithEvictionHeadRoom(pod *core.Pod) time.Duration {
-	gracePeriod := int64(core.DefaultTerminationGracePeriodSeconds)
+func getPodTerminationGracePeriodSeconds(pod *core.Pod) int64 {
 	if pod.Spec.TerminationGracePeriodSeconds != nil {
-		gracePeriod = *pod.Spec.TerminationGracePeriodSeconds
+		return *pod.Spec.TerminationGracePeriodSeconds
+	}
+	return int64(core.DefaultTerminationGracePeriodSeconds)
+}
+
+// ParseDeletionPropagation validates a deletion propagation policy: Orphan, Background or Foreground
+func ParseDeletionPropagation(value string) (meta.DeletionPropagation, error) {
+	switch p := meta.DeletionPropagation(value); p {
+	case meta.DeletePropagationOrphan, meta.DeletePropagationBackground, meta.DeletePropagationForeground:
+		return p, nil
 	}
-	return time.Duration(gracePeriod)*time.Second + d.evictionHeadroom
+	return "", fmt.Errorf("invalid propagation policy '%s', expecting %s, %s or %s", value, meta.DeletePropagationOrphan, meta.DeletePropagationBackground, meta.DeletePropagationForeground)
 }
 
-func (d *APIDrainer) getMinEvictionTimeoutWithEvictionHeadRoom(pod *core.Pod) time.Duration {
-	gracePeriod := d.minEvictionTimeout
+// getGracePeriodWithEvictionHeadRoom returns the time to wait for the deletion of an evicted pod: its grace period, raised to the floor
+// given by the MinGracePeriodAnnotationKey annotation, plus the eviction headroom
+func (d *APIDrainer) getGracePeriodWithEvictionHeadRoom(ctx context.Context, pod *core.Pod) time.Duration {
+	gracePeriod := time.Duration(getPodTerminationGracePeriodSeconds(pod)) * time.Second
+	if minGracePeriod, _ := d.lookupMinGracePeriod(pod); minGracePeriod > gracePeriod {
+		gracePeriod = minGracePeriod
+	}
+	return gracePeriod + d.getEvictionHeadroom(ctx)
+}
+
+// getMinEvictionTimeoutWithEvictionHeadRoom returns the timeout of the whole eviction of the pod: the min eviction timeout, raised to the
+// grace period of the pod and to the floor given by the MinGracePeriodAnnotationKey annotation, plus the eviction headroom
+func (d *APIDrainer) getMinEvictionTimeoutWithEvictionHeadRoom(ctx context.Context, pod *core.Pod) time.Duration {
+	gracePeriod := d.getMinEvictionTimeout(ctx)
 	if pod.Spec.TerminationGracePeriodSeconds != nil && time.Duration(*pod.Spec.TerminationGracePeriodSeconds)*time.Second > gracePeriod {
 		gracePeriod = time.Duration(*pod.Spec.TerminationGracePeriodSeconds) * time.Second
 	}
-	return gracePeriod + d.evictionHeadroom
+	if minGracePeriod, _ := d.lookupMinGracePeriod(pod); minGracePeriod > gracePeriod {
+		gracePeriod = minGracePeriod
+	}
+	return gracePeriod + d.getEvictionHeadroom(ctx)
+}
+
+// EstimateDrainDuration returns a best-effort estimation of the time needed to drain the node.
+// The pods are evicted in parallel, so the estimation is the longest of the pod estimations: the termination grace period with the
+// eviction headroom, plus the wait for the deletion and the recreation of each PVC to clean up. The PDB wait estimation is added on top.
+func (d *APIDrainer) EstimateDrainDuration(ctx context.Context, node *core.Node) (time.Duration, error) {
+	pods, err := d.getPodsToDrain(ctx, node.GetName(), nil, false)
+	if err != nil {
+		return 0, fmt.Errorf("cannot get pods for node %s: %w", node.GetName(), err)
+	}
+
+	var estimate time.Duration
+	for _, pod := range pods {
+		podEstimate := d.getGracePeriodWithEvictionHeadRoom(ctx, pod)
+		pvcs, err := d.getInScopePVCs(ctx, node, pod)
+		if err != nil {
+			return 0, err
+		}
+		for _, pvc := range pvcs {
+			recreateTimeout, _ := d.lookupPVCRecreateTimeout(pod)
+			podEstimate += d.getPVCDeletionTimeout(storageClassName(pvc)) + recreateTimeout
+		}
+		if podEstimate > estimate {
+			estimate = podEstimate
+		}
+	}
+
+	if d.pdbWaitEstimator != nil && len(pods) > 0 {
+		pdbWait, err := d.pdbWaitEstimator.EstimatePDBWait(ctx, node.GetName())
+		if err != nil {
+			return 0, fmt.Errorf("cannot estimate pdb wait for node %s: %w", node.GetName(), err)
+		}
+		estimate += pdbWait
+	}
+	return estimate, nil
 }
 
-func (d *APIDrainer) evictWithKubernetesAPI(ctx context.Context, node *core.Node, pod *core.Pod, abort <-chan struct{}) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "evictWithKubernetesAPI")
+func (d *APIDrainer) evictWithKubernetesAPI(ctx context.Context, node *core.Node, pod *core.Pod, abort <-chan struct{}, summary *PodEvictionSummary) error {
+	span, ctx := tracing.StartSpanFromContext(ctx, "evictWithKubernetesAPI")
 	defer span.Finish()
 
-	return d.evictionSequence(ctx, node, pod, abort,
+	deleteOptions := d.getEvictionDeleteOptions(ctx, pod)
+	useDelete := d.useDeletion(ctx, pod)
+	retriesOn500 := 0
+	return d.evictionSequence(ctx, node, pod, abort, summary,
 		// eviction function
-		func() error {
+		func(pod *core.Pod) error {
+			if useDelete {
+				opts := meta.DeleteOptions{}
+				if deleteOptions != nil {
+					opts = *deleteOptions
+				}
+				// never delete a pod recreated with the same name
+				opts.Preconditions = &meta.Preconditions{UID: &pod.UID}
+				TracedLogger(ctx, d.l).Info("deleting pod instead of evicting it", zap.String("node", node.Name), zap.String("pod", pod.Name), zap.String("pod_namespace", pod.Namespace))
+				return d.c.CoreV1().Pods(pod.GetNamespace()).Delete(ctx, pod.GetName(), opts)
+			}
 			return d.c.CoreV1().Pods(pod.GetNamespace()).EvictV1(ctx, &policy.Eviction{
-				ObjectMeta: meta.ObjectMeta{Namespace: pod.GetNamespace(), Name: pod.GetName()},
+				ObjectMeta:    meta.ObjectMeta{Namespace: pod.GetNamespace(), Name: pod.GetName()},
+				DeleteOptions: deleteOptions,
 			})
 		},
 		// error handling function
@@ -727,15 +2525,45 @@ func (d *APIDrainer) evictWithKubernetesAPI(ctx context.Context, node *core.Node
 			// The eviction API returns 500 if a pod
 			// matches more than one pod disruption budgets.
 			// We cannot use apierrors.IsInternalError because Reason is not set, just Code and Message.
-			if statErr, ok := err.(apierrors.APIStatus); ok && statErr.Status().Code == 500 {
+			statErr, ok := err.(apierrors.APIStatus)
+			if !ok || statErr.Status().Code != 500 {
+				return err // unexpected (we're already catching 429 and 500), may be a client side error
+			}
+			if strings.Contains(statErr.Status().Message, overlappingDisruptionBudgetsMessage) {
 				return OverlappingDisruptionBudgetsError{} // this one is typed because we match it to a failure cause
 			}
-			return err // unexpected (we're already catching 429 and 500), may be a client side error
+			// any other 500 may be a transient error of the API server
+			if retriesOn500 >= d.evictionAPIMaxRetriesOn500 {
+				return fmt.Errorf("eviction API error after %d retries: %w", retriesOn500, err)
+			}
+			waitTime := d.evictionAPIRetryOn500Backoff << retriesOn500
+			retriesOn500++
+			TracedLogger(ctx, d.l).Info("received 500 while evicting pod, retrying", zap.String("node", node.Name), zap.String("pod", pod.Name), zap.String("pod_namespace", pod.Namespace), zap.Int("retry", retriesOn500), zap.Duration("wait", waitTime), zap.Error(err))
+			d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, eventReasonEvictionAttemptFailed, "Attempt to evict pod from node %s failed: %v", node.Name, err)
+			select {
+			case <-time.After(waitTime):
+			case <-abort:
+			case <-ctx.Done():
+			}
+			return nil
 		},
 	)
 
 }
 
+// getStructuredConditionsAnnotations returns the annotation holding the offending conditions of the node in JSON, nil if the structured data is disabled
+func (d *APIDrainer) getStructuredConditionsAnnotations(node *core.Node) map[string]string {
+	if !d.structuredConditionsData {
+		return nil
+	}
+	conditions, err := FormatOffendingConditions(d.GetNodeOffendingConditions(node))
+	if err != nil {
+		d.l.Error("Cannot format the offending conditions", zap.String("node", node.Name), zap.Error(err))
+		return nil
+	}
+	return map[string]string{EvictionNodeConditionsJSONAnnotationKey: conditions}
+}
+
 // evictWithOperatorAPI This function calls an Operator endpoint to perform the eviction instead of the classic kubernetes eviction endpoint
 // The endpoint should support the same payload than the kubernetes eviction endpoint.
 // The expected responses are:
@@ -744,100 +2572,149 @@ func (d *APIDrainer) evictWithKubernetesAPI(ctx context.Context, node *core.Node
 // 404    : the pod is not found, already delete
 // 503    : the service is not able to answer now, potentially not reaching the leader, you should retry
 // 500    : server error, that could be a transient error, retry couple of times
-func (d *APIDrainer) evictWithOperatorAPI(ctx context.Context, url string, node *core.Node, pod *core.Pod, abort <-chan struct{}) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "evictWithKubernetesAPI")
+func (d *APIDrainer) evictWithOperatorAPI(ctx context.Context, url string, node *core.Node, pod *core.Pod, abort <-chan struct{}, summary *PodEvictionSummary) error {
+	span, ctx := tracing.StartSpanFromContext(ctx, "evictWithKubernetesAPI")
 	defer span.Finish()
 
-	conditions := GetConditionsTypes(GetNodeOffendingConditions(node, d.globalConfig.SuppliedConditions))
-	d.l.Info("using custom eviction endpoint", zap.String("pod", pod.Namespace+"/"+pod.Name), zap.String("endpoint", url))
-	maxRetryOn500 := 4
-	return d.evictionSequence(ctx, node, pod, abort,
-		// eviction function
-		func() error {
+	conditions := GetConditionsTypes(d.GetNodeOffendingConditions(node))
+	annotations := map[string]string{EvictionNodeConditionsAnnotationKey: strings.Join(conditions, ",")}
+	for k, v := range d.getStructuredConditionsAnnotations(node) {
+		annotations[k] = v
+	}
+	TracedLogger(ctx, d.l).Info("using custom eviction endpoint", zap.String("pod", pod.Namespace+"/"+pod.Name), zap.String("endpoint", url))
+	maxRetryOn500 := d.getEvictionEndpoint500Retries(ctx, pod)
+	deleteOptions := d.getEvictionDeleteOptions(ctx, pod)
+	endpointTimeout := d.getEvictionEndpointTimeout(ctx, pod)
+	dryRun := d.useEvictionEndpointDryRun(ctx, pod)
+	if dryRun {
+		if deleteOptions == nil {
+			deleteOptions = &meta.DeleteOptions{}
+		}
+		deleteOptions.DryRun = []string{meta.DryRunAll}
+	}
+	evictionFunc := func(pod *core.Pod) error {
 
-			logger := d.l.With(zap.String("node", node.Name)).With(zap.String("pod", pod.Namespace+"/"+pod.Name))
-			evictionPayload := &policy.Eviction{
-				ObjectMeta: meta.ObjectMeta{Namespace: pod.GetNamespace(), Name: pod.GetName(),
-					Annotations: map[string]string{EvictionNodeConditionsAnnotationKey: strings.Join(conditions, ",")}},
-			}
+		logger := TracedLogger(ctx, d.l).With(zap.String("node", node.Name)).With(zap.String("pod", pod.Namespace+"/"+pod.Name))
+		evictionPayload := &policy.Eviction{
+			ObjectMeta: meta.ObjectMeta{Namespace: pod.GetNamespace(), Name: pod.GetName(),
+				Annotations: annotations},
+			DeleteOptions: deleteOptions,
+		}
 
-			var client *http.Client
-			urlParsed, err := url2.Parse(url)
-			if err != nil {
-				logger.Info("custom eviction endpoint response error, can't parse URL", zap.Error(err))
-				return EvictionEndpointError{}
-			}
+		var client *http.Client
+		urlParsed, err := url2.Parse(url)
+		if err != nil {
+			logger.Info("custom eviction endpoint response error, can't parse URL", zap.Error(err))
+			return EvictionEndpointError{}
+		}
 
-			// building the base roundTripper
-			var roundTripper http.RoundTripper
-			if urlParsed.Scheme == "https" {
-				roundTripper = &http.Transport{
-					TLSClientConfig: &tls.Config{
-						// We are not trying to verify the server side for the moment
-						// Men in the middle risk is low if not null: CNP helps here.
-						// We can add more verification later if needed
-						InsecureSkipVerify: true,
-					},
+		// building the base roundTripper
+		var roundTripper http.RoundTripper
+		if d.evictionEndpointRoundTripper != nil {
+			roundTripper = d.evictionEndpointRoundTripper
+		} else if urlParsed.Scheme == "https" {
+			tlsConfig := d.evictionEndpointTLSConfig
+			if tlsConfig == nil {
+				tlsConfig = &tls.Config{
+					// By default we are not trying to verify the server side
+					// Men in the middle risk is low if not null: CNP helps here.
+					// The verification is configured with WithEvictionEndpointTLS
+					InsecureSkipVerify: true,
 				}
-			} else {
-				roundTripper = http.DefaultTransport
 			}
+			roundTripper = &http.Transport{TLSClientConfig: tlsConfig}
+		} else {
+			roundTripper = http.DefaultTransport
+		}
 
-			// If the user specify a parameter "token-audience" on the URL then we will forge a token that is using the value of the parameter as audience in the token
-			// If the user do not specify the audience then that means that he does not want the token (audience is mandatory)
-			tokenAudience := urlParsed.Query().Get("token-audience")
-			if tokenAudience != "" {
-				// Uses Emissary to get JWTs.
-				roundTripper = authnclient.NewRoundTripper(roundTripper, authnclient.NewEmissaryTokenGetter(tokenAudience))
-				// Removing this token parameter so that the server don't get it on the URL. The value is now available for the server inside the bearer token
-				urlParsed.Query().Del("token-audience")
-				logger.Info("Using token-audience parameter", zap.String("token-audience", tokenAudience))
-			}
+		// If the user specify a parameter "token-audience" on the URL then we will forge a token that is using the value of the parameter as audience in the token
+		// If the user do not specify the audience then that means that he does not want the token (audience is mandatory)
+		tokenAudience := urlParsed.Query().Get("token-audience")
+		if tokenAudience != "" {
+			// Uses Emissary to get JWTs.
+			roundTripper = authnclient.NewRoundTripper(roundTripper, authnclient.NewEmissaryTokenGetter(tokenAudience))
+			// Removing this token parameter so that the server don't get it on the URL. The value is now available for the server inside the bearer token
+			urlParsed.Query().Del("token-audience")
+			logger.Info("Using token-audience parameter", zap.String("token-audience", tokenAudience))
+		}
+		if dryRun {
+			query := urlParsed.Query()
+			query.Set(EvictionEndpointDryRunQueryParam, meta.DryRunAll)
+			urlParsed.RawQuery = query.Encode()
+		}
 
-			client = &http.Client{Transport: roundTripper, Timeout: 20 * time.Second}
-			logger.Info("calling eviction++", zap.String("url", urlParsed.String()))
-			req, err := http.NewRequest("POST", urlParsed.String(), GetEvictionJsonPayload(evictionPayload))
-			req = req.WithContext(ctx)
-			req.Header.Set("Content-Type", "application/json")
+		body, contentType, err := d.evictionRequestTransformer(evictionPayload)
+		if err != nil {
+			logger.Error("cannot build the custom eviction endpoint request", zap.Error(err))
+			return fmt.Errorf("cannot build eviction request for pod %s/%s: %w", pod.GetNamespace(), pod.GetName(), err)
+		}
 
-			client = httptrace.WrapClient(client)
-			resp, err := client.Do(req)
-			if err != nil {
-				logger.Info("custom eviction endpoint response error", zap.Error(err))
-				if tokenAudience != "" && strings.Contains(err.Error(), "unable to retrieve token from vault (http status: 400)") {
-					return AudienceNotFoundError{Audience: tokenAudience}
-				}
-				if os.IsTimeout(err) {
-					return EvictionEndpointError{IsRequestTimeout: true}
-				}
-				return EvictionEndpointError{}
+		client = &http.Client{Transport: roundTripper, Timeout: endpointTimeout}
+		logger.Info("calling eviction++", zap.String("url", urlParsed.String()))
+		req, err := http.NewRequest("POST", urlParsed.String(), bytes.NewReader(body))
+		req = req.WithContext(ctx)
+		req.Header.Set("Content-Type", contentType)
+
+		client.Transport = tracing.WrapRoundTripper(client.Transport)
+		start := time.Now()
+		resp, err := client.Do(req)
+		d.recordEvictionEndpointLatency(ctx, logger, node, pod, urlParsed.Host, resp, time.Since(start))
+		if err != nil {
+			logger.Info("custom eviction endpoint response error", zap.Error(err))
+			if tokenAudience != "" && strings.Contains(err.Error(), "unable to retrieve token from vault (http status: 400)") {
+				return AudienceNotFoundError{Audience: tokenAudience}
 			}
-			defer resp.Body.Close()
-			logger.Info("custom eviction endpoint response", zap.String("endpoint", url), zap.Int("responseCode", resp.StatusCode))
-			switch {
-			case resp.StatusCode == http.StatusOK:
+			if os.IsTimeout(err) {
+				return EvictionEndpointError{IsRequestTimeout: true}
+			}
+			return EvictionEndpointError{}
+		}
+		defer resp.Body.Close()
+		logger.Info("custom eviction endpoint response", zap.String("endpoint", url), zap.Int("responseCode", resp.StatusCode))
+		if dryRun {
+			return d.reportEvictionEndpointDryRun(ctx, logger, pod, resp)
+		}
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			return nil
+		case resp.StatusCode == http.StatusAccepted && d.evictionEndpointAcceptAsync:
+			d.eventRecorder.PodEventf(ctx, pod, core.EventTypeNormal, eventReasonEvictionAccepted, "Eviction accepted by the custom eviction endpoint, waiting for its asynchronous processing")
+			if d.evictionEndpointStatusURLHeader == "" || resp.Header.Get(d.evictionEndpointStatusURLHeader) == "" {
 				return nil
-			case resp.StatusCode == http.StatusTooManyRequests:
-				return apierrors.NewTooManyRequests("retry later", 10)
-			case resp.StatusCode == http.StatusNotFound:
-				return apierrors.NewNotFound(schema.GroupResource{Resource: "pod"}, pod.Name)
-			case resp.StatusCode == http.StatusServiceUnavailable:
-				return apierrors.NewTooManyRequests("retry later, service endpoint is not the leader", 15)
-			case resp.StatusCode == http.StatusInternalServerError:
-				respContent, _ := ioutil.ReadAll(resp.Body)
-				if maxRetryOn500 > 0 {
-					maxRetryOn500--
-					logger.Info("Custom eviction endpoint returned an error", zap.Int("code", resp.StatusCode), zap.String("body", string(respContent)))
-					return apierrors.NewTooManyRequests("retry later following endpoint error", 20)
-				}
-				logger.Error("Too many service error from custom eviction endpoint.", zap.Int("code", resp.StatusCode), zap.String("body", string(respContent)))
-				return EvictionEndpointError{StatusCode: resp.StatusCode, AfterSeveralRetries: true}
-			default:
-				respContent, _ := ioutil.ReadAll(resp.Body)
-				logger.Error("Unexpected response code from custom eviction endpoint.", zap.Int("code", resp.StatusCode), zap.String("body", string(respContent)))
-				return EvictionEndpointError{StatusCode: resp.StatusCode}
 			}
-		},
+			statusURL, err := urlParsed.Parse(resp.Header.Get(d.evictionEndpointStatusURLHeader))
+			if err != nil {
+				logger.Warn("Ignoring the status URL of the custom eviction endpoint, waiting for the pod deletion", zap.String("header", d.evictionEndpointStatusURLHeader), zap.Error(err))
+				return nil
+			}
+			return d.awaitEvictionEndpointStatus(ctx, logger, client, statusURL.String(), pod, abort)
+		case resp.StatusCode == http.StatusTooManyRequests:
+			return apierrors.NewTooManyRequests("retry later", 10)
+		case resp.StatusCode == http.StatusNotFound:
+			return apierrors.NewNotFound(schema.GroupResource{Resource: "pod"}, pod.Name)
+		case resp.StatusCode == http.StatusServiceUnavailable:
+			return apierrors.NewTooManyRequests("retry later, service endpoint is not the leader", 15)
+		case resp.StatusCode == http.StatusInternalServerError:
+			respContent := d.readEvictionEndpointErrorBody(resp)
+			if maxRetryOn500 > 0 {
+				maxRetryOn500--
+				logger.Info("Custom eviction endpoint returned an error", zap.Int("code", resp.StatusCode), zap.String("body", string(respContent)))
+				return apierrors.NewTooManyRequests("retry later following endpoint error", 20)
+			}
+			logger.Error("Too many service error from custom eviction endpoint.", zap.Int("code", resp.StatusCode), zap.String("body", string(respContent)))
+			return EvictionEndpointError{StatusCode: resp.StatusCode, AfterSeveralRetries: true}
+		default:
+			respContent := d.readEvictionEndpointErrorBody(resp)
+			logger.Error("Unexpected response code from custom eviction endpoint.", zap.Int("code", resp.StatusCode), zap.String("body", string(respContent)))
+			return EvictionEndpointError{StatusCode: resp.StatusCode}
+		}
+	}
+	if dryRun {
+		// a single call, there is no pod deletion to wait for
+		summary.attempts++
+		return evictionFunc(pod)
+	}
+	return d.evictionSequence(ctx, node, pod, abort, summary, evictionFunc,
 		// error handling function
 		func(err error) error {
 			return err
@@ -845,12 +2722,111 @@ func (d *APIDrainer) evictWithOperatorAPI(ctx context.Context, url string, node
 	)
 }
 
-func (d *APIDrainer) evictionSequence(ctx context.Context, node *core.Node, pod *core.Pod, abort <-chan struct{}, evictionFunc func() error, otherErrorsHandlerFunc func(e error) error) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "evictionSequence")
+// reportEvictionEndpointDryRun reports the verdict of a custom eviction endpoint to a dry-run eviction, the rejections are returned as an EvictionEndpointDryRunRejectedError
+func (d *APIDrainer) reportEvictionEndpointDryRun(ctx context.Context, logger *zap.Logger, pod *core.Pod, resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusAccepted {
+		logger.Info("custom eviction endpoint accepted the dry run eviction", zap.Int("code", resp.StatusCode))
+		d.eventRecorder.PodEventf(ctx, pod, core.EventTypeNormal, eventReasonEvictionDryRun, "Dry run eviction accepted by the custom eviction endpoint with code %d", resp.StatusCode)
+		return nil
+	}
+	respContent := d.readEvictionEndpointErrorBody(resp)
+	logger.Info("custom eviction endpoint rejected the dry run eviction", zap.Int("code", resp.StatusCode), zap.String("body", string(respContent)))
+	d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, eventReasonEvictionDryRun, "Dry run eviction rejected by the custom eviction endpoint with code %d: %s", resp.StatusCode, string(respContent))
+	return EvictionEndpointDryRunRejectedError{StatusCode: resp.StatusCode}
+}
+
+// awaitEvictionEndpointStatus polls the status URL of an eviction accepted asynchronously by a custom eviction endpoint, until the eviction
+// is processed. As the eviction is accepted, the polling is bounded by the pod grace period with the eviction headroom.
+func (d *APIDrainer) awaitEvictionEndpointStatus(ctx context.Context, logger *zap.Logger, client *http.Client, statusURL string, pod *core.Pod, abort <-chan struct{}) error {
+	ctx, cancel := context.WithTimeout(ctx, d.getGracePeriodWithEvictionHeadRoom(ctx, pod))
+	defer cancel()
+	logger = logger.With(zap.String("statusURL", statusURL))
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, statusURL, nil)
+		if err != nil {
+			logger.Error("cannot build the custom eviction endpoint status request", zap.Error(err))
+			return EvictionEndpointError{}
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			// the status endpoint may be temporarily unavailable, the polling goes on till the timeout
+			logger.Info("custom eviction endpoint status error", zap.Error(err))
+		} else {
+			respContent := d.readEvictionEndpointErrorBody(resp)
+			resp.Body.Close()
+			switch resp.StatusCode {
+			case http.StatusOK:
+				logger.Info("custom eviction endpoint processed the eviction")
+				return nil
+			case http.StatusAccepted:
+			default:
+				logger.Error("Unexpected status from custom eviction endpoint.", zap.Int("code", resp.StatusCode), zap.String("body", string(respContent)))
+				return EvictionEndpointError{StatusCode: resp.StatusCode}
+			}
+		}
+		select {
+		case <-abort:
+			return errors.New("pod eviction aborted")
+		case <-ctx.Done():
+			return PodEvictionTimeoutError{isEvictionPP: true}
+		case <-time.After(d.evictionEndpointStatusPoll):
+		}
+	}
+}
+
+// readEvictionEndpointErrorBody reads at most evictionEndpointMaxErrorBody bytes of the response body, so that a misbehaving endpoint cannot flood the logs
+func (d *APIDrainer) readEvictionEndpointErrorBody(resp *http.Response) []byte {
+	content, _ := ioutil.ReadAll(io.LimitReader(resp.Body, d.evictionEndpointMaxErrorBody))
+	return content
+}
+
+// recordEvictionEndpointLatency records the latency of a call to a custom eviction endpoint, and reports it if it is above the degraded threshold
+func (d *APIDrainer) recordEvictionEndpointLatency(ctx context.Context, logger *zap.Logger, node *core.Node, pod *core.Pod, endpoint string, resp *http.Response, latency time.Duration) {
+	result := "error"
+	if resp != nil {
+		result = strconv.Itoa(resp.StatusCode)
+	}
+	degraded := d.evictionEndpointDegradedThreshold > 0 && latency > d.evictionEndpointDegradedThreshold
+	tags, _ := tag.New(ctx, tag.Upsert(TagEvictionEndpoint, endpoint), tag.Upsert(TagResult, result), tag.Upsert(TagDegraded, strconv.FormatBool(degraded)))
+	d.statRecordForNode(tags, node, MeasureEvictionEndpointLatency.M(float64(latency.Milliseconds())))
+	if d.evictionEndpointStats != nil {
+		d.evictionEndpointStats.record(endpoint, resp, latency, time.Now())
+	}
+
+	if degraded {
+		logger.Warn("Custom eviction endpoint is slow", zap.String("endpoint", endpoint), zap.Duration("latency", latency), zap.Duration("threshold", d.evictionEndpointDegradedThreshold))
+		d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, eventReasonEvictionEndpointDegraded, "Custom eviction endpoint %s answered in %s, above the threshold of %s", endpoint, latency.Round(time.Millisecond), d.evictionEndpointDegradedThreshold)
+	}
+}
+
+// EvictionEndpointStats returns the health of each custom eviction endpoint called by this drainer, keyed by endpoint host.
+// It is computed on the last calls to the endpoint, across all the drains.
+func (d *APIDrainer) EvictionEndpointStats() map[string]EndpointHealth {
+	if d.evictionEndpointStats == nil {
+		return map[string]EndpointHealth{}
+	}
+	return d.evictionEndpointStats.summarize(d.evictionEndpointDegradedThreshold)
+}
+
+func (d *APIDrainer) evictionSequence(ctx context.Context, node *core.Node, pod *core.Pod, abort <-chan struct{}, summary *PodEvictionSummary, evictionFunc func(pod *core.Pod) error, otherErrorsHandlerFunc func(e error) error) (err error) {
+	span, ctx := tracing.StartSpanFromContext(ctx, "evictionSequence")
 	defer span.Finish()
+	d.setEvictionSpanTags(ctx, span, pod)
+
+	// the duration of the eviction goes from the first eviction call to the confirmation of the pod deletion, the failures are recorded when the sequence ends
+	var firstAttempt time.Time
+	durationRecorded := false
+	recordDuration := func(err error) {
+		if firstAttempt.IsZero() || durationRecorded {
+			return
+		}
+		durationRecorded = true
+		d.recordPodEvictionDuration(ctx, node, pod, abort, err, time.Since(firstAttempt))
+	}
+	defer func() { recordDuration(err) }()
 
 	// we will retry eviction till minEvictionTimeout (or podTerminationGracePeriod if it is bigger), augmented by evictionHeadroom
-	ctx, cancel := context.WithTimeout(ctx, d.getMinEvictionTimeoutWithEvictionHeadRoom(pod))
+	ctx, cancel := context.WithTimeout(ctx, d.getMinEvictionTimeoutWithEvictionHeadRoom(ctx, pod))
 	defer cancel()
 	backoff := wait.Backoff{
 		Duration: 10 * time.Second,
@@ -864,12 +2840,12 @@ func (d *APIDrainer) evictionSequence(ctx context.Context, node *core.Node, pod
 		case <-abort:
 			return errors.New("pod eviction aborted")
 		case <-ctx.Done():
-			_, ok := GetAnnotationFromPodOrController(EvictionAPIURLAnnotationKey, pod, d.runtimeObjectStore)
+			_, ok := d.getEvictionAPIURL(pod)
 			return PodEvictionTimeoutError{isEvictionPP: ok} // this one is typed because we match it to a failure cause
 		default:
-			pvcs, err := d.getInScopePVCs(ctx, pod)
+			pvcs, err := d.getInScopePVCs(ctx, node, pod)
 			if err != nil {
-				d.l.Error("Cannot fetch pod pvc's", zap.Error(err), zap.String("pod", pod.Name))
+				TracedLogger(ctx, d.l).Error("Cannot fetch pod pvc's", zap.Error(err), zap.String("pod", pod.Name))
 				continue
 			}
 
@@ -885,18 +2861,37 @@ func (d *APIDrainer) evictionSequence(ctx context.Context, node *core.Node, pod
 			// the confusion, let's not even try to evict terminating pods, because that
 			// doesn't make much sense anyway. However, we still want to wait for their
 			// deletion, which is why we filter here and not in GetPodsToDrain.
+			evicted := false
 			if pod.DeletionTimestamp == nil {
-				err = evictionFunc()
+				if summary.attempts > 0 {
+					summary.Retries++
+				}
+				summary.attempts++
+				if firstAttempt.IsZero() {
+					firstAttempt = time.Now()
+				}
+				// the pod may be the replacement of the evicted pod, see WithEvictPodsRecreatedOnNode
+				err = evictionFunc(pod)
+				evicted = err == nil
 			}
 			switch {
 			// The eviction API returns 429 Too Many Requests if a pod
 			// cannot currently be evicted, for example due to a pod
 			// disruption budget.
 			case apierrors.IsTooManyRequests(err):
-				d.l.Info("received 429 while evicting pod", zap.String("node", node.Name), zap.String("pod", pod.Name), zap.String("pod_namespace", pod.Namespace), zap.Error(err))
-				d.eventRecorder.NodeEventf(ctx, node, core.EventTypeWarning, eventReasonEvictionAttemptFailed, "Attempt to evict pod %s/%s failed: %v", pod.Namespace, pod.Name, err)
+				TracedLogger(ctx, d.l).Info("received 429 while evicting pod", zap.String("node", node.Name), zap.String("pod", pod.Name), zap.String("pod_namespace", pod.Namespace), zap.Error(err))
+				if !setEvictionAwaitingBudget(ctx, pod, true) {
+					d.eventRecorder.NodeEventf(ctx, node, core.EventTypeWarning, eventReasonEvictionAttemptFailed, "Attempt to evict pod %s/%s failed: %v", pod.Namespace, pod.Name, err)
+				}
 				d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, eventReasonEvictionAttemptFailed, "Attempt to evict pod from node %s failed: %v", node.Name, err)
-				waitTime := backoff.Step()
+				// the custom eviction endpoints also answer 429 to their transient errors, only the Kubernetes eviction API tells that the PDB blocks the eviction
+				if _, ok := d.getEvictionAPIURL(pod); !ok {
+					if blockedErr := d.checkPDBPermanentlyBlocked(ctx, pod); blockedErr != nil {
+						return blockedErr
+					}
+				}
+				// the jitter only shortens our own backoff, the Retry-After proposed by the API server is respected as is
+				waitTime := d.jitterEvictionWait(backoff.Step())
 				if statErr, ok := err.(apierrors.APIStatus); ok && statErr.Status().Details != nil {
 					if proposedWaitSeconds := statErr.Status().Details.RetryAfterSeconds; proposedWaitSeconds > 0 {
 						waitTime = time.Duration(proposedWaitSeconds) * time.Second
@@ -907,45 +2902,218 @@ func (d *APIDrainer) evictionSequence(ctx context.Context, node *core.Node, pod
 				case <-ctx.Done():
 				}
 			case apierrors.IsNotFound(err):
-				// the pod is already gone
+				// the pod is already gone, removed by someone else as none of our eviction calls was accepted
 				// maybe we still need to perform PVC management
-				err = d.deletePVCAndPV(ctx, pod, pvcs)
-				if err != nil {
-					return VolumeCleanupError{Err: err} // this one is typed because we match it to a failure cause
-				}
-				return nil
+				recordDuration(nil)
+				return d.cleanupVolumes(ctx, node, pod, pvcs, summary, false)
 			case err != nil:
+				setEvictionAwaitingBudget(ctx, pod, false)
 				if eh := otherErrorsHandlerFunc(err); eh != nil {
 					return eh
 				}
 			default: // this means the API answered 200/201, we wait for the pod deletion
+				setEvictionAwaitingBudget(ctx, pod, false)
 				// now that the eviction is confirmed we can only wait for the pod terminationGracePeriod (and evictionHeadroom to give some buffer)
-				err := d.awaitDeletion(ctx, pod, d.getGracePeriodWithEvictionHeadRoom(pod))
-				if err != nil {
-					return fmt.Errorf("cannot confirm pod was deleted: %w", err)
+				// The wait stops as soon as the eviction is aborted.
+				awaitCtx, cancelAwait := contextWithAbort(ctx, abort)
+				err := d.awaitDeletion(awaitCtx, pod, d.getGracePeriodWithEvictionHeadRoom(ctx, pod))
+				cancelAwait()
+				var recreated podRecreatedOnNodeError
+				if errors.As(err, &recreated) {
+					// the node still hosts the pod, its replacement is evicted with the same name
+					TracedLogger(ctx, d.l).Info("pod recreated on the node, evicting its replacement", zap.String("node", node.Name), zap.String("pod", pod.Name), zap.String("pod_namespace", pod.Namespace), zap.String("new_uid", string(recreated.pod.GetUID())))
+					d.eventRecorder.PodEventf(ctx, recreated.pod, core.EventTypeWarning, eventReasonPodRecreatedOnNode, "Pod recreated on node %s after the eviction of pod %s, evicting it too", node.Name, pod.GetUID())
+					pod = recreated.pod
+					continue
 				}
-				err = d.deletePVCAndPV(ctx, pod, pvcs)
 				if err != nil {
-					return VolumeCleanupError{Err: err} // this one is typed because we match it to a failure cause
+					return fmt.Errorf("cannot confirm pod was deleted: %w", err)
 				}
+				recordDuration(nil)
+				// a pod that was already terminating was not evicted by us
+				return d.cleanupVolumes(ctx, node, pod, pvcs, summary, evicted)
+			}
+		}
+	}
+}
+
+// recordPodEvictionDuration records the duration of the eviction of a pod, by eviction path and outcome
+func (d *APIDrainer) recordPodEvictionDuration(ctx context.Context, node *core.Node, pod *core.Pod, abort <-chan struct{}, err error, duration time.Duration) {
+	path := evictionPathKubernetes
+	if _, ok := d.getEvictionAPIURL(pod); ok {
+		path = evictionPathOperator
+	}
+	result := podEvictionResultSucceeded
+	if err != nil {
+		result = podEvictionResultFailed
+		select {
+		case <-abort:
+			result = podEvictionResultAborted
+		default:
+			if errors.As(err, &PodEvictionTimeoutError{}) || errors.As(err, &PodDeletionTimeoutError{}) {
+				result = podEvictionResultTimeout
+			}
+		}
+	}
+	tags, _ := tag.New(ctx, tag.Upsert(TagNodeName, node.GetName()), tag.Upsert(TagEvictionPath, path), tag.Upsert(TagResult, result))
+	d.statRecordForNode(tags, node, MeasurePodEvictionDuration.M(float64(duration.Milliseconds())))
+}
+
+// contextWithAbort returns a context that is also cancelled when abort is closed
+func contextWithAbort(ctx context.Context, abort <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-abort:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// cleanupVolumes deletes the PVCs and PVs of the pod once it is gone. If the pod was not evicted by the drainer the cleanup
+// can be skipped with WithSkipPVCCleanupIfRemovedByOthers: we cannot know if the actor that removed it wanted the volumes to be deleted.
+func (d *APIDrainer) cleanupVolumes(ctx context.Context, node *core.Node, pod *core.Pod, pvcs []*core.PersistentVolumeClaim, summary *PodEvictionSummary, evicted bool) error {
+	summary.RemovedByOtherActor = !evicted
+	if !evicted {
+		TracedLogger(ctx, d.l).Info("pod was removed by another actor", zap.String("node", node.Name), zap.String("pod", pod.Name), zap.String("pod_namespace", pod.Namespace))
+		if d.skipPVCCleanupIfRemovedByOthers {
+			if len(pvcs) > 0 {
+				d.eventRecorder.PodEventf(ctx, pod, core.EventTypeNormal, eventReasonPVCCleanupSkipped, "Skipping the cleanup of %d PVC(s), the pod was not evicted by draino", len(pvcs))
+			}
+			return nil
+		}
+		if d.confirmPodGoneBeforePVCCleanup && len(pvcs) > 0 {
+			replaced, err := d.isPodReplaced(ctx, pod)
+			if err != nil {
+				return VolumeCleanupError{Err: err}
+			}
+			if replaced {
+				d.eventRecorder.PodEventf(ctx, pod, core.EventTypeNormal, eventReasonPVCCleanupSkipped, "Skipping the cleanup of %d PVC(s), a pod with the same name was created since", len(pvcs))
 				return nil
 			}
 		}
 	}
+	if d.respectPVCRetentionPolicy && len(pvcs) > 0 {
+		retained, err := d.isPVCRetainedByStatefulSet(ctx, pod)
+		if err != nil {
+			return VolumeCleanupError{Err: err}
+		}
+		if retained {
+			d.eventRecorder.PodEventf(ctx, pod, core.EventTypeNormal, eventReasonPVCCleanupSkipped, "Skipping the cleanup of %d PVC(s), the StatefulSet retains its PVCs", len(pvcs))
+			return nil
+		}
+	}
+	var err error
+	summary.PVCsDeleted, err = d.deletePVCAndPV(ctx, pod, pvcs)
+	if err != nil {
+		return VolumeCleanupError{Err: err} // this one is typed because we match it to a failure cause
+	}
+	return nil
+}
+
+// isPodReplaced fetches the pod from the API, bypassing the caches. It returns true if a pod with the same name but another UID exists,
+// and an error if the pod itself is still present.
+func (d *APIDrainer) isPodReplaced(ctx context.Context, pod *core.Pod) (bool, error) {
+	got, err := d.c.CoreV1().Pods(pod.GetNamespace()).Get(ctx, pod.GetName(), meta.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot confirm pod %s/%s is gone: %w", pod.GetNamespace(), pod.GetName(), err)
+	}
+	if got.GetUID() == pod.GetUID() {
+		return false, fmt.Errorf("pod %s/%s is still present", pod.GetNamespace(), pod.GetName())
+	}
+	return true, nil
+}
+
+// isPVCRetainedByStatefulSet returns true if the pod is owned by a StatefulSet that retains its PVCs when it is deleted (whenDeleted).
+// The eviction is neither a deletion nor a scale down of the StatefulSet, the whenDeleted scope is used as the closest intent of its owner.
+func (d *APIDrainer) isPVCRetainedByStatefulSet(ctx context.Context, pod *core.Pod) (bool, error) {
+	owner := meta.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "StatefulSet" {
+		return false, nil
+	}
+	var sts appsv1.StatefulSet
+	if err := d.crClient.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: owner.Name}, &sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("cannot get statefulset %s/%s: %w", pod.Namespace, owner.Name, err)
+	}
+	policy := sts.Spec.PersistentVolumeClaimRetentionPolicy
+	return policy != nil && policy.WhenDeleted == appsv1.RetainPersistentVolumeClaimRetentionPolicyType, nil
 }
 
-func (d *APIDrainer) awaitDeletion(ctx context.Context, pod *core.Pod, timeout time.Duration) error {
+// checkPDBPermanentlyBlocked returns a PodDisruptionBudgetBlockedError if the fail fast is enabled and one of the PDBs of the pod is permanently blocked.
+// Errors while fetching the PDBs are only logged: the eviction keeps being retried.
+func (d *APIDrainer) checkPDBPermanentlyBlocked(ctx context.Context, pod *core.Pod) error {
+	if !d.failFastOnBlockedPDB || d.pdbIndexer == nil {
+		return nil
+	}
+	pdbs, err := d.pdbIndexer.GetPDBsForPods(ctx, []*core.Pod{pod})
+	if err != nil {
+		TracedLogger(ctx, d.l).Info("cannot get pdbs to check if they are blocked", zap.String("pod", pod.Namespace+"/"+pod.Name), zap.Error(err))
+		return nil
+	}
+	var blocked []*policy.PodDisruptionBudget
+	for _, pdb := range pdbs[index.GeneratePodIndexKey(pod.GetName(), pod.GetNamespace())] {
+		if utils.IsPDBPermanentlyBlocked(pdb) {
+			blocked = append(blocked, pdb)
+		}
+	}
+	if len(blocked) == 0 {
+		return nil
+	}
+	return PodDisruptionBudgetBlockedError{PodName: pod.GetNamespace() + "/" + pod.GetName(), PDBs: utils.GetPDBNames(blocked)} // this one is typed because we match it to a failure cause
+}
+
+// setEvictionSpanTags tags the span with the controller and the PDBs of the pod, when they can be resolved
+func (d *APIDrainer) setEvictionSpanTags(ctx context.Context, span tracing.Span, pod *core.Pod) {
+	if chain := GetOwnerChain(pod, d.runtimeObjectStore); len(chain) > 0 {
+		ctrl := chain[len(chain)-1]
+		span.SetTag("controller_kind", ctrl.Kind)
+		span.SetTag("controller_name", ctrl.Name)
+	}
+	if utils.HasPreStopHook(pod) {
+		span.SetTag("prestop_hook", true)
+	}
+	if d.pdbIndexer == nil {
+		return
+	}
+	pdbs, err := d.pdbIndexer.GetPDBsForPods(ctx, []*core.Pod{pod})
+	if err != nil {
+		TracedLogger(ctx, d.l).Info("cannot get pdbs for span tags", zap.String("pod", pod.Namespace+"/"+pod.Name), zap.Error(err))
+		return
+	}
+	if names := utils.GetPDBNames(pdbs[index.GeneratePodIndexKey(pod.GetName(), pod.GetNamespace())]); len(names) > 0 {
+		span.SetTag("pdb", strings.Join(names, ","))
+	}
+}
+
+// getDeletionPollPeriod returns the period of the checks of a deletion with the given timeout, between deletionPollFloor and deletionPollCeiling
+func (d *APIDrainer) getDeletionPollPeriod(timeout time.Duration) time.Duration {
 	// We need to optimise the pollPeriod to maximize the chance to capture the deletion and not falling into rate limiting issue on the client side
 	pollPeriod := timeout / 10 // let's make 10 tentatives to check deletion
-	if pollPeriod < 6*time.Second {
-		pollPeriod = 6 * time.Second
+	if pollPeriod < d.deletionPollFloor {
+		pollPeriod = d.deletionPollFloor
 	}
-	if pollPeriod > 2*time.Minute {
-		pollPeriod = 2 * time.Minute
+	if d.deletionPollCeiling > 0 && pollPeriod > d.deletionPollCeiling {
+		pollPeriod = d.deletionPollCeiling
 	}
+	return pollPeriod
+}
 
+func (d *APIDrainer) awaitDeletion(ctx context.Context, pod *core.Pod, timeout time.Duration) error {
+	pollPeriod := d.getDeletionPollPeriod(timeout)
+
+	hasPreStopHook := utils.HasPreStopHook(pod)
 	polls := 0
-	err := wait.PollImmediate(pollPeriod, timeout, func() (bool, error) {
+	preStopHookRunning := false
+	replacementPending := false
+	err := wait.PollImmediateWithContext(ctx, pollPeriod, timeout, func(ctx context.Context) (bool, error) {
 		polls += 1
 		var got core.Pod
 		err := d.crClient.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, &got)
@@ -956,87 +3124,263 @@ func (d *APIDrainer) awaitDeletion(ctx context.Context, pod *core.Pod, timeout t
 			return false, fmt.Errorf("cannot get pod %s/%s: %w", pod.GetNamespace(), pod.GetName(), err)
 		}
 		if got.GetUID() != pod.GetUID() {
+			if d.evictRecreatedPodsOnNode {
+				replacementPending = got.Spec.NodeName == ""
+				if replacementPending {
+					// the replacement may still be scheduled on the node
+					return false, nil
+				}
+				if got.Spec.NodeName == pod.Spec.NodeName {
+					return false, podRecreatedOnNodeError{pod: &got}
+				}
+			}
 			return true, nil
 		}
+		running := hasPreStopHook && isInTerminationGracePeriod(&got, time.Now())
+		if running && !preStopHookRunning {
+			TracedLogger(ctx, d.l).Info("pod is terminating, waiting for its PreStop hook", zap.String("pod", pod.Namespace+"/"+pod.Name), zap.Time("deletion_timestamp", got.DeletionTimestamp.Time))
+		}
+		preStopHookRunning = running
 		return false, nil
 	})
 	if err != nil {
+		// the poll reports the cancellation of the context as a timeout
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("stopped waiting for the deletion of pod %s/%s: %w", pod.GetNamespace(), pod.GetName(), ctxErr)
+		}
+		if errors.Is(err, wait.ErrWaitTimeout) && replacementPending {
+			// the replacement can be pending until the cleanup of the volumes of the evicted pod, e.g. a PVC bound to a local PV of the node
+			TracedLogger(ctx, d.l).Info("recreated pod still not scheduled, considering the pod deleted", zap.String("pod", pod.Namespace+"/"+pod.Name), zap.Duration("timeout", timeout))
+			return nil
+		}
 		if errors.Is(err, wait.ErrWaitTimeout) {
-			d.l.With(zap.String("pod", pod.Namespace+"/"+pod.Name), zap.Duration("timeout", timeout), zap.Duration("poll", pollPeriod), zap.Int("polls", polls)).
-				Warn("pod deletion timed out")
-			return PodDeletionTimeoutError{} // this one is typed because we match it to a failure cause
+			logger := TracedLogger(ctx, d.l).With(zap.String("pod", pod.Namespace+"/"+pod.Name), zap.Duration("timeout", timeout), zap.Duration("poll", pollPeriod), zap.Int("polls", polls), zap.Bool("prestop_hook", hasPreStopHook))
+			if preStopHookRunning {
+				logger.Info("pod deletion timed out while its PreStop hook is running")
+			} else {
+				logger.Warn("pod deletion timed out")
+			}
+			return PodDeletionTimeoutError{PreStopHookRunning: preStopHookRunning} // this one is typed because we match it to a failure cause
 		}
 		return err // unexpected Get error above
 	}
 	return nil
 }
 
-func (d *APIDrainer) deletePVCAndPV(ctx context.Context, pod *core.Pod, pvcs []*core.PersistentVolumeClaim) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "deletePVCAndPV")
+// isInTerminationGracePeriod returns true if the pod is terminating and its grace period is not over yet.
+// The deletion timestamp is set by the API server to the end of the grace period.
+func isInTerminationGracePeriod(pod *core.Pod, now time.Time) bool {
+	return pod.DeletionTimestamp != nil && now.Before(pod.DeletionTimestamp.Time)
+}
+
+// deletePVCAndPV returns the names of the deleted PVCs
+func (d *APIDrainer) deletePVCAndPV(ctx context.Context, pod *core.Pod, pvcs []*core.PersistentVolumeClaim) ([]string, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx, "deletePVCAndPV")
 	defer span.Finish()
 	span.SetTag("pod", pod.GetName())
 
 	pvcDeleted, err := d.deletePVCAssociatedWithStorageClass(ctx, pod, pvcs)
+	names := make([]string, 0, len(pvcDeleted))
+	for _, pvc := range pvcDeleted {
+		names = append(names, pvc.GetName())
+	}
 	if err != nil {
-		return err
+		return names, err
 	}
 
 	// now if the pod is pending because it is missing the PVC and if it is controlled by a statefulset we should delete it to have statefulset controller rebuilding the PVC
 	if len(pvcDeleted) > 0 {
 		if err := d.deletePVAssociatedWithDeletedPVC(ctx, pod, pvcDeleted); err != nil {
-			return err
+			return names, err
 		}
-		for _, pvc := range pvcDeleted {
-			if err := d.podDeleteRetryWaitingForPVC(ctx, pod, pvc); err != nil {
-				return err
-			}
+		if err := d.podDeleteRetryWaitingForPVCs(ctx, pod, pvcDeleted); err != nil {
+			return names, err
 		}
 	}
-	return nil
+	return names, nil
+}
+
+// pvcToRecreate is a PVC deleted during the eviction of a pod, waiting for its recreation by the StatefulSet controller
+type pvcToRecreate struct {
+	pvc                         *core.PersistentVolumeClaim
+	unboundWaitForFirstConsumer bool
+}
+
+// pvcNames returns the comma separated namespaced names of the PVCs
+func pvcNames(pvcs []pvcToRecreate) string {
+	names := make([]string, 0, len(pvcs))
+	for _, p := range pvcs {
+		names = append(names, p.pvc.Namespace+"/"+p.pvc.Name)
+	}
+	return strings.Join(names, ",")
 }
 
-func (d *APIDrainer) podDeleteRetryWaitingForPVC(ctx context.Context, pod *core.Pod, pvc *core.PersistentVolumeClaim) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "podDeleteRetryWaitingForPVC")
+// podDeleteRetryWaitingForPVCs waits for the recreation of all the deleted PVCs of the pod in a single loop,
+// so that the pod deletions needed to force the recreation are shared by its PVCs.
+func (d *APIDrainer) podDeleteRetryWaitingForPVCs(ctx context.Context, pod *core.Pod, pvcs []*core.PersistentVolumeClaim) error {
+	span, ctx := tracing.StartSpanFromContext(ctx, "podDeleteRetryWaitingForPVCs")
 	defer span.Finish()
-	span.SetTag("pvc", pvc.GetName())
+	span.SetTag("pvcs", len(pvcs))
 
-	podDeleteCheckPVCFunc := func() (bool, error) {
-		// check if the PVC was created
-		gotPVC, err := d.c.CoreV1().PersistentVolumeClaims(pvc.GetNamespace()).Get(ctx, pvc.GetName(), meta.GetOptions{})
-		if err != nil && !apierrors.IsNotFound(err) {
-			return false, err
+	pending := make([]pvcToRecreate, 0, len(pvcs))
+	for _, pvc := range pvcs {
+		unboundWaitForFirstConsumer, err := d.isUnboundWaitForFirstConsumerPVC(ctx, pvc)
+		if err != nil {
+			return err
 		}
+		pending = append(pending, pvcToRecreate{pvc: pvc, unboundWaitForFirstConsumer: unboundWaitForFirstConsumer})
+	}
 
-		if !apierrors.IsNotFound(err) {
-			if gotPVC != nil && string(gotPVC.UID) != "" && string(gotPVC.UID) != string(pvc.UID) {
-				d.l.Info("associated pvc was recreated", zap.String("pod", pod.GetName()), zap.String("namespace", pod.GetNamespace()), zap.String("pvc", pvc.GetName()), zap.String("pvc-old-uid", string(pvc.GetUID())), zap.String("pvc-new-uid", string(gotPVC.GetUID())))
-				return true, nil
-			}
+	timeout := d.getPVCRecreateTimeout(ctx, pod)
+	podDeletions := 0
+	start := time.Now()
+	err := wait.PollImmediate(DefaultPodDeletePeriodWaitingForPVC, timeout, func() (bool, error) {
+		var err error
+		pending, err = d.podDeleteCheckPVCs(ctx, pod, pending, &podDeletions)
+		return len(pending) == 0, err
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		err = PVCRecreateTimeoutError{PodName: pod.Namespace + "/" + pod.Name, PVCName: pvcNames(pending), Timeout: timeout, PodDeletions: podDeletions}
+	}
+	span.SetTag("podDeletions", podDeletions)
+	recordPVCRecreateDuration(ctx, err, time.Since(start))
+	return err
+}
+
+// getPVCRecreateTimeout returns the timeout of the PVC recreation, the PVCRecreateTimeoutAnnotationKey annotation of the pod or its controller takes precedence.
+// An invalid annotation is reported with a warning event, it is meant for the eviction path: the estimations use lookupPVCRecreateTimeout.
+func (d *APIDrainer) getPVCRecreateTimeout(ctx context.Context, pod *core.Pod) time.Duration {
+	timeout, invalidValue := d.lookupPVCRecreateTimeout(pod)
+	if invalidValue != "" {
+		TracedLogger(ctx, d.l).Warn("Ignoring pvc recreate timeout annotation", zap.String("pod", pod.Namespace+"/"+pod.Name), zap.String("value", invalidValue))
+		d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, eventReasonBadValueForAnnotation, "Ignoring %s annotation, '%s' is not a positive duration", PVCRecreateTimeoutAnnotationKey, invalidValue)
+	}
+	return timeout
+}
+
+// lookupPVCRecreateTimeout returns the timeout of the PVC recreation without reporting anything. An invalid annotation falls back
+// to the default timeout and is returned as invalidValue.
+func (d *APIDrainer) lookupPVCRecreateTimeout(pod *core.Pod) (timeout time.Duration, invalidValue string) {
+	value, ok := GetAnnotationFromPodOrController(PVCRecreateTimeoutAnnotationKey, pod, d.runtimeObjectStore)
+	if !ok {
+		return d.pvcRecreateTimeout, ""
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return d.pvcRecreateTimeout, value
+	}
+	return timeout, ""
+}
+
+// recordPVCRecreateDuration records the time spent waiting for the StatefulSet controller to recreate a deleted PVC, by outcome
+func recordPVCRecreateDuration(ctx context.Context, err error, duration time.Duration) {
+	result := pvcRecreateResultRecreated
+	if errors.As(err, &PVCRecreateTimeoutError{}) {
+		result = pvcRecreateResultTimeout
+	} else if errors.As(err, &MaxPodDeletionsForPVCRecreateExceededError{}) {
+		result = pvcRecreateResultMaxPodDel
+	} else if err != nil {
+		result = pvcRecreateResultError
+	}
+	tags, _ := tag.New(ctx, tag.Upsert(TagResult, result))
+	stats.Record(tags, MeasurePVCRecreateDuration.M(float64(duration.Milliseconds())))
+}
+
+// podDeleteCheckPVCs returns the PVCs that are not recreated yet, and deletes the pod once for all of them to force their recreation.
+// For unbound PVCs of WaitForFirstConsumer storage classes the pod is deleted only if it was not already replaced:
+// the replacement pod will trigger the creation and the binding of the new PVC.
+// podDeletions counts the deletions of the pod, a MaxPodDeletionsForPVCRecreateExceededError is returned instead of exceeding the configured maximum.
+func (d *APIDrainer) podDeleteCheckPVCs(ctx context.Context, pod *core.Pod, pvcs []pvcToRecreate, podDeletions *int) ([]pvcToRecreate, error) {
+	pending := make([]pvcToRecreate, 0, len(pvcs))
+	for _, p := range pvcs {
+		recreated, err := d.isPVCRecreated(ctx, pod, p.pvc)
+		if err != nil {
+			return pvcs, err
+		}
+		if !recreated {
+			pending = append(pending, p)
 		}
+	}
+	if len(pending) == 0 {
+		return pending, nil
+	}
 
-		d.l.Info("deleting pod to force pvc recreate", zap.String("pod", pod.GetName()), zap.String("namespace", pod.GetNamespace()))
-		err = d.c.CoreV1().Pods(pod.GetNamespace()).Delete(ctx, pod.GetName(), meta.DeleteOptions{})
+	waitForReplacement := true
+	for _, p := range pending {
+		if !p.unboundWaitForFirstConsumer {
+			waitForReplacement = false
+			break
+		}
+	}
+	if waitForReplacement {
+		gotPod, err := d.c.CoreV1().Pods(pod.GetNamespace()).Get(ctx, pod.GetName(), meta.GetOptions{})
 		if err != nil && !apierrors.IsNotFound(err) {
-			return false, fmt.Errorf("cannot delete pod %s/%s to regenerated PVC: %w", pod.GetNamespace(), pod.GetName(), err)
+			return pending, fmt.Errorf("cannot get pod %s/%s: %w", pod.GetNamespace(), pod.GetName(), err)
 		}
+		if apierrors.IsNotFound(err) || gotPod.GetUID() != pod.GetUID() {
+			TracedLogger(ctx, d.l).Info("waiting for the replacement pod to trigger the pvc binding", zap.String("pod", pod.GetName()), zap.String("namespace", pod.GetNamespace()), zap.String("pvcs", pvcNames(pending)))
+			return pending, nil
+		}
+	}
+
+	if d.maxPodDeletionsForPVCRecreate > 0 && *podDeletions >= d.maxPodDeletionsForPVCRecreate {
+		return pending, MaxPodDeletionsForPVCRecreateExceededError{PodName: pod.Namespace + "/" + pod.Name, PVCName: pvcNames(pending), Max: d.maxPodDeletionsForPVCRecreate}
+	}
+	TracedLogger(ctx, d.l).Info("deleting pod to force pvc recreate", zap.String("pod", pod.GetName()), zap.String("namespace", pod.GetNamespace()), zap.String("pvcs", pvcNames(pending)))
+	err := d.c.CoreV1().Pods(pod.GetNamespace()).Delete(ctx, pod.GetName(), meta.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return pending, fmt.Errorf("cannot delete pod %s/%s to regenerated PVC: %w", pod.GetNamespace(), pod.GetName(), err)
+	}
+	*podDeletions++
+	return pending, nil
+}
+
+// isPVCRecreated tells if the PVC was recreated with a new uid
+func (d *APIDrainer) isPVCRecreated(ctx context.Context, pod *core.Pod, pvc *core.PersistentVolumeClaim) (bool, error) {
+	gotPVC, err := d.c.CoreV1().PersistentVolumeClaims(pvc.GetNamespace()).Get(ctx, pvc.GetName(), meta.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if gotPVC == nil || string(gotPVC.UID) == "" || string(gotPVC.UID) == string(pvc.UID) {
+		return false, nil
+	}
+	TracedLogger(ctx, d.l).Info("associated pvc was recreated", zap.String("pod", pod.GetName()), zap.String("namespace", pod.GetNamespace()), zap.String("pvc", pvc.GetName()), zap.String("pvc-old-uid", string(pvc.GetUID())), zap.String("pvc-new-uid", string(gotPVC.GetUID())))
+	d.eventRecorder.PersistentVolumeClaimEventf(ctx, gotPVC, core.EventTypeNormal, eventReasonPVCRecreated, "PVC recreated after the eviction of pod %s/%s, previous uid %s", pod.Namespace, pod.Name, pvc.UID)
+	d.eventRecorder.PodEventf(ctx, pod, core.EventTypeNormal, eventReasonPVCRecreated, "PVC %s recreated with uid %s, previous uid %s", pvc.Name, gotPVC.UID, pvc.UID)
+	return true, nil
+}
+
+// isUnboundWaitForFirstConsumerPVC tells if the PVC has no bound PV yet and uses a storage class with the WaitForFirstConsumer binding mode
+func (d *APIDrainer) isUnboundWaitForFirstConsumerPVC(ctx context.Context, pvc *core.PersistentVolumeClaim) (bool, error) {
+	if pvc.Spec.VolumeName != "" || pvc.Spec.StorageClassName == nil {
+		return false, nil
+	}
+	sc, err := d.c.StorageV1().StorageClasses().Get(ctx, *pvc.Spec.StorageClassName, meta.GetOptions{})
+	if apierrors.IsNotFound(err) {
 		return false, nil
 	}
-	return wait.PollImmediate(DefaultPodDeletePeriodWaitingForPVC, DefaultPVCRecreateTimeout, podDeleteCheckPVCFunc)
+	if err != nil {
+		return false, fmt.Errorf("cannot get storage class %s: %w", *pvc.Spec.StorageClassName, err)
+	}
+	return sc.VolumeBindingMode != nil && *sc.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer, nil
 }
 
 func (d *APIDrainer) deletePVAssociatedWithDeletedPVC(ctx context.Context, pod *core.Pod, pvcDeleted []*core.PersistentVolumeClaim) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "deletePVAssociatedWithDeletedPVC")
+	span, ctx := tracing.StartSpanFromContext(ctx, "deletePVAssociatedWithDeletedPVC")
 	defer span.Finish()
 
-	for _, claim := range pvcDeleted {
+	return d.forEachPVC(ctx, pvcDeleted, func(ctx context.Context, _ int, claim *core.PersistentVolumeClaim) error {
 		if claim.Spec.VolumeName == "" {
-			continue
+			return nil
 		}
 		var pv core.PersistentVolume
 		err := d.crClient.Get(ctx, types.NamespacedName{Name: claim.Spec.VolumeName}, &pv)
 		if apierrors.IsNotFound(err) {
-			d.l.Info("GET: PV not found", zap.String("name", claim.Spec.VolumeName), zap.String("claim", claim.Name), zap.String("claimNamespace", claim.Namespace))
-			continue // This PV was already deleted
+			TracedLogger(ctx, d.l).Info("GET: PV not found", zap.String("name", claim.Spec.VolumeName), zap.String("claim", claim.Name), zap.String("claimNamespace", claim.Namespace))
+			return nil // This PV was already deleted
 		}
 
 		d.eventRecorder.PersistentVolumeEventf(ctx, &pv, core.EventTypeNormal, "Eviction", fmt.Sprintf("Deletion requested due to association with evicted pvc %s/%s and pod %s/%s", claim.Namespace, claim.Name, pod.Namespace, pod.Name))
@@ -1044,33 +3388,28 @@ func (d *APIDrainer) deletePVAssociatedWithDeletedPVC(ctx context.Context, pod *
 
 		err = d.c.CoreV1().PersistentVolumes().Delete(ctx, pv.Name, meta.DeleteOptions{})
 		if apierrors.IsNotFound(err) {
-			d.l.Info("DELETE: PV not found", zap.String("name", pv.Name))
-			continue // This PV was already deleted
+			TracedLogger(ctx, d.l).Info("DELETE: PV not found", zap.String("name", pv.Name))
+			return nil // This PV was already deleted
 		}
 		if err != nil {
 			d.eventRecorder.PersistentVolumeEventf(ctx, &pv, core.EventTypeWarning, "EvictionFailure", fmt.Sprintf("Could not delete PV: %v", err))
 			d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, "EvictionFailure", fmt.Sprintf("Could not delete PV %s: %v", pv.Name, err))
 			return fmt.Errorf("cannot delete pv %s: %w", pv.Name, err)
 		}
-		d.l.Info("deleting pv", zap.String("pv", pv.Name))
+		timeout := d.getPVCDeletionTimeout(pv.Spec.StorageClassName)
+		TracedLogger(ctx, d.l).Info("deleting pv", zap.String("pv", pv.Name), zap.String("storageClassName", pv.Spec.StorageClassName), zap.Duration("timeout", timeout))
 
-		// wait for PVC complete deletion
-		if err := d.awaitPVDeletion(ctx, &pv, time.Minute); err != nil {
+		// wait for PV complete deletion
+		if err := d.awaitPVDeletion(ctx, &pv, timeout); err != nil {
 			return fmt.Errorf("pv deletion timeout %s: %w", pv.Name, err)
 		}
-	}
-	return nil
+		recordVolumeDeleted(ctx, MeasurePVDeleted, pv.Spec.StorageClassName)
+		return nil
+	})
 }
 
 func (d *APIDrainer) awaitPVDeletion(ctx context.Context, pv *core.PersistentVolume, timeout time.Duration) error {
-	// We need to optimise the pollPeriod to maximize the chance to capture the deletion and not falling into rate limiting issue on the client side
-	pollPeriod := timeout / 10 // let's make 10 tentatives to check deletion
-	if pollPeriod < 6*time.Second {
-		pollPeriod = 6 * time.Second
-	}
-	if pollPeriod > 2*time.Minute {
-		pollPeriod = 2 * time.Minute
-	}
+	pollPeriod := d.getDeletionPollPeriod(timeout)
 
 	return wait.PollImmediate(pollPeriod, timeout, func() (bool, error) {
 		var got core.PersistentVolume
@@ -1088,7 +3427,9 @@ func (d *APIDrainer) awaitPVDeletion(ctx context.Context, pv *core.PersistentVol
 	})
 }
 
-func PVCStorageClassCleanupEnabled(p *v1.Pod, store RuntimeObjectStore, defaultTrueIfNoEvictionUrl bool) bool {
+// PVCStorageClassCleanupEnabled returns the value of the PVCStorageClassCleanupAnnotationKey annotation of the pod or its controller,
+// defaultCleanup if the annotation is not set. Use GlobalConfig.IsPVCCleanupEnabled to get the default of the configuration.
+func PVCStorageClassCleanupEnabled(p *v1.Pod, store RuntimeObjectStore, defaultCleanup bool) bool {
 	valAnnotation, _ := GetAnnotationFromPodOrController(PVCStorageClassCleanupAnnotationKey, p, store)
 	if valAnnotation == PVCStorageClassCleanupAnnotationTrueValue {
 		return true
@@ -1096,26 +3437,26 @@ func PVCStorageClassCleanupEnabled(p *v1.Pod, store RuntimeObjectStore, defaultT
 	if valAnnotation == PVCStorageClassCleanupAnnotationFalseValue {
 		return false
 	}
-
-	if defaultTrueIfNoEvictionUrl {
-		_, evictionUrlFound := GetAnnotationFromPodOrController(EvictionAPIURLAnnotationKey, p, store)
-		return !evictionUrlFound
-	}
-
-	return false
+	return defaultCleanup
 }
 
 // getInScopePVCs will return all pvcs that are "in scope" and available.
 // Where in scope means that the storage class is allowed to be deleted by configuration.
-func (d *APIDrainer) getInScopePVCs(ctx context.Context, pod *core.Pod) ([]*core.PersistentVolumeClaim, error) {
-	span, ctx := tracer.StartSpanFromContext(ctx, "fetchPVCsAssociatedWithPod")
+// Nothing is in scope if the node has the annotation PVCCleanupDisabledNodeAnnotationKey set to true.
+func (d *APIDrainer) getInScopePVCs(ctx context.Context, node *core.Node, pod *core.Pod) ([]*core.PersistentVolumeClaim, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx, "fetchPVCsAssociatedWithPod")
 	defer span.Finish()
 
-	if d.storageClassesAllowingPVDeletion == nil {
+	storageClassesAllowingPVDeletion := d.getStorageClassesAllowingPVDeletion(ctx)
+	if storageClassesAllowingPVDeletion == nil {
+		return nil, nil
+	}
+
+	if node.GetAnnotations()[PVCCleanupDisabledNodeAnnotationKey] == "true" {
 		return nil, nil
 	}
 
-	if !PVCStorageClassCleanupEnabled(pod, d.runtimeObjectStore, d.globalConfig.PVCManagementEnableIfNoEvictionUrl) {
+	if !d.globalConfig.IsPVCCleanupEnabled(pod, d.runtimeObjectStore) {
 		return nil, nil
 	}
 
@@ -1124,22 +3465,25 @@ func (d *APIDrainer) getInScopePVCs(ctx context.Context, pod *core.Pod) ([]*core
 		if v.PersistentVolumeClaim == nil {
 			continue
 		}
-		d.l.Info("looking at volume with PVC", zap.String("name", v.Name), zap.String("claim", v.PersistentVolumeClaim.ClaimName))
+		TracedLogger(ctx, d.l).Info("looking at volume with PVC", zap.String("name", v.Name), zap.String("claim", v.PersistentVolumeClaim.ClaimName))
 		pvc, err := d.c.CoreV1().PersistentVolumeClaims(pod.GetNamespace()).Get(ctx, v.PersistentVolumeClaim.ClaimName, meta.GetOptions{})
 		if apierrors.IsNotFound(err) {
-			d.l.Info("GET: PVC not found", zap.String("name", v.Name), zap.String("claim", v.PersistentVolumeClaim.ClaimName))
+			TracedLogger(ctx, d.l).Info("GET: PVC not found", zap.String("name", v.Name), zap.String("claim", v.PersistentVolumeClaim.ClaimName))
 			continue // This PVC was already deleted
 		}
 		if err != nil {
 			return nil, fmt.Errorf("cannot get pvc %s/%s: %w", pod.GetNamespace(), v.PersistentVolumeClaim.ClaimName, err)
 		}
 		if pvc.Spec.StorageClassName == nil {
-			d.l.Info("PVC with no StorageClassName", zap.String("claim", v.PersistentVolumeClaim.ClaimName))
+			TracedLogger(ctx, d.l).Info("PVC with no StorageClassName", zap.String("claim", v.PersistentVolumeClaim.ClaimName))
 			continue
 		}
-		if _, ok := d.storageClassesAllowingPVDeletion[*pvc.Spec.StorageClassName]; !ok {
-			d.l.Info("Skipping StorageClassName", zap.String("storageClassName", *pvc.Spec.StorageClassName))
-			continue
+		if _, ok := storageClassesAllowingPVDeletion[*pvc.Spec.StorageClassName]; !ok {
+			if !isPVCCleanupForced(pod, pvc) {
+				TracedLogger(ctx, d.l).Info("Skipping StorageClassName", zap.String("storageClassName", *pvc.Spec.StorageClassName))
+				continue
+			}
+			TracedLogger(ctx, d.l).Info("Forcing the cleanup of a PVC whose storage class does not allow the deletion", zap.String("claim", pvc.Name), zap.String("storageClassName", *pvc.Spec.StorageClassName))
 		}
 
 		claims = append(claims, pvc)
@@ -1150,69 +3494,297 @@ func (d *APIDrainer) getInScopePVCs(ctx context.Context, pod *core.Pod) ([]*core
 // deletePVCAssociatedWithStorageClass takes care of deleting the PVCs associated with the annotated classes
 // returns the list of deleted PVCs and the first error encountered if any
 func (d *APIDrainer) deletePVCAssociatedWithStorageClass(ctx context.Context, pod *core.Pod, pvcs []*core.PersistentVolumeClaim) ([]*core.PersistentVolumeClaim, error) {
-	span, ctx := tracer.StartSpanFromContext(ctx, "deletePVCAssociatedWithStorageClass")
+	span, ctx := tracing.StartSpanFromContext(ctx, "deletePVCAssociatedWithStorageClass")
 	defer span.Finish()
 
-	deletedPVCs := []*core.PersistentVolumeClaim{}
-	for _, pvc := range pvcs {
+	// the PVCs are deleted in parallel, deleted keeps the order of pvcs
+	deleted := make([]bool, len(pvcs))
+	err := d.forEachPVC(ctx, pvcs, func(ctx context.Context, i int, pvc *core.PersistentVolumeClaim) error {
 		var freshPvc core.PersistentVolumeClaim
 		err := d.crClient.Get(ctx, types.NamespacedName{Namespace: pvc.Namespace, Name: pvc.Name}, &freshPvc)
 		if apierrors.IsNotFound(err) {
-			d.l.Info("DELETE: PVC not found", zap.String("claim", pvc.Name))
-			continue // This PVC was already deleted
+			TracedLogger(ctx, d.l).Info("DELETE: PVC not found", zap.String("claim", pvc.Name))
+			return nil // This PVC was already deleted
 		}
 		if pvc.UID != freshPvc.UID {
-			d.l.Info("DELETE: PVC already replaced", zap.String("claim", pvc.Name))
-			continue
+			TracedLogger(ctx, d.l).Info("DELETE: PVC already replaced", zap.String("claim", pvc.Name))
+			return nil
 		}
 
+		if !d.reservePVCDeletion(ctx) {
+			d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, "EvictionFailure", fmt.Sprintf("Maximum number of PVC deletions reached for the drain, not deleting PVC %s/%s", pvc.Namespace, pvc.Name))
+			return MaxPVCDeletionsExceededError{NodeName: pod.Spec.NodeName, PVCName: pvc.Namespace + "/" + pvc.Name, Max: d.maxPVCDeletionsPerDrain}
+		}
+
+		if isPVCCleanupForced(pod, pvc) {
+			if _, ok := d.getStorageClassesAllowingPVDeletion(ctx)[storageClassName(pvc)]; !ok {
+				d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, eventReasonPVCCleanupForced, "Forcing the deletion of PVC %s/%s, its storage class %s does not allow the deletion", pvc.Namespace, pvc.Name, storageClassName(pvc))
+				d.eventRecorder.PersistentVolumeClaimEventf(ctx, pvc, core.EventTypeWarning, eventReasonPVCCleanupForced, "Forcing the deletion with the %s annotation, the storage class %s does not allow the deletion", PVCForceCleanupAnnotationKey, storageClassName(pvc))
+			}
+		}
 		d.eventRecorder.PodEventf(ctx, pod, core.EventTypeNormal, "Eviction", fmt.Sprintf("Deletion of associated PVC %s/%s", pvc.Namespace, pvc.Name))
 		d.eventRecorder.PersistentVolumeClaimEventf(ctx, pvc, core.EventTypeNormal, "Eviction", fmt.Sprintf("Deletion requested due to association with evicted pod %s/%s", pod.Namespace, pod.Name))
 
 		err = d.c.CoreV1().PersistentVolumeClaims(pod.GetNamespace()).Delete(ctx, pvc.Name, meta.DeleteOptions{})
 		if apierrors.IsNotFound(err) {
-			d.l.Info("DELETE: PVC not found", zap.String("claim", pvc.Name))
-			continue // This PVC was already deleted
+			TracedLogger(ctx, d.l).Info("DELETE: PVC not found", zap.String("claim", pvc.Name))
+			d.releasePVCDeletion(ctx)
+			return nil // This PVC was already deleted
 		}
 		if err != nil {
+			// nothing was deleted, the retries of the cleanup can use the slot again
+			d.releasePVCDeletion(ctx)
 			d.eventRecorder.PodEventf(ctx, pod, core.EventTypeWarning, "EvictionFailure", fmt.Sprintf("Could not delete PVC %s/%s: %v", pvc.Namespace, pvc.Name, err))
 			d.eventRecorder.PersistentVolumeClaimEventf(ctx, pvc, core.EventTypeWarning, "EvictionFailure", fmt.Sprintf("Could not delete: %v", err))
-			return deletedPVCs, fmt.Errorf("cannot delete pvc %s/%s: %w", pod.GetNamespace(), pvc.Name, err)
+			return fmt.Errorf("cannot delete pvc %s/%s: %w", pod.GetNamespace(), pvc.Name, err)
 		}
-		d.l.Info("deleting pvc", zap.String("pvc", pvc.Name), zap.String("namespace", pod.GetNamespace()), zap.String("pvc-uid", string(pvc.GetUID())))
+		timeout := d.getPVCDeletionTimeout(storageClassName(pvc))
+		TracedLogger(ctx, d.l).Info("deleting pvc", zap.String("pvc", pvc.Name), zap.String("namespace", pod.GetNamespace()), zap.String("pvc-uid", string(pvc.GetUID())), zap.String("storageClassName", storageClassName(pvc)), zap.Duration("timeout", timeout))
 
 		// wait for PVC complete deletion
-		if err := d.awaitPVCDeletion(ctx, pvc, awaitPVCDeletionTimeout); err != nil {
-			return deletedPVCs, fmt.Errorf("pvc deletion timeout %s/%s: %w", pod.GetNamespace(), pvc.Name, err)
+		if err := d.awaitPVCDeletion(ctx, pvc, timeout); err != nil {
+			return fmt.Errorf("pvc deletion timeout %s/%s: %w", pod.GetNamespace(), pvc.Name, err)
+		}
+		recordVolumeDeleted(ctx, MeasurePVCDeleted, storageClassName(pvc))
+		deleted[i] = true
+		return nil
+	})
+	deletedPVCs := []*core.PersistentVolumeClaim{}
+	for i, pvc := range pvcs {
+		if deleted[i] {
+			deletedPVCs = append(deletedPVCs, pvc)
+		}
+	}
+	return deletedPVCs, err
+}
+
+// isPVCCleanupForced returns true if the pod or the PVC has the PVCForceCleanupAnnotationKey annotation set to "true"
+func isPVCCleanupForced(pod *core.Pod, pvc *core.PersistentVolumeClaim) bool {
+	return pod.GetAnnotations()[PVCForceCleanupAnnotationKey] == "true" || pvc.GetAnnotations()[PVCForceCleanupAnnotationKey] == "true"
+}
+
+// getPVCDeletionTimeout returns the time waiting for the deletion of a PVC or a PV of the storage class
+func (d *APIDrainer) getPVCDeletionTimeout(storageClass string) time.Duration {
+	if timeout, ok := d.pvcDeletionTimeouts[storageClass]; ok && timeout > 0 {
+		return timeout
+	}
+	return awaitPVCDeletionTimeout
+}
+
+func storageClassName(pvc *core.PersistentVolumeClaim) string {
+	if pvc.Spec.StorageClassName == nil {
+		return ""
+	}
+	return *pvc.Spec.StorageClassName
+}
+
+// recordVolumeDeleted counts one more PVC or PV, depending on the measure, deleted for the storage class
+func recordVolumeDeleted(ctx context.Context, measure *stats.Int64Measure, storageClass string) {
+	tags, _ := tag.New(ctx, tag.Upsert(TagStorageClass, storageClass))
+	stats.Record(tags, measure.M(1))
+}
+
+// forEachPVC calls fn for each PVC, with at most pvcCleanupParallelism calls running at once. It returns the first error:
+// the PVCs not started yet are skipped and the context given to the calls in progress is cancelled.
+func (d *APIDrainer) forEachPVC(ctx context.Context, pvcs []*core.PersistentVolumeClaim, fn func(ctx context.Context, i int, pvc *core.PersistentVolumeClaim) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	workers := d.pvcCleanupParallelism
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(pvcs) {
+		workers = len(pvcs)
+	}
+	var (
+		wg       sync.WaitGroup
+		errLock  sync.Mutex
+		firstErr error
+	)
+	failed := make(chan struct{})
+	next := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if err := fn(ctx, i, pvcs[i]); err != nil {
+					errLock.Lock()
+					if firstErr == nil {
+						firstErr = err
+						close(failed)
+						cancel()
+					}
+					errLock.Unlock()
+				}
+			}
+		}()
+	}
+dispatch:
+	for i := range pvcs {
+		select {
+		case next <- i:
+		case <-failed:
+			break dispatch
+		case <-ctx.Done():
+			errLock.Lock()
+			if firstErr == nil {
+				firstErr = ctx.Err()
+			}
+			errLock.Unlock()
+			break dispatch
 		}
-		deletedPVCs = append(deletedPVCs, pvc)
 	}
-	return deletedPVCs, nil
+	close(next)
+	wg.Wait()
+	return firstErr
+}
+
+type pvcDeletionCounterKey struct{}
+
+// pvcDeletionCounter counts the PVCs deleted by the evictions, running in parallel, of a drain
+type pvcDeletionCounter struct {
+	sync.Mutex
+	count int
+}
+
+func withPVCDeletionCounter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pvcDeletionCounterKey{}, &pvcDeletionCounter{})
+}
+
+// reservePVCDeletion counts one more PVC deletion for the drain in the context.
+// It returns false, without counting it, if the limit set by WithMaxPVCDeletionsPerDrain is already reached.
+func (d *APIDrainer) reservePVCDeletion(ctx context.Context) bool {
+	if d.maxPVCDeletionsPerDrain <= 0 {
+		return true
+	}
+	counter, ok := ctx.Value(pvcDeletionCounterKey{}).(*pvcDeletionCounter)
+	if !ok {
+		return true
+	}
+	counter.Lock()
+	defer counter.Unlock()
+	if counter.count >= d.maxPVCDeletionsPerDrain {
+		return false
+	}
+	counter.count++
+	return true
+}
+
+// releasePVCDeletion cancels a reservation made with reservePVCDeletion, when the PVC was not deleted by the drainer
+func (d *APIDrainer) releasePVCDeletion(ctx context.Context) {
+	if d.maxPVCDeletionsPerDrain <= 0 {
+		return
+	}
+	if counter, ok := ctx.Value(pvcDeletionCounterKey{}).(*pvcDeletionCounter); ok {
+		counter.Lock()
+		defer counter.Unlock()
+		counter.count--
+	}
+}
+
+type evictionAttemptAggregatorKey struct{}
+
+// evictionAttemptAggregator collects the failed eviction attempts of the evictions, running in parallel, of a drain
+type evictionAttemptAggregator struct {
+	sync.Mutex
+	// awaitingBudget holds the namespace/name of the pods whose last eviction attempt was rejected with a 429
+	awaitingBudget map[string]struct{}
+	// failedAttempts counts the attempts rejected since the last summary
+	failedAttempts int
+}
+
+func withEvictionAttemptAggregator(ctx context.Context) context.Context {
+	return context.WithValue(ctx, evictionAttemptAggregatorKey{}, &evictionAttemptAggregator{awaitingBudget: map[string]struct{}{}})
+}
+
+// setEvictionAwaitingBudget records whether the eviction of the pod is waiting for PDB budget in the aggregator of the context.
+// It returns false if the context has no aggregator, the failed attempts must then be reported one by one.
+func setEvictionAwaitingBudget(ctx context.Context, pod *core.Pod, awaiting bool) bool {
+	aggregator, ok := ctx.Value(evictionAttemptAggregatorKey{}).(*evictionAttemptAggregator)
+	if !ok {
+		return false
+	}
+	aggregator.Lock()
+	defer aggregator.Unlock()
+	key := pod.Namespace + "/" + pod.Name
+	if awaiting {
+		aggregator.awaitingBudget[key] = struct{}{}
+		aggregator.failedAttempts++
+	} else {
+		delete(aggregator.awaitingBudget, key)
+	}
+	return true
+}
+
+// maxPodsInEvictionAttemptsSummary is the number of pods listed by name in the summary of the failed eviction attempts
+const maxPodsInEvictionAttemptsSummary = 10
+
+// reportEvictionAttemptsFailed emits a node event summarizing the evictions awaiting PDB budget, if there was any failed attempt since the last summary
+func (d *APIDrainer) reportEvictionAttemptsFailed(ctx context.Context, n *core.Node) {
+	aggregator, ok := ctx.Value(evictionAttemptAggregatorKey{}).(*evictionAttemptAggregator)
+	if !ok {
+		return
+	}
+	aggregator.Lock()
+	failedAttempts := aggregator.failedAttempts
+	aggregator.failedAttempts = 0
+	pods := make([]string, 0, len(aggregator.awaitingBudget))
+	for pod := range aggregator.awaitingBudget {
+		pods = append(pods, pod)
+	}
+	aggregator.Unlock()
+	if failedAttempts == 0 || len(pods) == 0 {
+		return
+	}
+
+	sort.Strings(pods)
+	listed := strings.Join(pods, ", ")
+	if len(pods) > maxPodsInEvictionAttemptsSummary {
+		listed = fmt.Sprintf("%s and %d more", strings.Join(pods[:maxPodsInEvictionAttemptsSummary], ", "), len(pods)-maxPodsInEvictionAttemptsSummary)
+	}
+	d.eventRecorder.NodeEventf(ctx, n, core.EventTypeWarning, eventReasonEvictionAttemptsFailed, "%d pods awaiting PDB budget (%d failed eviction attempts): %s", len(pods), failedAttempts, listed)
+}
+
+// maxPodsInDrainPlan is the number of pods listed by name in the drain plan event
+const maxPodsInDrainPlan = 20
+
+// reportDrainPlan emits a node event listing the pods to evict, in order
+func (d *APIDrainer) reportDrainPlan(ctx context.Context, n *core.Node, pods []*core.Pod) {
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, pod.GetNamespace()+"/"+pod.GetName())
+	}
+	listed := strings.Join(names, ", ")
+	if len(names) > maxPodsInDrainPlan {
+		listed = fmt.Sprintf("%s and %d more", strings.Join(names[:maxPodsInDrainPlan], ", "), len(names)-maxPodsInDrainPlan)
+	}
+	d.eventRecorder.NodeEventf(ctx, n, core.EventTypeNormal, eventReasonDrainPlan, "Drain plan: evicting %d pod(s) in order: %s", len(pods), listed)
 }
 
 func (d *APIDrainer) awaitPVCDeletion(ctx context.Context, pvc *core.PersistentVolumeClaim, timeout time.Duration) error {
 	return wait.PollImmediate(1*time.Second, timeout, func() (bool, error) {
-		d.l.Info("waiting for pvc complete deletion", zap.String("pvc", pvc.Name), zap.String("namespace", pvc.GetNamespace()), zap.String("pvc-uid", string(pvc.GetUID())))
+		TracedLogger(ctx, d.l).Info("waiting for pvc complete deletion", zap.String("pvc", pvc.Name), zap.String("namespace", pvc.GetNamespace()), zap.String("pvc-uid", string(pvc.GetUID())))
 		var got core.PersistentVolumeClaim
 		err := d.crClient.Get(ctx, types.NamespacedName{Namespace: pvc.Namespace, Name: pvc.Name}, &got)
 		if apierrors.IsNotFound(err) {
-			d.l.Info("pvc not found. It is deleted.", zap.String("pvc", pvc.Name), zap.String("namespace", pvc.GetNamespace()), zap.String("pvc-uid", string(pvc.GetUID())))
+			TracedLogger(ctx, d.l).Info("pvc not found. It is deleted.", zap.String("pvc", pvc.Name), zap.String("namespace", pvc.GetNamespace()), zap.String("pvc-uid", string(pvc.GetUID())))
 			return true, nil
 		}
 		if err != nil {
 			return false, fmt.Errorf("cannot get pvc %s/%s: %w", pvc.GetNamespace(), pvc.GetName(), err)
 		}
 		if string(got.GetUID()) != string(pvc.GetUID()) {
-			d.l.Info("pvc found but with different UID. It is deleted.", zap.String("pvc", pvc.Name), zap.String("namespace", pvc.GetNamespace()), zap.String("pvc-uid", string(pvc.GetUID())), zap.String("pvc-new-uid", string(got.GetUID())))
+			TracedLogger(ctx, d.l).Info("pvc found but with different UID. It is deleted.", zap.String("pvc", pvc.Name), zap.String("namespace", pvc.GetNamespace()), zap.String("pvc-uid", string(pvc.GetUID())), zap.String("pvc-new-uid", string(got.GetUID())))
 			return true, nil
 		}
-		d.l.Info("pvc still present", zap.String("pvc", pvc.Name), zap.String("namespace", pvc.GetNamespace()), zap.String("pvc-uid", string(pvc.GetUID())))
+		TracedLogger(ctx, d.l).Info("pvc still present", zap.String("pvc", pvc.Name), zap.String("namespace", pvc.GetNamespace()), zap.String("pvc-uid", string(pvc.GetUID())))
 		return false, nil
 	})
 }
 
 func (d *APIDrainer) performNodeReplacement(ctx context.Context, n *core.Node, reason string) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "performNodeReplacement")
+	span, ctx := tracing.StartSpanFromContext(ctx, "performNodeReplacement")
 	defer span.Finish()
 
 	fresh, err := d.c.CoreV1().Nodes().Get(ctx, n.GetName(), meta.GetOptions{})
@@ -1224,19 +3796,19 @@ func (d *APIDrainer) performNodeReplacement(ctx context.Context, n *core.Node, r
 		return fmt.Errorf("cannot request replacement node %s: %w", fresh.GetName(), err)
 	}
 	tags, _ := tag.New(context.Background(), tag.Upsert(TagNodeName, n.GetName()), tag.Upsert(TagReason, reason)) // nolint:gosec
-	StatRecordForNode(tags, n, MeasureNodesReplacementRequest.M(1))
+	d.statRecordForNode(tags, n, MeasureNodesReplacementRequest.M(1))
 	return nil
 }
 
 func (d *APIDrainer) ReplaceNode(ctx context.Context, n *core.Node) (bool, error) {
-	span, ctx := tracer.StartSpanFromContext(ctx, "ReplaceNode")
+	span, ctx := tracing.StartSpanFromContext(ctx, "ReplaceNode")
 	defer span.Finish()
 	err := d.performNodeReplacement(ctx, n, newNodeRequestReasonReplacement)
 	return err != nil, err
 }
 
 func (d *APIDrainer) PreprovisionNode(ctx context.Context, n *core.Node) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "PreprovisionNode")
+	span, ctx := tracing.StartSpanFromContext(ctx, "PreprovisionNode")
 	defer span.Finish()
 
 	return d.performNodeReplacement(ctx, n, newNodeRequestReasonPreprovisioning)
@@ -1250,18 +3822,27 @@ func (d *APIDrainer) GetReplacementStatus(ctx context.Context, n *core.Node) (No
 	return NodeReplacementStatus(freshNode.Labels[NodeLabelKeyReplaceRequest]), nil
 }
 
-var evictionPayloadEncoder runtime.Encoder
+var (
+	evictionPayloadEncoder     runtime.Encoder
+	evictionPayloadEncoderOnce sync.Once
+)
 
+// GetEvictionPayloadEncoder returns the JSON encoder used for the policy/v1 eviction payloads. It is built once and safe for concurrent use.
 func GetEvictionPayloadEncoder() runtime.Encoder {
-	if evictionPayloadEncoder != nil {
-		return evictionPayloadEncoder
-	}
-	scheme := runtime.NewScheme()
-	policy.SchemeBuilder.AddToScheme(scheme)
+	evictionPayloadEncoderOnce.Do(func() {
+		scheme := runtime.NewScheme()
+		policy.SchemeBuilder.AddToScheme(scheme)
+		evictionPayloadEncoder = NewEvictionPayloadEncoder(scheme, policy.SchemeGroupVersion)
+	})
+	return evictionPayloadEncoder
+}
+
+// NewEvictionPayloadEncoder builds a JSON encoder for the objects of the given scheme, encoded in the given version.
+// It is meant for the integrators that send their own eviction objects to a custom eviction endpoint: the types must be registered in the scheme.
+func NewEvictionPayloadEncoder(scheme *runtime.Scheme, version schema.GroupVersion) runtime.Encoder {
 	codecFactory := serializer.NewCodecFactory(scheme)
 	jsonSerializer := runtimejson.NewSerializerWithOptions(runtimejson.DefaultMetaFactory, scheme, scheme, runtimejson.SerializerOptions{})
-	evictionPayloadEncoder = codecFactory.WithoutConversion().EncoderForVersion(jsonSerializer, policy.SchemeGroupVersion)
-	return evictionPayloadEncoder
+	return codecFactory.WithoutConversion().EncoderForVersion(jsonSerializer, version)
 }
 
 func GetEvictionJsonPayload(obj *policy.Eviction) *bytes.Buffer {
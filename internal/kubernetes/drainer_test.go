@@ -17,29 +17,62 @@ and limitations under the License.
 package kubernetes
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	appsv1 "k8s.io/api/apps/v1"
 	core "k8s.io/api/core/v1"
 	policy "k8s.io/api/policy/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 
 	//"k8s.io/client-go/kubernetes/fake"
 	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/planetlabs/draino/internal/kubernetes/index"
 	"github.com/planetlabs/draino/internal/kubernetes/k8sclient"
+	"github.com/planetlabs/draino/internal/tracing"
 )
 
 const (
@@ -87,6 +120,52 @@ func newFakeClientSet(objects []runtime.Object, rs ...reactor) kubernetes.Interf
 	return cs
 }
 
+// recordedEvent is an event captured by the capturingRecorder
+type recordedEvent struct {
+	object      runtime.Object
+	annotations map[string]string
+	eventType   string
+	reason      string
+	message     string
+}
+
+// capturingRecorder is a record.EventRecorder that keeps the object associated with each event
+type capturingRecorder struct {
+	sync.Mutex
+	events []recordedEvent
+}
+
+var _ record.EventRecorder = &capturingRecorder{}
+
+func (r *capturingRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.Lock()
+	defer r.Unlock()
+	r.events = append(r.events, recordedEvent{object: object, eventType: eventtype, reason: reason, message: message})
+}
+
+func (r *capturingRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *capturingRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Lock()
+	defer r.Unlock()
+	r.events = append(r.events, recordedEvent{object: object, annotations: annotations, eventType: eventtype, reason: reason, message: fmt.Sprintf(messageFmt, args...)})
+}
+
+// reasonsFor returns the reasons of all the events that were emitted on objects matching the predicate
+func (r *capturingRecorder) reasonsFor(match func(obj runtime.Object) bool) []string {
+	r.Lock()
+	defer r.Unlock()
+	var reasons []string
+	for _, e := range r.events {
+		if match(e.object) {
+			reasons = append(reasons, e.reason)
+		}
+	}
+	return reasons
+}
+
 func TestDrain(t *testing.T) {
 	taintDraining := []core.Taint{{
 		Key:    k8sclient.DrainoTaintKey,
@@ -461,6 +540,139 @@ func TestGetDrainConditionStatus(t *testing.T) {
 	}
 }
 
+func TestDrainConditionMessage_RoundTrip(t *testing.T) {
+	when := time.Date(2020, 3, 20, 15, 50, 34, 0, time.FixedZone("CET", 3600))
+	finish := when.Add(5*time.Minute + 16*time.Second)
+	tests := []struct {
+		name      string
+		failCount int32
+		when      time.Time
+		finish    time.Time
+		failed    bool
+		reason    string
+		expected  string
+	}{
+		{
+			name:      "scheduled",
+			failCount: 0,
+			when:      when,
+			expected:  "[0] | Drain activity scheduled 2020-03-20T15:50:34+01:00",
+		},
+		{
+			name:      "completed",
+			failCount: 1,
+			when:      when,
+			finish:    finish,
+			expected:  "[1] | Drain activity scheduled 2020-03-20T15:50:34+01:00 | Completed: 2020-03-20T15:55:50+01:00",
+		},
+		{
+			name:      "failed",
+			failCount: 2,
+			when:      when,
+			finish:    finish,
+			failed:    true,
+			expected:  "[2] | Drain activity scheduled 2020-03-20T15:50:34+01:00 | Failed: 2020-03-20T15:55:50+01:00",
+		},
+		{
+			name:      "utc dates",
+			failCount: 12,
+			when:      when.UTC(),
+			finish:    finish.UTC(),
+			failed:    true,
+			expected:  "[12] | Drain activity scheduled 2020-03-20T14:50:34Z | Failed: 2020-03-20T14:55:50Z",
+		},
+		{
+			name:      "failed with reason",
+			failCount: 3,
+			when:      when,
+			finish:    finish,
+			failed:    true,
+			reason:    string(OverlappingPodDisruptionBudgets),
+			expected:  "[3] | Drain activity scheduled 2020-03-20T15:50:34+01:00 | Failed: 2020-03-20T15:55:50+01:00 | FailureReason: overlapping_pod_disruption_budgets",
+		},
+		{
+			name:      "reason of a completed drain",
+			failCount: 1,
+			when:      when,
+			finish:    finish,
+			reason:    string(VolumeCleanup),
+			expected:  "[1] | Drain activity scheduled 2020-03-20T15:50:34+01:00 | Completed: 2020-03-20T15:55:50+01:00",
+		},
+		{
+			name:      "reason with separator",
+			failCount: 1,
+			when:      when,
+			finish:    finish,
+			failed:    true,
+			reason:    "a | b",
+			expected:  "[1] | Drain activity scheduled 2020-03-20T15:50:34+01:00 | Failed: 2020-03-20T15:55:50+01:00 | FailureReason: a   b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := FormatDrainConditionMessageWithReason(tt.failCount, tt.when, tt.finish, tt.failed, tt.reason)
+			assert.Equal(t, tt.expected, msg)
+			if tt.reason == "" {
+				assert.Equal(t, msg, FormatDrainConditionMessage(tt.failCount, tt.when, tt.finish, tt.failed))
+			}
+
+			failCount, when, finish, failed, err := ParseDrainConditionMessage(msg)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.failCount, failCount)
+			assert.True(t, tt.when.Equal(when), "when: %v", when)
+			assert.True(t, tt.finish.Equal(finish), "finish: %v", finish)
+			assert.Equal(t, tt.failed, failed)
+
+			for _, status := range []core.ConditionStatus{core.ConditionTrue, core.ConditionFalse} {
+				if status == core.ConditionFalse && tt.finish.IsZero() {
+					continue
+				}
+				node := &core.Node{Status: core.NodeStatus{Conditions: []core.NodeCondition{{Type: ConditionDrainedScheduled, Status: status, Message: msg}}}}
+				drainStatus, err := GetDrainConditionStatus(node)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.failCount, drainStatus.FailedCount)
+				assert.Equal(t, status == core.ConditionFalse && !tt.failed, drainStatus.Completed)
+				assert.Equal(t, status == core.ConditionFalse && tt.failed, drainStatus.Failed)
+				if drainStatus.Failed {
+					assert.Equal(t, ParseDrainConditionFailureReason(tt.expected), drainStatus.FailureReason)
+				} else {
+					assert.Empty(t, drainStatus.FailureReason)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDrainConditionMessage(t *testing.T) {
+	tests := []struct {
+		name              string
+		msg               string
+		expectedFailCount int32
+		expectedFinished  bool
+		expectedFailed    bool
+		expectErr         bool
+	}{
+		{name: "without fail count", msg: "Drain activity scheduled 2021-11-16T03:50:44Z"},
+		{name: "without date", msg: "[3] | Drain activity scheduled", expectedFailCount: 3},
+		{name: "invalid fail count", msg: "[x] | Drain activity scheduled 2021-11-16T03:50:44Z", expectErr: true},
+		{name: "invalid finish date", msg: "[1] | Drain activity scheduled 2021-11-16T03:50:44Z | Completed: yesterday", expectErr: true},
+		{name: "completed", msg: "Drain activity scheduled 2021-11-16T03:50:44Z | Completed: 2021-11-16T03:55:44Z", expectedFinished: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failCount, _, finish, failed, err := ParseDrainConditionMessage(tt.msg)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedFailCount, failCount)
+			assert.Equal(t, tt.expectedFinished, !finish.IsZero())
+			assert.Equal(t, tt.expectedFailed, failed)
+		})
+	}
+}
+
 func TestMarkDrain(t *testing.T) {
 	ctx := context.Background()
 	now := meta.Time{Time: time.Now()}
@@ -601,6 +813,51 @@ func TestSerializePolicy(t *testing.T) {
 	assert.Equal(t, "{\"kind\":\"Eviction\",\"apiVersion\":\"policy/v1\",\"metadata\":{\"name\":\"test-pod\",\"namespace\":\"test-namespace\",\"creationTimestamp\":null}}\n", string(GetEvictionJsonPayload(evictionPayload).Bytes()))
 }
 
+func TestGetEvictionPayloadEncoder_Concurrent(t *testing.T) {
+	const workers = 50
+	encoders := make([]runtime.Encoder, workers)
+	payloads := make([]string, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			encoders[i] = GetEvictionPayloadEncoder()
+			payloads[i] = GetEvictionJsonPayload(&policy.Eviction{ObjectMeta: meta.ObjectMeta{Namespace: "ns", Name: "pod-" + strconv.Itoa(i)}}).String()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < workers; i++ {
+		assert.True(t, encoders[0] == encoders[i], "the encoder must be built only once")
+		assert.Contains(t, payloads[i], "\"name\":\"pod-"+strconv.Itoa(i)+"\"")
+	}
+}
+
+// extendedEviction is an eviction object carrying extra information for a custom eviction endpoint
+type extendedEviction struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+	Reason          string `json:"reason"`
+}
+
+func (e *extendedEviction) DeepCopyObject() runtime.Object {
+	out := *e
+	e.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return &out
+}
+
+func TestNewEvictionPayloadEncoder_CustomScheme(t *testing.T) {
+	gv := schema.GroupVersion{Group: "eviction.example.com", Version: "v1alpha1"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gv.WithKind("ExtendedEviction"), &extendedEviction{})
+
+	buffer := bytes.NewBuffer(nil)
+	obj := &extendedEviction{ObjectMeta: meta.ObjectMeta{Namespace: "test-namespace", Name: "test-pod"}, Reason: "maintenance"}
+	assert.NoError(t, NewEvictionPayloadEncoder(scheme, gv).Encode(obj, buffer))
+	assert.Equal(t, "{\"kind\":\"ExtendedEviction\",\"apiVersion\":\"eviction.example.com/v1alpha1\",\"metadata\":{\"name\":\"test-pod\",\"namespace\":\"test-namespace\",\"creationTimestamp\":null},\"reason\":\"maintenance\"}\n", buffer.String())
+}
+
 func TestAPIDrainer_MarkDrainDelete(t *testing.T) {
 	ctx := context.Background()
 	someTimeAgo := meta.NewTime(time.Date(1978, time.April, 12, 22, 00, 00, 00, time.UTC))
@@ -710,3 +967,4682 @@ func TestAPIDrainer_MarkDrainDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestAPIDrainer_ResetRetryAnnotationForNodes(t *testing.T) {
+	failedNode := func(name, team string) *core.Node {
+		return &core.Node{ObjectMeta: meta.ObjectMeta{
+			Name:        name,
+			Labels:      map[string]string{"team": team},
+			Annotations: map[string]string{drainRetryFailedAnnotationKey: drainRetryFailedAnnotationValue},
+		}}
+	}
+	c := fake.NewSimpleClientset(failedNode("node-a1", "a"), failedNode("node-a2", "a"), failedNode("node-b1", "b"))
+	d := NewAPIDrainer(c, &NoopEventRecorder{})
+
+	results, err := d.ResetRetryAnnotationForNodes(context.Background(), labels.SelectorFromSet(labels.Set{"team": "a"}))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]error{"node-a1": nil, "node-a2": nil}, results)
+
+	for name, expectedFailed := range map[string]bool{"node-a1": false, "node-a2": false, "node-b1": true} {
+		n, err := c.CoreV1().Nodes().Get(context.Background(), name, meta.GetOptions{})
+		assert.NoError(t, err)
+		_, failed := n.Annotations[drainRetryFailedAnnotationKey]
+		assert.Equal(t, expectedFailed, failed, name)
+	}
+
+	results, err = d.ResetRetryAnnotationForNodes(context.Background(), labels.SelectorFromSet(labels.Set{"team": "c"}))
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+// listPodsByNodeReactor honours the spec.nodeName field selector of the pod listings, that is ignored by the fake clientset
+func listPodsByNodeReactor(c *fake.Clientset) clienttesting.ReactionFunc {
+	return func(action clienttesting.Action) (bool, runtime.Object, error) {
+		selector := action.(clienttesting.ListAction).GetListRestrictions().Fields
+		obj, err := c.Tracker().List(core.SchemeGroupVersion.WithResource("pods"), core.SchemeGroupVersion.WithKind("Pod"), action.GetNamespace())
+		if err != nil {
+			return true, nil, err
+		}
+		filtered := &core.PodList{}
+		for _, p := range obj.(*core.PodList).Items {
+			if selector == nil || selector.Matches(fields.Set{"spec.nodeName": p.Spec.NodeName}) {
+				filtered.Items = append(filtered.Items, p)
+			}
+		}
+		return true, filtered, nil
+	}
+}
+
+func TestDrain_WorkloadUnavailabilityCoordinator(t *testing.T) {
+	taintDraining := []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}
+	nodes := []*core.Node{
+		{ObjectMeta: meta.ObjectMeta{Name: "node-1"}, Spec: core.NodeSpec{Taints: taintDraining}},
+		{ObjectMeta: meta.ObjectMeta{Name: "node-2"}, Spec: core.NodeSpec{Taints: taintDraining}},
+	}
+
+	tests := []struct {
+		name                string
+		maxUnavailablePct   int
+		expectedMaxInFlight int
+	}{
+		{name: "half of the workload", maxUnavailablePct: 50, expectedMaxInFlight: 2},
+		{name: "a quarter of the workload", maxUnavailablePct: 25, expectedMaxInFlight: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods := workloadPods("web-5d8f7c", 4, "node-1", "node-2")
+			objects := []runtime.Object{nodes[0], nodes[1]}
+			for i := range pods {
+				objects = append(objects, pods[i])
+			}
+			c := fake.NewSimpleClientset(objects...)
+			store, closeFunc := RunStoreForTest(context.Background(), c)
+			defer closeFunc()
+			coordinator := NewWorkloadUnavailabilityCoordinator(store, tt.maxUnavailablePct)
+
+			c.PrependReactor("list", "pods", listPodsByNodeReactor(c))
+			var lock sync.Mutex
+			evicted, maxInFlight := 0, 0
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				coordinator.Lock()
+				inFlight := coordinator.inFlight["ns/ReplicaSet/web-5d8f7c"]
+				coordinator.Unlock()
+				lock.Lock()
+				evicted++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				lock.Unlock()
+				// keep the reservation while the other drains are trying to evict the pods of the workload
+				time.Sleep(20 * time.Millisecond)
+				eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+				return true, nil, c.Tracker().Delete(core.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+			})
+			d := NewAPIDrainer(c, &NoopEventRecorder{},
+				WithRuntimeObjectStore(store),
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()), // the pods are not found, so the deletions are confirmed
+				WithWorkloadUnavailabilityCoordinator(coordinator),
+			)
+
+			var wg sync.WaitGroup
+			errs := make([]error, len(nodes))
+			for i := range nodes {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs[i] = d.Drain(context.Background(), nodes[i])
+				}(i)
+			}
+			wg.Wait()
+
+			assert.Equal(t, []error{nil, nil}, errs)
+			assert.Equal(t, 4, evicted)
+			assert.LessOrEqual(t, maxInFlight, tt.expectedMaxInFlight)
+			assert.Empty(t, coordinator.inFlight)
+		})
+	}
+}
+
+func TestAPIDrainer_PreviewConditionImpact(t *testing.T) {
+	newNode := func(name string, conditions ...core.NodeCondition) *core.Node {
+		return &core.Node{ObjectMeta: meta.ObjectMeta{Name: name}, Status: core.NodeStatus{Conditions: conditions}}
+	}
+	c := fake.NewSimpleClientset(
+		newNode("node-b", core.NodeCondition{Type: "KernelDeadlock", Status: core.ConditionTrue, LastTransitionTime: meta.NewTime(time.Now().Add(-time.Hour))}),
+		newNode("node-a", core.NodeCondition{Type: "DiskPressure", Status: core.ConditionTrue, LastTransitionTime: meta.NewTime(time.Now().Add(-time.Hour))}),
+		newNode("healthy", core.NodeCondition{Type: "KernelDeadlock", Status: core.ConditionFalse}),
+		newNode("recent", core.NodeCondition{Type: "DiskPressure", Status: core.ConditionTrue, LastTransitionTime: meta.NewTime(time.Now())}),
+	)
+	store, closeFunc := RunStoreForTest(context.Background(), c)
+	defer closeFunc()
+	current, err := ParseConditions([]string{"OutOfDisk"})
+	assert.NoError(t, err)
+	d := NewAPIDrainer(c, &NoopEventRecorder{}, WithRuntimeObjectStore(store), WithGlobalConfig(GlobalConfig{SuppliedConditions: current}))
+
+	tests := []struct {
+		name       string
+		conditions []string
+		expected   []string
+	}{
+		{
+			name:       "nodes matching any condition",
+			conditions: []string{"KernelDeadlock", `DiskPressure={"delay":"10m"}`},
+			expected:   []string{"node-a", "node-b"},
+		},
+		{
+			name:       "status not matching",
+			conditions: []string{`KernelDeadlock={"conditionStatus":"Unknown"}`},
+		},
+		{
+			name:       "no condition",
+			conditions: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conditions, err := ParseConditions(tt.conditions)
+			assert.NoError(t, err)
+			matching, err := d.PreviewConditionImpact(context.Background(), conditions)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, matching)
+			assert.Equal(t, current, d.getSuppliedConditions(), "the preview must not replace the supplied conditions")
+		})
+	}
+
+	_, err = NewAPIDrainer(c, &NoopEventRecorder{}).PreviewConditionImpact(context.Background(), current)
+	assert.Error(t, err)
+}
+
+func TestAPIDrainer_DrainPods(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}}}
+	newPod := func(name, node string) *core.Pod {
+		return &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec:       core.PodSpec{NodeName: node, TerminationGracePeriodSeconds: &podGracePeriodSeconds},
+		}
+	}
+	pods := []*core.Pod{newPod("pod-0", nodeName), newPod("pod-1", nodeName), newPod("pod-2", nodeName)}
+
+	tests := []struct {
+		name            string
+		pods            []*core.Pod
+		expectedEvicted []string
+		expectErr       bool
+	}{
+		{
+			name:            "only the given pods are evicted",
+			pods:            []*core.Pod{pods[0], pods[2]},
+			expectedEvicted: []string{"pod-0", "pod-2"},
+		},
+		{
+			name: "no pod given",
+		},
+		{
+			name:      "pod of another node",
+			pods:      []*core.Pod{pods[0], newPod("pod-3", "other-node")},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(node, pods[0], pods[1], pods[2])
+			var lock sync.Mutex
+			var evicted []string
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+				lock.Lock()
+				evicted = append(evicted, eviction.Name)
+				lock.Unlock()
+				return true, nil, c.Tracker().Delete(core.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+			})
+			d := NewAPIDrainer(c, &NoopEventRecorder{},
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()), // the pods are not found, so the deletions are confirmed
+				// the pods that were not given are left on the node without failing the drain
+				WithDrainCompletionVerification(true),
+			)
+
+			err := d.DrainPods(context.Background(), node, tt.pods)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.ElementsMatch(t, tt.expectedEvicted, evicted)
+
+			remaining, err := c.CoreV1().Pods("ns").List(context.Background(), meta.ListOptions{})
+			assert.NoError(t, err)
+			assert.Len(t, remaining.Items, len(pods)-len(tt.expectedEvicted))
+			for _, pod := range remaining.Items {
+				assert.NotContains(t, tt.expectedEvicted, pod.Name)
+			}
+		})
+	}
+}
+
+func TestAPIDrainer_ShuffleEvictionOrder(t *testing.T) {
+	pods := make([]*core.Pod, 10)
+	for i := range pods {
+		pods[i] = &core.Pod{ObjectMeta: meta.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), Namespace: "ns"}}
+	}
+	names := func(pods []*core.Pod) []string {
+		var names []string
+		for _, p := range pods {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+	original := names(pods)
+
+	t.Run("listing order by default", func(t *testing.T) {
+		d := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{})
+		assert.Equal(t, original, names(d.shuffleEvictionOrder(pods)))
+	})
+
+	t.Run("deterministic shuffle with a fixed seed", func(t *testing.T) {
+		d1 := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{}, WithRandomizedEvictionOrder(42))
+		d2 := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{}, WithRandomizedEvictionOrder(42))
+
+		first := names(d1.shuffleEvictionOrder(pods))
+		assert.Equal(t, first, names(d2.shuffleEvictionOrder(pods)))
+		assert.NotEqual(t, original, first)
+		assert.ElementsMatch(t, original, first)
+		// the next drains get other orders, still reproducible
+		second := names(d1.shuffleEvictionOrder(pods))
+		assert.NotEqual(t, first, second)
+		assert.Equal(t, second, names(d2.shuffleEvictionOrder(pods)))
+		// the pods of the caller are not reordered
+		assert.Equal(t, original, names(pods))
+	})
+}
+
+func TestDrain_DrainPlanEvent(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}}}
+	pods := make([]*core.Pod, 5)
+	objects := []runtime.Object{node}
+	for i := range pods {
+		pods[i] = &core.Pod{ObjectMeta: meta.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+		objects = append(objects, pods[i])
+	}
+	// the plan follows the randomized order, reproduced with the same seed
+	var expectedOrder []string
+	for _, p := range NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{}, WithRandomizedEvictionOrder(7)).shuffleEvictionOrder(pods) {
+		expectedOrder = append(expectedOrder, p.Namespace+"/"+p.Name)
+	}
+
+	c := fake.NewSimpleClientset(objects...)
+	c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+		return true, nil, c.Tracker().Delete(core.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+	})
+	recorder := &capturingRecorder{}
+	d := NewAPIDrainer(c, NewEventRecorder(recorder), WithContainerRuntimeClient(crfake.NewClientBuilder().Build()), WithRandomizedEvictionOrder(7), WithDrainPlanEvent(true))
+	assert.NoError(t, d.DrainPods(context.Background(), node, pods))
+
+	recorder.Lock()
+	defer recorder.Unlock()
+	var plans []string
+	for _, e := range recorder.events {
+		if ref, ok := e.object.(*core.ObjectReference); ok && ref.Kind == "Node" && e.reason == eventReasonDrainPlan {
+			plans = append(plans, e.message)
+		}
+	}
+	assert.Equal(t, []string{"Drain plan: evicting 5 pod(s) in order: " + strings.Join(expectedOrder, ", ")}, plans)
+}
+
+func TestDrain_EvictionAttemptEventsAggregation(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}}}
+	isNode := func(obj runtime.Object) bool {
+		ref, ok := obj.(*core.ObjectReference)
+		return ok && ref.Kind == "Node"
+	}
+	count := func(reasons []string, reason string) int {
+		n := 0
+		for _, r := range reasons {
+			if r == reason {
+				n++
+			}
+		}
+		return n
+	}
+
+	tests := []struct {
+		name   string
+		period time.Duration
+	}{
+		{name: "one node event per failed attempt"},
+		{name: "aggregated node events", period: 700 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []runtime.Object{node}
+			for i := 0; i < 3; i++ {
+				objects = append(objects, &core.Pod{
+					ObjectMeta: meta.ObjectMeta{Name: fmt.Sprintf("%s-%d", podName, i), Namespace: "ns"},
+					Spec:       core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds},
+				})
+			}
+			c := fake.NewSimpleClientset(objects...)
+			attempts := map[string]int{}
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+				// each eviction is rejected twice before being accepted
+				if attempts[eviction.Name]++; attempts[eviction.Name] <= 2 {
+					return true, nil, apierrors.NewTooManyRequests("pdb does not allow any disruption", 1)
+				}
+				return true, nil, c.Tracker().Delete(core.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+			})
+			recorder := &capturingRecorder{}
+			d := NewAPIDrainer(c, NewEventRecorder(recorder),
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()), // the pods are not found, so the deletions are confirmed
+				WithEvictionAttemptEventsAggregation(tt.period),
+			)
+
+			assert.NoError(t, d.Drain(context.Background(), node))
+			nodeReasons := recorder.reasonsFor(isNode)
+			podReasons := recorder.reasonsFor(func(obj runtime.Object) bool { _, ok := obj.(*core.Pod); return ok })
+			assert.Equal(t, 6, count(podReasons, eventReasonEvictionAttemptFailed), "the pod events are not aggregated")
+			if tt.period == 0 {
+				assert.Equal(t, 6, count(nodeReasons, eventReasonEvictionAttemptFailed))
+				assert.Zero(t, count(nodeReasons, eventReasonEvictionAttemptsFailed))
+				return
+			}
+			assert.Zero(t, count(nodeReasons, eventReasonEvictionAttemptFailed))
+			summaries := count(nodeReasons, eventReasonEvictionAttemptsFailed)
+			assert.GreaterOrEqual(t, summaries, 1)
+			assert.Less(t, summaries, 6)
+
+			recorder.Lock()
+			defer recorder.Unlock()
+			for _, e := range recorder.events {
+				if e.reason == eventReasonEvictionAttemptsFailed {
+					assert.Equal(t, fmt.Sprintf("3 pods awaiting PDB budget (3 failed eviction attempts): ns/%[1]s-0, ns/%[1]s-1, ns/%[1]s-2", podName), e.message)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestDrain_StructuredConditionsData(t *testing.T) {
+	conditions, err := ParseConditions([]string{`KernelDeadlock={"conditionStatus":"True"}`, `ReadonlyFilesystem={"conditionStatus":"True"}`, `Unrelated={"conditionStatus":"True"}`})
+	assert.NoError(t, err)
+	node := &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: nodeName},
+		Spec: core.NodeSpec{Taints: []core.Taint{{
+			Key:    k8sclient.DrainoTaintKey,
+			Value:  k8sclient.TaintDraining,
+			Effect: core.TaintEffectNoSchedule,
+		}}},
+		Status: core.NodeStatus{Conditions: []core.NodeCondition{
+			{Type: "KernelDeadlock", Status: core.ConditionTrue},
+			{Type: "ReadonlyFilesystem", Status: core.ConditionTrue},
+			{Type: "Unrelated", Status: core.ConditionFalse},
+		}},
+	}
+	pod := &core.Pod{
+		ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"},
+		Spec:       core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds},
+	}
+	isEvictionStarting := func(e recordedEvent) bool {
+		_, ok := e.object.(*core.Pod)
+		return ok && e.reason == eventReasonEvictionStarting
+	}
+
+	tests := []struct {
+		name                string
+		structuredData      bool
+		expectedAnnotations map[string]string
+	}{
+		{
+			name:                "structured data activated",
+			structuredData:      true,
+			expectedAnnotations: map[string]string{EvictionNodeConditionsJSONAnnotationKey: `[{"type":"KernelDeadlock","status":"True"},{"type":"ReadonlyFilesystem","status":"True"}]`},
+		},
+		{
+			name:           "structured data not activated",
+			structuredData: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(node, pod)
+			recorder := &capturingRecorder{}
+			d := NewAPIDrainer(c, NewEventRecorder(recorder),
+				WithGlobalConfig(GlobalConfig{SuppliedConditions: conditions}),
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()), // the pod is not found, so the deletion is confirmed
+				WithStructuredConditionsData(tt.structuredData),
+			)
+			assert.NoError(t, d.Drain(context.Background(), node))
+
+			var events []recordedEvent
+			for _, e := range recorder.events {
+				if isEvictionStarting(e) {
+					events = append(events, e)
+				}
+			}
+			if assert.Len(t, events, 1) {
+				assert.Equal(t, tt.expectedAnnotations, events[0].annotations)
+				if tt.structuredData {
+					var offending []OffendingCondition
+					assert.NoError(t, json.Unmarshal([]byte(events[0].annotations[EvictionNodeConditionsJSONAnnotationKey]), &offending))
+					assert.Equal(t, []OffendingCondition{{Type: "KernelDeadlock", Status: core.ConditionTrue}, {Type: "ReadonlyFilesystem", Status: core.ConditionTrue}}, offending)
+				}
+			}
+		})
+	}
+}
+
+func TestAPIDrainer_GetGracePeriodWithEvictionHeadRoom(t *testing.T) {
+	tests := []struct {
+		name                  string
+		deploymentAnnotations map[string]string
+		podAnnotations        map[string]string
+		expected              time.Duration
+		// expectedEvictionTimeout is the timeout of the whole eviction, it defaults to the min eviction timeout with headroom
+		expectedEvictionTimeout time.Duration
+	}{
+		{
+			name:     "grace period of the pod",
+			expected: 10*time.Second + DefaultEvictionOverhead,
+		},
+		{
+			name:                  "min grace period of the controller",
+			deploymentAnnotations: map[string]string{MinGracePeriodAnnotationKey: "5m"},
+			expected:              5*time.Minute + DefaultEvictionOverhead,
+		},
+		{
+			name:                  "min grace period of the pod takes precedence",
+			deploymentAnnotations: map[string]string{MinGracePeriodAnnotationKey: "5m"},
+			podAnnotations:        map[string]string{MinGracePeriodAnnotationKey: "2m"},
+			expected:              2*time.Minute + DefaultEvictionOverhead,
+		},
+		{
+			name:                  "min grace period below the grace period of the pod",
+			deploymentAnnotations: map[string]string{MinGracePeriodAnnotationKey: "1s"},
+			expected:              10*time.Second + DefaultEvictionOverhead,
+		},
+		{
+			name:                    "min grace period above the min eviction timeout",
+			deploymentAnnotations:   map[string]string{MinGracePeriodAnnotationKey: "10m"},
+			expected:                10*time.Minute + DefaultEvictionOverhead,
+			expectedEvictionTimeout: 10*time.Minute + DefaultEvictionOverhead,
+		},
+		{
+			name:                  "invalid min grace period",
+			deploymentAnnotations: map[string]string{MinGracePeriodAnnotationKey: "forever"},
+			expected:              10*time.Second + DefaultEvictionOverhead,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{ObjectMeta: meta.ObjectMeta{Name: deploymentName, Namespace: "ns", Annotations: tt.deploymentAnnotations}}
+			pod := &core.Pod{
+				ObjectMeta: meta.ObjectMeta{
+					Name:            podName,
+					Namespace:       "ns",
+					Annotations:     tt.podAnnotations,
+					OwnerReferences: []meta.OwnerReference{{Controller: &isController, Kind: kindReplicaSet, Name: deploymentName + "-5d8f7c"}},
+				},
+				Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds},
+			}
+			store, closeFunc := RunStoreForTest(context.Background(), fake.NewSimpleClientset(deployment, pod))
+			defer closeFunc()
+			d := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{}, WithRuntimeObjectStore(store))
+
+			assert.Equal(t, tt.expected, d.getGracePeriodWithEvictionHeadRoom(context.Background(), pod))
+			if tt.expectedEvictionTimeout == 0 {
+				tt.expectedEvictionTimeout = DefaultMinEvictionTimeout + DefaultEvictionOverhead
+			}
+			assert.Equal(t, tt.expectedEvictionTimeout, d.getMinEvictionTimeoutWithEvictionHeadRoom(context.Background(), pod))
+		})
+	}
+}
+
+func TestAPIDrainer_GlobalConfigTimings(t *testing.T) {
+	globalConfig := GlobalConfig{MinEvictionTimeout: 2 * time.Minute, EvictionHeadroom: time.Minute, PVCRecreateTimeout: 3 * time.Minute}
+	tests := []struct {
+		name                       string
+		options                    []APIDrainerOption
+		expectedMinEvictionTimeout time.Duration
+		expectedEvictionHeadroom   time.Duration
+		expectedPVCRecreateTimeout time.Duration
+	}{
+		{
+			name:                       "drainer defaults",
+			options:                    []APIDrainerOption{WithGlobalConfig(GlobalConfig{})},
+			expectedMinEvictionTimeout: DefaultMinEvictionTimeout,
+			expectedEvictionHeadroom:   DefaultEvictionOverhead,
+			expectedPVCRecreateTimeout: DefaultPVCRecreateTimeout,
+		},
+		{
+			name:                       "global config timings",
+			options:                    []APIDrainerOption{WithGlobalConfig(globalConfig)},
+			expectedMinEvictionTimeout: 2 * time.Minute,
+			expectedEvictionHeadroom:   time.Minute,
+			expectedPVCRecreateTimeout: 3 * time.Minute,
+		},
+		{
+			name:                       "options set before the global config take precedence",
+			options:                    []APIDrainerOption{MaxGracePeriod(DefaultMinEvictionTimeout), WithPVCRecreateTimeout(time.Second), WithGlobalConfig(globalConfig)},
+			expectedMinEvictionTimeout: DefaultMinEvictionTimeout,
+			expectedEvictionHeadroom:   time.Minute,
+			expectedPVCRecreateTimeout: time.Second,
+		},
+		{
+			name:                       "options set after the global config take precedence",
+			options:                    []APIDrainerOption{WithGlobalConfig(globalConfig), EvictionHeadroom(5 * time.Second)},
+			expectedMinEvictionTimeout: 2 * time.Minute,
+			expectedEvictionHeadroom:   5 * time.Second,
+			expectedPVCRecreateTimeout: 3 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{}, tt.options...)
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+
+			ctx := context.Background()
+			assert.Equal(t, tt.expectedMinEvictionTimeout+tt.expectedEvictionHeadroom, d.getMinEvictionTimeoutWithEvictionHeadRoom(ctx, pod))
+			assert.Equal(t, tt.expectedEvictionHeadroom, d.getEvictionHeadroom(ctx))
+			assert.Equal(t, tt.expectedPVCRecreateTimeout, d.getPVCRecreateTimeout(ctx, pod))
+		})
+	}
+}
+
+func TestParseFailureCauseEventReasons(t *testing.T) {
+	tests := []struct {
+		name      string
+		entries   []string
+		expected  map[FailureCause]string
+		expectErr bool
+	}{
+		{
+			name:     "mapped causes",
+			entries:  []string{"pod_disruption_budget_blocked=EvictionBlockedByPDB", " eviction_endpoint_400 = EvictionRejected "},
+			expected: map[FailureCause]string{PodDisruptionBudgetBlocked: "EvictionBlockedByPDB", "eviction_endpoint_400": "EvictionRejected"},
+		},
+		{
+			name:     "PreStop hook deletion timeout",
+			entries:  []string{"pod_deletion_timeout_prestop_hook=PreStopHookTimeout"},
+			expected: map[FailureCause]string{PodDeletionTimeoutPreStopHook: "PreStopHookTimeout"},
+		},
+		{
+			name:     "nothing mapped",
+			expected: map[FailureCause]string{},
+		},
+		{
+			name:      "missing reason",
+			entries:   []string{"pod_disruption_budget_blocked="},
+			expectErr: true,
+		},
+		{
+			name:      "reason with spaces",
+			entries:   []string{"pod_disruption_budget_blocked=Blocked by PDB"},
+			expectErr: true,
+		},
+		{
+			name:      "duplicated cause",
+			entries:   []string{"volume_cleanup=A", "volume_cleanup=B"},
+			expectErr: true,
+		},
+		{
+			name:      "unknown cause",
+			entries:   []string{"pod_disruption_budget_blockd=EvictionBlockedByPDB"},
+			expectErr: true,
+		},
+		{
+			name:      "unknown eviction endpoint cause",
+			entries:   []string{"eviction_endpoint_teapot=EvictionRejected"},
+			expectErr: true,
+		},
+		{
+			name:     "eviction endpoint causes",
+			entries:  []string{"eviction_endpoint_request_timeout=A", "eviction_endpoint_request_timeout_504=B", "pod_eviction_timeout_kubeapi=C"},
+			expected: map[FailureCause]string{"eviction_endpoint_request_timeout": "A", "eviction_endpoint_request_timeout_504": "B", PodEvictionTimeoutKubeAPI: "C"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasons, err := ParseFailureCauseEventReasons(tt.entries)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, reasons)
+		})
+	}
+}
+
+func TestDrain_FailureCauseEventReasons(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+	pod := &core.Pod{
+		ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", Annotations: map[string]string{EvictionAPIURLAnnotationKey: server.URL}},
+		Spec:       core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds},
+	}
+
+	tests := []struct {
+		name           string
+		reasons        map[FailureCause]string
+		expectedReason string
+	}{
+		{
+			name:           "default reason",
+			expectedReason: eventReasonEvictionFailed,
+		},
+		{
+			name:           "mapped cause",
+			reasons:        map[FailureCause]string{"eviction_endpoint_400": "EvictionRejected"},
+			expectedReason: "EvictionRejected",
+		},
+		{
+			name:           "other cause mapped",
+			reasons:        map[FailureCause]string{PodDisruptionBudgetBlocked: "EvictionBlockedByPDB"},
+			expectedReason: eventReasonEvictionFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &capturingRecorder{}
+			d := NewAPIDrainer(fake.NewSimpleClientset(node, pod), NewEventRecorder(recorder), WithFailureCauseEventReasons(tt.reasons))
+
+			err := d.Drain(context.Background(), node)
+			assert.Equal(t, FailureCause("eviction_endpoint_400"), GetFailureCause(err))
+			isNode := func(obj runtime.Object) bool { ref, ok := obj.(*core.ObjectReference); return ok && ref.Kind == "Node" }
+			isPod := func(obj runtime.Object) bool { _, ok := obj.(*core.Pod); return ok }
+			assert.Equal(t, []string{eventReasonEvictionStarting, tt.expectedReason}, recorder.reasonsFor(isNode))
+			assert.Equal(t, []string{eventReasonEvictionStarting, tt.expectedReason}, recorder.reasonsFor(isPod))
+		})
+	}
+}
+
+func TestDrain_ControllerEvents(t *testing.T) {
+	taintDraining := []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: taintDraining}}
+	deployment := &appsv1.Deployment{ObjectMeta: meta.ObjectMeta{Name: deploymentName, Namespace: "ns"}}
+	pod := &core.Pod{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      podName,
+			Namespace: "ns",
+			OwnerReferences: []meta.OwnerReference{{
+				Controller: &isController,
+				Kind:       kindReplicaSet,
+				Name:       deploymentName + "-5d8f7c",
+			}},
+		},
+		Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds},
+	}
+	isDeployment := func(obj runtime.Object) bool {
+		d, ok := obj.(*appsv1.Deployment)
+		return ok && d.Name == deploymentName
+	}
+
+	tests := []struct {
+		name             string
+		controllerEvents bool
+		expectedReasons  []string
+	}{
+		{
+			name:             "controller events activated",
+			controllerEvents: true,
+			expectedReasons:  []string{eventReasonEvictionStarting, eventReasonEvictionSucceeded},
+		},
+		{
+			name:             "controller events not activated",
+			controllerEvents: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(node, deployment, pod)
+			store, closeFunc := RunStoreForTest(context.Background(), c)
+			defer closeFunc()
+
+			recorder := &capturingRecorder{}
+			d := NewAPIDrainer(c, NewEventRecorder(recorder),
+				WithRuntimeObjectStore(store),
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()), // the pod is not found, so the deletion is confirmed
+				WithControllerEvents(tt.controllerEvents),
+			)
+			assert.NoError(t, d.Drain(context.Background(), node))
+			assert.Equal(t, tt.expectedReasons, recorder.reasonsFor(isDeployment))
+		})
+	}
+}
+
+func TestAPIDrainer_GetEvictablePodsOnCandidateNodes(t *testing.T) {
+	nodeWith := func(name string, status core.ConditionStatus) *core.Node {
+		return &core.Node{
+			ObjectMeta: meta.ObjectMeta{Name: name},
+			Status:     core.NodeStatus{Conditions: []core.NodeCondition{{Type: "Cool", Status: status}}},
+		}
+	}
+	podOn := func(name, node string) *core.Pod {
+		return &core.Pod{ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "ns"}, Spec: core.PodSpec{NodeName: node}}
+	}
+	nodes := []*core.Node{
+		nodeWith("candidate-1", core.ConditionTrue),
+		nodeWith("candidate-2", core.ConditionTrue),
+		nodeWith("healthy", core.ConditionFalse),
+	}
+	conditions, err := ParseConditions([]string{"Cool"})
+	assert.NoError(t, err)
+
+	c := fake.NewSimpleClientset(
+		podOn("pod-1a", "candidate-1"),
+		podOn("pod-1b", "candidate-1"),
+		podOn("protected", "candidate-1"),
+		podOn("pod-2a", "candidate-2"),
+		podOn("pod-healthy", "healthy"),
+	)
+	store, closeFunc := RunStoreForTest(context.Background(), c)
+	defer closeFunc()
+	d := NewAPIDrainer(c, &NoopEventRecorder{}, WithPodFilter(func(p core.Pod) (bool, string, error) {
+		return p.GetName() != "protected", "protected", nil
+	}))
+
+	pods, err := d.GetEvictablePodsOnCandidateNodes(context.Background(), nodes, conditions, store.Pods())
+	assert.NoError(t, err)
+	names := []string{}
+	for _, p := range pods {
+		names = append(names, p.GetName())
+	}
+	assert.ElementsMatch(t, []string{"pod-1a", "pod-1b", "pod-2a"}, names)
+
+	pods, err = d.GetEvictablePodsOnCandidateNodes(context.Background(), nodes, nil, store.Pods())
+	assert.NoError(t, err)
+	assert.Empty(t, pods)
+}
+
+func TestAPIDrainer_GetInScopePVCs_NodeAnnotation(t *testing.T) {
+	storageClass := "local"
+	pvc := &core.PersistentVolumeClaim{
+		ObjectMeta: meta.ObjectMeta{Name: "data", Namespace: "ns"},
+		Spec:       core.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+	}
+	pod := &core.Pod{
+		ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", Annotations: map[string]string{PVCStorageClassCleanupAnnotationKey: PVCStorageClassCleanupAnnotationTrueValue}},
+		Spec: core.PodSpec{Volumes: []core.Volume{{
+			Name:         "data",
+			VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+		}}},
+	}
+
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		expectedPVCs []string
+	}{
+		{
+			name:         "no annotation",
+			expectedPVCs: []string{"data"},
+		},
+		{
+			name:         "cleanup disabled on the node",
+			annotations:  map[string]string{PVCCleanupDisabledNodeAnnotationKey: "true"},
+			expectedPVCs: []string{},
+		},
+		{
+			name:         "annotation set to false",
+			annotations:  map[string]string{PVCCleanupDisabledNodeAnnotationKey: "false"},
+			expectedPVCs: []string{"data"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName, Annotations: tt.annotations}}
+			d := NewAPIDrainer(fake.NewSimpleClientset(pvc), &NoopEventRecorder{}, WithStorageClassesAllowingDeletion([]string{storageClass}))
+
+			pvcs, err := d.getInScopePVCs(context.Background(), node, pod)
+			assert.NoError(t, err)
+			names := []string{}
+			for _, p := range pvcs {
+				names = append(names, p.GetName())
+			}
+			assert.Equal(t, tt.expectedPVCs, names)
+		})
+	}
+}
+
+func TestAPIDrainer_GetInScopePVCs_ForceCleanup(t *testing.T) {
+	allowedClass, disallowedClass := "local", "remote"
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+
+	tests := []struct {
+		name            string
+		storageClass    string
+		podAnnotations  map[string]string
+		pvcAnnotations  map[string]string
+		expectedPVCs    []string
+		expectedReasons []string
+	}{
+		{
+			name:         "disallowed storage class",
+			storageClass: disallowedClass,
+			expectedPVCs: []string{},
+		},
+		{
+			name:            "disallowed storage class forced on the pvc",
+			storageClass:    disallowedClass,
+			pvcAnnotations:  map[string]string{PVCForceCleanupAnnotationKey: "true"},
+			expectedPVCs:    []string{"data"},
+			expectedReasons: []string{eventReasonPVCCleanupForced, eventReasonPVCCleanupForced, "Eviction", "Eviction"},
+		},
+		{
+			name:            "disallowed storage class forced on the pod",
+			storageClass:    disallowedClass,
+			podAnnotations:  map[string]string{PVCForceCleanupAnnotationKey: "true"},
+			expectedPVCs:    []string{"data"},
+			expectedReasons: []string{eventReasonPVCCleanupForced, eventReasonPVCCleanupForced, "Eviction", "Eviction"},
+		},
+		{
+			name:           "force annotation set to false",
+			storageClass:   disallowedClass,
+			pvcAnnotations: map[string]string{PVCForceCleanupAnnotationKey: "false"},
+			expectedPVCs:   []string{},
+		},
+		{
+			name:            "allowed storage class is not reported as forced",
+			storageClass:    allowedClass,
+			pvcAnnotations:  map[string]string{PVCForceCleanupAnnotationKey: "true"},
+			expectedPVCs:    []string{"data"},
+			expectedReasons: []string{"Eviction", "Eviction"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageClass := tt.storageClass
+			pvc := &core.PersistentVolumeClaim{
+				ObjectMeta: meta.ObjectMeta{Name: "data", Namespace: "ns", UID: "data", Annotations: tt.pvcAnnotations},
+				Spec:       core.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+			}
+			annotations := map[string]string{PVCStorageClassCleanupAnnotationKey: PVCStorageClassCleanupAnnotationTrueValue}
+			for k, v := range tt.podAnnotations {
+				annotations[k] = v
+			}
+			pod := &core.Pod{
+				ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", Annotations: annotations},
+				Spec: core.PodSpec{NodeName: nodeName, Volumes: []core.Volume{{
+					Name:         "data",
+					VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+				}}},
+			}
+			crClient := crfake.NewClientBuilder().WithObjects(pvc).Build()
+			c := fake.NewSimpleClientset(pvc)
+			c.PrependReactor("delete", "persistentvolumeclaims", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				return false, nil, crClient.Delete(context.Background(), pvc.DeepCopy())
+			})
+			recorder := &capturingRecorder{}
+			d := NewAPIDrainer(c, NewEventRecorder(recorder), WithContainerRuntimeClient(crClient), WithStorageClassesAllowingDeletion([]string{allowedClass}))
+
+			pvcs, err := d.getInScopePVCs(context.Background(), node, pod)
+			assert.NoError(t, err)
+			names := []string{}
+			for _, p := range pvcs {
+				names = append(names, p.GetName())
+			}
+			assert.Equal(t, tt.expectedPVCs, names)
+
+			deleted, err := d.deletePVCAssociatedWithStorageClass(context.Background(), pod, pvcs)
+			assert.NoError(t, err)
+			assert.Len(t, deleted, len(tt.expectedPVCs))
+			assert.Equal(t, tt.expectedReasons, recorder.reasonsFor(func(runtime.Object) bool { return true }))
+		})
+	}
+}
+
+func TestAPIDrainer_EvictionSequence_PodRemovedByOtherActor(t *testing.T) {
+	storageClass := "local"
+	pvc := &core.PersistentVolumeClaim{
+		ObjectMeta: meta.ObjectMeta{Name: "data", Namespace: "ns"},
+		Spec:       core.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+	}
+	newPod := func(terminating bool) *core.Pod {
+		pod := &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", Annotations: map[string]string{PVCStorageClassCleanupAnnotationKey: PVCStorageClassCleanupAnnotationTrueValue}},
+			Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds, Volumes: []core.Volume{{
+				Name:         "data",
+				VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+			}}},
+		}
+		if terminating {
+			now := meta.Now()
+			pod.DeletionTimestamp = &now
+		}
+		return pod
+	}
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	isPod := func(obj runtime.Object) bool {
+		_, ok := obj.(*core.Pod)
+		return ok
+	}
+
+	tests := []struct {
+		name            string
+		terminating     bool
+		evictionErr     error
+		skipCleanup     bool
+		expectedRemoved bool
+		expectedEvents  []string
+	}{
+		{
+			name: "pod evicted by the drainer",
+		},
+		{
+			name:            "pod removed by another actor",
+			evictionErr:     apierrors.NewNotFound(core.Resource("pods"), podName),
+			expectedRemoved: true,
+		},
+		{
+			name:            "pod removed by another actor, cleanup skipped",
+			evictionErr:     apierrors.NewNotFound(core.Resource("pods"), podName),
+			skipCleanup:     true,
+			expectedRemoved: true,
+			expectedEvents:  []string{eventReasonPVCCleanupSkipped},
+		},
+		{
+			name:            "terminating pod removed by another actor, cleanup skipped",
+			terminating:     true,
+			skipCleanup:     true,
+			expectedRemoved: true,
+			expectedEvents:  []string{eventReasonPVCCleanupSkipped},
+		},
+		{
+			name:        "pod evicted by the drainer, cleanup not skipped",
+			skipCleanup: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &capturingRecorder{}
+			d := NewAPIDrainer(fake.NewSimpleClientset(pvc), NewEventRecorder(recorder),
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()),
+				WithStorageClassesAllowingDeletion([]string{storageClass}),
+				WithSkipPVCCleanupIfRemovedByOthers(tt.skipCleanup),
+			)
+			summary := &PodEvictionSummary{}
+			evictionCalls := 0
+			err := d.evictionSequence(context.Background(), node, newPod(tt.terminating), make(chan struct{}), summary,
+				func(*core.Pod) error {
+					evictionCalls++
+					return tt.evictionErr
+				},
+				func(e error) error { return e },
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedRemoved, summary.RemovedByOtherActor)
+			assert.Equal(t, tt.expectedEvents, recorder.reasonsFor(isPod))
+			if tt.terminating {
+				assert.Equal(t, 0, evictionCalls)
+			}
+		})
+	}
+}
+
+func TestAPIDrainer_EvictionSequence_ConfirmPodGoneBeforePVCCleanup(t *testing.T) {
+	storageClass := "local"
+	pvc := &core.PersistentVolumeClaim{
+		ObjectMeta: meta.ObjectMeta{Name: "data", Namespace: "ns"},
+		Spec:       core.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+	}
+	newPod := func(uid types.UID) *core.Pod {
+		return &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: uid, Annotations: map[string]string{PVCStorageClassCleanupAnnotationKey: PVCStorageClassCleanupAnnotationTrueValue}},
+			Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds, Volumes: []core.Volume{{
+				Name:         "data",
+				VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+			}}},
+		}
+	}
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+
+	tests := []struct {
+		name            string
+		existingPod     *core.Pod
+		expectErr       bool
+		expectedDeleted bool
+		expectedEvents  []string
+	}{
+		{
+			name:            "pod confirmed gone",
+			expectedDeleted: true,
+			expectedEvents:  []string{"Eviction"},
+		},
+		{
+			name:           "pod name reused",
+			existingPod:    newPod("new-uid"),
+			expectedEvents: []string{eventReasonPVCCleanupSkipped},
+		},
+		{
+			name:        "pod still present",
+			existingPod: newPod("old-uid"),
+			expectErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []runtime.Object{pvc.DeepCopy()}
+			if tt.existingPod != nil {
+				objects = append(objects, tt.existingPod)
+			}
+			c := fake.NewSimpleClientset(objects...)
+			deleted := false
+			c.PrependReactor("delete", "persistentvolumeclaims", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				deleted = true
+				return true, nil, apierrors.NewNotFound(core.Resource("persistentvolumeclaims"), action.(clienttesting.DeleteAction).GetName())
+			})
+			recorder := &capturingRecorder{}
+			d := NewAPIDrainer(c, NewEventRecorder(recorder),
+				WithContainerRuntimeClient(crfake.NewClientBuilder().WithObjects(pvc.DeepCopy()).Build()),
+				WithStorageClassesAllowingDeletion([]string{storageClass}),
+				WithConfirmPodGoneBeforePVCCleanup(true),
+			)
+			err := d.evictionSequence(context.Background(), node, newPod("old-uid"), make(chan struct{}), &PodEvictionSummary{},
+				func(*core.Pod) error { return apierrors.NewNotFound(core.Resource("pods"), podName) },
+				func(e error) error { return e },
+			)
+			if tt.expectErr {
+				assert.True(t, errors.As(err, &VolumeCleanupError{}))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedEvents, recorder.reasonsFor(func(obj runtime.Object) bool { _, ok := obj.(*core.Pod); return ok }))
+			assert.Equal(t, tt.expectedDeleted, deleted)
+		})
+	}
+}
+
+func TestAPIDrainer_PVCRetentionPolicy(t *testing.T) {
+	storageClass := "local"
+	isController := true
+	stsPod := &core.Pod{
+		ObjectMeta: meta.ObjectMeta{Name: "db-0", Namespace: "ns", OwnerReferences: []meta.OwnerReference{{Kind: "StatefulSet", Name: "db", Controller: &isController}}},
+		Spec: core.PodSpec{NodeName: nodeName, Volumes: []core.Volume{{
+			Name:         "data",
+			VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+		}}},
+	}
+	statefulSet := func(whenDeleted appsv1.PersistentVolumeClaimRetentionPolicyType) *appsv1.StatefulSet {
+		sts := &appsv1.StatefulSet{ObjectMeta: meta.ObjectMeta{Name: "db", Namespace: "ns"}}
+		if whenDeleted != "" {
+			sts.Spec.PersistentVolumeClaimRetentionPolicy = &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+				WhenDeleted: whenDeleted,
+				WhenScaled:  appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+			}
+		}
+		return sts
+	}
+
+	tests := []struct {
+		name             string
+		pod              *core.Pod
+		objects          []client.Object
+		expectedRetained bool
+	}{
+		{
+			name:             "Retain retention policy",
+			pod:              stsPod,
+			objects:          []client.Object{statefulSet(appsv1.RetainPersistentVolumeClaimRetentionPolicyType)},
+			expectedRetained: true,
+		},
+		{
+			name:    "Delete retention policy",
+			pod:     stsPod,
+			objects: []client.Object{statefulSet(appsv1.DeletePersistentVolumeClaimRetentionPolicyType)},
+		},
+		{
+			name:    "no retention policy",
+			pod:     stsPod,
+			objects: []client.Object{statefulSet("")},
+		},
+		{
+			name: "statefulset not found",
+			pod:  stsPod,
+		},
+		{
+			name: "pod not owned by a statefulset",
+			pod:  &core.Pod{ObjectMeta: meta.ObjectMeta{Name: "pod", Namespace: "ns"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{},
+				WithContainerRuntimeClient(crfake.NewClientBuilder().WithObjects(tt.objects...).Build()),
+			)
+			retained, err := d.isPVCRetainedByStatefulSet(context.Background(), tt.pod)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedRetained, retained)
+		})
+	}
+
+	t.Run("Should skip the cleanup when the PVCs are retained", func(t *testing.T) {
+		pvc := &core.PersistentVolumeClaim{
+			ObjectMeta: meta.ObjectMeta{Name: "data", Namespace: "ns"},
+			Spec:       core.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+		}
+		recorder := &capturingRecorder{}
+		kclient := fake.NewSimpleClientset(pvc)
+		d := NewAPIDrainer(kclient, NewEventRecorder(recorder),
+			WithContainerRuntimeClient(crfake.NewClientBuilder().WithObjects(statefulSet(appsv1.RetainPersistentVolumeClaimRetentionPolicyType), pvc).Build()),
+			WithStorageClassesAllowingDeletion([]string{storageClass}),
+			WithRespectPVCRetentionPolicy(true),
+		)
+		summary := &PodEvictionSummary{}
+		assert.NoError(t, d.cleanupVolumes(context.Background(), &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}, stsPod, []*core.PersistentVolumeClaim{pvc}, summary, true))
+		assert.Empty(t, summary.PVCsDeleted)
+		assert.Equal(t, []string{eventReasonPVCCleanupSkipped}, recorder.reasonsFor(func(obj runtime.Object) bool {
+			_, ok := obj.(*core.Pod)
+			return ok
+		}))
+		_, err := kclient.CoreV1().PersistentVolumeClaims("ns").Get(context.Background(), "data", meta.GetOptions{})
+		assert.NoError(t, err)
+	})
+}
+
+func TestAPIDrainer_AwaitDeletion_PreStopHook(t *testing.T) {
+	preStop := &core.Lifecycle{PreStop: &core.LifecycleHandler{Exec: &core.ExecAction{Command: []string{"sleep", "600"}}}}
+	terminatingPod := func(lifecycle *core.Lifecycle, endOfGracePeriod time.Time) *core.Pod {
+		deletion := meta.NewTime(endOfGracePeriod)
+		return &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: "uid", DeletionTimestamp: &deletion, Finalizers: []string{"test/finalizer"}},
+			Spec:       core.PodSpec{NodeName: nodeName, Containers: []core.Container{{Name: "app", Lifecycle: lifecycle}}},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		pod           *core.Pod
+		expectedErr   error
+		expectedCause FailureCause
+	}{
+		{
+			name:          "PreStop hook running within the grace period",
+			pod:           terminatingPod(preStop, time.Now().Add(time.Hour)),
+			expectedErr:   PodDeletionTimeoutError{PreStopHookRunning: true},
+			expectedCause: PodDeletionTimeoutPreStopHook,
+		},
+		{
+			name:          "PreStop hook, grace period over",
+			pod:           terminatingPod(preStop, time.Now().Add(-time.Minute)),
+			expectedErr:   PodDeletionTimeoutError{},
+			expectedCause: PodDeletionTimeout,
+		},
+		{
+			name:          "no PreStop hook",
+			pod:           terminatingPod(nil, time.Now().Add(time.Hour)),
+			expectedErr:   PodDeletionTimeoutError{},
+			expectedCause: PodDeletionTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{}, WithContainerRuntimeClient(crfake.NewClientBuilder().WithObjects(tt.pod).Build()))
+
+			err := d.awaitDeletion(context.Background(), tt.pod, 100*time.Millisecond)
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedCause, GetFailureCause(err))
+		})
+	}
+}
+
+func TestAPIDrainer_AwaitDeletion_Cancellation(t *testing.T) {
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: "uid"}, Spec: core.PodSpec{NodeName: nodeName}}
+	d := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{}, WithContainerRuntimeClient(crfake.NewClientBuilder().WithObjects(pod).Build()))
+
+	t.Run("genuine timeout", func(t *testing.T) {
+		err := d.awaitDeletion(context.Background(), pod, 100*time.Millisecond)
+		assert.Equal(t, PodDeletionTimeoutError{}, err)
+	})
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		start := time.Now()
+		err := d.awaitDeletion(ctx, pod, time.Hour)
+		assert.Less(t, time.Since(start), 5*time.Second, "the poll must stop with the context")
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.False(t, errors.As(err, &PodDeletionTimeoutError{}))
+		assert.NotEqual(t, PodDeletionTimeout, GetFailureCause(err))
+	})
+	t.Run("eviction aborted", func(t *testing.T) {
+		c := fake.NewSimpleClientset(pod)
+		// the eviction is accepted but the pod is never deleted
+		c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			return action.GetSubresource() == "eviction", nil, nil
+		})
+		d := NewAPIDrainer(c, &NoopEventRecorder{}, WithContainerRuntimeClient(crfake.NewClientBuilder().WithObjects(pod).Build()))
+		abort := make(chan struct{})
+		time.AfterFunc(100*time.Millisecond, func() { close(abort) })
+		start := time.Now()
+		err := d.evict(context.Background(), &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}, pod, abort, &PodEvictionSummary{})
+		assert.Less(t, time.Since(start), 5*time.Second, "the wait for the deletion must stop with the abort")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestAPIDrainer_EvictPodsRecreatedOnNode(t *testing.T) {
+	tests := []struct {
+		name              string
+		enabled           bool
+		recreatedOn       string
+		expectedEvictions int32
+		expectedEvents    []string
+	}{
+		{
+			name:              "recreated on the same node, disabled",
+			recreatedOn:       nodeName,
+			expectedEvictions: 1,
+		},
+		{
+			name:              "recreated on the same node",
+			enabled:           true,
+			recreatedOn:       nodeName,
+			expectedEvictions: 2,
+			expectedEvents:    []string{eventReasonPodRecreatedOnNode},
+		},
+		{
+			name:              "recreated on a different node",
+			enabled:           true,
+			recreatedOn:       "other-node",
+			expectedEvictions: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: "uid-1"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+			crClient := crfake.NewClientBuilder().WithObjects(pod.DeepCopy()).Build()
+			c := fake.NewSimpleClientset(pod)
+			var evictions int32
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				current := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}}
+				assert.NoError(t, crClient.Delete(context.Background(), current))
+				if atomic.AddInt32(&evictions, 1) == 1 {
+					// the controller recreates the pod with the same name
+					recreated := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: "uid-2"}, Spec: core.PodSpec{NodeName: tt.recreatedOn}}
+					assert.NoError(t, crClient.Create(context.Background(), recreated))
+				}
+				return true, nil, nil
+			})
+			recorder := &capturingRecorder{}
+			d := NewAPIDrainer(c, NewEventRecorder(recorder), WithContainerRuntimeClient(crClient), WithEvictPodsRecreatedOnNode(tt.enabled))
+
+			err := d.evict(context.Background(), &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}, pod, make(chan struct{}), &PodEvictionSummary{})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvictions, atomic.LoadInt32(&evictions))
+			assert.Equal(t, tt.expectedEvents, recorder.reasonsFor(func(obj runtime.Object) bool {
+				p, ok := obj.(*core.Pod)
+				return ok && p.GetUID() == "uid-2"
+			}))
+		})
+	}
+
+	t.Run("deleted and recreated on the same node", func(t *testing.T) {
+		pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: "uid-1"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+		crClient := crfake.NewClientBuilder().WithObjects(pod.DeepCopy()).Build()
+		c := fake.NewSimpleClientset(pod)
+		var preconditionUIDs []types.UID
+		c.PrependReactor("delete", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			preconditions := action.(clienttesting.DeleteAction).GetDeleteOptions().Preconditions
+			if !assert.NotNil(t, preconditions) || !assert.NotNil(t, preconditions.UID) {
+				return true, nil, nil
+			}
+			preconditionUIDs = append(preconditionUIDs, *preconditions.UID)
+			var current core.Pod
+			assert.NoError(t, crClient.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: podName}, &current))
+			if current.GetUID() != *preconditions.UID {
+				return true, nil, apierrors.NewConflict(core.Resource("pods"), podName, errors.New("precondition failed"))
+			}
+			assert.NoError(t, crClient.Delete(context.Background(), &current))
+			if len(preconditionUIDs) == 1 {
+				// the controller recreates the pod with the same name on the node
+				assert.NoError(t, crClient.Create(context.Background(), &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: "uid-2"}, Spec: core.PodSpec{NodeName: nodeName}}))
+			}
+			return true, nil, nil
+		})
+		d := NewAPIDrainer(c, &NoopEventRecorder{}, WithContainerRuntimeClient(crClient), WithEvictPodsRecreatedOnNode(true), WithDeletionInsteadOfEviction(true))
+
+		err := d.evict(context.Background(), &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}, pod, make(chan struct{}), &PodEvictionSummary{})
+		assert.NoError(t, err)
+		assert.Equal(t, []types.UID{"uid-1", "uid-2"}, preconditionUIDs, "the replacement must be deleted with its own UID")
+	})
+
+	t.Run("recreated and not scheduled yet", func(t *testing.T) {
+		pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: "uid-1"}, Spec: core.PodSpec{NodeName: nodeName}}
+		recreated := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: "uid-2"}}
+		crClient := crfake.NewClientBuilder().WithObjects(recreated).Build()
+
+		d := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{}, WithContainerRuntimeClient(crClient))
+		assert.NoError(t, d.awaitDeletion(context.Background(), pod, 100*time.Millisecond))
+
+		d = NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{}, WithContainerRuntimeClient(crClient), WithEvictPodsRecreatedOnNode(true))
+		start := time.Now()
+		assert.NoError(t, d.awaitDeletion(context.Background(), pod, 100*time.Millisecond))
+		assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond, "the wait must go on while the recreated pod is not scheduled")
+	})
+
+	t.Run("recreated and pending until the cleanup of its PVC", func(t *testing.T) {
+		storageClass := "local"
+		var noGracePeriod int64
+		pvc := &core.PersistentVolumeClaim{
+			ObjectMeta: meta.ObjectMeta{Name: "data", Namespace: "ns"},
+			Spec:       core.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+		}
+		pod := &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: "uid-1", Annotations: map[string]string{PVCStorageClassCleanupAnnotationKey: PVCStorageClassCleanupAnnotationTrueValue}},
+			Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &noGracePeriod, Volumes: []core.Volume{{
+				Name:         "data",
+				VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+			}}},
+		}
+		crClient := crfake.NewClientBuilder().WithObjects(pod.DeepCopy(), pvc.DeepCopy()).Build()
+		c := fake.NewSimpleClientset(pod, pvc)
+		c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "eviction" {
+				return false, nil, nil
+			}
+			// the replacement stays pending, its PVC is bound to a local PV of the drained node
+			assert.NoError(t, crClient.Delete(context.Background(), &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}}))
+			assert.NoError(t, crClient.Create(context.Background(), &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: "uid-2"}}))
+			return true, nil, nil
+		})
+		pvcDeleted := false
+		c.PrependReactor("delete", "persistentvolumeclaims", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			pvcDeleted = true
+			return true, nil, apierrors.NewNotFound(core.Resource("persistentvolumeclaims"), action.(clienttesting.DeleteAction).GetName())
+		})
+		d := NewAPIDrainer(c, &NoopEventRecorder{},
+			WithContainerRuntimeClient(crClient),
+			WithStorageClassesAllowingDeletion([]string{storageClass}),
+			WithEvictPodsRecreatedOnNode(true),
+			EvictionHeadroom(100*time.Millisecond),
+		)
+
+		err := d.evict(context.Background(), &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}, pod, make(chan struct{}), &PodEvictionSummary{})
+		assert.NoError(t, err)
+		assert.True(t, pvcDeleted, "the PVC blocking the replacement must be cleaned up")
+	})
+}
+
+type fixedPDBWaitEstimator time.Duration
+
+func (e fixedPDBWaitEstimator) EstimatePDBWait(ctx context.Context, nodeName string) (time.Duration, error) {
+	return time.Duration(e), nil
+}
+
+func TestAPIDrainer_GetDeletionPollPeriod(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []APIDrainerOption
+		timeout  time.Duration
+		expected time.Duration
+	}{
+		{
+			name:     "default floor",
+			timeout:  30 * time.Second,
+			expected: DefaultDeletionPollFloor,
+		},
+		{
+			name:     "tenth of the timeout",
+			timeout:  10 * time.Minute,
+			expected: time.Minute,
+		},
+		{
+			name:     "default ceiling",
+			timeout:  time.Hour,
+			expected: DefaultDeletionPollCeiling,
+		},
+		{
+			name:     "lower floor",
+			options:  []APIDrainerOption{WithDeletionPollBounds(time.Second, DefaultDeletionPollCeiling)},
+			timeout:  30 * time.Second,
+			expected: 3 * time.Second,
+		},
+		{
+			name:     "higher floor",
+			options:  []APIDrainerOption{WithDeletionPollBounds(30*time.Second, DefaultDeletionPollCeiling)},
+			timeout:  2 * time.Minute,
+			expected: 30 * time.Second,
+		},
+		{
+			name:     "lower ceiling",
+			options:  []APIDrainerOption{WithDeletionPollBounds(time.Second, 20*time.Second)},
+			timeout:  10 * time.Minute,
+			expected: 20 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{}, tt.options...)
+			assert.Equal(t, tt.expected, d.getDeletionPollPeriod(tt.timeout))
+		})
+	}
+}
+
+func TestAPIDrainer_EstimateDrainDuration(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	podWithGracePeriod := func(name string, seconds int64) *core.Pod {
+		return &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec:       core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &seconds},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		pods      []runtime.Object
+		options   []APIDrainerOption
+		estimated time.Duration
+	}{
+		{
+			name:      "no pod",
+			options:   []APIDrainerOption{WithPDBWaitEstimator(fixedPDBWaitEstimator(time.Minute))},
+			estimated: 0,
+		},
+		{
+			name:      "short grace periods",
+			pods:      []runtime.Object{podWithGracePeriod("a", 10), podWithGracePeriod("b", 20)},
+			estimated: 20*time.Second + DefaultEvictionOverhead,
+		},
+		{
+			name:      "long grace period",
+			pods:      []runtime.Object{podWithGracePeriod("a", 10), podWithGracePeriod("b", 600)},
+			estimated: 600*time.Second + DefaultEvictionOverhead,
+		},
+		{
+			name:      "default grace period",
+			pods:      []runtime.Object{&core.Pod{ObjectMeta: meta.ObjectMeta{Name: "a", Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName}}},
+			estimated: core.DefaultTerminationGracePeriodSeconds*time.Second + DefaultEvictionOverhead,
+		},
+		{
+			name:      "with pdb wait",
+			pods:      []runtime.Object{podWithGracePeriod("a", 10)},
+			options:   []APIDrainerOption{WithPDBWaitEstimator(fixedPDBWaitEstimator(time.Minute))},
+			estimated: 10*time.Second + DefaultEvictionOverhead + time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]APIDrainerOption{WithContainerRuntimeClient(crfake.NewClientBuilder().Build())}, tt.options...)
+			d := NewAPIDrainer(fake.NewSimpleClientset(tt.pods...), &NoopEventRecorder{}, options...)
+
+			estimated, err := d.EstimateDrainDuration(context.Background(), node)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.estimated, estimated)
+		})
+	}
+}
+
+func TestAPIDrainer_VolumeCleanupMetrics(t *testing.T) {
+	pvcDeletedView := &view.View{Name: "test_pvc_deleted", Measure: MeasurePVCDeleted, Aggregation: view.Count(), TagKeys: []tag.Key{TagStorageClass}}
+	pvDeletedView := &view.View{Name: "test_pv_deleted", Measure: MeasurePVDeleted, Aggregation: view.Count(), TagKeys: []tag.Key{TagStorageClass}}
+	assert.NoError(t, view.Register(pvcDeletedView, pvDeletedView))
+	defer view.Unregister(pvcDeletedView, pvDeletedView)
+
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName}}
+	volumeFor := func(name, storageClass string) (*core.PersistentVolumeClaim, *core.PersistentVolume) {
+		pvc := &core.PersistentVolumeClaim{
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "ns", UID: types.UID(name)},
+			Spec:       core.PersistentVolumeClaimSpec{StorageClassName: &storageClass, VolumeName: "pv-" + name},
+		}
+		pv := &core.PersistentVolume{
+			ObjectMeta: meta.ObjectMeta{Name: "pv-" + name, UID: types.UID("pv-" + name)},
+			Spec:       core.PersistentVolumeSpec{StorageClassName: storageClass},
+		}
+		return pvc, pv
+	}
+	pvc0, pv0 := volumeFor("data-0", "fast")
+	pvc1, pv1 := volumeFor("data-1", "fast")
+	pvc2, pv2 := volumeFor("data-2", "slow")
+	pvcs := []*core.PersistentVolumeClaim{pvc0, pvc1, pvc2}
+
+	crClient := crfake.NewClientBuilder().WithObjects(pvc0, pvc1, pvc2, pv0, pv1, pv2).Build()
+	c := fake.NewSimpleClientset(pvc0, pvc1, pvc2, pv0, pv1, pv2)
+	c.PrependReactor("delete", "persistentvolumeclaims", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		name := action.(clienttesting.DeleteAction).GetName()
+		return false, nil, crClient.Delete(context.Background(), &core.PersistentVolumeClaim{ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "ns"}})
+	})
+	c.PrependReactor("delete", "persistentvolumes", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		name := action.(clienttesting.DeleteAction).GetName()
+		return false, nil, crClient.Delete(context.Background(), &core.PersistentVolume{ObjectMeta: meta.ObjectMeta{Name: name}})
+	})
+	d := NewAPIDrainer(c, &NoopEventRecorder{}, WithContainerRuntimeClient(crClient))
+
+	deleted, err := d.deletePVCAssociatedWithStorageClass(context.Background(), pod, pvcs)
+	assert.NoError(t, err)
+	assert.NoError(t, d.deletePVAssociatedWithDeletedPVC(context.Background(), pod, deleted))
+
+	countsPerStorageClass := func(name string) map[string]int64 {
+		rows, err := view.RetrieveData(name)
+		assert.NoError(t, err)
+		counts := map[string]int64{}
+		for _, row := range rows {
+			counts[row.Tags[0].Value] = row.Data.(*view.CountData).Value
+		}
+		return counts
+	}
+	assert.Equal(t, map[string]int64{"fast": 2, "slow": 1}, countsPerStorageClass(pvcDeletedView.Name))
+	assert.Equal(t, map[string]int64{"fast": 2, "slow": 1}, countsPerStorageClass(pvDeletedView.Name))
+}
+
+func TestAPIDrainer_DeletePVCAssociatedWithStorageClass_MaxDeletions(t *testing.T) {
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName}}
+	pvcFor := func(name string) *core.PersistentVolumeClaim {
+		return &core.PersistentVolumeClaim{ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "ns", UID: types.UID(name)}}
+	}
+
+	tests := []struct {
+		name string
+		max  int
+		// deletedByOtherPods is the number of PVCs already deleted during the drain for the other pods of the node
+		deletedByOtherPods int
+		expectedDeleted    []string
+		expectedErr        error
+	}{
+		{
+			name:            "no limit",
+			expectedDeleted: []string{"data-0", "data-1", "data-2"},
+		},
+		{
+			name:            "under the cap",
+			max:             3,
+			expectedDeleted: []string{"data-0", "data-1", "data-2"},
+		},
+		{
+			name:            "over the cap",
+			max:             2,
+			expectedDeleted: []string{"data-0", "data-1"},
+			expectedErr:     MaxPVCDeletionsExceededError{NodeName: nodeName, PVCName: "ns/data-2", Max: 2},
+		},
+		{
+			name:               "cap shared with the other pods of the drain",
+			max:                3,
+			deletedByOtherPods: 2,
+			expectedDeleted:    []string{"data-0"},
+			expectedErr:        MaxPVCDeletionsExceededError{NodeName: nodeName, PVCName: "ns/data-1", Max: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pvcs := []*core.PersistentVolumeClaim{pvcFor("data-0"), pvcFor("data-1"), pvcFor("data-2")}
+			crClient := crfake.NewClientBuilder().WithObjects(pvcs[0], pvcs[1], pvcs[2]).Build()
+			c := fake.NewSimpleClientset(pvcs[0], pvcs[1], pvcs[2])
+			c.PrependReactor("delete", "persistentvolumeclaims", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				name := action.(clienttesting.DeleteAction).GetName()
+				return false, nil, crClient.Delete(context.Background(), pvcFor(name))
+			})
+			// the PVCs are processed one after the other to know which one exceeds the cap
+			d := NewAPIDrainer(c, &NoopEventRecorder{}, WithContainerRuntimeClient(crClient), WithMaxPVCDeletionsPerDrain(tt.max), WithPVCCleanupParallelism(1))
+
+			ctx := withPVCDeletionCounter(context.Background())
+			for i := 0; i < tt.deletedByOtherPods; i++ {
+				assert.True(t, d.reservePVCDeletion(ctx))
+			}
+			deleted, err := d.deletePVCAssociatedWithStorageClass(ctx, pod, pvcs)
+			names := []string{}
+			for _, pvc := range deleted {
+				names = append(names, pvc.GetName())
+			}
+			assert.Equal(t, tt.expectedDeleted, names)
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var maxErr MaxPVCDeletionsExceededError
+			assert.True(t, errors.As(err, &maxErr))
+			assert.Equal(t, tt.expectedErr, maxErr)
+			assert.Equal(t, MaxPVCDeletionsExceeded, GetFailureCause(VolumeCleanupError{Err: err}))
+		})
+	}
+
+	t.Run("failed deletion releases its slot", func(t *testing.T) {
+		pvcs := []*core.PersistentVolumeClaim{pvcFor("data-0")}
+		crClient := crfake.NewClientBuilder().WithObjects(pvcs[0]).Build()
+		c := fake.NewSimpleClientset(pvcs[0])
+		failures := 2
+		c.PrependReactor("delete", "persistentvolumeclaims", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			if failures > 0 {
+				failures--
+				return true, nil, apierrors.NewInternalError(errors.New("transient error"))
+			}
+			return false, nil, crClient.Delete(context.Background(), pvcFor(action.(clienttesting.DeleteAction).GetName()))
+		})
+		d := NewAPIDrainer(c, &NoopEventRecorder{}, WithContainerRuntimeClient(crClient), WithMaxPVCDeletionsPerDrain(1))
+
+		ctx := withPVCDeletionCounter(context.Background())
+		for i := 0; i < 2; i++ {
+			_, err := d.deletePVCAssociatedWithStorageClass(ctx, pod, pvcs)
+			assert.True(t, apierrors.IsInternalError(err), "unexpected error %v", err)
+		}
+		deleted, err := d.deletePVCAssociatedWithStorageClass(ctx, pod, pvcs)
+		assert.NoError(t, err)
+		assert.Len(t, deleted, 1)
+	})
+}
+
+// inFlightPVCGetClient counts the concurrent Get calls on PVCs, each call lasting delay
+type inFlightPVCGetClient struct {
+	client.Client
+	delay                 time.Duration
+	inFlight, maxInFlight int32
+}
+
+func (c *inFlightPVCGetClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if _, ok := obj.(*core.PersistentVolumeClaim); ok {
+		current := atomic.AddInt32(&c.inFlight, 1)
+		defer atomic.AddInt32(&c.inFlight, -1)
+		for {
+			previous := atomic.LoadInt32(&c.maxInFlight)
+			if current <= previous || atomic.CompareAndSwapInt32(&c.maxInFlight, previous, current) {
+				break
+			}
+		}
+		time.Sleep(c.delay)
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func TestAPIDrainer_DeletePVCAssociatedWithStorageClass_Parallelism(t *testing.T) {
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName}}
+	pvcFor := func(name string) *core.PersistentVolumeClaim {
+		return &core.PersistentVolumeClaim{ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "ns", UID: types.UID(name)}}
+	}
+	tests := []struct {
+		name        string
+		parallelism int
+		failing     string
+	}{
+		{
+			name:        "serial",
+			parallelism: 1,
+		},
+		{
+			name:        "parallel",
+			parallelism: 3,
+		},
+		{
+			name:        "more workers than PVCs",
+			parallelism: 10,
+		},
+		{
+			name:        "deletion failure",
+			parallelism: 3,
+			failing:     "data-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pvcs []*core.PersistentVolumeClaim
+			var objects []runtime.Object
+			for i := 0; i < 6; i++ {
+				pvcs = append(pvcs, pvcFor(fmt.Sprintf("data-%d", i)))
+				objects = append(objects, pvcs[i])
+			}
+			crObjects := crfake.NewClientBuilder().WithRuntimeObjects(objects...).Build()
+			crClient := &inFlightPVCGetClient{Client: crObjects, delay: 50 * time.Millisecond}
+			c := fake.NewSimpleClientset(objects...)
+			c.PrependReactor("delete", "persistentvolumeclaims", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				name := action.(clienttesting.DeleteAction).GetName()
+				if name == tt.failing {
+					return true, nil, apierrors.NewInternalError(errors.New("storage backend unavailable"))
+				}
+				return false, nil, crObjects.Delete(context.Background(), pvcFor(name))
+			})
+			d := NewAPIDrainer(c, &NoopEventRecorder{}, WithContainerRuntimeClient(crClient), WithPVCCleanupParallelism(tt.parallelism))
+
+			deleted, err := d.deletePVCAssociatedWithStorageClass(context.Background(), pod, pvcs)
+			names := map[string]bool{}
+			for _, pvc := range deleted {
+				names[pvc.GetName()] = true
+			}
+			// the deleted PVCs are exactly the ones that are gone
+			for _, pvc := range pvcs {
+				getErr := crObjects.Get(context.Background(), types.NamespacedName{Namespace: pvc.Namespace, Name: pvc.Name}, &core.PersistentVolumeClaim{})
+				assert.Equal(t, apierrors.IsNotFound(getErr), names[pvc.Name], pvc.Name)
+			}
+			expectedInFlight := tt.parallelism
+			if expectedInFlight > len(pvcs) {
+				expectedInFlight = len(pvcs)
+			}
+			assert.Equal(t, int32(expectedInFlight), atomic.LoadInt32(&crClient.maxInFlight))
+			if tt.failing == "" {
+				assert.NoError(t, err)
+				assert.Len(t, deleted, len(pvcs))
+				for i := range deleted {
+					assert.Equal(t, pvcs[i].Name, deleted[i].Name, "the deleted PVCs keep the order of the PVCs")
+				}
+				return
+			}
+			assert.ErrorContains(t, err, "cannot delete pvc ns/"+tt.failing)
+			assert.False(t, names[tt.failing])
+			assert.Less(t, len(deleted), len(pvcs))
+		})
+	}
+}
+
+func TestAPIDrainer_PVCDeletionTimeouts(t *testing.T) {
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName}}
+	tests := []struct {
+		name            string
+		timeouts        map[string]time.Duration
+		storageClass    string
+		expectedTimeout time.Duration
+	}{
+		{
+			name:            "no override",
+			storageClass:    "ebs",
+			expectedTimeout: awaitPVCDeletionTimeout,
+		},
+		{
+			name:            "storage class override",
+			timeouts:        map[string]time.Duration{"ebs": 5 * time.Minute, "ceph": 10 * time.Minute},
+			storageClass:    "ebs",
+			expectedTimeout: 5 * time.Minute,
+		},
+		{
+			name:            "unknown storage class",
+			timeouts:        map[string]time.Duration{"ceph": 10 * time.Minute},
+			storageClass:    "local-ssd",
+			expectedTimeout: awaitPVCDeletionTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pvc := &core.PersistentVolumeClaim{ObjectMeta: meta.ObjectMeta{Name: "data-0", Namespace: "ns", UID: "data-0"}, Spec: core.PersistentVolumeClaimSpec{StorageClassName: &tt.storageClass}}
+			crClient := crfake.NewClientBuilder().WithObjects(pvc).Build()
+			c := fake.NewSimpleClientset(pvc)
+			c.PrependReactor("delete", "persistentvolumeclaims", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				return false, nil, crClient.Delete(context.Background(), pvc)
+			})
+			observedCore, logs := observer.New(zap.InfoLevel)
+			d := NewAPIDrainer(c, &NoopEventRecorder{}, WithContainerRuntimeClient(crClient), WithPVCDeletionTimeouts(tt.timeouts), WithAPIDrainerLogger(zap.New(observedCore)))
+
+			assert.Equal(t, tt.expectedTimeout, d.getPVCDeletionTimeout(tt.storageClass))
+			deleted, err := d.deletePVCAssociatedWithStorageClass(context.Background(), pod, []*core.PersistentVolumeClaim{pvc})
+			assert.NoError(t, err)
+			assert.Len(t, deleted, 1)
+			entries := logs.FilterMessage("deleting pvc").All()
+			if assert.Len(t, entries, 1) {
+				assert.Equal(t, tt.expectedTimeout, entries[0].ContextMap()["timeout"])
+				assert.Equal(t, tt.storageClass, entries[0].ContextMap()["storageClassName"])
+			}
+		})
+	}
+}
+
+func TestAPIDrainer_PVCDeletionTimeouts_Expiry(t *testing.T) {
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName}}
+	timeouts := map[string]time.Duration{"fast-detach": 500 * time.Millisecond, "slow-detach": 10 * time.Second}
+	tests := []struct {
+		name         string
+		storageClass string
+		expectedErr  bool
+	}{
+		{
+			name:         "short timeout expires",
+			storageClass: "fast-detach",
+			expectedErr:  true,
+		},
+		{
+			name:         "long timeout waits for the deletion",
+			storageClass: "slow-detach",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pvc := &core.PersistentVolumeClaim{ObjectMeta: meta.ObjectMeta{Name: "data-0", Namespace: "ns", UID: "data-0"}, Spec: core.PersistentVolumeClaimSpec{StorageClassName: &tt.storageClass}}
+			crClient := crfake.NewClientBuilder().WithObjects(pvc).Build()
+			c := fake.NewSimpleClientset(pvc)
+			// the storage backend takes some time to release the volume
+			c.PrependReactor("delete", "persistentvolumeclaims", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				time.AfterFunc(1500*time.Millisecond, func() { _ = crClient.Delete(context.Background(), pvc) })
+				return false, nil, nil
+			})
+			d := NewAPIDrainer(c, &NoopEventRecorder{}, WithContainerRuntimeClient(crClient), WithPVCDeletionTimeouts(timeouts))
+
+			deleted, err := d.deletePVCAssociatedWithStorageClass(context.Background(), pod, []*core.PersistentVolumeClaim{pvc})
+			if tt.expectedErr {
+				assert.ErrorContains(t, err, "pvc deletion timeout ns/data-0")
+				assert.Empty(t, deleted)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, deleted, 1)
+		})
+	}
+}
+
+func TestParsePVCDeletionTimeouts(t *testing.T) {
+	tests := []struct {
+		name      string
+		entries   []string
+		expected  map[string]time.Duration
+		expectErr bool
+	}{
+		{
+			name:     "timeouts",
+			entries:  []string{"ebs=5m", " ceph = 10m "},
+			expected: map[string]time.Duration{"ebs": 5 * time.Minute, "ceph": 10 * time.Minute},
+		},
+		{
+			name:     "nothing overridden",
+			expected: map[string]time.Duration{},
+		},
+		{
+			name:      "missing timeout",
+			entries:   []string{"ebs="},
+			expectErr: true,
+		},
+		{
+			name:      "invalid timeout",
+			entries:   []string{"ebs=forever"},
+			expectErr: true,
+		},
+		{
+			name:      "negative timeout",
+			entries:   []string{"ebs=-1m"},
+			expectErr: true,
+		},
+		{
+			name:      "duplicated storage class",
+			entries:   []string{"ebs=1m", "ebs=2m"},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeouts, err := ParsePVCDeletionTimeouts(tt.entries)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, timeouts)
+		})
+	}
+}
+
+func TestAPIDrainer_PodDeleteCheckPVC(t *testing.T) {
+	waitForFirstConsumer := storagev1.VolumeBindingWaitForFirstConsumer
+	immediate := storagev1.VolumeBindingImmediate
+	scWFFC := &storagev1.StorageClass{ObjectMeta: meta.ObjectMeta{Name: "local-wffc"}, VolumeBindingMode: &waitForFirstConsumer}
+	scImmediate := &storagev1.StorageClass{ObjectMeta: meta.ObjectMeta{Name: "remote"}, VolumeBindingMode: &immediate}
+	evictedPod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: "evicted"}}
+	replacementPod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: "replacement"}}
+	pvcFor := func(storageClass, volumeName string) *core.PersistentVolumeClaim {
+		return &core.PersistentVolumeClaim{
+			ObjectMeta: meta.ObjectMeta{Name: "data", Namespace: "ns", UID: "deleted-pvc"},
+			Spec:       core.PersistentVolumeClaimSpec{StorageClassName: &storageClass, VolumeName: volumeName},
+		}
+	}
+
+	tests := []struct {
+		name                  string
+		pvc                   *core.PersistentVolumeClaim
+		objects               []runtime.Object
+		expectedUnboundWFFC   bool
+		expectedPodDeleted    bool
+		expectedPVCRecreation bool
+	}{
+		{
+			name:                "unbound WaitForFirstConsumer pvc, pod already replaced",
+			pvc:                 pvcFor(scWFFC.Name, ""),
+			objects:             []runtime.Object{scWFFC, replacementPod},
+			expectedUnboundWFFC: true,
+			expectedPodDeleted:  false,
+		},
+		{
+			name:                "unbound WaitForFirstConsumer pvc, pod not replaced",
+			pvc:                 pvcFor(scWFFC.Name, ""),
+			objects:             []runtime.Object{scWFFC, evictedPod},
+			expectedUnboundWFFC: true,
+			expectedPodDeleted:  true,
+		},
+		{
+			name:                "bound WaitForFirstConsumer pvc",
+			pvc:                 pvcFor(scWFFC.Name, "pv-1"),
+			objects:             []runtime.Object{scWFFC, replacementPod},
+			expectedUnboundWFFC: false,
+			expectedPodDeleted:  true,
+		},
+		{
+			name:                "unbound Immediate pvc",
+			pvc:                 pvcFor(scImmediate.Name, ""),
+			objects:             []runtime.Object{scImmediate, replacementPod},
+			expectedUnboundWFFC: false,
+			expectedPodDeleted:  true,
+		},
+		{
+			name: "unbound WaitForFirstConsumer pvc already recreated",
+			pvc:  pvcFor(scWFFC.Name, ""),
+			objects: []runtime.Object{scWFFC, replacementPod, &core.PersistentVolumeClaim{
+				ObjectMeta: meta.ObjectMeta{Name: "data", Namespace: "ns", UID: "recreated-pvc"},
+			}},
+			expectedUnboundWFFC:   true,
+			expectedPodDeleted:    false,
+			expectedPVCRecreation: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(tt.objects...)
+			recorder := &capturingRecorder{}
+			d := NewAPIDrainer(c, NewEventRecorder(recorder))
+
+			unboundWFFC, err := d.isUnboundWaitForFirstConsumerPVC(context.Background(), tt.pvc)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedUnboundWFFC, unboundWFFC)
+
+			podDeletions := 0
+			pending, err := d.podDeleteCheckPVCs(context.Background(), evictedPod, []pvcToRecreate{{pvc: tt.pvc, unboundWaitForFirstConsumer: unboundWFFC}}, &podDeletions)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedPVCRecreation, len(pending) == 0)
+
+			podDeleted := false
+			for _, a := range c.Actions() {
+				if a.GetVerb() == "delete" && a.GetResource().Resource == "pods" {
+					podDeleted = true
+				}
+			}
+			assert.Equal(t, tt.expectedPodDeleted, podDeleted)
+
+			var expectedPVCEvents []string
+			if tt.expectedPVCRecreation {
+				expectedPVCEvents = []string{eventReasonPVCRecreated}
+			}
+			assert.Equal(t, expectedPVCEvents, recorder.reasonsFor(func(obj runtime.Object) bool {
+				pvc, ok := obj.(*core.PersistentVolumeClaim)
+				return ok && pvc.UID == "recreated-pvc"
+			}))
+			assert.Equal(t, expectedPVCEvents, recorder.reasonsFor(func(obj runtime.Object) bool {
+				_, ok := obj.(*core.Pod)
+				return ok
+			}))
+		})
+	}
+}
+
+func TestAPIDrainer_PodDeleteRetryWaitingForPVC_Limits(t *testing.T) {
+	deletedPVC := &core.PersistentVolumeClaim{ObjectMeta: meta.ObjectMeta{Name: "data", Namespace: "ns", UID: "deleted-pvc"}}
+	podWithAnnotations := func(annotations map[string]string) *core.Pod {
+		return &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: "evicted", Annotations: annotations}}
+	}
+
+	t.Run("timeout from the drainer", func(t *testing.T) {
+		pod := podWithAnnotations(nil)
+		d := NewAPIDrainer(fake.NewSimpleClientset(pod), &NoopEventRecorder{}, WithPVCRecreateTimeout(time.Millisecond))
+		err := d.podDeleteRetryWaitingForPVCs(context.Background(), pod, []*core.PersistentVolumeClaim{deletedPVC})
+		var timeoutErr PVCRecreateTimeoutError
+		if assert.True(t, errors.As(err, &timeoutErr)) {
+			assert.Equal(t, time.Millisecond, timeoutErr.Timeout)
+			assert.Equal(t, "ns/data", timeoutErr.PVCName)
+			assert.GreaterOrEqual(t, timeoutErr.PodDeletions, 1)
+		}
+		assert.Equal(t, PVCRecreateTimeout, GetFailureCause(VolumeCleanupError{Err: err}))
+	})
+
+	t.Run("timeout from the pod annotation", func(t *testing.T) {
+		pod := podWithAnnotations(map[string]string{PVCRecreateTimeoutAnnotationKey: "2ms"})
+		d := NewAPIDrainer(fake.NewSimpleClientset(pod), &NoopEventRecorder{})
+		err := d.podDeleteRetryWaitingForPVCs(context.Background(), pod, []*core.PersistentVolumeClaim{deletedPVC})
+		var timeoutErr PVCRecreateTimeoutError
+		if assert.True(t, errors.As(err, &timeoutErr)) {
+			assert.Equal(t, 2*time.Millisecond, timeoutErr.Timeout)
+		}
+	})
+
+	t.Run("invalid pod annotation", func(t *testing.T) {
+		pod := podWithAnnotations(map[string]string{PVCRecreateTimeoutAnnotationKey: "soon"})
+		recorder := &capturingRecorder{}
+		d := NewAPIDrainer(fake.NewSimpleClientset(pod), NewEventRecorder(recorder), WithPVCRecreateTimeout(time.Minute))
+		// the estimations do not report the invalid annotation
+		timeout, invalidValue := d.lookupPVCRecreateTimeout(pod)
+		assert.Equal(t, time.Minute, timeout)
+		assert.Equal(t, "soon", invalidValue)
+		assert.Empty(t, recorder.reasonsFor(func(runtime.Object) bool { return true }))
+
+		assert.Equal(t, time.Minute, d.getPVCRecreateTimeout(context.Background(), pod))
+		assert.Equal(t, []string{eventReasonBadValueForAnnotation}, recorder.reasonsFor(func(runtime.Object) bool { return true }))
+	})
+
+	t.Run("max pod deletions", func(t *testing.T) {
+		pod := podWithAnnotations(nil)
+		c := fake.NewSimpleClientset(pod)
+		d := NewAPIDrainer(c, &NoopEventRecorder{}, WithMaxPodDeletionsForPVCRecreate(2))
+
+		podDeletions := 1
+		toRecreate := []pvcToRecreate{{pvc: deletedPVC}}
+		pending, err := d.podDeleteCheckPVCs(context.Background(), pod, toRecreate, &podDeletions)
+		assert.NoError(t, err)
+		assert.Len(t, pending, 1)
+		assert.Equal(t, 2, podDeletions)
+
+		pending, err = d.podDeleteCheckPVCs(context.Background(), pod, toRecreate, &podDeletions)
+		assert.Equal(t, MaxPodDeletionsForPVCRecreateExceededError{PodName: "ns/" + podName, PVCName: "ns/data", Max: 2}, err)
+		assert.Len(t, pending, 1)
+		assert.Equal(t, 2, podDeletions)
+		assert.Equal(t, MaxPodDeletionsForPVCRecreate, GetFailureCause(VolumeCleanupError{Err: err}))
+
+		deletions := 0
+		for _, a := range c.Actions() {
+			if a.GetVerb() == "delete" && a.GetResource().Resource == "pods" {
+				deletions++
+			}
+		}
+		assert.Equal(t, 1, deletions)
+	})
+
+	t.Run("pod deletions shared by the pvcs of the pod", func(t *testing.T) {
+		pod := podWithAnnotations(nil)
+		otherPVC := &core.PersistentVolumeClaim{ObjectMeta: meta.ObjectMeta{Name: "logs", Namespace: "ns", UID: "deleted-logs-pvc"}}
+		c := fake.NewSimpleClientset(pod)
+		d := NewAPIDrainer(c, &NoopEventRecorder{}, WithMaxPodDeletionsForPVCRecreate(1))
+
+		podDeletions := 0
+		toRecreate := []pvcToRecreate{{pvc: deletedPVC}, {pvc: otherPVC}}
+		pending, err := d.podDeleteCheckPVCs(context.Background(), pod, toRecreate, &podDeletions)
+		assert.NoError(t, err)
+		assert.Len(t, pending, 2)
+		assert.Equal(t, 1, podDeletions)
+
+		_, err = d.podDeleteCheckPVCs(context.Background(), pod, toRecreate, &podDeletions)
+		assert.Equal(t, MaxPodDeletionsForPVCRecreateExceededError{PodName: "ns/" + podName, PVCName: "ns/data,ns/logs", Max: 1}, err)
+
+		deletions := 0
+		for _, a := range c.Actions() {
+			if a.GetVerb() == "delete" && a.GetResource().Resource == "pods" {
+				deletions++
+			}
+		}
+		assert.Equal(t, 1, deletions)
+	})
+}
+
+func TestAPIDrainer_PodDeleteRetryWaitingForPVC_Metric(t *testing.T) {
+	recreateView := &view.View{
+		Name:        "test_pvc_recreate_duration",
+		Measure:     MeasurePVCRecreateDuration,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{TagResult},
+	}
+	assert.NoError(t, view.Register(recreateView))
+	defer view.Unregister(recreateView)
+
+	evictedPod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: "evicted"}}
+	deletedPVC := &core.PersistentVolumeClaim{ObjectMeta: meta.ObjectMeta{Name: "data", Namespace: "ns", UID: "deleted-pvc"}}
+	recreatedPVC := &core.PersistentVolumeClaim{ObjectMeta: meta.ObjectMeta{Name: "data", Namespace: "ns", UID: "recreated-pvc"}}
+	d := NewAPIDrainer(fake.NewSimpleClientset(recreatedPVC), &NoopEventRecorder{})
+
+	assert.NoError(t, d.podDeleteRetryWaitingForPVCs(context.Background(), evictedPod, []*core.PersistentVolumeClaim{deletedPVC}))
+
+	rows, err := view.RetrieveData(recreateView.Name)
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 1) {
+		assert.Equal(t, []tag.Tag{{Key: TagResult, Value: pvcRecreateResultRecreated}}, rows[0].Tags)
+	}
+}
+
+func TestAPIDrainer_GetPodsToDrain_NamespaceAllowList(t *testing.T) {
+	podIn := func(namespace string) *core.Pod {
+		return &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: "pod-" + namespace, Namespace: namespace},
+			Spec:       core.PodSpec{NodeName: nodeName},
+		}
+	}
+	isPodOf := func(namespace string) func(obj runtime.Object) bool {
+		return func(obj runtime.Object) bool {
+			p, ok := obj.(*core.Pod)
+			return ok && p.Namespace == namespace
+		}
+	}
+
+	tests := []struct {
+		name           string
+		allowList      []string
+		expectedPods   []string
+		expectedEvents []string
+	}{
+		{
+			name:         "no allow list",
+			expectedPods: []string{"pod-allowed", "pod-other"},
+		},
+		{
+			name:           "allowed and disallowed namespaces",
+			allowList:      []string{"allowed"},
+			expectedPods:   []string{"pod-allowed"},
+			expectedEvents: []string{eventReasonNamespaceNotAllowed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &capturingRecorder{}
+			d := NewAPIDrainer(fake.NewSimpleClientset(podIn("allowed"), podIn("other")), NewEventRecorder(recorder),
+				WithNamespaceAllowList(tt.allowList),
+			)
+			pods, err := d.GetPodsToDrain(context.Background(), nodeName, nil)
+			assert.NoError(t, err)
+
+			var names []string
+			for _, p := range pods {
+				names = append(names, p.Name)
+			}
+			assert.ElementsMatch(t, tt.expectedPods, names)
+			assert.Empty(t, recorder.reasonsFor(isPodOf("allowed")))
+			assert.Equal(t, tt.expectedEvents, recorder.reasonsFor(isPodOf("other")))
+		})
+	}
+}
+
+func TestAPIDrainer_GetPodsToDrain_FilterError(t *testing.T) {
+	pod := func(name string) *core.Pod {
+		return &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec:       core.PodSpec{NodeName: nodeName},
+		}
+	}
+	isPod := func(name string) func(obj runtime.Object) bool {
+		return func(obj runtime.Object) bool {
+			p, ok := obj.(*core.Pod)
+			return ok && p.Name == name
+		}
+	}
+	filter := func(p core.Pod) (bool, string, error) {
+		if p.Name == "pod-unknown" {
+			return false, "", errors.New("cannot classify pod")
+		}
+		return true, "", nil
+	}
+
+	tests := []struct {
+		name           string
+		skipOnError    bool
+		expectedErr    bool
+		expectedPods   []string
+		expectedEvents []string
+	}{
+		{
+			name:        "abort on filter error",
+			skipOnError: false,
+			expectedErr: true,
+		},
+		{
+			name:           "skip the pod on filter error",
+			skipOnError:    true,
+			expectedPods:   []string{"pod-ok"},
+			expectedEvents: []string{eventReasonPodFilterError},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &capturingRecorder{}
+			d := NewAPIDrainer(fake.NewSimpleClientset(pod("pod-ok"), pod("pod-unknown")), NewEventRecorder(recorder),
+				WithPodFilter(filter),
+				WithSkipPodsOnFilterError(tt.skipOnError),
+			)
+			pods, err := d.GetPodsToDrain(context.Background(), nodeName, nil)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				assert.Nil(t, pods)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			var names []string
+			for _, p := range pods {
+				names = append(names, p.Name)
+			}
+			assert.Equal(t, tt.expectedPods, names)
+			assert.Empty(t, recorder.reasonsFor(isPod("pod-ok")))
+			assert.Equal(t, tt.expectedEvents, recorder.reasonsFor(isPod("pod-unknown")))
+		})
+	}
+}
+
+func TestAPIDrainer_GetPodsToDrain_Pagination(t *testing.T) {
+	var all []core.Pod
+	for i := 0; i < 5; i++ {
+		all = append(all, core.Pod{ObjectMeta: meta.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName}})
+	}
+	tests := []struct {
+		name          string
+		pageSize      int64
+		expectedCalls int32
+	}{
+		{name: "default page size", pageSize: DefaultPodListPageSize, expectedCalls: 1},
+		{name: "several pages", pageSize: 2, expectedCalls: 3},
+		{name: "pagination disabled", pageSize: 0, expectedCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			// the apiserver serves the pods from the offset given by the continue token
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				assert.Equal(t, "/api/v1/pods", r.URL.Path)
+				assert.Equal(t, "spec.nodeName="+nodeName, r.URL.Query().Get("fieldSelector"))
+				limit, start := len(all), 0
+				if v := r.URL.Query().Get("limit"); v != "" {
+					assert.Equal(t, strconv.FormatInt(tt.pageSize, 10), v)
+					limit, _ = strconv.Atoi(v)
+				} else {
+					assert.Zero(t, tt.pageSize)
+				}
+				if v := r.URL.Query().Get("continue"); v != "" {
+					start, _ = strconv.Atoi(v)
+				}
+				end := start + limit
+				list := core.PodList{TypeMeta: meta.TypeMeta{Kind: "PodList", APIVersion: "v1"}}
+				if end < len(all) {
+					list.Continue = strconv.Itoa(end)
+				} else {
+					end = len(all)
+				}
+				list.Items = all[start:end]
+				w.Header().Set("Content-Type", "application/json")
+				assert.NoError(t, json.NewEncoder(w).Encode(list))
+			}))
+			defer server.Close()
+			c, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+			assert.NoError(t, err)
+			d := NewAPIDrainer(c, &NoopEventRecorder{}, WithPodListPageSize(tt.pageSize))
+
+			pods, err := d.GetPodsToDrain(context.Background(), nodeName, nil)
+			assert.NoError(t, err)
+			var names []string
+			for _, p := range pods {
+				names = append(names, p.Name)
+			}
+			assert.Equal(t, []string{"pod-0", "pod-1", "pod-2", "pod-3", "pod-4"}, names)
+			assert.Equal(t, tt.expectedCalls, atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+// staticPodStore lists the same pods for any node
+type staticPodStore struct {
+	PodStore
+	pods []*core.Pod
+}
+
+func (s *staticPodStore) ListPodsForNode(nodeName string) ([]*core.Pod, error) {
+	return s.pods, nil
+}
+
+func TestAPIDrainer_GetPodsToDrain_Cache(t *testing.T) {
+	pods := []*core.Pod{
+		{ObjectMeta: meta.ObjectMeta{Name: "pod-a", Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName}},
+		{ObjectMeta: meta.ObjectMeta{Name: "pod-b", Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName}},
+	}
+	tests := []struct {
+		name            string
+		ttl             time.Duration
+		podStore        PodStore
+		expectedFilters int32
+	}{
+		{
+			name:            "cache disabled",
+			expectedFilters: 4,
+		},
+		{
+			name:            "cache without pod store",
+			ttl:             200 * time.Millisecond,
+			expectedFilters: 2,
+		},
+		{
+			name:            "cache with pod store",
+			ttl:             200 * time.Millisecond,
+			podStore:        &staticPodStore{pods: pods},
+			expectedFilters: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filtered int32
+			filter := func(p core.Pod) (bool, string, error) {
+				atomic.AddInt32(&filtered, 1)
+				return true, "", nil
+			}
+			d := NewAPIDrainer(fake.NewSimpleClientset(pods[0], pods[1]), &NoopEventRecorder{}, WithPodFilter(filter), WithPodsToDrainCacheTTL(tt.ttl))
+
+			first, err := d.GetPodsToDrain(context.Background(), nodeName, tt.podStore)
+			assert.NoError(t, err)
+			assert.Len(t, first, 2)
+			// the caller can reorder the pods without altering the cached list
+			first[0], first[1] = first[1], first[0]
+			second, err := d.GetPodsToDrain(context.Background(), nodeName, tt.podStore)
+			assert.NoError(t, err)
+			if assert.Len(t, second, 2) {
+				assert.Equal(t, "pod-a", second[0].Name)
+			}
+			assert.Equal(t, tt.expectedFilters, atomic.LoadInt32(&filtered))
+
+			if tt.ttl > 0 {
+				time.Sleep(tt.ttl + 50*time.Millisecond)
+				_, err = d.GetPodsToDrain(context.Background(), nodeName, tt.podStore)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedFilters+2, atomic.LoadInt32(&filtered), "the pods are filtered again once the entry expired")
+
+				// the entry is dropped by the drains, whatever their outcome
+				assert.Error(t, d.Drain(context.Background(), &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}))
+				_, err = d.GetPodsToDrain(context.Background(), nodeName, tt.podStore)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedFilters+4, atomic.LoadInt32(&filtered), "the pods are filtered again after a drain")
+			}
+		})
+	}
+}
+
+func TestAPIDrainer_GetPodsToDrain_PodNameExclusion(t *testing.T) {
+	pod := func(name string) *core.Pod {
+		return &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec:       core.PodSpec{NodeName: nodeName},
+		}
+	}
+	isPod := func(name string) func(obj runtime.Object) bool {
+		return func(obj runtime.Object) bool {
+			p, ok := obj.(*core.Pod)
+			return ok && p.Name == name
+		}
+	}
+
+	tests := []struct {
+		name           string
+		exclusions     []string
+		expectedPods   []string
+		expectedEvents map[string][]string
+	}{
+		{
+			name:         "no exclusion",
+			expectedPods: []string{"api-canary", "api-stable"},
+		},
+		{
+			name:           "matching pod name",
+			exclusions:     []string{".*-canary"},
+			expectedPods:   []string{"api-stable"},
+			expectedEvents: map[string][]string{"api-canary": {eventReasonPodNameExcluded}},
+		},
+		{
+			name:         "non matching pod names",
+			exclusions:   []string{"^worker-", "-preview$"},
+			expectedPods: []string{"api-canary", "api-stable"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var exclusions []regexp.Regexp
+			for _, expr := range tt.exclusions {
+				exclusions = append(exclusions, *regexp.MustCompile(expr))
+			}
+			recorder := &capturingRecorder{}
+			d := NewAPIDrainer(fake.NewSimpleClientset(pod("api-canary"), pod("api-stable")), NewEventRecorder(recorder),
+				WithPodNameExclusion(exclusions),
+			)
+			pods, err := d.GetPodsToDrain(context.Background(), nodeName, nil)
+			assert.NoError(t, err)
+
+			var names []string
+			for _, p := range pods {
+				names = append(names, p.Name)
+			}
+			assert.ElementsMatch(t, tt.expectedPods, names)
+			for _, name := range []string{"api-canary", "api-stable"} {
+				assert.Equal(t, tt.expectedEvents[name], recorder.reasonsFor(isPod(name)), name)
+			}
+		})
+	}
+}
+
+func TestAPIDrainer_GetPodsToDrain_SkipControllerKinds(t *testing.T) {
+	isController := true
+	pod := func(name string, owner meta.OwnerReference) *core.Pod {
+		return &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "ns", OwnerReferences: []meta.OwnerReference{owner}},
+			Spec:       core.PodSpec{NodeName: nodeName},
+		}
+	}
+	gameServerPod := pod("game-0", meta.OwnerReference{APIVersion: "agones.dev/v1", Kind: "GameServer", Name: "game", Controller: &isController})
+	deploymentPod := pod("api-5d8f7c-x2c4d", meta.OwnerReference{APIVersion: "apps/v1", Kind: kindReplicaSet, Name: "api-5d8f7c", Controller: &isController})
+	deployment := &appsv1.Deployment{ObjectMeta: meta.ObjectMeta{Name: "api", Namespace: "ns"}}
+	isPod := func(name string) func(obj runtime.Object) bool {
+		return func(obj runtime.Object) bool {
+			p, ok := obj.(*core.Pod)
+			return ok && p.Name == name
+		}
+	}
+
+	tests := []struct {
+		name           string
+		kinds          []schema.GroupKind
+		expectedPods   []string
+		expectedEvents map[string][]string
+	}{
+		{
+			name:         "no skipped kind",
+			expectedPods: []string{gameServerPod.Name, deploymentPod.Name},
+		},
+		{
+			name:           "pod owned by a skipped kind",
+			kinds:          []schema.GroupKind{{Group: "agones.dev", Kind: "GameServer"}},
+			expectedPods:   []string{deploymentPod.Name},
+			expectedEvents: map[string][]string{gameServerPod.Name: {eventReasonControllerSkipped}},
+		},
+		{
+			name:         "same kind in another group",
+			kinds:        []schema.GroupKind{{Group: "example.com", Kind: "GameServer"}},
+			expectedPods: []string{gameServerPod.Name, deploymentPod.Name},
+		},
+		{
+			name:           "owner resolved from the store",
+			kinds:          []schema.GroupKind{{Group: "apps", Kind: "Deployment"}},
+			expectedPods:   []string{gameServerPod.Name},
+			expectedEvents: map[string][]string{deploymentPod.Name: {eventReasonControllerSkipped}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, closeFunc := RunStoreForTest(context.Background(), fake.NewSimpleClientset(deployment))
+			defer closeFunc()
+			recorder := &capturingRecorder{}
+			d := NewAPIDrainer(fake.NewSimpleClientset(gameServerPod, deploymentPod), NewEventRecorder(recorder),
+				WithRuntimeObjectStore(store),
+				WithSkipControllerKinds(tt.kinds),
+			)
+			pods, err := d.GetPodsToDrain(context.Background(), nodeName, nil)
+			assert.NoError(t, err)
+
+			var names []string
+			for _, p := range pods {
+				names = append(names, p.Name)
+			}
+			assert.ElementsMatch(t, tt.expectedPods, names)
+			for _, name := range []string{gameServerPod.Name, deploymentPod.Name} {
+				assert.Equal(t, tt.expectedEvents[name], recorder.reasonsFor(isPod(name)), name)
+			}
+		})
+	}
+}
+
+func TestAPIDrainer_GetPodsToDrain_PendingPods(t *testing.T) {
+	pod := func(name string, phase core.PodPhase) *core.Pod {
+		return &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec:       core.PodSpec{NodeName: nodeName},
+			Status:     core.PodStatus{Phase: phase},
+		}
+	}
+	isPod := func(name string) func(obj runtime.Object) bool {
+		return func(obj runtime.Object) bool {
+			p, ok := obj.(*core.Pod)
+			return ok && p.Name == name
+		}
+	}
+
+	tests := []struct {
+		name           string
+		exclude        bool
+		expectedPods   []string
+		expectedEvents []string
+	}{
+		{
+			name:           "pending pods included by default",
+			expectedPods:   []string{"pending", "running"},
+			expectedEvents: []string{eventReasonPendingPodIncluded},
+		},
+		{
+			name:           "pending pods excluded",
+			exclude:        true,
+			expectedPods:   []string{"running"},
+			expectedEvents: []string{eventReasonPendingPodSkipped},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &capturingRecorder{}
+			d := NewAPIDrainer(fake.NewSimpleClientset(pod("pending", core.PodPending), pod("running", core.PodRunning)), NewEventRecorder(recorder),
+				WithExcludePendingPods(tt.exclude),
+			)
+			pods, err := d.GetPodsToDrain(context.Background(), nodeName, nil)
+			assert.NoError(t, err)
+
+			var names []string
+			for _, p := range pods {
+				names = append(names, p.Name)
+			}
+			assert.ElementsMatch(t, tt.expectedPods, names)
+			assert.Equal(t, tt.expectedEvents, recorder.reasonsFor(isPod("pending")))
+			assert.Empty(t, recorder.reasonsFor(isPod("running")))
+		})
+	}
+}
+
+func TestAPIDrainer_GetPodsToDrain_PodsSkippedMetric(t *testing.T) {
+	skippedView := &view.View{
+		Name:        "test_skipped_pods_total",
+		Measure:     MeasurePodsSkipped,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{TagReason},
+	}
+	assert.NoError(t, view.Register(skippedView))
+	defer view.Unregister(skippedView)
+
+	pod := func(name, namespace string, annotations map[string]string, volumes ...core.Volume) *core.Pod {
+		return &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: namespace, Annotations: annotations},
+			Spec:       core.PodSpec{NodeName: nodeName, Volumes: volumes},
+		}
+	}
+	mirror := map[string]string{core.MirrorPodAnnotationKey: "true"}
+	emptyDir := core.Volume{Name: "scratch", VolumeSource: core.VolumeSource{EmptyDir: &core.EmptyDirVolumeSource{}}}
+	c := fake.NewSimpleClientset(
+		pod("mirror-1", "ns", mirror),
+		pod("mirror-2", "ns", mirror),
+		pod("local-storage", "ns", nil, emptyDir),
+		pod("evictable", "ns", nil),
+		pod("other-namespace", "other", nil),
+	)
+	d := NewAPIDrainer(c, &NoopEventRecorder{},
+		WithPodFilter(NewPodFilters(MirrorPodFilter, LocalStoragePodFilter)),
+		WithNamespaceAllowList([]string{"ns"}),
+	)
+	pods, err := d.GetPodsToDrain(context.Background(), nodeName, nil)
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+
+	rows, err := view.RetrieveData(skippedView.Name)
+	assert.NoError(t, err)
+	counts := map[string]int64{}
+	for _, r := range rows {
+		counts[r.Tags[0].Value] = r.Data.(*view.CountData).Value
+	}
+	assert.Equal(t, map[string]int64{
+		"pod-mirror":                        2,
+		"pod-local-storage-emptydir":        1,
+		podSkippedReasonNamespaceNotAllowed: 1,
+	}, counts)
+}
+
+func TestAPIDrainer_EvictionRequestTransformer(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+
+	tests := []struct {
+		name                string
+		options             []APIDrainerOption
+		expectedContentType string
+		checkBody           func(t *testing.T, body []byte)
+	}{
+		{
+			name:                "default transformer",
+			expectedContentType: "application/json",
+			checkBody: func(t *testing.T, body []byte) {
+				var eviction policy.Eviction
+				assert.NoError(t, json.Unmarshal(body, &eviction))
+				assert.Equal(t, "ns/"+podName, eviction.Namespace+"/"+eviction.Name)
+			},
+		},
+		{
+			name:                "nil transformer",
+			options:             []APIDrainerOption{WithEvictionRequestTransformer(nil)},
+			expectedContentType: "application/json",
+			checkBody: func(t *testing.T, body []byte) {
+				var eviction policy.Eviction
+				assert.NoError(t, json.Unmarshal(body, &eviction))
+				assert.Equal(t, "ns/"+podName, eviction.Namespace+"/"+eviction.Name)
+			},
+		},
+		{
+			name: "custom transformer",
+			options: []APIDrainerOption{WithEvictionRequestTransformer(func(e *policy.Eviction) ([]byte, string, error) {
+				return []byte("evict " + e.Namespace + "/" + e.Name), "text/plain", nil
+			})},
+			expectedContentType: "text/plain",
+			checkBody: func(t *testing.T, body []byte) {
+				assert.Equal(t, "evict ns/"+podName, string(body))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody []byte
+			var gotContentType string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotContentType = r.Header.Get("Content-Type")
+				gotBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{
+				Name:        podName,
+				Namespace:   "ns",
+				Annotations: map[string]string{EvictionAPIURLAnnotationKey: server.URL},
+			}, Spec: core.PodSpec{TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+			options := append([]APIDrainerOption{WithContainerRuntimeClient(crfake.NewClientBuilder().Build())}, tt.options...)
+			d := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{}, options...)
+
+			assert.NoError(t, d.evict(context.Background(), node, pod, make(chan struct{}), &PodEvictionSummary{}))
+			assert.Equal(t, tt.expectedContentType, gotContentType)
+			tt.checkBody(t, gotBody)
+		})
+	}
+}
+
+func TestAPIDrainer_EvictionDeleteOptions(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	foreground := meta.DeletePropagationForeground
+	orphan := meta.DeletePropagationOrphan
+	gracePeriod := int64(10)
+	annotationGracePeriod := int64(42)
+	minGracePeriod := int64(91)
+
+	tests := []struct {
+		name        string
+		global      *meta.DeleteOptions
+		annotations map[string]string
+		expected    *meta.DeleteOptions
+	}{
+		{
+			name: "nothing configured",
+		},
+		{
+			name:     "global options",
+			global:   &meta.DeleteOptions{PropagationPolicy: &foreground, GracePeriodSeconds: &gracePeriod},
+			expected: &meta.DeleteOptions{PropagationPolicy: &foreground, GracePeriodSeconds: &gracePeriod},
+		},
+		{
+			name:        "annotations only",
+			annotations: map[string]string{EvictionPropagationPolicyAnnotationKey: "Foreground"},
+			expected:    &meta.DeleteOptions{PropagationPolicy: &foreground},
+		},
+		{
+			name:   "annotations override global options",
+			global: &meta.DeleteOptions{PropagationPolicy: &foreground, GracePeriodSeconds: &gracePeriod},
+			annotations: map[string]string{
+				EvictionPropagationPolicyAnnotationKey: "Orphan",
+				EvictionGracePeriodAnnotationKey:       "42",
+			},
+			expected: &meta.DeleteOptions{PropagationPolicy: &orphan, GracePeriodSeconds: &annotationGracePeriod},
+		},
+		{
+			name:   "invalid annotations are ignored",
+			global: &meta.DeleteOptions{PropagationPolicy: &foreground, GracePeriodSeconds: &gracePeriod},
+			annotations: map[string]string{
+				EvictionPropagationPolicyAnnotationKey: "Sideways",
+				EvictionGracePeriodAnnotationKey:       "-5",
+			},
+			expected: &meta.DeleteOptions{PropagationPolicy: &foreground, GracePeriodSeconds: &gracePeriod},
+		},
+		{
+			name:        "min grace period raises the grace period of the pod",
+			annotations: map[string]string{MinGracePeriodAnnotationKey: "90.5s"},
+			expected:    &meta.DeleteOptions{GracePeriodSeconds: &minGracePeriod},
+		},
+		{
+			name:   "min grace period raises the configured grace period",
+			global: &meta.DeleteOptions{PropagationPolicy: &foreground, GracePeriodSeconds: &gracePeriod},
+			annotations: map[string]string{
+				EvictionGracePeriodAnnotationKey: "42",
+				MinGracePeriodAnnotationKey:      "91s",
+			},
+			expected: &meta.DeleteOptions{PropagationPolicy: &foreground, GracePeriodSeconds: &minGracePeriod},
+		},
+		{
+			name:        "min grace period below the grace period of the pod",
+			annotations: map[string]string{MinGracePeriodAnnotationKey: "5s"},
+		},
+		{
+			name:        "invalid min grace period is ignored",
+			annotations: map[string]string{MinGracePeriodAnnotationKey: "-1m"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", Annotations: tt.annotations},
+				Spec: core.PodSpec{TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+			var sent *meta.DeleteOptions
+			c := fake.NewSimpleClientset()
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				sent = action.(clienttesting.CreateAction).GetObject().(*policy.Eviction).DeleteOptions
+				return true, nil, nil
+			})
+			d := NewAPIDrainer(c, &NoopEventRecorder{},
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()),
+				WithEvictionDeleteOptions(tt.global),
+			)
+
+			assert.NoError(t, d.evict(context.Background(), node, pod, make(chan struct{}), &PodEvictionSummary{}))
+			assert.Equal(t, tt.expected, sent)
+		})
+	}
+
+	t.Run("invalid min grace period reported once", func(t *testing.T) {
+		pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", Annotations: map[string]string{MinGracePeriodAnnotationKey: "-1m"}},
+			Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+		c := fake.NewSimpleClientset(pod)
+		recorder := &capturingRecorder{}
+		d := NewAPIDrainer(c, NewEventRecorder(recorder), WithContainerRuntimeClient(crfake.NewClientBuilder().Build()))
+
+		_, err := d.EstimateDrainDuration(context.Background(), node)
+		assert.NoError(t, err)
+		assert.NoError(t, d.evict(context.Background(), node, pod, make(chan struct{}), &PodEvictionSummary{}))
+		reported := 0
+		for _, reason := range recorder.reasonsFor(func(obj runtime.Object) bool { _, ok := obj.(*core.Pod); return ok }) {
+			if reason == eventReasonBadValueForAnnotation {
+				reported++
+			}
+		}
+		assert.Equal(t, 1, reported)
+	})
+
+	t.Run("custom eviction endpoint", func(t *testing.T) {
+		var eviction policy.Eviction
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			assert.NoError(t, json.Unmarshal(body, &eviction))
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", Annotations: map[string]string{
+			EvictionAPIURLAnnotationKey:            server.URL,
+			EvictionPropagationPolicyAnnotationKey: "Foreground",
+		}}, Spec: core.PodSpec{TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+		d := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{}, WithContainerRuntimeClient(crfake.NewClientBuilder().Build()))
+
+		assert.NoError(t, d.evict(context.Background(), node, pod, make(chan struct{}), &PodEvictionSummary{}))
+		assert.Equal(t, &meta.DeleteOptions{PropagationPolicy: &foreground}, eviction.DeleteOptions)
+	})
+}
+
+type fakePDBIndexer struct {
+	pdbs map[string][]*policy.PodDisruptionBudget
+}
+
+var _ index.PDBIndexer = &fakePDBIndexer{}
+
+func (f *fakePDBIndexer) GetPDBsBlockedByPod(ctx context.Context, podName, ns string) ([]*policy.PodDisruptionBudget, error) {
+	return nil, nil
+}
+
+func (f *fakePDBIndexer) GetPDBsForPods(ctx context.Context, pods []*core.Pod) (map[string][]*policy.PodDisruptionBudget, error) {
+	return f.pdbs, nil
+}
+
+func TestDrain_RequirePDB(t *testing.T) {
+	taintDraining := []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: taintDraining}}
+	podA := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: "pod-a", Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+	podB := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: "pod-b", Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+	pdb := &policy.PodDisruptionBudget{ObjectMeta: meta.ObjectMeta{Name: "pdb", Namespace: "ns"}}
+
+	tests := []struct {
+		name        string
+		pdbs        map[string][]*policy.PodDisruptionBudget
+		expectedErr error
+	}{
+		{
+			name: "all pods protected",
+			pdbs: map[string][]*policy.PodDisruptionBudget{
+				index.GeneratePodIndexKey("pod-a", "ns"): {pdb},
+				index.GeneratePodIndexKey("pod-b", "ns"): {pdb},
+			},
+		},
+		{
+			name: "some pods unprotected",
+			pdbs: map[string][]*policy.PodDisruptionBudget{
+				index.GeneratePodIndexKey("pod-a", "ns"): {pdb},
+			},
+			expectedErr: PodsWithoutPDBError{NodeName: nodeName, Pods: []string{"ns/pod-b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(node, podA, podB)
+			d := NewAPIDrainer(c, &NoopEventRecorder{},
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()),
+				WithRequirePDB(true),
+				WithPDBIndexer(&fakePDBIndexer{pdbs: tt.pdbs}),
+			)
+			err := d.Drain(context.Background(), node)
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, PodsWithoutPDB, GetFailureCause(err))
+
+			// no eviction was attempted
+			for _, a := range c.Actions() {
+				assert.NotEqual(t, "eviction", a.GetSubresource())
+			}
+		})
+	}
+}
+
+func TestDrain_FailFastOnBlockedPDB(t *testing.T) {
+	taintDraining := []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: taintDraining}}
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+
+	tests := []struct {
+		name          string
+		status        policy.PodDisruptionBudgetStatus
+		expectedCalls int
+		expectedErr   error
+	}{
+		{
+			name:          "permanently blocked pdb",
+			status:        policy.PodDisruptionBudgetStatus{DisruptionsAllowed: 0, CurrentHealthy: 2, DesiredHealthy: 2, ExpectedPods: 2},
+			expectedCalls: 1,
+			expectedErr:   PodDisruptionBudgetBlockedError{PodName: "ns/" + podName, PDBs: []string{"pdb"}},
+		},
+		{
+			name:          "temporarily blocked pdb",
+			status:        policy.PodDisruptionBudgetStatus{DisruptionsAllowed: 0, CurrentHealthy: 1, DesiredHealthy: 1, ExpectedPods: 2},
+			expectedCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdb := &policy.PodDisruptionBudget{ObjectMeta: meta.ObjectMeta{Name: "pdb", Namespace: "ns"}, Status: tt.status}
+			c := fake.NewSimpleClientset(node, pod)
+			calls := 0
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				calls++
+				if calls == 1 {
+					return true, nil, apierrors.NewTooManyRequests("pdb does not allow any disruption", 1)
+				}
+				eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+				return true, nil, c.Tracker().Delete(core.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+			})
+			d := NewAPIDrainer(c, &NoopEventRecorder{},
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()),
+				WithFailFastOnBlockedPDB(true),
+				WithPDBIndexer(&fakePDBIndexer{pdbs: map[string][]*policy.PodDisruptionBudget{index.GeneratePodIndexKey(podName, "ns"): {pdb}}}))
+
+			err := d.Drain(context.Background(), node)
+			if tt.expectedErr != nil {
+				var blockedErr PodDisruptionBudgetBlockedError
+				if assert.True(t, errors.As(err, &blockedErr), "unexpected error %v", err) {
+					assert.Equal(t, tt.expectedErr, blockedErr)
+				}
+				assert.Equal(t, PodDisruptionBudgetBlocked, GetFailureCause(err))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedCalls, calls)
+		})
+	}
+
+	t.Run("custom eviction endpoint not available", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		endpointPod := pod.DeepCopy()
+		endpointPod.Annotations = map[string]string{EvictionAPIURLAnnotationKey: server.URL}
+		blockedPDB := &policy.PodDisruptionBudget{ObjectMeta: meta.ObjectMeta{Name: "pdb", Namespace: "ns"}, Status: tests[0].status}
+		d := NewAPIDrainer(fake.NewSimpleClientset(node, endpointPod), &NoopEventRecorder{},
+			WithContainerRuntimeClient(crfake.NewClientBuilder().Build()),
+			WithFailFastOnBlockedPDB(true),
+			WithPDBIndexer(&fakePDBIndexer{pdbs: map[string][]*policy.PodDisruptionBudget{index.GeneratePodIndexKey(podName, "ns"): {blockedPDB}}}))
+
+		// the 503 of the endpoint is retried until the eviction times out, it is not reported as a blocked PDB
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		err := d.evict(ctx, node, endpointPod, make(chan struct{}), &PodEvictionSummary{})
+		assert.Error(t, err)
+		assert.False(t, errors.As(err, &PodDisruptionBudgetBlockedError{}), "unexpected error %v", err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestDrain_AlternativePlacementCheck(t *testing.T) {
+	taintDraining := []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}
+	hardwareLabels := map[string]string{"hardware": "unique"}
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName, Labels: hardwareLabels}, Spec: core.NodeSpec{Taints: taintDraining}}
+	otherNode := &core.Node{ObjectMeta: meta.ObjectMeta{Name: "other-node"}}
+	newPod := func(nodeSelector, annotations map[string]string) *core.Pod {
+		return &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", Annotations: annotations},
+			Spec:       core.PodSpec{NodeName: nodeName, NodeSelector: nodeSelector, TerminationGracePeriodSeconds: &podGracePeriodSeconds},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		pod         *core.Pod
+		expectedErr error
+	}{
+		{
+			name: "pod with alternative placement",
+			pod:  newPod(nil, nil),
+		},
+		{
+			name:        "pod without alternative placement",
+			pod:         newPod(hardwareLabels, nil),
+			expectedErr: PodsWithoutAlternativePlacementError{NodeName: nodeName, Pods: []string{"ns/" + podName}},
+		},
+		{
+			name: "pod without alternative placement, eviction acknowledged",
+			pod:  newPod(hardwareLabels, map[string]string{EvictWithoutAlternativePlacementAnnotationKey: "true"}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(node, otherNode, tt.pod)
+			store, closeFunc := RunStoreForTest(context.Background(), c)
+			defer closeFunc()
+			d := NewAPIDrainer(c, &NoopEventRecorder{},
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()),
+				WithRuntimeObjectStore(store),
+				WithAlternativePlacementCheck(true),
+			)
+			err := d.Drain(context.Background(), node)
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, PodsWithoutAlternativePlacement, GetFailureCause(err))
+			for _, a := range c.Actions() {
+				assert.NotEqual(t, "eviction", a.GetSubresource())
+			}
+		})
+	}
+}
+
+func TestMarkDrain_DrainDuration(t *testing.T) {
+	durationView := &view.View{
+		Name:        "test_drain_duration",
+		Measure:     MeasureDrainDuration,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{TagConfigName, TagResult},
+	}
+	assert.NoError(t, view.Register(durationView))
+	defer view.Unregister(durationView)
+
+	finish := time.Now()
+	taintAdded := meta.NewTime(finish.Add(-45 * time.Minute))
+	node := &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: nodeName, Annotations: map[string]string{}},
+		Spec: core.NodeSpec{Taints: []core.Taint{{
+			Key:       k8sclient.DrainoTaintKey,
+			Value:     k8sclient.TaintDraining,
+			Effect:    core.TaintEffectNoSchedule,
+			TimeAdded: &taintAdded,
+		}}},
+	}
+	d := NewAPIDrainer(fake.NewSimpleClientset(node), &NoopEventRecorder{}, WithGlobalConfig(GlobalConfig{ConfigName: "test-config"}))
+	assert.NoError(t, d.MarkDrain(context.Background(), node, finish.Add(-time.Hour), finish, true, 1))
+
+	rows, err := view.RetrieveData(durationView.Name)
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 1) {
+		assert.ElementsMatch(t, []tag.Tag{{Key: TagConfigName, Value: "test-config"}, {Key: TagResult, Value: "failed"}}, rows[0].Tags)
+		assert.Equal(t, float64((45 * time.Minute).Milliseconds()), rows[0].Data.(*view.LastValueData).Value)
+	}
+}
+
+func TestAPIDrainer_MarkDrainWithReason(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName, Annotations: map[string]string{}}}
+	c := fake.NewSimpleClientset(node)
+	d := NewAPIDrainer(c, &NoopEventRecorder{})
+
+	finish := time.Now()
+	drainErr := VolumeCleanupError{Err: errors.New("pvc deletion timeout")}
+	assert.NoError(t, d.MarkDrainWithReason(context.Background(), node, finish.Add(-time.Hour), finish, true, 1, string(GetFailureCause(drainErr))))
+
+	n, err := c.Tracker().Get(core.SchemeGroupVersion.WithResource("nodes"), "", nodeName)
+	assert.NoError(t, err)
+	status, err := GetDrainConditionStatus(n.(*core.Node))
+	assert.NoError(t, err)
+	assert.True(t, status.Failed)
+	assert.Equal(t, int32(1), status.FailedCount)
+	assert.Equal(t, string(VolumeCleanup), status.FailureReason)
+
+	// the next successful drain clears the reason
+	assert.NoError(t, d.MarkDrain(context.Background(), n.(*core.Node), finish, finish.Add(time.Minute), false, 1))
+	n, err = c.Tracker().Get(core.SchemeGroupVersion.WithResource("nodes"), "", nodeName)
+	assert.NoError(t, err)
+	status, err = GetDrainConditionStatus(n.(*core.Node))
+	assert.NoError(t, err)
+	assert.True(t, status.Completed)
+	assert.Empty(t, status.FailureReason)
+}
+
+func TestAPIDrainer_MarkDrain_FinalFailedEvent(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName, Annotations: map[string]string{}}}
+	c := fake.NewSimpleClientset(node)
+	recorder := &capturingRecorder{}
+	d := NewAPIDrainer(c, NewEventRecorder(recorder), WithMaxDrainAttemptsBeforeFail(3))
+
+	finalFailedEvents := func() []recordedEvent {
+		recorder.Lock()
+		defer recorder.Unlock()
+		var events []recordedEvent
+		for _, e := range recorder.events {
+			if e.reason == eventReasonDrainFinalFailed {
+				events = append(events, e)
+			}
+		}
+		return events
+	}
+	finish := time.Now()
+	for failCount := int32(1); failCount <= 4; failCount++ {
+		assert.NoError(t, d.MarkDrainWithReason(context.Background(), node, finish.Add(-time.Hour), finish, true, failCount, string(PodEvictionTimeout)))
+		expected := 0
+		if failCount >= 3 {
+			expected = 1
+		}
+		assert.Len(t, finalFailedEvents(), expected, "fail count %d", failCount)
+	}
+
+	events := finalFailedEvents()
+	assert.Equal(t, core.EventTypeWarning, events[0].eventType)
+	assert.Equal(t, "Drain failed 3 time(s), the maximum number of attempts is reached and the drain is not retried: "+string(PodEvictionTimeout), events[0].message)
+	assert.Equal(t, "Node", events[0].object.(*core.ObjectReference).Kind)
+
+	// the free-text reason is not used as a format string
+	recorder.Lock()
+	recorder.events = nil
+	recorder.Unlock()
+	d = NewAPIDrainer(fake.NewSimpleClientset(node), NewEventRecorder(recorder), WithMaxDrainAttemptsBeforeFail(1))
+	assert.NoError(t, d.MarkDrainWithReason(context.Background(), node, finish.Add(-time.Hour), finish, true, 1, "disk 100% full"))
+	if events = finalFailedEvents(); assert.Len(t, events, 1) {
+		assert.Equal(t, "Drain failed 1 time(s), the maximum number of attempts is reached and the drain is not retried: disk 100% full", events[0].message)
+	}
+}
+
+func TestDrain_NodeStabilityGate(t *testing.T) {
+	tests := []struct {
+		name        string
+		flapping    bool
+		ready       core.ConditionStatus
+		expectedErr error
+	}{
+		{
+			name:  "stable node",
+			ready: core.ConditionTrue,
+		},
+		{
+			name:        "flapping node",
+			ready:       core.ConditionTrue,
+			flapping:    true,
+			expectedErr: NodeNotStableError{NodeName: nodeName, Timeout: 500 * time.Millisecond},
+		},
+		{
+			name:        "node not ready",
+			ready:       core.ConditionFalse,
+			expectedErr: NodeNotStableError{NodeName: nodeName, Timeout: 500 * time.Millisecond},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &core.Node{
+				ObjectMeta: meta.ObjectMeta{Name: nodeName, Annotations: map[string]string{}},
+				Status:     core.NodeStatus{Conditions: []core.NodeCondition{{Type: core.NodeReady, Status: tt.ready}}},
+			}
+			c := fake.NewSimpleClientset(node)
+			gets := 0
+			if tt.flapping {
+				c.PrependReactor("get", "nodes", func(action clienttesting.Action) (bool, runtime.Object, error) {
+					obj, err := c.Tracker().Get(core.SchemeGroupVersion.WithResource("nodes"), "", nodeName)
+					if err != nil {
+						return true, nil, err
+					}
+					// the node becomes not ready every other observation
+					if gets++; gets%2 == 0 {
+						n := obj.(*core.Node).DeepCopy()
+						for i := range n.Status.Conditions {
+							if n.Status.Conditions[i].Type == core.NodeReady {
+								n.Status.Conditions[i].Status = core.ConditionFalse
+							}
+						}
+						return true, n, nil
+					}
+					return true, obj, nil
+				})
+			}
+			d := NewAPIDrainer(c, &NoopEventRecorder{}, WithSkipTaintCheck(true), WithNodeStabilityGate(100*time.Millisecond, 500*time.Millisecond))
+
+			err := d.DrainPods(context.Background(), node, []*core.Pod{})
+			assert.Equal(t, tt.expectedErr, err)
+			if tt.expectedErr != nil {
+				assert.Equal(t, NodeNotStable, GetFailureCause(err))
+			}
+		})
+	}
+
+	notReadyNode := &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: nodeName, Annotations: map[string]string{}},
+		Status:     core.NodeStatus{Conditions: []core.NodeCondition{{Type: core.NodeReady, Status: core.ConditionFalse}}},
+	}
+	t.Run("context cancelled", func(t *testing.T) {
+		d := NewAPIDrainer(fake.NewSimpleClientset(notReadyNode), &NoopEventRecorder{}, WithSkipTaintCheck(true), WithNodeStabilityGate(100*time.Millisecond, time.Hour))
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		start := time.Now()
+		err := d.DrainPods(ctx, notReadyNode, []*core.Pod{})
+		assert.Less(t, time.Since(start), 5*time.Second, "the wait must stop with the context")
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotEqual(t, NodeNotStable, GetFailureCause(err))
+	})
+	t.Run("drain cancelled", func(t *testing.T) {
+		d := NewAPIDrainer(fake.NewSimpleClientset(notReadyNode), &NoopEventRecorder{}, WithSkipTaintCheck(true), WithNodeStabilityGate(100*time.Millisecond, time.Hour))
+		time.AfterFunc(100*time.Millisecond, func() { d.CancelDrain(notReadyNode) })
+		start := time.Now()
+		err := d.DrainPods(context.Background(), notReadyNode, []*core.Pod{})
+		assert.Less(t, time.Since(start), 5*time.Second, "the wait must stop with the drain")
+		assert.Equal(t, DrainCancelledError{NodeName: nodeName}, err)
+	})
+}
+
+func TestAPIDrainer_EvictionEndpointErrorBodyIsTruncated(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(strings.Repeat("x", 1024*1024)))
+	}))
+	defer server.Close()
+
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{
+		Name:        podName,
+		Namespace:   "ns",
+		Annotations: map[string]string{EvictionAPIURLAnnotationKey: server.URL},
+	}, Spec: core.PodSpec{TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+
+	tests := []struct {
+		name         string
+		options      []APIDrainerOption
+		expectedSize int
+	}{
+		{
+			name:         "default limit",
+			expectedSize: DefaultEvictionEndpointMaxErrorBody,
+		},
+		{
+			name:         "custom limit",
+			options:      []APIDrainerOption{WithEvictionEndpointMaxErrorBody(10)},
+			expectedSize: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observedCore, logs := observer.New(zap.InfoLevel)
+			options := append([]APIDrainerOption{WithAPIDrainerLogger(zap.New(observedCore))}, tt.options...)
+			d := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{}, options...)
+
+			err := d.evict(context.Background(), node, pod, make(chan struct{}), &PodEvictionSummary{})
+			assert.Equal(t, EvictionEndpointError{StatusCode: http.StatusBadRequest}, err)
+
+			entries := logs.FilterMessage("Unexpected response code from custom eviction endpoint.").All()
+			if assert.Len(t, entries, 1) {
+				assert.Len(t, entries[0].ContextMap()["body"], tt.expectedSize)
+			}
+		})
+	}
+}
+
+// recordingRoundTripper records the requests and answers them with a canned response, without any network call
+type recordingRoundTripper struct {
+	requests   []*http.Request
+	bodies     [][]byte
+	statusCode int
+}
+
+func (rt *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	rt.requests = append(rt.requests, req)
+	rt.bodies = append(rt.bodies, body)
+	return &http.Response{StatusCode: rt.statusCode, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+func TestAPIDrainer_EvictionEndpointStructuredConditionsData(t *testing.T) {
+	conditions, err := ParseConditions([]string{`KernelDeadlock={"conditionStatus":"True"}`})
+	assert.NoError(t, err)
+	node := &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: nodeName},
+		Status:     core.NodeStatus{Conditions: []core.NodeCondition{{Type: "KernelDeadlock", Status: core.ConditionTrue}}},
+	}
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{
+		Name:        podName,
+		Namespace:   "ns",
+		Annotations: map[string]string{EvictionAPIURLAnnotationKey: "https://eviction.example.invalid/evict"},
+	}, Spec: core.PodSpec{TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+
+	roundTripper := &recordingRoundTripper{statusCode: http.StatusOK}
+	d := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{},
+		WithGlobalConfig(GlobalConfig{SuppliedConditions: conditions}),
+		WithEvictionEndpointRoundTripper(roundTripper),
+		WithContainerRuntimeClient(crfake.NewClientBuilder().Build()),
+		WithStructuredConditionsData(true),
+	)
+	assert.NoError(t, d.evict(context.Background(), node, pod, make(chan struct{}), &PodEvictionSummary{}))
+
+	if assert.Len(t, roundTripper.bodies, 1) {
+		var eviction policy.Eviction
+		assert.NoError(t, json.Unmarshal(roundTripper.bodies[0], &eviction))
+		assert.Equal(t, map[string]string{
+			EvictionNodeConditionsAnnotationKey:     "KernelDeadlock",
+			EvictionNodeConditionsJSONAnnotationKey: `[{"type":"KernelDeadlock","status":"True"}]`,
+		}, eviction.Annotations)
+	}
+}
+
+func TestAPIDrainer_EvictionEndpointRoundTripper(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{
+		Name:        podName,
+		Namespace:   "ns",
+		Annotations: map[string]string{EvictionAPIURLAnnotationKey: "https://eviction.example.invalid/evict"},
+	}, Spec: core.PodSpec{TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+
+	tests := []struct {
+		name        string
+		statusCode  int
+		expectedErr error
+	}{
+		{
+			name:       "eviction accepted",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:        "unexpected response",
+			statusCode:  http.StatusBadRequest,
+			expectedErr: EvictionEndpointError{StatusCode: http.StatusBadRequest},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roundTripper := &recordingRoundTripper{statusCode: tt.statusCode}
+			d := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{},
+				WithEvictionEndpointRoundTripper(roundTripper),
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()),
+			)
+
+			err := d.evict(context.Background(), node, pod, make(chan struct{}), &PodEvictionSummary{})
+			assert.Equal(t, tt.expectedErr, err)
+
+			if assert.Len(t, roundTripper.requests, 1) {
+				req := roundTripper.requests[0]
+				assert.Equal(t, http.MethodPost, req.Method)
+				assert.Equal(t, "https://eviction.example.invalid/evict", req.URL.String())
+				assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+				var eviction policy.Eviction
+				assert.NoError(t, json.Unmarshal(roundTripper.bodies[0], &eviction))
+				assert.Equal(t, podName, eviction.Name)
+				assert.Equal(t, "ns", eviction.Namespace)
+			}
+		})
+	}
+}
+
+func TestAPIDrainer_EvictionEndpointTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	// all the httptest servers share the same certificate, the other CA is a new self-signed one
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotAfter: time.Now().Add(time.Hour), IsCA: true, BasicConstraintsValid: true}
+	otherCert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	otherCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: otherCert})
+
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{
+		Name:        podName,
+		Namespace:   "ns",
+		Annotations: map[string]string{EvictionAPIURLAnnotationKey: server.URL},
+	}, Spec: core.PodSpec{TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+
+	tests := []struct {
+		name        string
+		options     []APIDrainerOption
+		expectedErr error
+	}{
+		{
+			name: "not verified by default",
+		},
+		{
+			name:    "verification explicitly skipped",
+			options: []APIDrainerOption{WithEvictionEndpointTLS(nil, true)},
+		},
+		{
+			name:    "verified with the CA bundle",
+			options: []APIDrainerOption{WithEvictionEndpointTLS(serverCA, false)},
+		},
+		{
+			name:        "insecure ignored with a CA bundle",
+			options:     []APIDrainerOption{WithEvictionEndpointTLS(otherCA, true)},
+			expectedErr: EvictionEndpointError{},
+		},
+		{
+			name:        "unknown authority",
+			options:     []APIDrainerOption{WithEvictionEndpointTLS(nil, false)},
+			expectedErr: EvictionEndpointError{},
+		},
+		{
+			name:        "invalid CA bundle",
+			options:     []APIDrainerOption{WithEvictionEndpointTLS([]byte("not a certificate"), false)},
+			expectedErr: EvictionEndpointError{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]APIDrainerOption{WithContainerRuntimeClient(crfake.NewClientBuilder().Build())}, tt.options...)
+			d := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{}, options...)
+
+			err := d.evict(context.Background(), node, pod, make(chan struct{}), &PodEvictionSummary{})
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
+
+func TestAPIDrainer_EvictionEndpointTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+
+	isController := true
+	tests := []struct {
+		name              string
+		options           []APIDrainerOption
+		timeout           string
+		deploymentTimeout string
+		expectedErr       error
+		expectedEvents    []string
+	}{
+		{
+			name: "default timeout",
+		},
+		{
+			name:        "configured timeout",
+			options:     []APIDrainerOption{WithEvictionEndpointTimeout(50 * time.Millisecond)},
+			expectedErr: EvictionEndpointError{IsRequestTimeout: true},
+		},
+		{
+			name:    "annotation overrides the configured timeout",
+			options: []APIDrainerOption{WithEvictionEndpointTimeout(50 * time.Millisecond)},
+			timeout: "5s",
+		},
+		{
+			name:        "annotation shortens the default timeout",
+			timeout:     "50ms",
+			expectedErr: EvictionEndpointError{IsRequestTimeout: true},
+		},
+		{
+			name:              "controller annotation",
+			options:           []APIDrainerOption{WithEvictionEndpointTimeout(50 * time.Millisecond)},
+			deploymentTimeout: "5s",
+		},
+		{
+			name:              "pod annotation takes precedence over the controller",
+			timeout:           "50ms",
+			deploymentTimeout: "5s",
+			expectedErr:       EvictionEndpointError{IsRequestTimeout: true},
+		},
+		{
+			name:           "invalid annotation ignored",
+			options:        []APIDrainerOption{WithEvictionEndpointTimeout(50 * time.Millisecond)},
+			timeout:        "-5s",
+			expectedErr:    EvictionEndpointError{IsRequestTimeout: true},
+			expectedEvents: []string{eventReasonBadValueForAnnotation},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{
+				Name:        podName,
+				Namespace:   "ns",
+				Annotations: map[string]string{EvictionAPIURLAnnotationKey: server.URL},
+			}, Spec: core.PodSpec{TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+			if tt.timeout != "" {
+				pod.Annotations[EvictionAPITimeoutAnnotationKey] = tt.timeout
+			}
+			deployment := &appsv1.Deployment{ObjectMeta: meta.ObjectMeta{Name: deploymentName, Namespace: "ns"}}
+			if tt.deploymentTimeout != "" {
+				deployment.Annotations = map[string]string{EvictionAPITimeoutAnnotationKey: tt.deploymentTimeout}
+				pod.OwnerReferences = []meta.OwnerReference{{Controller: &isController, Kind: kindReplicaSet, Name: deploymentName + "-5d8f7c"}}
+			}
+			store, closeFunc := RunStoreForTest(context.Background(), fake.NewSimpleClientset(deployment, pod))
+			defer closeFunc()
+			recorder := &capturingRecorder{}
+			options := append([]APIDrainerOption{WithContainerRuntimeClient(crfake.NewClientBuilder().Build()), WithRuntimeObjectStore(store)}, tt.options...)
+			d := NewAPIDrainer(fake.NewSimpleClientset(), NewEventRecorder(recorder), options...)
+
+			err := d.evict(context.Background(), node, pod, make(chan struct{}), &PodEvictionSummary{})
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedEvents, recorder.reasonsFor(func(obj runtime.Object) bool { _, ok := obj.(*core.Pod); return ok }))
+		})
+	}
+}
+
+func TestAPIDrainer_EvictionEndpointDryRun(t *testing.T) {
+	var dryRuns, evictions int32
+	var rejectDryRun atomic.Value
+	rejectDryRun.Store(false)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var eviction policy.Eviction
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&eviction))
+		if r.URL.Query().Get(EvictionEndpointDryRunQueryParam) != meta.DryRunAll {
+			atomic.AddInt32(&evictions, 1)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		atomic.AddInt32(&dryRuns, 1)
+		if assert.NotNil(t, eviction.DeleteOptions) {
+			assert.Equal(t, []string{meta.DryRunAll}, eviction.DeleteOptions.DryRun)
+		}
+		if rejectDryRun.Load().(bool) {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte("blocked by budget"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+
+	tests := []struct {
+		name              string
+		options           []APIDrainerOption
+		annotation        string
+		reject            bool
+		expectedErr       error
+		expectedDryRuns   int32
+		expectedEvictions int32
+		expectedEvents    []string
+	}{
+		{
+			name:              "disabled",
+			expectedEvictions: 1,
+		},
+		{
+			name:            "dry run accepted",
+			options:         []APIDrainerOption{WithEvictionEndpointDryRun(true)},
+			expectedDryRuns: 1,
+			expectedEvents:  []string{eventReasonEvictionDryRun},
+		},
+		{
+			name:            "dry run rejected",
+			options:         []APIDrainerOption{WithEvictionEndpointDryRun(true)},
+			reject:          true,
+			expectedErr:     EvictionEndpointDryRunRejectedError{StatusCode: http.StatusTooManyRequests},
+			expectedDryRuns: 1,
+			expectedEvents:  []string{eventReasonEvictionDryRun},
+		},
+		{
+			name:            "annotation enables the dry run",
+			annotation:      "true",
+			expectedDryRuns: 1,
+			expectedEvents:  []string{eventReasonEvictionDryRun},
+		},
+		{
+			name:              "annotation disables the dry run",
+			options:           []APIDrainerOption{WithEvictionEndpointDryRun(true)},
+			annotation:        "false",
+			expectedEvictions: 1,
+		},
+		{
+			name:            "invalid annotation ignored",
+			options:         []APIDrainerOption{WithEvictionEndpointDryRun(true)},
+			annotation:      "maybe",
+			expectedDryRuns: 1,
+			expectedEvents:  []string{eventReasonBadValueForAnnotation, eventReasonEvictionDryRun},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&dryRuns, 0)
+			atomic.StoreInt32(&evictions, 0)
+			rejectDryRun.Store(tt.reject)
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{
+				Name:        podName,
+				Namespace:   "ns",
+				Annotations: map[string]string{EvictionAPIURLAnnotationKey: server.URL},
+			}, Spec: core.PodSpec{TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+			if tt.annotation != "" {
+				pod.Annotations[EvictionAPIDryRunAnnotationKey] = tt.annotation
+			}
+			recorder := &capturingRecorder{}
+			options := append([]APIDrainerOption{WithContainerRuntimeClient(crfake.NewClientBuilder().Build())}, tt.options...)
+			d := NewAPIDrainer(fake.NewSimpleClientset(), NewEventRecorder(recorder), options...)
+
+			err := d.evict(context.Background(), node, pod, make(chan struct{}), &PodEvictionSummary{})
+			assert.Equal(t, tt.expectedErr, err)
+			if tt.expectedErr != nil {
+				assert.Equal(t, EvictionEndpointDryRunRejected, GetFailureCause(err))
+			}
+			assert.Equal(t, tt.expectedDryRuns, atomic.LoadInt32(&dryRuns))
+			assert.Equal(t, tt.expectedEvictions, atomic.LoadInt32(&evictions), "the dry runs never evict the pod")
+			assert.Equal(t, tt.expectedEvents, recorder.reasonsFor(func(obj runtime.Object) bool { _, ok := obj.(*core.Pod); return ok }))
+		})
+	}
+}
+
+func TestAPIDrainer_EvictionEndpoint500Retries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+
+	tests := []struct {
+		name            string
+		options         []APIDrainerOption
+		retries         string
+		expectedRetries int
+		expectedEvents  []string
+	}{
+		{
+			name:            "default retries",
+			expectedRetries: DefaultEvictionEndpoint500Retries,
+		},
+		{
+			name:            "configured retries",
+			options:         []APIDrainerOption{WithEvictionEndpoint500Retries(0)},
+			expectedRetries: 0,
+		},
+		{
+			name:            "annotation overrides the configured retries",
+			options:         []APIDrainerOption{WithEvictionEndpoint500Retries(0)},
+			retries:         "10",
+			expectedRetries: 10,
+		},
+		{
+			name:            "annotation disables the retries",
+			retries:         "0",
+			expectedRetries: 0,
+		},
+		{
+			name:            "invalid annotation ignored",
+			options:         []APIDrainerOption{WithEvictionEndpoint500Retries(0)},
+			retries:         "-1",
+			expectedRetries: 0,
+			expectedEvents:  []string{eventReasonBadValueForAnnotation},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{
+				Name:        podName,
+				Namespace:   "ns",
+				Annotations: map[string]string{EvictionAPIURLAnnotationKey: server.URL},
+			}, Spec: core.PodSpec{TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+			if tt.retries != "" {
+				pod.Annotations[EvictionAPI500RetriesAnnotationKey] = tt.retries
+			}
+			recorder := &capturingRecorder{}
+			options := append([]APIDrainerOption{WithContainerRuntimeClient(crfake.NewClientBuilder().Build())}, tt.options...)
+			d := NewAPIDrainer(fake.NewSimpleClientset(), NewEventRecorder(recorder), options...)
+
+			assert.Equal(t, tt.expectedRetries, d.getEvictionEndpoint500Retries(context.Background(), pod))
+			assert.Equal(t, tt.expectedEvents, recorder.reasonsFor(func(obj runtime.Object) bool { _, ok := obj.(*core.Pod); return ok }))
+			if tt.expectedRetries > 0 {
+				// each retry waits for the endpoint backoff, only the evictions failing at once are run
+				return
+			}
+			atomic.StoreInt32(&calls, 0)
+			err := d.evict(context.Background(), node, pod, make(chan struct{}), &PodEvictionSummary{})
+			assert.Equal(t, EvictionEndpointError{StatusCode: http.StatusInternalServerError, AfterSeveralRetries: true}, err)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestAPIDrainer_DeletionInsteadOfEviction(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	tests := []struct {
+		name           string
+		options        []APIDrainerOption
+		useDelete      string
+		expectedDelete bool
+		expectedEvents []string
+	}{
+		{
+			name: "evicted by default",
+		},
+		{
+			name:           "deleted",
+			options:        []APIDrainerOption{WithDeletionInsteadOfEviction(true)},
+			expectedDelete: true,
+		},
+		{
+			name:           "deleted with the annotation",
+			useDelete:      "true",
+			expectedDelete: true,
+		},
+		{
+			name:      "evicted with the annotation",
+			options:   []APIDrainerOption{WithDeletionInsteadOfEviction(true)},
+			useDelete: "false",
+		},
+		{
+			name:           "invalid annotation ignored",
+			options:        []APIDrainerOption{WithDeletionInsteadOfEviction(true)},
+			useDelete:      "maybe",
+			expectedDelete: true,
+			expectedEvents: []string{eventReasonBadValueForAnnotation},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{
+				Name:      podName,
+				Namespace: "ns",
+				UID:       "uid-1",
+			}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+			if tt.useDelete != "" {
+				pod.Annotations = map[string]string{UseDeleteAnnotationKey: tt.useDelete}
+			}
+			c := fake.NewSimpleClientset(pod)
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				return true, nil, c.Tracker().Delete(core.SchemeGroupVersion.WithResource("pods"), "ns", podName)
+			})
+			recorder := &capturingRecorder{}
+			options := append([]APIDrainerOption{WithContainerRuntimeClient(crfake.NewClientBuilder().Build())}, tt.options...)
+			d := NewAPIDrainer(c, NewEventRecorder(recorder), options...)
+
+			assert.NoError(t, d.evict(context.Background(), node, pod, make(chan struct{}), &PodEvictionSummary{}))
+			var deleted, evicted bool
+			for _, action := range c.Actions() {
+				if action.Matches("delete", "pods") {
+					deleted = true
+					preconditions := action.(clienttesting.DeleteAction).GetDeleteOptions().Preconditions
+					if assert.NotNil(t, preconditions) && assert.NotNil(t, preconditions.UID) {
+						assert.Equal(t, pod.UID, *preconditions.UID)
+					}
+				}
+				evicted = evicted || (action.Matches("create", "pods") && action.GetSubresource() == "eviction")
+			}
+			assert.Equal(t, tt.expectedDelete, deleted)
+			assert.Equal(t, !tt.expectedDelete, evicted)
+			assert.Equal(t, tt.expectedEvents, recorder.reasonsFor(func(obj runtime.Object) bool { _, ok := obj.(*core.Pod); return ok }))
+		})
+	}
+}
+
+func TestAPIDrainer_EvictionEndpointAcceptAsync(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	tests := []struct {
+		name               string
+		options            []APIDrainerOption
+		statusURL          string
+		statusCodes        []int
+		expectedErr        error
+		expectedStatusGets int
+		expectedEvents     []string
+	}{
+		{
+			name:        "202 not accepted",
+			expectedErr: EvictionEndpointError{StatusCode: http.StatusAccepted},
+		},
+		{
+			name:           "202 then deleted",
+			options:        []APIDrainerOption{WithEvictionEndpointAcceptAsync("X-Eviction-Status", 10*time.Millisecond)},
+			expectedEvents: []string{eventReasonEvictionAccepted},
+		},
+		{
+			name:               "202 then status polled till processed",
+			options:            []APIDrainerOption{WithEvictionEndpointAcceptAsync("X-Eviction-Status", 10*time.Millisecond)},
+			statusURL:          "/status/123",
+			statusCodes:        []int{http.StatusAccepted, http.StatusAccepted, http.StatusOK},
+			expectedStatusGets: 3,
+			expectedEvents:     []string{eventReasonEvictionAccepted},
+		},
+		{
+			name:               "status polling failure",
+			options:            []APIDrainerOption{WithEvictionEndpointAcceptAsync("X-Eviction-Status", 10*time.Millisecond)},
+			statusURL:          "/status/123",
+			statusCodes:        []int{http.StatusAccepted, http.StatusGone},
+			expectedErr:        EvictionEndpointError{StatusCode: http.StatusGone},
+			expectedStatusGets: 2,
+			expectedEvents:     []string{eventReasonEvictionAccepted},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{
+				Name:      podName,
+				Namespace: "ns",
+			}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+			c := fake.NewSimpleClientset(pod)
+			deletePod := func() {
+				assert.NoError(t, c.Tracker().Delete(core.SchemeGroupVersion.WithResource("pods"), "ns", podName))
+			}
+			statusGets := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet && r.URL.Path == tt.statusURL {
+					code := tt.statusCodes[statusGets]
+					statusGets++
+					if code == http.StatusOK {
+						deletePod()
+					}
+					w.WriteHeader(code)
+					return
+				}
+				if tt.statusURL != "" {
+					w.Header().Set("X-Eviction-Status", tt.statusURL)
+				} else {
+					// without status URL the pod is deleted asynchronously, before the drainer checks
+					deletePod()
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer server.Close()
+			pod.Annotations = map[string]string{EvictionAPIURLAnnotationKey: server.URL + "/evict"}
+
+			recorder := &capturingRecorder{}
+			options := append([]APIDrainerOption{WithContainerRuntimeClient(crfake.NewClientBuilder().Build())}, tt.options...)
+			d := NewAPIDrainer(c, NewEventRecorder(recorder), options...)
+
+			err := d.evict(context.Background(), node, pod, make(chan struct{}), &PodEvictionSummary{})
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedStatusGets, statusGets)
+			assert.Equal(t, tt.expectedEvents, recorder.reasonsFor(func(obj runtime.Object) bool { _, ok := obj.(*core.Pod); return ok }))
+		})
+	}
+}
+
+func TestAPIDrainer_EvictionEndpointLatency(t *testing.T) {
+	latencyView := &view.View{
+		Name:        "test_eviction_endpoint_latency",
+		Measure:     MeasureEvictionEndpointLatency,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{TagResult, TagDegraded},
+	}
+
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	tests := []struct {
+		name          string
+		serverLatency time.Duration
+		threshold     time.Duration
+		degraded      bool
+	}{
+		{
+			name: "no threshold",
+		},
+		{
+			name:      "below threshold",
+			threshold: time.Minute,
+		},
+		{
+			name:          "slow but successful",
+			serverLatency: 100 * time.Millisecond,
+			threshold:     10 * time.Millisecond,
+			degraded:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, view.Register(latencyView))
+			defer view.Unregister(latencyView)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(tt.serverLatency)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{
+				Name:        podName,
+				Namespace:   "ns",
+				Annotations: map[string]string{EvictionAPIURLAnnotationKey: server.URL},
+			}, Spec: core.PodSpec{TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+			recorder := &capturingRecorder{}
+			d := NewAPIDrainer(fake.NewSimpleClientset(), NewEventRecorder(recorder),
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()),
+				WithEvictionEndpointDegradedThreshold(tt.threshold),
+			)
+
+			assert.NoError(t, d.evict(context.Background(), node, pod, make(chan struct{}), &PodEvictionSummary{}))
+
+			rows, err := view.RetrieveData(latencyView.Name)
+			assert.NoError(t, err)
+			if assert.Len(t, rows, 1) {
+				assert.ElementsMatch(t, []tag.Tag{{Key: TagResult, Value: "200"}, {Key: TagDegraded, Value: strconv.FormatBool(tt.degraded)}}, rows[0].Tags)
+			}
+			reasons := recorder.reasonsFor(func(obj runtime.Object) bool { _, ok := obj.(*core.Pod); return ok })
+			if tt.degraded {
+				assert.Contains(t, reasons, eventReasonEvictionEndpointDegraded)
+			} else {
+				assert.NotContains(t, reasons, eventReasonEvictionEndpointDegraded)
+			}
+		})
+	}
+}
+
+// evictForSpanTags evicts a pod of a deployment, covered by a pod disruption budget, with the tracer currently configured
+func evictForSpanTags(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	deployment := &appsv1.Deployment{ObjectMeta: meta.ObjectMeta{Name: deploymentName, Namespace: "ns"}}
+	pod := &core.Pod{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      podName,
+			Namespace: "ns",
+			OwnerReferences: []meta.OwnerReference{{
+				Controller: &isController,
+				Kind:       kindReplicaSet,
+				Name:       deploymentName + "-5d8f7c",
+			}},
+		},
+		Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds},
+	}
+	pdbIndexer := &fakePDBIndexer{pdbs: map[string][]*policy.PodDisruptionBudget{
+		index.GeneratePodIndexKey(podName, "ns"): {{ObjectMeta: meta.ObjectMeta{Name: "app-pdb", Namespace: "ns"}}},
+	}}
+
+	c := fake.NewSimpleClientset(node, deployment, pod)
+	store, closeFunc := RunStoreForTest(context.Background(), c)
+	defer closeFunc()
+	d := NewAPIDrainer(c, &NoopEventRecorder{},
+		WithRuntimeObjectStore(store),
+		WithContainerRuntimeClient(crfake.NewClientBuilder().Build()),
+		WithPDBIndexer(pdbIndexer),
+	)
+	assert.NoError(t, d.evict(context.Background(), node, pod, make(chan struct{}), &PodEvictionSummary{}))
+}
+
+func TestAPIDrainer_EvictionSpanTags(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	evictForSpanTags(t)
+
+	var found bool
+	for _, span := range mt.FinishedSpans() {
+		if span.OperationName() != "evictionSequence" {
+			continue
+		}
+		found = true
+		assert.Equal(t, "Deployment", span.Tag("controller_kind"))
+		assert.Equal(t, deploymentName, span.Tag("controller_name"))
+		assert.Equal(t, "app-pdb", span.Tag("pdb"))
+	}
+	assert.True(t, found, "evictionSequence span not found")
+}
+
+func TestAPIDrainer_EvictionSpanTags_OpenTelemetry(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := tracing.SetTracer(tracing.NewOpenTelemetryTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))))
+	defer tracing.SetTracer(previous)
+
+	evictForSpanTags(t)
+
+	var found bool
+	for _, span := range recorder.Ended() {
+		if span.Name() != "evictionSequence" {
+			continue
+		}
+		found = true
+		assert.ElementsMatch(t, []attribute.KeyValue{
+			attribute.String("controller_kind", "Deployment"),
+			attribute.String("controller_name", deploymentName),
+			attribute.String("pdb", "app-pdb"),
+		}, span.Attributes())
+	}
+	assert.True(t, found, "evictionSequence span not found")
+}
+
+func TestDrain_VerifyDrainCompletion(t *testing.T) {
+	taintDraining := []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: taintDraining}}
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+	lateArrival := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: "late-pod", Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName}}
+
+	tests := []struct {
+		name        string
+		reappear    bool
+		expectedErr error
+	}{
+		{
+			name: "clean node",
+		},
+		{
+			name:        "pod scheduled during the drain",
+			reappear:    true,
+			expectedErr: PodsRemainingAfterDrainError{NodeName: nodeName, Pods: []string{"ns/late-pod"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(node, pod)
+			podsGVR := core.SchemeGroupVersion.WithResource("pods")
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+				if err := c.Tracker().Delete(podsGVR, eviction.Namespace, eviction.Name); err != nil {
+					return true, nil, err
+				}
+				if tt.reappear {
+					return true, nil, c.Tracker().Add(lateArrival.DeepCopy())
+				}
+				return true, nil, nil
+			})
+			d := NewAPIDrainer(c, &NoopEventRecorder{},
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()),
+				WithDrainCompletionVerification(true),
+			)
+			err := d.Drain(context.Background(), node)
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, PodsRemainingAfterDrain, GetFailureCause(err))
+		})
+	}
+}
+
+func TestDrain_SkipTaintCheck(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+
+	tests := []struct {
+		name           string
+		options        []APIDrainerOption
+		expectedErr    error
+		expectEviction bool
+	}{
+		{
+			name:        "default checks the taint",
+			expectedErr: NodeHasNotDrainingTaintError{NodeName: nodeName},
+		},
+		{
+			name:           "skip taint check",
+			options:        []APIDrainerOption{WithSkipTaintCheck(true)},
+			expectEviction: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(node, pod)
+			evicted := false
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				evicted = true
+				eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+				return true, nil, c.Tracker().Delete(core.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+			})
+			options := append([]APIDrainerOption{WithContainerRuntimeClient(crfake.NewClientBuilder().Build())}, tt.options...)
+			d := NewAPIDrainer(c, &NoopEventRecorder{}, options...)
+
+			err := d.Drain(context.Background(), node)
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tt.expectedErr, err)
+			}
+			assert.Equal(t, tt.expectEviction, evicted)
+		})
+	}
+}
+
+func TestDrain_NonBlockingEviction(t *testing.T) {
+	taintDraining := []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: taintDraining}}
+	newPod := func(name string, annotations map[string]string) *core.Pod {
+		return &core.Pod{ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "ns", Annotations: annotations}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+	}
+	nonBlocking := map[string]string{EvictionNonBlockingAnnotationKey: "true"}
+
+	tests := []struct {
+		name          string
+		failingPod    *core.Pod
+		expectedError bool
+	}{
+		{
+			name:          "non-blocking pod failing",
+			failingPod:    newPod("cache", nonBlocking),
+			expectedError: false,
+		},
+		{
+			name:          "blocking pod failing",
+			failingPod:    newPod("cache", nil),
+			expectedError: true,
+		},
+		{
+			name:          "annotation not set to true",
+			failingPod:    newPod("cache", map[string]string{EvictionNonBlockingAnnotationKey: "false"}),
+			expectedError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(node, tt.failingPod, newPod("app", nil))
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+				if eviction.Name == tt.failingPod.Name {
+					return true, nil, apierrors.NewInternalError(errors.New("This pod has more than one PodDisruptionBudget, which the eviction subresource does not support."))
+				}
+				return true, nil, c.Tracker().Delete(core.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+			})
+			recorder := &capturingRecorder{}
+			// the pod left on the node must not fail the verification of the drain completion
+			d := NewAPIDrainer(c, NewEventRecorder(recorder), WithContainerRuntimeClient(crfake.NewClientBuilder().Build()), WithDrainCompletionVerification(true))
+
+			err := d.Drain(context.Background(), node)
+			reasons := recorder.reasonsFor(func(obj runtime.Object) bool { ref, ok := obj.(*core.ObjectReference); return ok && ref.Kind == "Node" })
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.NotContains(t, reasons, eventReasonNonBlockingEvictionFailed)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Contains(t, reasons, eventReasonNonBlockingEvictionFailed)
+			_, err = c.CoreV1().Pods("ns").Get(context.Background(), "app", meta.GetOptions{})
+			assert.True(t, apierrors.IsNotFound(err), "the blocking pod must be evicted")
+		})
+	}
+}
+
+func TestDrain_EvictionAPIRetriesOn500(t *testing.T) {
+	overlappingErr := apierrors.NewInternalError(errors.New("This pod has more than one PodDisruptionBudget, which the eviction subresource does not support."))
+	transientErr := apierrors.NewInternalError(errors.New("etcdserver: request timed out"))
+	tests := []struct {
+		name             string
+		errors           []error
+		expectErr        bool
+		expectedCause    FailureCause
+		expectedAttempts int
+	}{
+		{
+			name:             "transient 500 retried",
+			errors:           []error{transientErr, transientErr},
+			expectedAttempts: 3,
+		},
+		{
+			name:             "overlapping PDBs fail fast",
+			errors:           []error{overlappingErr},
+			expectErr:        true,
+			expectedCause:    OverlappingPodDisruptionBudgets,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "transient 500 failing after the retries",
+			errors:           []error{transientErr, transientErr, transientErr},
+			expectErr:        true,
+			expectedAttempts: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: []core.Taint{{
+				Key:    k8sclient.DrainoTaintKey,
+				Value:  k8sclient.TaintDraining,
+				Effect: core.TaintEffectNoSchedule,
+			}}}}
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+			c := fake.NewSimpleClientset(node, pod)
+			var attempts int32
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				if attempt := int(atomic.AddInt32(&attempts, 1)); attempt <= len(tt.errors) {
+					return true, nil, tt.errors[attempt-1]
+				}
+				return true, nil, c.Tracker().Delete(core.SchemeGroupVersion.WithResource("pods"), "ns", podName)
+			})
+			d := NewAPIDrainer(c, &NoopEventRecorder{}, WithContainerRuntimeClient(crfake.NewClientBuilder().Build()), WithEvictionAPIRetriesOn500(2, time.Millisecond))
+
+			err := d.Drain(context.Background(), node)
+			assert.Equal(t, tt.expectedAttempts, int(atomic.LoadInt32(&attempts)))
+			if !tt.expectErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, tt.expectedCause, GetFailureCause(err))
+		})
+	}
+}
+
+func TestDrain_FailuresMetric(t *testing.T) {
+	failuresView := &view.View{
+		Name:        "test_drain_failures",
+		Measure:     MeasureDrainFailures,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{TagFailureCause},
+	}
+	assert.NoError(t, view.Register(failuresView))
+	defer view.Unregister(failuresView)
+
+	taintDraining := []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	drain := func(node *core.Node, pod *core.Pod) {
+		c := fake.NewSimpleClientset(node, pod)
+		c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "eviction" {
+				return false, nil, nil
+			}
+			return true, nil, apierrors.NewInternalError(errors.New("This pod has more than one PodDisruptionBudget, which the eviction subresource does not support."))
+		})
+		d := NewAPIDrainer(c, &NoopEventRecorder{}, WithContainerRuntimeClient(crfake.NewClientBuilder().Build()))
+		assert.Error(t, d.Drain(context.Background(), node))
+	}
+	newPod := func(annotations map[string]string) *core.Pod {
+		return &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", Annotations: annotations}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+	}
+	taintedNode := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: taintDraining}}
+
+	drain(&core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}, newPod(nil))
+	drain(taintedNode, newPod(nil))
+	drain(taintedNode, newPod(nil))
+	drain(taintedNode, newPod(map[string]string{EvictionAPIURLAnnotationKey: server.URL}))
+
+	rows, err := view.RetrieveData(failuresView.Name)
+	assert.NoError(t, err)
+	counts := map[string]int64{}
+	for _, row := range rows {
+		counts[row.Tags[0].Value] = row.Data.(*view.CountData).Value
+	}
+	assert.Equal(t, map[string]int64{
+		"unknown":                               1,
+		string(OverlappingPodDisruptionBudgets): 2,
+		"eviction_endpoint_418":                 1,
+	}, counts)
+}
+
+func TestDrain_EvictionAttemptsMetric(t *testing.T) {
+	attemptsView := &view.View{
+		Name:        "test_eviction_attempts",
+		Measure:     MeasureEvictionAttempts,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{TagResult},
+	}
+	assert.NoError(t, view.Register(attemptsView))
+	defer view.Unregister(attemptsView)
+
+	taintDraining := []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: taintDraining}}
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+
+	c := fake.NewSimpleClientset(node, pod)
+	calls := 0
+	c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		calls++
+		if calls == 1 {
+			return true, nil, apierrors.NewTooManyRequests("pdb does not allow any disruption", 1)
+		}
+		eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+		return true, nil, c.Tracker().Delete(core.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+	})
+	d := NewAPIDrainer(c, &NoopEventRecorder{}, WithContainerRuntimeClient(crfake.NewClientBuilder().Build()))
+	assert.NoError(t, d.Drain(context.Background(), node))
+
+	rows, err := view.RetrieveData(attemptsView.Name)
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 1) {
+		assert.Equal(t, []tag.Tag{{Key: TagResult, Value: "succeeded"}}, rows[0].Tags)
+		assert.Equal(t, float64(2), rows[0].Data.(*view.LastValueData).Value)
+	}
+}
+
+func TestDrain_ConditionsRecheck(t *testing.T) {
+	taintDraining := []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}
+	suppliedConditions, err := ParseConditions([]string{"KernelDeadlock"})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name              string
+		conditionsClear   bool
+		expectedErr       error
+		expectedEvictions int
+	}{
+		{
+			name:            "conditions clear during the drain",
+			conditionsClear: true,
+			expectedErr:     ConditionsResolvedError{NodeName: nodeName},
+		},
+		{
+			name:              "conditions persist",
+			expectedEvictions: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &core.Node{
+				ObjectMeta: meta.ObjectMeta{Name: nodeName},
+				Spec:       core.NodeSpec{Taints: taintDraining},
+				Status:     core.NodeStatus{Conditions: []core.NodeCondition{{Type: "KernelDeadlock", Status: core.ConditionTrue}}},
+			}
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+
+			c := fake.NewSimpleClientset(node, pod)
+			var lock sync.Mutex
+			calls, evictions := 0, 0
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				lock.Lock()
+				defer lock.Unlock()
+				calls++
+				if calls == 1 {
+					if tt.conditionsClear {
+						cleared := node.DeepCopy()
+						cleared.Status.Conditions = nil
+						if err := c.Tracker().Update(core.SchemeGroupVersion.WithResource("nodes"), cleared, ""); err != nil {
+							return true, nil, err
+						}
+					}
+					return true, nil, apierrors.NewTooManyRequests("pdb does not allow any disruption", 1)
+				}
+				if tt.conditionsClear {
+					return true, nil, apierrors.NewTooManyRequests("pdb does not allow any disruption", 1)
+				}
+				evictions++
+				eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+				return true, nil, c.Tracker().Delete(core.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+			})
+			d := NewAPIDrainer(c, &NoopEventRecorder{},
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()),
+				WithGlobalConfig(GlobalConfig{SuppliedConditions: suppliedConditions}),
+				WithConditionsRecheckPeriod(100*time.Millisecond))
+
+			err := d.Drain(context.Background(), node)
+			if tt.expectedErr != nil {
+				assert.Equal(t, tt.expectedErr, err)
+				assert.Equal(t, ConditionsResolved, GetFailureCause(err))
+			} else {
+				assert.NoError(t, err)
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			assert.Equal(t, tt.expectedEvictions, evictions)
+		})
+	}
+}
+
+func TestDrain_SummaryCallback(t *testing.T) {
+	taintDraining := []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: taintDraining}}
+	podsGVR := core.SchemeGroupVersion.WithResource("pods")
+
+	var c *fake.Clientset
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := c.Tracker().Delete(podsGVR, "ns", "operator-pod"); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	apiPod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: "api-pod", Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+	operatorPod := &core.Pod{ObjectMeta: meta.ObjectMeta{
+		Name:        "operator-pod",
+		Namespace:   "ns",
+		Annotations: map[string]string{EvictionAPIURLAnnotationKey: server.URL},
+	}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+
+	c = fake.NewSimpleClientset(node, apiPod, operatorPod)
+	c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+		return true, nil, c.Tracker().Delete(podsGVR, eviction.Namespace, eviction.Name)
+	})
+
+	var summaries []DrainSummary
+	d := NewAPIDrainer(c, &NoopEventRecorder{},
+		WithContainerRuntimeClient(crfake.NewClientBuilder().Build()),
+		WithDrainSummaryCallback(func(s DrainSummary) { summaries = append(summaries, s) }),
+	)
+	assert.NoError(t, d.Drain(context.Background(), node))
+
+	assert.Len(t, summaries, 1)
+	summary := summaries[0]
+	assert.Equal(t, nodeName, summary.NodeName)
+	assert.NoError(t, summary.Err)
+	assert.Greater(t, summary.Duration, time.Duration(0))
+	assert.Equal(t, 0, summary.TotalRetries)
+	assert.Len(t, summary.Pods, 2)
+
+	endpoints := map[string]string{}
+	for _, p := range summary.Pods {
+		assert.Equal(t, "ns", p.Namespace)
+		assert.Greater(t, p.Duration, time.Duration(0))
+		assert.LessOrEqual(t, p.Duration, summary.Duration)
+		assert.Equal(t, 0, p.Retries)
+		assert.Empty(t, p.PVCsDeleted)
+		assert.NoError(t, p.Err)
+		endpoints[p.Name] = p.EvictionEndpoint
+	}
+	assert.Equal(t, map[string]string{"api-pod": "", "operator-pod": server.URL}, endpoints)
+}
+
+func TestDrain_SummaryCallback_AlreadyDrained(t *testing.T) {
+	taintDraining := []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}
+	podsGVR := core.SchemeGroupVersion.WithResource("pods")
+
+	tests := []struct {
+		name                   string
+		pods                   []runtime.Object
+		expectedAlreadyDrained bool
+	}{
+		{
+			name:                   "empty node",
+			expectedAlreadyDrained: true,
+		},
+		{
+			name:                   "node with a pod",
+			pods:                   []runtime.Object{&core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}},
+			expectedAlreadyDrained: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: taintDraining}}
+			c := fake.NewSimpleClientset(append([]runtime.Object{node}, tt.pods...)...)
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+				return true, nil, c.Tracker().Delete(podsGVR, eviction.Namespace, eviction.Name)
+			})
+
+			var summaries []DrainSummary
+			d := NewAPIDrainer(c, &NoopEventRecorder{},
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()),
+				WithDrainSummaryCallback(func(s DrainSummary) { summaries = append(summaries, s) }),
+			)
+			assert.NoError(t, d.Drain(context.Background(), node))
+
+			assert.Len(t, summaries, 1)
+			assert.Equal(t, tt.expectedAlreadyDrained, summaries[0].AlreadyDrained)
+			assert.Len(t, summaries[0].Pods, len(tt.pods))
+		})
+	}
+}
+
+func TestDrain_EvictionEndpointResolver(t *testing.T) {
+	taintDraining := []core.Taint{{
+		Key:    k8sclient.DrainoTaintKey,
+		Value:  k8sclient.TaintDraining,
+		Effect: core.TaintEffectNoSchedule,
+	}}
+	podsGVR := core.SchemeGroupVersion.WithResource("pods")
+
+	var c *fake.Clientset
+	evictingHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var eviction policy.Eviction
+		if err := json.NewDecoder(r.Body).Decode(&eviction); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := c.Tracker().Delete(podsGVR, eviction.Namespace, eviction.Name); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mappedServer := httptest.NewServer(evictingHandler)
+	defer mappedServer.Close()
+	annotationServer := httptest.NewServer(evictingHandler)
+	defer annotationServer.Close()
+
+	tests := []struct {
+		name             string
+		pod              *core.Pod
+		expectedEndpoint string
+	}{
+		{
+			name:             "mapping hit",
+			pod:              &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "mapped"}},
+			expectedEndpoint: mappedServer.URL,
+		},
+		{
+			name:             "annotation overrides the mapping",
+			pod:              &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "mapped", Annotations: map[string]string{EvictionAPIURLAnnotationKey: annotationServer.URL}}},
+			expectedEndpoint: annotationServer.URL,
+		},
+		{
+			name:             "no match uses the kubernetes API",
+			pod:              &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}},
+			expectedEndpoint: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}, Spec: core.NodeSpec{Taints: taintDraining}}
+			tt.pod.Spec = core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}
+			c = fake.NewSimpleClientset(node, tt.pod)
+			c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+				return true, nil, c.Tracker().Delete(podsGVR, eviction.Namespace, eviction.Name)
+			})
+
+			var summaries []DrainSummary
+			d := NewAPIDrainer(c, &NoopEventRecorder{},
+				WithContainerRuntimeClient(crfake.NewClientBuilder().Build()),
+				WithEvictionEndpointResolver(NewEvictionEndpointMapping(map[string]string{"mapped": mappedServer.URL})),
+				WithDrainSummaryCallback(func(s DrainSummary) { summaries = append(summaries, s) }),
+			)
+			assert.NoError(t, d.Drain(context.Background(), node))
+
+			if assert.Len(t, summaries, 1) && assert.Len(t, summaries[0].Pods, 1) {
+				assert.Equal(t, tt.expectedEndpoint, summaries[0].Pods[0].EvictionEndpoint)
+			}
+		})
+	}
+}
+
+func TestParseRetryMaxAttempt(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		expected  int32
+		expectErr bool
+	}{
+		{name: "valid", value: "5", expected: 5},
+		{name: "negative", value: "-3", expected: 0, expectErr: true},
+		{name: "zero", value: "0", expected: 0, expectErr: true},
+		{name: "too large", value: "1000", expected: 100, expectErr: true},
+		{name: "non numeric", value: "five", expected: 0, expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRetryMaxAttempt(tt.value)
+			assert.Equal(t, tt.expected, got)
+			assert.Equal(t, tt.expectErr, err != nil)
+
+			node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName, Annotations: map[string]string{CustomRetryMaxAttemptAnnotation: tt.value}}}
+			customValue, usedDefault, nodeErr := GetNodeRetryMaxAttempt(node)
+			assert.Equal(t, got, customValue)
+			assert.Equal(t, got == 0, usedDefault)
+			assert.Equal(t, err, nodeErr)
+		})
+	}
+}
+
+func TestAPIDrainer_PodEvictionDuration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+
+	tests := []struct {
+		name           string
+		evictionAPIURL string
+		abort          bool
+		options        []APIDrainerOption
+		expectedPath   string
+		expectedResult string
+	}{
+		{
+			name:           "kubernetes eviction",
+			expectedPath:   evictionPathKubernetes,
+			expectedResult: podEvictionResultSucceeded,
+		},
+		{
+			name:           "kubernetes eviction aborted",
+			abort:          true,
+			expectedPath:   evictionPathKubernetes,
+			expectedResult: podEvictionResultAborted,
+		},
+		{
+			name:           "operator eviction",
+			evictionAPIURL: server.URL,
+			expectedPath:   evictionPathOperator,
+			expectedResult: podEvictionResultSucceeded,
+		},
+		{
+			name:           "operator eviction failed",
+			evictionAPIURL: server.URL + "?fail=true",
+			options:        []APIDrainerOption{WithEvictionEndpoint500Retries(0)},
+			expectedPath:   evictionPathOperator,
+			expectedResult: podEvictionResultFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			durationView := &view.View{
+				Name:        "test_pod_eviction_duration",
+				Measure:     MeasurePodEvictionDuration,
+				Aggregation: view.Count(),
+				TagKeys:     []tag.Key{TagNodeName, TagEvictionPath, TagResult},
+			}
+			assert.NoError(t, view.Register(durationView))
+			defer view.Unregister(durationView)
+
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns", UID: "uid"}, Spec: core.PodSpec{NodeName: nodeName, TerminationGracePeriodSeconds: &podGracePeriodSeconds}}
+			if tt.evictionAPIURL != "" {
+				pod.Annotations = map[string]string{EvictionAPIURLAnnotationKey: tt.evictionAPIURL}
+			}
+			c := fake.NewSimpleClientset(pod)
+			crBuilder := crfake.NewClientBuilder()
+			abort := make(chan struct{})
+			if tt.abort {
+				// the eviction is accepted but the pod is never deleted
+				c.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+					return action.GetSubresource() == "eviction", nil, nil
+				})
+				crBuilder = crBuilder.WithObjects(pod)
+				time.AfterFunc(100*time.Millisecond, func() { close(abort) })
+			}
+			options := append([]APIDrainerOption{WithContainerRuntimeClient(crBuilder.Build())}, tt.options...)
+			d := NewAPIDrainer(c, &NoopEventRecorder{}, options...)
+
+			err := d.evict(context.Background(), node, pod, abort, &PodEvictionSummary{})
+			assert.Equal(t, tt.expectedResult == podEvictionResultSucceeded, err == nil, "unexpected error: %v", err)
+
+			rows, err := view.RetrieveData(durationView.Name)
+			assert.NoError(t, err)
+			if assert.Len(t, rows, 1) {
+				assert.ElementsMatch(t, []tag.Tag{{Key: TagNodeName, Value: nodeName}, {Key: TagEvictionPath, Value: tt.expectedPath}, {Key: TagResult, Value: tt.expectedResult}}, rows[0].Tags)
+				assert.Equal(t, int64(1), rows[0].Data.(*view.CountData).Value)
+			}
+		})
+	}
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"fmt"
 	"github.com/go-logr/logr"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	client "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -16,6 +16,8 @@ import (
 
 	core "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
+
+	"github.com/planetlabs/draino/internal/tracing"
 )
 
 // This interface centralizes all k8s event interaction for this project.
@@ -24,8 +26,10 @@ import (
 type EventRecorder interface {
 	NodeEventf(ctx context.Context, obj *core.Node, eventtype, reason, messageFmt string, args ...interface{})
 	PodEventf(ctx context.Context, obj *core.Pod, eventtype, reason, messageFmt string, args ...interface{})
+	PodAnnotatedEventf(ctx context.Context, obj *core.Pod, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{})
 	PersistentVolumeEventf(ctx context.Context, obj *core.PersistentVolume, eventtype, reason, messageFmt string, args ...interface{})
 	PersistentVolumeClaimEventf(ctx context.Context, obj *core.PersistentVolumeClaim, eventtype, reason, messageFmt string, args ...interface{})
+	ControllerEventf(ctx context.Context, obj v1.Object, eventtype, reason, messageFmt string, args ...interface{})
 }
 
 type eventRecorder struct {
@@ -39,8 +43,8 @@ func NewEventRecorder(k8sEventRecorder record.EventRecorder) EventRecorder {
 	}
 }
 
-func createSpan(ctx context.Context, operationName string, name string, eventType, reason, messageFmt string, args ...interface{}) (tracer.Span, context.Context) {
-	span, ctx := tracer.StartSpanFromContext(ctx, operationName)
+func createSpan(ctx context.Context, operationName string, name string, eventType, reason, messageFmt string, args ...interface{}) (tracing.Span, context.Context) {
+	span, ctx := tracing.StartSpanFromContext(ctx, operationName)
 
 	span.SetTag("name", name)
 	span.SetTag("eventType", eventType)
@@ -68,6 +72,14 @@ func (e *eventRecorder) PodEventf(ctx context.Context, obj *core.Pod, eventType,
 	e.eventRecorder.Eventf(obj, eventType, reason, messageFmt, args...)
 }
 
+// PodAnnotatedEventf emits an event on the pod carrying the annotations, for the consumers that need structured data
+func (e *eventRecorder) PodAnnotatedEventf(ctx context.Context, obj *core.Pod, annotations map[string]string, eventType, reason, messageFmt string, args ...interface{}) {
+	span, _ := createSpan(ctx, "PodEvent", obj.GetName(), eventType, reason, messageFmt, args...)
+	defer span.Finish()
+
+	e.eventRecorder.AnnotatedEventf(obj, annotations, eventType, reason, messageFmt, args...)
+}
+
 func (e *eventRecorder) PersistentVolumeEventf(ctx context.Context, obj *core.PersistentVolume, eventType, reason, messageFmt string, args ...interface{}) {
 	span, _ := createSpan(ctx, "PesistentVolumeEvent", obj.GetName(), eventType, reason, messageFmt, args...)
 	defer span.Finish()
@@ -82,16 +94,33 @@ func (e *eventRecorder) PersistentVolumeClaimEventf(ctx context.Context, obj *co
 	e.eventRecorder.Eventf(obj, eventType, reason, messageFmt, args...)
 }
 
+// ControllerEventf emits an event on the controller object (Deployment/StatefulSet) of a pod.
+// Events on pods vanish with the pods, the controller gives a more durable visibility.
+func (e *eventRecorder) ControllerEventf(ctx context.Context, obj v1.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	span, _ := createSpan(ctx, "ControllerEvent", obj.GetName(), eventType, reason, messageFmt, args...)
+	defer span.Finish()
+
+	runtimeObj, ok := obj.(runtime.Object)
+	if !ok {
+		return
+	}
+	e.eventRecorder.Eventf(runtimeObj, eventType, reason, messageFmt, args...)
+}
+
 type NoopEventRecorder struct{}
 
 func (n NoopEventRecorder) NodeEventf(ctx context.Context, obj *core.Node, eventtype, reason, messageFmt string, args ...interface{}) {
 }
 func (n NoopEventRecorder) PodEventf(ctx context.Context, obj *core.Pod, eventtype, reason, messageFmt string, args ...interface{}) {
 }
+func (n NoopEventRecorder) PodAnnotatedEventf(ctx context.Context, obj *core.Pod, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+}
 func (n NoopEventRecorder) PersistentVolumeEventf(ctx context.Context, obj *core.PersistentVolume, eventtype, reason, messageFmt string, args ...interface{}) {
 }
 func (n NoopEventRecorder) PersistentVolumeClaimEventf(ctx context.Context, obj *core.PersistentVolumeClaim, eventtype, reason, messageFmt string, args ...interface{}) {
 }
+func (n NoopEventRecorder) ControllerEventf(ctx context.Context, obj v1.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+}
 
 var _ EventRecorder = &NoopEventRecorder{}
 
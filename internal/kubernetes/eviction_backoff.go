@@ -0,0 +1,58 @@
+package kubernetes
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// EvictionBackoffJitterNone keeps the waits of the eviction backoff as they are
+	EvictionBackoffJitterNone = "none"
+	// EvictionBackoffJitterFull draws the wait uniformly in [wait*(1-fraction), wait]
+	EvictionBackoffJitterFull = "full"
+	// EvictionBackoffJitterEqual keeps half of the jittered part: the wait is drawn uniformly in [wait*(1-fraction/2), wait]
+	EvictionBackoffJitterEqual = "equal"
+)
+
+// EvictionBackoffJitter randomizes the wait before the next attempt to evict a pod, so that the retries of the pods rejected at the same
+// time are spread. random is drawn uniformly in [0,1).
+type EvictionBackoffJitter func(wait time.Duration, random float64) time.Duration
+
+// FullJitter removes up to fraction of the wait, with fraction 1 the wait is anywhere between 0 and the backoff
+func FullJitter(fraction float64) EvictionBackoffJitter {
+	return func(wait time.Duration, random float64) time.Duration {
+		return wait - time.Duration(float64(wait)*fraction*random)
+	}
+}
+
+// EqualJitter removes up to half of fraction of the wait, with fraction 1 the wait is at least half the backoff
+func EqualJitter(fraction float64) EvictionBackoffJitter {
+	return FullJitter(fraction / 2)
+}
+
+// ParseEvictionBackoffJitter returns the jitter of the strategy, nil for EvictionBackoffJitterNone. The fraction must be in [0,1].
+func ParseEvictionBackoffJitter(strategy string, fraction float64) (EvictionBackoffJitter, error) {
+	if fraction < 0 || fraction > 1 {
+		return nil, fmt.Errorf("invalid eviction backoff jitter fraction %v, expecting a value between 0 and 1", fraction)
+	}
+	switch strategy {
+	case EvictionBackoffJitterNone, "":
+		return nil, nil
+	case EvictionBackoffJitterFull:
+		return FullJitter(fraction), nil
+	case EvictionBackoffJitterEqual:
+		return EqualJitter(fraction), nil
+	}
+	return nil, fmt.Errorf("invalid eviction backoff jitter strategy '%s', expecting %s, %s or %s", strategy, EvictionBackoffJitterNone, EvictionBackoffJitterFull, EvictionBackoffJitterEqual)
+}
+
+// jitterEvictionWait applies the jitter of the drainer, if any, to the wait before the next eviction attempt
+func (d *APIDrainer) jitterEvictionWait(wait time.Duration) time.Duration {
+	if d.evictionBackoffJitter == nil {
+		return wait
+	}
+	d.evictionBackoffRandLock.Lock()
+	random := d.evictionBackoffRand.Float64()
+	d.evictionBackoffRandLock.Unlock()
+	return d.evictionBackoffJitter(wait, random)
+}
@@ -0,0 +1,102 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseEvictionBackoffJitter(t *testing.T) {
+	tests := []struct {
+		name      string
+		strategy  string
+		fraction  float64
+		expectNil bool
+		expectErr bool
+	}{
+		{name: "none", strategy: EvictionBackoffJitterNone, fraction: 1, expectNil: true},
+		{name: "default", strategy: "", fraction: 1, expectNil: true},
+		{name: "full", strategy: EvictionBackoffJitterFull, fraction: 0.5},
+		{name: "equal", strategy: EvictionBackoffJitterEqual, fraction: 1},
+		{name: "unknown strategy", strategy: "decorrelated", fraction: 1, expectErr: true},
+		{name: "negative fraction", strategy: EvictionBackoffJitterFull, fraction: -0.1, expectErr: true},
+		{name: "fraction above 1", strategy: EvictionBackoffJitterFull, fraction: 1.5, expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jitter, err := ParseEvictionBackoffJitter(tt.strategy, tt.fraction)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectNil, jitter == nil)
+		})
+	}
+}
+
+func TestAPIDrainer_JitterEvictionWait(t *testing.T) {
+	const wait = 10 * time.Second
+	tests := []struct {
+		name         string
+		options      []APIDrainerOption
+		expectedMin  time.Duration
+		expectedMean time.Duration
+	}{
+		{
+			name:         "no jitter",
+			expectedMin:  wait,
+			expectedMean: wait,
+		},
+		{
+			name:         "full jitter",
+			options:      []APIDrainerOption{WithEvictionBackoffJitter(FullJitter(1), 42)},
+			expectedMin:  0,
+			expectedMean: wait / 2,
+		},
+		{
+			name:         "partial full jitter",
+			options:      []APIDrainerOption{WithEvictionBackoffJitter(FullJitter(0.4), 42)},
+			expectedMin:  6 * time.Second,
+			expectedMean: 8 * time.Second,
+		},
+		{
+			name:         "equal jitter",
+			options:      []APIDrainerOption{WithEvictionBackoffJitter(EqualJitter(1), 42)},
+			expectedMin:  wait / 2,
+			expectedMean: 7500 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewAPIDrainer(fake.NewSimpleClientset(), &NoopEventRecorder{}, tt.options...)
+
+			const draws = 10000
+			var sum time.Duration
+			// the waits are uniformly distributed in [expectedMin, wait]: 10 buckets of the range receive about the same number of draws
+			buckets := make([]int, 10)
+			for i := 0; i < draws; i++ {
+				got := d.jitterEvictionWait(wait)
+				if !assert.True(t, got >= tt.expectedMin && got <= wait, "wait %v out of [%v, %v]", got, tt.expectedMin, wait) {
+					return
+				}
+				sum += got
+				if tt.expectedMin < wait {
+					bucket := int(float64(got-tt.expectedMin) / float64(wait-tt.expectedMin) * 10)
+					if bucket == 10 {
+						bucket--
+					}
+					buckets[bucket]++
+				}
+			}
+			assert.InDelta(t, float64(tt.expectedMean), float64(sum/draws), float64(wait)/100)
+			if tt.expectedMin < wait {
+				for i, count := range buckets {
+					assert.InDelta(t, draws/10, count, draws/50, "bucket %d", i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// EvictionEndpointMappingConfigMapKey is the key of the configmap data holding the eviction endpoint mapping.
+// There is one entry per line, formatted as <namespace>=<url> or <namespace>/<controller kind>/<controller name>=<url>.
+const EvictionEndpointMappingConfigMapKey = "eviction-endpoints"
+
+// EvictionEndpointResolver returns the custom eviction endpoint of a pod that is not annotated with EvictionAPIURLAnnotationKey
+type EvictionEndpointResolver interface {
+	ResolveEvictionEndpoint(pod *core.Pod, store RuntimeObjectStore) (string, bool)
+}
+
+// EvictionEndpointMapping resolves the eviction endpoint of the pods from their namespace and controllers.
+// The entries of the controllers take precedence over the entry of the namespace.
+type EvictionEndpointMapping struct {
+	sync.RWMutex
+	endpoints map[string]string
+}
+
+var _ EvictionEndpointResolver = &EvictionEndpointMapping{}
+
+// GetEvictionEndpoint returns the custom eviction endpoint of the pod: the EvictionAPIURLAnnotationKey annotation of the pod or its controller,
+// else the one given by the resolver if any
+func GetEvictionEndpoint(pod *core.Pod, store RuntimeObjectStore, resolver EvictionEndpointResolver) (string, bool) {
+	if endpoint, ok := GetAnnotationFromPodOrController(EvictionAPIURLAnnotationKey, pod, store); ok {
+		return endpoint, true
+	}
+	if resolver != nil {
+		return resolver.ResolveEvictionEndpoint(pod, store)
+	}
+	return "", false
+}
+
+// HasEvictionEndpoint tells if the pod is evicted with a custom eviction endpoint, see GetEvictionEndpoint
+func HasEvictionEndpoint(pod *core.Pod, store RuntimeObjectStore, resolver EvictionEndpointResolver) bool {
+	_, ok := GetEvictionEndpoint(pod, store, resolver)
+	return ok
+}
+
+// PodHasNoEvictionEndpoint keeps the pods that are evicted with the kubernetes API, see GetEvictionEndpoint
+func PodHasNoEvictionEndpoint(store RuntimeObjectStore, resolver EvictionEndpointResolver) PodFilterFunc {
+	return func(p core.Pod) (bool, string, error) {
+		if HasEvictionEndpoint(&p, store, resolver) {
+			return false, "eviction-endpoint", nil
+		}
+		return true, "", nil
+	}
+}
+
+// NewEvictionEndpointMapping returns a mapping using the given entries, keyed by namespace or namespace/kind/name
+func NewEvictionEndpointMapping(endpoints map[string]string) *EvictionEndpointMapping {
+	return &EvictionEndpointMapping{endpoints: endpoints}
+}
+
+// SetEndpoints replaces all the entries of the mapping
+func (m *EvictionEndpointMapping) SetEndpoints(endpoints map[string]string) {
+	m.Lock()
+	defer m.Unlock()
+	m.endpoints = endpoints
+}
+
+// ResolveEvictionEndpoint looks for the controllers of the pod, starting from its direct controller, then for its namespace
+func (m *EvictionEndpointMapping) ResolveEvictionEndpoint(pod *core.Pod, store RuntimeObjectStore) (string, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	if len(m.endpoints) == 0 {
+		return "", false
+	}
+	for _, ref := range GetOwnerChain(pod, store) {
+		if endpoint, ok := m.endpoints[ref.Namespace+"/"+ref.Kind+"/"+ref.Name]; ok {
+			return endpoint, true
+		}
+	}
+	endpoint, ok := m.endpoints[pod.Namespace]
+	return endpoint, ok
+}
+
+// ParseEvictionEndpointMapping parses the entries stored under EvictionEndpointMappingConfigMapKey
+func ParseEvictionEndpointMapping(cm *core.ConfigMap) (map[string]string, error) {
+	endpoints := map[string]string{}
+	for _, line := range strings.Split(cm.Data[EvictionEndpointMappingConfigMapKey], "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		key, endpoint, found := strings.Cut(line, "=")
+		key, endpoint = strings.TrimSpace(key), strings.TrimSpace(endpoint)
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid eviction endpoint entry '%s', expecting <namespace>[/<kind>/<name>]=<url>", line)
+		}
+		if parts := strings.Split(key, "/"); len(parts) != 1 && len(parts) != 3 {
+			return nil, fmt.Errorf("invalid eviction endpoint key '%s', expecting <namespace> or <namespace>/<kind>/<name>", key)
+		}
+		if u, err := url.Parse(endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid eviction endpoint url '%s' for '%s'", endpoint, key)
+		}
+		if _, exist := endpoints[key]; exist {
+			return nil, fmt.Errorf("duplicated eviction endpoint entry for '%s'", key)
+		}
+		endpoints[key] = endpoint
+	}
+	return endpoints, nil
+}
+
+// EvictionEndpointMappingConfigMapWatch watches a configmap and reloads the mapping each time it is created or updated
+type EvictionEndpointMappingConfigMapWatch struct {
+	cache.SharedInformer
+	name    string
+	logger  *zap.Logger
+	mapping *EvictionEndpointMapping
+}
+
+// NewEvictionEndpointMappingConfigMapWatch creates a watch on the configmap namespace/name feeding the mapping
+func NewEvictionEndpointMappingConfigMapWatch(ctx context.Context, c kubernetes.Interface, namespace, name string, logger *zap.Logger, mapping *EvictionEndpointMapping) *EvictionEndpointMappingConfigMapWatch {
+	w := &EvictionEndpointMappingConfigMapWatch{
+		SharedInformer: newConfigMapInformer(ctx, c, namespace, name),
+		name:           name,
+		logger:         logger.With(zap.String("configmap", namespace+"/"+name)),
+		mapping:        mapping,
+	}
+	w.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    w.onChange,
+		UpdateFunc: func(_, newObj interface{}) { w.onChange(newObj) },
+	})
+	return w
+}
+
+func (w *EvictionEndpointMappingConfigMapWatch) Start(ctx context.Context) {
+	w.Run(ctx.Done())
+}
+
+func (w *EvictionEndpointMappingConfigMapWatch) onChange(obj interface{}) {
+	cm, ok := obj.(*core.ConfigMap)
+	if !ok || cm.Name != w.name {
+		return
+	}
+	endpoints, err := ParseEvictionEndpointMapping(cm)
+	if err != nil {
+		w.logger.Error("Ignoring eviction endpoint mapping update, the configmap content is not valid", zap.Error(err))
+		return
+	}
+	w.logger.Info("Reloading eviction endpoint mapping from configmap", zap.Int("entries", len(endpoints)))
+	w.mapping.SetEndpoints(endpoints)
+}
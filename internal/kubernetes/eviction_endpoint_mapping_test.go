@@ -0,0 +1,144 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseEvictionEndpointMapping(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		expected  map[string]string
+		expectErr bool
+	}{
+		{
+			name:     "namespace and controller entries",
+			data:     "\nns-a=http://a.svc/evict\n ns-b/StatefulSet/db = https://db.svc:8443/evict \n",
+			expected: map[string]string{"ns-a": "http://a.svc/evict", "ns-b/StatefulSet/db": "https://db.svc:8443/evict"},
+		},
+		{
+			name:     "empty",
+			expected: map[string]string{},
+		},
+		{
+			name:      "missing url",
+			data:      "ns-a",
+			expectErr: true,
+		},
+		{
+			name:      "invalid key",
+			data:      "ns-a/StatefulSet=http://a.svc/evict",
+			expectErr: true,
+		},
+		{
+			name:      "relative url",
+			data:      "ns-a=/evict",
+			expectErr: true,
+		},
+		{
+			name:      "duplicated entry",
+			data:      "ns-a=http://a.svc/evict\nns-a=http://b.svc/evict",
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := &core.ConfigMap{Data: map[string]string{EvictionEndpointMappingConfigMapKey: tt.data}}
+			endpoints, err := ParseEvictionEndpointMapping(cm)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, endpoints)
+		})
+	}
+}
+
+func TestEvictionEndpointMapping_ResolveEvictionEndpoint(t *testing.T) {
+	isController := true
+	mapping := NewEvictionEndpointMapping(map[string]string{
+		"ns":                 "http://namespace.svc/evict",
+		"ns/StatefulSet/db":  "http://db.svc/evict",
+		"other/ReplicaSet/x": "http://x.svc/evict",
+	})
+	tests := []struct {
+		name             string
+		pod              *core.Pod
+		expectedEndpoint string
+		expectedFound    bool
+	}{
+		{
+			name: "controller entry",
+			pod: &core.Pod{ObjectMeta: meta.ObjectMeta{Name: "db-0", Namespace: "ns", OwnerReferences: []meta.OwnerReference{
+				{Kind: "StatefulSet", Name: "db", Controller: &isController},
+			}}},
+			expectedEndpoint: "http://db.svc/evict",
+			expectedFound:    true,
+		},
+		{
+			name: "namespace entry when the controller is not mapped",
+			pod: &core.Pod{ObjectMeta: meta.ObjectMeta{Name: "web-0", Namespace: "ns", OwnerReferences: []meta.OwnerReference{
+				{Kind: "StatefulSet", Name: "web", Controller: &isController},
+			}}},
+			expectedEndpoint: "http://namespace.svc/evict",
+			expectedFound:    true,
+		},
+		{
+			name:          "no match",
+			pod:           &core.Pod{ObjectMeta: meta.ObjectMeta{Name: "pod", Namespace: "other"}},
+			expectedFound: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint, found := mapping.ResolveEvictionEndpoint(tt.pod, nil)
+			assert.Equal(t, tt.expectedFound, found)
+			assert.Equal(t, tt.expectedEndpoint, endpoint)
+		})
+	}
+}
+
+func TestGetEvictionEndpoint(t *testing.T) {
+	mapping := NewEvictionEndpointMapping(map[string]string{"ns": "http://namespace.svc/evict"})
+	tests := []struct {
+		name             string
+		pod              *core.Pod
+		resolver         EvictionEndpointResolver
+		expectedEndpoint string
+		expectedFound    bool
+	}{
+		{
+			name:             "annotation takes precedence over the mapping",
+			pod:              &core.Pod{ObjectMeta: meta.ObjectMeta{Name: "pod", Namespace: "ns", Annotations: map[string]string{EvictionAPIURLAnnotationKey: "http://pod.svc/evict"}}},
+			resolver:         mapping,
+			expectedEndpoint: "http://pod.svc/evict",
+			expectedFound:    true,
+		},
+		{
+			name:             "mapping",
+			pod:              &core.Pod{ObjectMeta: meta.ObjectMeta{Name: "pod", Namespace: "ns"}},
+			resolver:         mapping,
+			expectedEndpoint: "http://namespace.svc/evict",
+			expectedFound:    true,
+		},
+		{
+			name:          "no resolver",
+			pod:           &core.Pod{ObjectMeta: meta.ObjectMeta{Name: "pod", Namespace: "ns"}},
+			expectedFound: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint, found := GetEvictionEndpoint(tt.pod, nil, tt.resolver)
+			assert.Equal(t, tt.expectedFound, found)
+			assert.Equal(t, tt.expectedEndpoint, endpoint)
+			assert.Equal(t, tt.expectedFound, HasEvictionEndpoint(tt.pod, nil, tt.resolver))
+			assert.Equal(t, !tt.expectedFound, PVCCleanupDefaultIfNoEvictionURL(true, tt.resolver)(tt.pod, nil))
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package kubernetes
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// evictionEndpointStatsWindow is the number of recent calls kept per custom eviction endpoint to compute its health
+const evictionEndpointStatsWindow = 100
+
+// EndpointHealth summarizes the recent calls made by draino to a custom eviction endpoint
+type EndpointHealth struct {
+	// Calls is the number of calls the summary is computed on, at most evictionEndpointStatsWindow
+	Calls int
+	// SuccessRate is the ratio of the calls that got an answer below 500, transport errors and server errors are failures
+	SuccessRate float64
+	P95Latency  time.Duration
+	LastCall    time.Time
+	// Degraded is set when the p95 latency is above the degraded threshold of the drainer
+	Degraded bool
+}
+
+type evictionEndpointCall struct {
+	at      time.Time
+	latency time.Duration
+	success bool
+}
+
+// evictionEndpointStats keeps the last calls to each custom eviction endpoint, shared by all the drains
+type evictionEndpointStats struct {
+	sync.Mutex
+	// calls is a ring buffer per endpoint host, next is the position of the next call to record
+	calls map[string][]evictionEndpointCall
+	next  map[string]int
+}
+
+func newEvictionEndpointStats() *evictionEndpointStats {
+	return &evictionEndpointStats{calls: map[string][]evictionEndpointCall{}, next: map[string]int{}}
+}
+
+func (s *evictionEndpointStats) record(endpoint string, resp *http.Response, latency time.Duration, at time.Time) {
+	call := evictionEndpointCall{at: at, latency: latency, success: resp != nil && resp.StatusCode < http.StatusInternalServerError}
+	s.Lock()
+	defer s.Unlock()
+	if calls := s.calls[endpoint]; len(calls) < evictionEndpointStatsWindow {
+		s.calls[endpoint] = append(calls, call)
+		return
+	}
+	s.calls[endpoint][s.next[endpoint]] = call
+	s.next[endpoint] = (s.next[endpoint] + 1) % evictionEndpointStatsWindow
+}
+
+func (s *evictionEndpointStats) summarize(degradedThreshold time.Duration) map[string]EndpointHealth {
+	s.Lock()
+	defer s.Unlock()
+	result := make(map[string]EndpointHealth, len(s.calls))
+	for endpoint, calls := range s.calls {
+		health := EndpointHealth{Calls: len(calls)}
+		latencies := make([]time.Duration, len(calls))
+		successes := 0
+		for i, call := range calls {
+			latencies[i] = call.latency
+			if call.success {
+				successes++
+			}
+			if call.at.After(health.LastCall) {
+				health.LastCall = call.at
+			}
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		// nearest-rank percentile
+		health.P95Latency = latencies[(len(latencies)*95+99)/100-1]
+		health.SuccessRate = float64(successes) / float64(len(calls))
+		health.Degraded = degradedThreshold > 0 && health.P95Latency > degradedThreshold
+		result[endpoint] = health
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+package kubernetes
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAPIDrainer_EvictionEndpointStats(t *testing.T) {
+	d := NewAPIDrainer(fake.NewSimpleClientset(), NewEventRecorder(&capturingRecorder{}), WithEvictionEndpointDegradedThreshold(time.Second))
+	assert.Equal(t, map[string]EndpointHealth{}, d.EvictionEndpointStats())
+
+	ctx := context.Background()
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName}}
+	pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName, Namespace: "ns"}}
+	call := func(endpoint string, status int, latency time.Duration) {
+		var resp *http.Response
+		if status != 0 {
+			resp = &http.Response{StatusCode: status}
+		}
+		d.recordEvictionEndpointLatency(ctx, zap.NewNop(), node, pod, endpoint, resp, latency)
+	}
+
+	// 18 fast calls, one slow call and a transport error on the first endpoint
+	for i := 0; i < 18; i++ {
+		call("fast.svc", http.StatusOK, 10*time.Millisecond)
+	}
+	call("fast.svc", http.StatusTooManyRequests, 500*time.Millisecond)
+	call("fast.svc", 0, 5*time.Second)
+	// the second endpoint answers slowly with server errors
+	call("slow.svc", http.StatusInternalServerError, 2*time.Second)
+	call("slow.svc", http.StatusOK, 3*time.Second)
+
+	stats := d.EvictionEndpointStats()
+	assert.Len(t, stats, 2)
+
+	fast := stats["fast.svc"]
+	assert.Equal(t, 20, fast.Calls)
+	assert.Equal(t, 0.95, fast.SuccessRate)
+	assert.Equal(t, 500*time.Millisecond, fast.P95Latency)
+	assert.False(t, fast.Degraded)
+	assert.False(t, fast.LastCall.IsZero())
+
+	slow := stats["slow.svc"]
+	assert.Equal(t, 2, slow.Calls)
+	assert.Equal(t, 0.5, slow.SuccessRate)
+	assert.Equal(t, 3*time.Second, slow.P95Latency)
+	assert.True(t, slow.Degraded)
+
+	// only the last calls are accounted for
+	for i := 0; i < evictionEndpointStatsWindow; i++ {
+		call("slow.svc", http.StatusOK, 100*time.Millisecond)
+	}
+	slow = d.EvictionEndpointStats()["slow.svc"]
+	assert.Equal(t, evictionEndpointStatsWindow, slow.Calls)
+	assert.Equal(t, 1.0, slow.SuccessRate)
+	assert.Equal(t, 100*time.Millisecond, slow.P95Latency)
+	assert.False(t, slow.Degraded)
+}
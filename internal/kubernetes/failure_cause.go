@@ -3,6 +3,8 @@ package kubernetes
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 )
 
 type FailureCause string
@@ -10,12 +12,93 @@ type FailureCause string
 const (
 	OverlappingPodDisruptionBudgets FailureCause = "overlapping_pod_disruption_budgets"
 	PodEvictionTimeout              FailureCause = "pod_eviction_timeout"
+	PodEvictionTimeoutEvictionPP    FailureCause = PodEvictionTimeout + "_evictionpp"
+	PodEvictionTimeoutKubeAPI       FailureCause = PodEvictionTimeout + "_kubeapi"
 	PodDeletionTimeout              FailureCause = "pod_deletion_timeout"
+	PodDeletionTimeoutPreStopHook   FailureCause = "pod_deletion_timeout_prestop_hook"
 	VolumeCleanup                   FailureCause = "volume_cleanup"
 	NodePreprovisioning             FailureCause = "node_preprovisioning_timeout"
 	AudienceNotFound                FailureCause = "audience_not_found"
+	PodsWithoutPDB                  FailureCause = "pods_without_pod_disruption_budget"
+	PodsRemainingAfterDrain         FailureCause = "pods_remaining_after_drain"
+	PodsWithoutAlternativePlacement FailureCause = "pods_without_alternative_placement"
+	PodDisruptionBudgetBlocked      FailureCause = "pod_disruption_budget_blocked"
+	ConditionsResolved              FailureCause = "conditions_resolved"
+	MaxPVCDeletionsExceeded         FailureCause = "max_pvc_deletions_exceeded"
+	PVCRecreateTimeout              FailureCause = "pvc_recreate_timeout"
+	MaxPodDeletionsForPVCRecreate   FailureCause = "max_pod_deletions_for_pvc_recreate_exceeded"
+	WorkloadUnavailabilityCap       FailureCause = "workload_unavailability_cap"
+	NodeNotStable                   FailureCause = "node_not_stable"
+	DrainPaused                     FailureCause = "drain_paused"
+	DrainCancelled                  FailureCause = "drain_cancelled"
+	DrainDryRunBlocked              FailureCause = "drain_dry_run_blocked"
+	EvictionEndpointDryRunRejected  FailureCause = "eviction_endpoint_dry_run_rejected"
+	// EvictionEndpoint is suffixed with _request_timeout and/or with the status code, e.g. eviction_endpoint_503
+	EvictionEndpoint FailureCause = "eviction_endpoint"
 )
 
+// failureCauses are the fixed failure causes returned by GetFailureCause
+var failureCauses = map[FailureCause]struct{}{
+	OverlappingPodDisruptionBudgets: {},
+	PodEvictionTimeoutEvictionPP:    {},
+	PodEvictionTimeoutKubeAPI:       {},
+	PodDeletionTimeout:              {},
+	PodDeletionTimeoutPreStopHook:   {},
+	VolumeCleanup:                   {},
+	NodePreprovisioning:             {},
+	AudienceNotFound:                {},
+	PodsWithoutPDB:                  {},
+	PodsRemainingAfterDrain:         {},
+	PodsWithoutAlternativePlacement: {},
+	PodDisruptionBudgetBlocked:      {},
+	ConditionsResolved:              {},
+	MaxPVCDeletionsExceeded:         {},
+	PVCRecreateTimeout:              {},
+	MaxPodDeletionsForPVCRecreate:   {},
+	WorkloadUnavailabilityCap:       {},
+	NodeNotStable:                   {},
+	DrainPaused:                     {},
+	DrainCancelled:                  {},
+	DrainDryRunBlocked:              {},
+	EvictionEndpointDryRunRejected:  {},
+	EvictionEndpoint:                {},
+}
+
+// evictionEndpointCauseRegexp matches the failure causes of the EvictionEndpointError
+var evictionEndpointCauseRegexp = regexp.MustCompile("^" + string(EvictionEndpoint) + "(_request_timeout)?(_[1-5][0-9][0-9])?$")
+
+// IsKnownFailureCause tells if the failure cause can be returned by GetFailureCause
+func IsKnownFailureCause(cause FailureCause) bool {
+	if _, ok := failureCauses[cause]; ok {
+		return true
+	}
+	return evictionEndpointCauseRegexp.MatchString(string(cause))
+}
+
+// ParseFailureCauseEventReasons parses a mapping of failure causes to event reasons, each entry formatted as <failure cause>=<reason>.
+// The failure causes are the values returned by GetFailureCause, e.g. pod_eviction_timeout_kubeapi, the unknown ones are rejected.
+func ParseFailureCauseEventReasons(entries []string) (map[FailureCause]string, error) {
+	reasons := map[FailureCause]string{}
+	for _, entry := range entries {
+		cause, reason, found := strings.Cut(entry, "=")
+		cause, reason = strings.TrimSpace(cause), strings.TrimSpace(reason)
+		if !found || cause == "" || reason == "" {
+			return nil, fmt.Errorf("invalid failure cause event reason '%s', expecting <failure cause>=<reason>", entry)
+		}
+		if !IsKnownFailureCause(FailureCause(cause)) {
+			return nil, fmt.Errorf("unknown failure cause '%s'", cause)
+		}
+		if strings.ContainsAny(reason, " \t") {
+			return nil, fmt.Errorf("invalid event reason '%s' for '%s', it should not contain spaces", reason, cause)
+		}
+		if _, exist := reasons[FailureCause(cause)]; exist {
+			return nil, fmt.Errorf("duplicated event reason for failure cause '%s'", cause)
+		}
+		reasons[FailureCause(cause)] = reason
+	}
+	return reasons, nil
+}
+
 func GetFailureCause(err error) FailureCause {
 	if errors.As(err, &NodePreprovisioningTimeoutError{}) {
 		return NodePreprovisioning
@@ -25,23 +108,37 @@ func GetFailureCause(err error) FailureCause {
 	}
 	var peErr PodEvictionTimeoutError
 	if errors.As(err, &peErr) {
-		cause := PodEvictionTimeout
 		if peErr.isEvictionPP {
-			cause += "_evictionpp"
-		} else {
-			cause += "_kubeapi"
+			return PodEvictionTimeoutEvictionPP
 		}
-		return cause
+		return PodEvictionTimeoutKubeAPI
 	}
-	if errors.As(err, &PodDeletionTimeoutError{}) {
+	var pdErr PodDeletionTimeoutError
+	if errors.As(err, &pdErr) {
+		if pdErr.PreStopHookRunning {
+			return PodDeletionTimeoutPreStopHook
+		}
 		return PodDeletionTimeout
 	}
+	// checked before VolumeCleanupError that wraps it
+	if errors.As(err, &MaxPVCDeletionsExceededError{}) {
+		return MaxPVCDeletionsExceeded
+	}
+	if errors.As(err, &PVCRecreateTimeoutError{}) {
+		return PVCRecreateTimeout
+	}
+	if errors.As(err, &MaxPodDeletionsForPVCRecreateExceededError{}) {
+		return MaxPodDeletionsForPVCRecreate
+	}
 	if errors.As(err, &VolumeCleanupError{}) {
 		return VolumeCleanup
 	}
+	if errors.As(err, &EvictionEndpointDryRunRejectedError{}) {
+		return EvictionEndpointDryRunRejected
+	}
 	var eeErr EvictionEndpointError
 	if errors.As(err, &eeErr) {
-		cause := "eviction_endpoint"
+		cause := string(EvictionEndpoint)
 		if eeErr.IsRequestTimeout {
 			cause += "_request_timeout"
 		}
@@ -53,6 +150,36 @@ func GetFailureCause(err error) FailureCause {
 	if errors.As(err, &AudienceNotFoundError{}) {
 		return AudienceNotFound
 	}
+	if errors.As(err, &PodsWithoutPDBError{}) {
+		return PodsWithoutPDB
+	}
+	if errors.As(err, &PodsRemainingAfterDrainError{}) {
+		return PodsRemainingAfterDrain
+	}
+	if errors.As(err, &PodsWithoutAlternativePlacementError{}) {
+		return PodsWithoutAlternativePlacement
+	}
+	if errors.As(err, &PodDisruptionBudgetBlockedError{}) {
+		return PodDisruptionBudgetBlocked
+	}
+	if errors.As(err, &ConditionsResolvedError{}) {
+		return ConditionsResolved
+	}
+	if errors.As(err, &WorkloadUnavailabilityCapError{}) {
+		return WorkloadUnavailabilityCap
+	}
+	if errors.As(err, &NodeNotStableError{}) {
+		return NodeNotStable
+	}
+	if errors.As(err, &DrainPausedError{}) {
+		return DrainPaused
+	}
+	if errors.As(err, &DrainCancelledError{}) {
+		return DrainCancelled
+	}
+	if errors.As(err, &DrainDryRunBlockedError{}) {
+		return DrainDryRunBlocked
+	}
 
 	return ""
 }
@@ -0,0 +1,56 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	core "k8s.io/api/core/v1"
+)
+
+// MaxMetricNodeLabelTags caps the number of node labels added as tags to the drain metrics, each tag multiplies the cardinality of the metrics
+const MaxMetricNodeLabelTags = 5
+
+// metricNodeLabelReservedTags are the tags already set on the metrics recorded for a node, a node label cannot replace them
+var metricNodeLabelReservedTags = []tag.Key{TagNodeName, TagTeam, TagNodegroupName, TagNodegroupNamePrefix, TagNodegroupNamespace, TagResult, TagReason,
+	TagFailureCause, TagConfigName, TagEvictionEndpoint, TagDegraded, TagEvictionPath}
+
+// MetricNodeLabelTagKeys returns the tag keys of the node labels, named after the labels. It fails if there are more than MaxMetricNodeLabelTags
+// labels, or if a label is not a valid tag key or is the name of a tag already set on the metrics of the nodes.
+func MetricNodeLabelTagKeys(labels []string) ([]tag.Key, error) {
+	if len(labels) > MaxMetricNodeLabelTags {
+		return nil, fmt.Errorf("too many node labels as metric tags: %d, the maximum is %d", len(labels), MaxMetricNodeLabelTags)
+	}
+	keys := make([]tag.Key, 0, len(labels))
+	seen := map[string]struct{}{}
+	for _, label := range labels {
+		if _, ok := seen[label]; ok {
+			return nil, fmt.Errorf("duplicated node label '%s' as metric tag", label)
+		}
+		seen[label] = struct{}{}
+		key, err := tag.NewKey(label)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node label '%s' as metric tag: %w", label, err)
+		}
+		for _, reserved := range metricNodeLabelReservedTags {
+			if reserved.Name() == label {
+				return nil, fmt.Errorf("node label '%s' cannot be a metric tag, the tag is already set on the metrics", label)
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// statRecordForNode records the measurement like StatRecordForNode, with the node labels configured with WithMetricNodeLabelTags as tags
+func (d *APIDrainer) statRecordForNode(ctx context.Context, node *core.Node, m stats.Measurement) {
+	if len(d.metricNodeLabelTags) > 0 {
+		mutators := make([]tag.Mutator, 0, len(d.metricNodeLabelTags))
+		for _, key := range d.metricNodeLabelTags {
+			mutators = append(mutators, tag.Upsert(key, node.GetLabels()[key.Name()]))
+		}
+		ctx, _ = tag.New(ctx, mutators...)
+	}
+	StatRecordForNode(ctx, node, m)
+}
@@ -0,0 +1,119 @@
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMetricNodeLabelTagKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      []string
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:     "no label",
+			expected: []string{},
+		},
+		{
+			name:     "labels",
+			labels:   []string{"env", "topology.kubernetes.io/zone"},
+			expected: []string{"env", "topology.kubernetes.io/zone"},
+		},
+		{
+			name:        "too many labels",
+			labels:      []string{"a", "b", "c", "d", "e", "f"},
+			expectedErr: "too many node labels as metric tags: 6, the maximum is 5",
+		},
+		{
+			name:        "duplicated label",
+			labels:      []string{"env", "env"},
+			expectedErr: "duplicated node label 'env' as metric tag",
+		},
+		{
+			name:        "invalid label",
+			labels:      []string{strings.Repeat("a", 256)},
+			expectedErr: "invalid node label",
+		},
+		{
+			name:        "label named after an existing tag",
+			labels:      []string{"team"},
+			expectedErr: "node label 'team' cannot be a metric tag, the tag is already set on the metrics",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := MetricNodeLabelTagKeys(tt.labels)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			names := []string{}
+			for _, key := range keys {
+				names = append(names, key.Name())
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestAPIDrainer_MetricNodeLabelTags(t *testing.T) {
+	envKey, _ := tag.NewKey("env")
+	zoneKey, _ := tag.NewKey("zone")
+	// the missing taint has no failure cause
+	cause := tag.Tag{Key: TagFailureCause, Value: "unknown"}
+	tests := []struct {
+		name         string
+		labels       []string
+		expectedTags []tag.Tag
+	}{
+		{
+			name:         "no label tags",
+			expectedTags: []tag.Tag{cause},
+		},
+		{
+			name:   "configured labels",
+			labels: []string{"env", "zone"},
+			// the node has no zone label, the tag is empty and left out of the row
+			expectedTags: []tag.Tag{cause, {Key: envKey, Value: "staging"}},
+		},
+		{
+			name:         "invalid labels ignored",
+			labels:       []string{"env", "team"},
+			expectedTags: []tag.Tag{cause, {Key: envKey, Value: "staging"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failuresView := &view.View{
+				Name:        "test_drain_failures_node_labels",
+				Measure:     MeasureDrainFailures,
+				Aggregation: view.Count(),
+				TagKeys:     []tag.Key{TagFailureCause, envKey, zoneKey},
+			}
+			assert.NoError(t, view.Register(failuresView))
+			defer view.Unregister(failuresView)
+
+			// the node has no draining taint, the drain fails right away
+			node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: nodeName, Labels: map[string]string{"env": "staging", "other": "ignored"}}}
+			d := NewAPIDrainer(fake.NewSimpleClientset(node), &NoopEventRecorder{}, WithMetricNodeLabelTags(tt.labels))
+			assert.Equal(t, NodeHasNotDrainingTaintError{NodeName: nodeName}, d.Drain(context.Background(), node))
+
+			rows, err := view.RetrieveData(failuresView.Name)
+			assert.NoError(t, err)
+			if assert.Len(t, rows, 1) {
+				assert.ElementsMatch(t, tt.expectedTags, rows[0].Tags)
+			}
+		})
+	}
+}
@@ -11,6 +11,17 @@ var (
 	MeasureNodesDrainScheduled     = stats.Int64("draino/nodes_drainScheduled", "Number of nodes drain scheduled.", stats.UnitDimensionless)
 	MeasureNodesReplacementRequest = stats.Int64("draino/nodes_replacement_request", "Number of nodes replacement requested.", stats.UnitDimensionless)
 	MeasurePreprovisioningLatency  = stats.Float64("draino/nodes_preprovisioning_latency", "Latency to get a node preprovisioned", stats.UnitMilliseconds)
+	MeasurePodsSkipped             = stats.Int64("draino/pods_skipped", "Number of pods skipped during drains.", stats.UnitDimensionless)
+	MeasureDrainDuration           = stats.Float64("draino/drain_duration", "Duration between the draining taint and the end of the drain", stats.UnitMilliseconds)
+	MeasureDrainFailures           = stats.Int64("draino/drain_failures", "Number of failed drains.", stats.UnitDimensionless)
+	MeasureEvictionAttempts        = stats.Int64("draino/eviction_attempts", "Number of eviction attempts per pod eviction.", stats.UnitDimensionless)
+	MeasureEvictionEndpointLatency = stats.Float64("draino/eviction_endpoint_latency", "Latency of the calls to the custom eviction endpoints", stats.UnitMilliseconds)
+	MeasurePreActivityWait         = stats.Float64("draino/pre_activity_wait", "Duration between the drain-candidate taint and the end of the pre activities", stats.UnitMilliseconds)
+	MeasurePVCRecreateDuration     = stats.Float64("draino/pvc_recreate_duration", "Duration waiting for the recreation of a deleted PVC", stats.UnitMilliseconds)
+	MeasurePVCDeleted              = stats.Int64("draino/pvc_deleted", "Number of PVCs deleted by the volume cleanup.", stats.UnitDimensionless)
+	MeasurePVDeleted               = stats.Int64("draino/pv_deleted", "Number of PVs deleted by the volume cleanup.", stats.UnitDimensionless)
+	MeasurePDBsEvaluated           = stats.Int64("draino/pdbs_evaluated", "Number of PDBs associated to the pod evaluated by a pod drain simulation.", stats.UnitDimensionless)
+	MeasurePodEvictionDuration     = stats.Float64("draino/pod_eviction_duration", "Duration between the first eviction call and the confirmation of the pod deletion", stats.UnitMilliseconds)
 
 	TagNodeName, _                        = tag.NewKey("node_name")
 	TagConditions, _                      = tag.NewKey("conditions")
@@ -33,4 +44,10 @@ var (
 	TagUserAllowedConditionsAnnotation, _ = tag.NewKey("user_allowed_conditions_annotation")
 	TagUserEvictionURL, _                 = tag.NewKey("eviction_url")
 	TagOverdue, _                         = tag.NewKey("overdue")
+	TagConfigName, _                      = tag.NewKey("config_name")
+	TagEvictionEndpoint, _                = tag.NewKey("eviction_endpoint")
+	TagDegraded, _                        = tag.NewKey("degraded")
+	TagStorageClass, _                    = tag.NewKey("storage_class")
+	TagNamespace, _                       = tag.NewKey("namespace")
+	TagEvictionPath, _                    = tag.NewKey("eviction_path")
 )
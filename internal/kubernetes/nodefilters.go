@@ -121,7 +121,7 @@ func ConvertLabelsToFilterExpr(labelsSlice []string) (*string, error) {
 }
 
 // GetUnscheduledPodsBoundToNodeByPV Check if there is any pod that would be bound to that node due to PV/PVC and that is not yet scheduled
-func GetUnscheduledPodsBoundToNodeByPV(node *core.Node, store RuntimeObjectStore, pvcManagementDefaultTrueIfNoEvictionURL bool, logger *zap.Logger) ([]*core.Pod, error) {
+func GetUnscheduledPodsBoundToNodeByPV(node *core.Node, store RuntimeObjectStore, pvcCleanupDefault PVCCleanupDefaultFunc, logger *zap.Logger) ([]*core.Pod, error) {
 	var result []*core.Pod
 	// Is there a local PV on the node
 	pvs := store.PersistentVolumes().GetPVForNode(node)
@@ -157,7 +157,7 @@ func GetUnscheduledPodsBoundToNodeByPV(node *core.Node, store RuntimeObjectStore
 				LogForVerboseNode(logger, node, fmt.Sprintf("Pod for claim "+pv.Spec.ClaimRef.Name+", adding pod "+pod.Name))
 
 				var pendingPodDelay time.Duration
-				if PVCStorageClassCleanupEnabled(pod, store, pvcManagementDefaultTrueIfNoEvictionURL) {
+				if PVCStorageClassCleanupEnabled(pod, store, pvcCleanupDefault(pod, store)) {
 					// The pod must be long (enough) pending to be sure that we are not looking at the fresh STS while we are performing the PVC cleanup
 					// Adding a 10s delay on top of PVC deletion timeout to be sure that we have time to perform the PVC cleanup
 					pendingPodDelay = 10*time.Second + awaitPVCDeletionTimeout
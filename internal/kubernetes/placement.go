@@ -0,0 +1,119 @@
+package kubernetes
+
+import (
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// EvictWithoutAlternativePlacementAnnotationKey acknowledges that the pod can be evicted even if no other node can host it
+const EvictWithoutAlternativePlacementAnnotationKey = "draino/evict-without-alternative-placement"
+
+// PodFitsNode tells if the node is compatible with the placement constraints of the pod: node selector, required node affinity and taints.
+// Resources are not taken into account, the goal is to detect pods that are bound to a given node by their specification.
+func PodFitsNode(pod *core.Pod, node *core.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	if len(pod.Spec.NodeSelector) > 0 && !labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(labels.Set(node.GetLabels())) {
+		return false
+	}
+	if affinity := pod.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil {
+		if required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil && !matchNodeSelectorTerms(required.NodeSelectorTerms, node) {
+			return false
+		}
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == core.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !toleratesTaint(pod.Spec.Tolerations, taint) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasAlternativePlacement tells if at least one of the nodes, other than the one of the pod, is compatible with the placement constraints of the pod
+func HasAlternativePlacement(pod *core.Pod, nodes []*core.Node) bool {
+	for _, n := range nodes {
+		if n.GetName() == pod.Spec.NodeName {
+			continue
+		}
+		if PodFitsNode(pod, n) {
+			return true
+		}
+	}
+	return false
+}
+
+func toleratesTaint(tolerations []core.Toleration, taint *core.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchNodeSelectorTerms returns true if one of the terms matches the node, the terms are ORed
+func matchNodeSelectorTerms(terms []core.NodeSelectorTerm, node *core.Node) bool {
+	for _, term := range terms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			// an empty term matches no objects
+			continue
+		}
+		if matchNodeSelectorRequirements(term.MatchExpressions, labels.Set(node.GetLabels())) &&
+			matchNodeSelectorRequirements(term.MatchFields, labels.Set{"metadata.name": node.GetName()}) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchNodeSelectorRequirements(requirements []core.NodeSelectorRequirement, set labels.Set) bool {
+	for _, r := range requirements {
+		var op selection.Operator
+		switch r.Operator {
+		case core.NodeSelectorOpIn:
+			op = selection.In
+		case core.NodeSelectorOpNotIn:
+			op = selection.NotIn
+		case core.NodeSelectorOpExists:
+			op = selection.Exists
+		case core.NodeSelectorOpDoesNotExist:
+			op = selection.DoesNotExist
+		case core.NodeSelectorOpGt:
+			op = selection.GreaterThan
+		case core.NodeSelectorOpLt:
+			op = selection.LessThan
+		default:
+			return false
+		}
+		req, err := labels.NewRequirement(r.Key, op, r.Values)
+		if err != nil {
+			return false
+		}
+		if !req.Matches(set) {
+			return false
+		}
+	}
+	return true
+}
+
+// podsWithoutAlternativePlacement returns the namespace/name of the pods that cannot be placed on any of the other nodes and that do not have the acknowledgment annotation
+func podsWithoutAlternativePlacement(pods []*core.Pod, nodes []*core.Node, store RuntimeObjectStore) []string {
+	var stuck []string
+	for _, p := range pods {
+		if ack, _ := GetAnnotationFromPodOrController(EvictWithoutAlternativePlacementAnnotationKey, p, store); strings.EqualFold(ack, "true") {
+			continue
+		}
+		if !HasAlternativePlacement(p, nodes) {
+			stuck = append(stuck, p.GetNamespace()+"/"+p.GetName())
+		}
+	}
+	return stuck
+}
@@ -0,0 +1,90 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodFitsNode(t *testing.T) {
+	gpuNode := &core.Node{ObjectMeta: meta.ObjectMeta{Name: "gpu-node", Labels: map[string]string{"hardware": "gpu", "zone": "a"}}}
+	requiredAffinity := func(requirements ...core.NodeSelectorRequirement) *core.Affinity {
+		return &core.Affinity{NodeAffinity: &core.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &core.NodeSelector{
+			NodeSelectorTerms: []core.NodeSelectorTerm{{MatchExpressions: requirements}},
+		}}}
+	}
+
+	tests := []struct {
+		name     string
+		spec     core.PodSpec
+		node     *core.Node
+		expected bool
+	}{
+		{
+			name:     "no constraint",
+			node:     gpuNode,
+			expected: true,
+		},
+		{
+			name:     "matching node selector",
+			spec:     core.PodSpec{NodeSelector: map[string]string{"hardware": "gpu"}},
+			node:     gpuNode,
+			expected: true,
+		},
+		{
+			name:     "node selector not matching",
+			spec:     core.PodSpec{NodeSelector: map[string]string{"hardware": "fpga"}},
+			node:     gpuNode,
+			expected: false,
+		},
+		{
+			name:     "matching required affinity",
+			spec:     core.PodSpec{Affinity: requiredAffinity(core.NodeSelectorRequirement{Key: "zone", Operator: core.NodeSelectorOpIn, Values: []string{"a", "b"}})},
+			node:     gpuNode,
+			expected: true,
+		},
+		{
+			name:     "required affinity not matching",
+			spec:     core.PodSpec{Affinity: requiredAffinity(core.NodeSelectorRequirement{Key: "zone", Operator: core.NodeSelectorOpNotIn, Values: []string{"a"}})},
+			node:     gpuNode,
+			expected: false,
+		},
+		{
+			name: "required affinity on the node name",
+			spec: core.PodSpec{Affinity: &core.Affinity{NodeAffinity: &core.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &core.NodeSelector{
+				NodeSelectorTerms: []core.NodeSelectorTerm{{MatchFields: []core.NodeSelectorRequirement{{Key: "metadata.name", Operator: core.NodeSelectorOpIn, Values: []string{"other-node"}}}}},
+			}}}},
+			node:     gpuNode,
+			expected: false,
+		},
+		{
+			name:     "unschedulable node",
+			node:     &core.Node{ObjectMeta: meta.ObjectMeta{Name: "cordoned"}, Spec: core.NodeSpec{Unschedulable: true}},
+			expected: false,
+		},
+		{
+			name:     "taint not tolerated",
+			node:     &core.Node{ObjectMeta: meta.ObjectMeta{Name: "tainted"}, Spec: core.NodeSpec{Taints: []core.Taint{{Key: "dedicated", Value: "db", Effect: core.TaintEffectNoSchedule}}}},
+			expected: false,
+		},
+		{
+			name:     "taint tolerated",
+			spec:     core.PodSpec{Tolerations: []core.Toleration{{Key: "dedicated", Operator: core.TolerationOpEqual, Value: "db", Effect: core.TaintEffectNoSchedule}}},
+			node:     &core.Node{ObjectMeta: meta.ObjectMeta{Name: "tainted"}, Spec: core.NodeSpec{Taints: []core.Taint{{Key: "dedicated", Value: "db", Effect: core.TaintEffectNoSchedule}}}},
+			expected: true,
+		},
+		{
+			name:     "prefer no schedule taint",
+			node:     &core.Node{ObjectMeta: meta.ObjectMeta{Name: "tainted"}, Spec: core.NodeSpec{Taints: []core.Taint{{Key: "dedicated", Effect: core.TaintEffectPreferNoSchedule}}}},
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &core.Pod{ObjectMeta: meta.ObjectMeta{Name: podName}, Spec: tt.spec}
+			assert.Equal(t, tt.expected, PodFitsNode(pod, tt.node))
+		})
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/oklog/run"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 	core "k8s.io/api/core/v1"
@@ -40,7 +41,7 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 
-	kubernetestrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/k8s.io/client-go/kubernetes"
+	"github.com/planetlabs/draino/internal/tracing"
 )
 
 // Component is the name of this application.
@@ -80,7 +81,7 @@ func BuildConfigFromFlags(apiserver, kubecfg string) (*rest.Config, error) {
 		return nil, err
 	}
 
-	config.WrapTransport = kubernetestrace.WrapRoundTripper
+	config.WrapTransport = tracing.WrapKubernetesRoundTripper
 	return config, nil
 }
 
@@ -255,6 +256,9 @@ func TracedLogger(context context.Context, logger *zap.Logger) *zap.Logger {
 		spanID := strconv.FormatUint(sctx.SpanID(), 10)
 		return logger.With(zap.String("dd.trace_id", traceID), zap.String("dd.span_id", spanID))
 	}
+	if sctx := trace.SpanContextFromContext(context); sctx.IsValid() {
+		return logger.With(zap.String("trace_id", sctx.TraceID().String()), zap.String("span_id", sctx.SpanID().String()))
+	}
 	return logger
 }
 
@@ -319,6 +323,73 @@ func GetControllerForPod(pod *core.Pod, store RuntimeObjectStore) (ctrl metav1.O
 	return nil, false
 }
 
+// ObjectRef identifies an object of the ownership chain of a pod
+type ObjectRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (r ObjectRef) String() string {
+	return r.Kind + "/" + r.Name
+}
+
+// GetOwnerChain returns the ownership chain of the pod, starting from its direct controller.
+// Only statefulSets and deployments are resolved in the store, the chain stops at the first owner that cannot be found.
+func GetOwnerChain(pod *core.Pod, store RuntimeObjectStore) []ObjectRef {
+	chain, _ := getOwnerChainWithGroupKinds(pod, store)
+	return chain
+}
+
+// getOwnerChainWithGroupKinds returns the chain of GetOwnerChain, and the group and kind of each of its owners
+func getOwnerChainWithGroupKinds(pod *core.Pod, store RuntimeObjectStore) ([]ObjectRef, []schema.GroupKind) {
+	var chain []ObjectRef
+	var groupKinds []schema.GroupKind
+	owner := metav1.GetControllerOf(pod)
+	for owner != nil {
+		chain = append(chain, ObjectRef{Kind: owner.Kind, Namespace: pod.Namespace, Name: owner.Name})
+		groupKinds = append(groupKinds, schema.FromAPIVersionAndKind(owner.APIVersion, owner.Kind).GroupKind())
+		if store == nil {
+			break
+		}
+		var next metav1.Object
+		switch owner.Kind {
+		case "ReplicaSet":
+			idx := strings.LastIndex(owner.Name, "-")
+			if idx < 0 {
+				break
+			}
+			if deployment, err := store.Deployments().Get(pod.Namespace, owner.Name[:idx]); err == nil {
+				chain = append(chain, ObjectRef{Kind: "Deployment", Namespace: pod.Namespace, Name: deployment.Name})
+				groupKinds = append(groupKinds, schema.GroupKind{Group: "apps", Kind: "Deployment"})
+				next = deployment
+			}
+		case "StatefulSet":
+			if sts, err := store.StatefulSets().Get(pod.Namespace, owner.Name); err == nil {
+				next = sts
+			}
+		case "Deployment":
+			if deployment, err := store.Deployments().Get(pod.Namespace, owner.Name); err == nil {
+				next = deployment
+			}
+		}
+		if next == nil {
+			break
+		}
+		owner = metav1.GetControllerOfNoCopy(next)
+	}
+	return chain, groupKinds
+}
+
+// FormatOwnerChain returns a compact representation of the chain, like "ReplicaSet/app-5d8f -> Deployment/app"
+func FormatOwnerChain(chain []ObjectRef) string {
+	refs := make([]string, len(chain))
+	for i := range chain {
+		refs[i] = chain[i].String()
+	}
+	return strings.Join(refs, " -> ")
+}
+
 func IsPodFromStatefulset(pod *core.Pod) bool {
 	for _, r := range pod.OwnerReferences {
 		if r.Kind == "StatefulSet" {
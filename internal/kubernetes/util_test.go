@@ -1,14 +1,23 @@
 package kubernetes
 
 import (
+	"context"
 	"reflect"
+	"strconv"
 	"testing"
 
 	openapi_v2 "github.com/google/gnostic/openapiv2"
+	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	appsv1 "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/openapi"
 	"k8s.io/client-go/rest"
 )
@@ -179,6 +188,30 @@ func newFakeDiscoveryClient() discovery.DiscoveryInterface {
 	return &fakeDiscoveryInterface{}
 }
 
+func TestTracedLoggerForNode(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	node := &core.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+	observed, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(observed)
+
+	TracedLoggerForNode(context.Background(), node, logger).Info("no span")
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "drain")
+	TracedLoggerForNode(ctx, node, logger).Info("in span")
+	span.Finish()
+
+	entries := logs.All()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, nodeName, entries[0].ContextMap()["node"])
+	assert.NotContains(t, entries[0].ContextMap(), "dd.trace_id")
+	assert.NotContains(t, entries[0].ContextMap(), "dd.span_id")
+
+	fields := entries[1].ContextMap()
+	assert.Equal(t, nodeName, fields["node"])
+	assert.Equal(t, strconv.FormatUint(span.Context().TraceID(), 10), fields["dd.trace_id"])
+	assert.Equal(t, strconv.FormatUint(span.Context().SpanID(), 10), fields["dd.span_id"])
+}
+
 func TestGetAPIResourcesForGVK(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -241,3 +274,40 @@ func TestGetAPIResourcesForGVK(t *testing.T) {
 		})
 	}
 }
+
+func TestGetOwnerChain(t *testing.T) {
+	isController := true
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "ns"}}
+	tests := []struct {
+		name     string
+		pod      *core.Pod
+		expected []ObjectRef
+	}{
+		{
+			name: "deployment owned pod",
+			pod: &core.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app-5d8f-x2k", Namespace: "ns", OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: "app-5d8f", Controller: &isController},
+			}}},
+			expected: []ObjectRef{
+				{Kind: "ReplicaSet", Namespace: "ns", Name: "app-5d8f"},
+				{Kind: "Deployment", Namespace: "ns", Name: "app"},
+			},
+		},
+		{
+			name:     "bare pod",
+			pod:      &core.Pod{ObjectMeta: metav1.ObjectMeta{Name: "bare", Namespace: "ns"}},
+			expected: nil,
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store, closeFunc := RunStoreForTest(ctx, fake.NewSimpleClientset(deployment))
+	defer closeFunc()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, GetOwnerChain(tt.pod, store))
+		})
+	}
+	assert.Equal(t, "ReplicaSet/app-5d8f -> Deployment/app", FormatOwnerChain(tests[0].expected))
+}
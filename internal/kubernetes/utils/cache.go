@@ -22,6 +22,17 @@ type TTLCache[T any] interface {
 	// Get returns the element of the given key
 	// The boolean will be false if there is no element with this key in the cache
 	Get(string, time.Time) (T, bool)
+	// Delete removes the element of the given key, if any
+	Delete(string)
+	// Entries returns all the elements of the cache that did not reach their TTL
+	Entries(time.Time) []TTLCacheEntry[T]
+}
+
+// TTLCacheEntry is an element of the cache with its expiry
+type TTLCacheEntry[T any] struct {
+	Key   string
+	Value T
+	Until time.Time
 }
 
 type ttlCacheImpl[T any] struct {
@@ -103,3 +114,23 @@ func (c *ttlCacheImpl[T]) Get(key string, now time.Time) (T, bool) {
 
 	return parsed.entry, true
 }
+
+func (c *ttlCacheImpl[T]) Delete(key string) {
+	c.cache.Delete(key)
+}
+
+func (c *ttlCacheImpl[T]) Entries(now time.Time) []TTLCacheEntry[T] {
+	var res []TTLCacheEntry[T]
+	for _, key := range c.cache.ListKeys() {
+		entry, exist := c.cache.Get(key)
+		if !exist {
+			continue
+		}
+		parsed, ok := entry.(ttlEntry[T])
+		if !ok || parsed.until.Before(now) {
+			continue
+		}
+		res = append(res, TTLCacheEntry[T]{Key: key, Value: parsed.entry, Until: parsed.until})
+	}
+	return res
+}
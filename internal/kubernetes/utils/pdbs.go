@@ -9,3 +9,17 @@ func GetPDBNames(pdbs []*policyv1.PodDisruptionBudget) []string {
 	}
 	return res
 }
+
+// IsPDBPermanentlyBlocked tells if the PDB does not allow any disruption while all the pods it covers are healthy.
+// In that case no replacement is pending, so the budget will not be released by waiting: the PDB is too strict
+// for the number of pods (for example maxUnavailable=0 or minAvailable equal to the number of replicas).
+// A status that was not computed for the latest generation of the PDB is never considered as permanently blocked.
+func IsPDBPermanentlyBlocked(pdb *policyv1.PodDisruptionBudget) bool {
+	if pdb.Status.ObservedGeneration < pdb.Generation {
+		return false
+	}
+	return pdb.Status.DisruptionsAllowed == 0 &&
+		pdb.Status.ExpectedPods > 0 &&
+		pdb.Status.CurrentHealthy == pdb.Status.ExpectedPods &&
+		pdb.Status.CurrentHealthy <= pdb.Status.DesiredHealthy
+}
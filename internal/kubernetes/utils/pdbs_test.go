@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsPDBPermanentlyBlocked(t *testing.T) {
+	tests := []struct {
+		name       string
+		generation int64
+		status     policyv1.PodDisruptionBudgetStatus
+		expected   bool
+	}{
+		{
+			name:     "disruption allowed",
+			status:   policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 1, CurrentHealthy: 3, DesiredHealthy: 2, ExpectedPods: 3},
+			expected: false,
+		},
+		{
+			name:     "all pods healthy and no disruption allowed",
+			status:   policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0, CurrentHealthy: 3, DesiredHealthy: 3, ExpectedPods: 3},
+			expected: true,
+		},
+		{
+			name:     "min available above the number of pods",
+			status:   policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0, CurrentHealthy: 2, DesiredHealthy: 3, ExpectedPods: 2},
+			expected: true,
+		},
+		{
+			name:     "replacement pending",
+			status:   policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0, CurrentHealthy: 2, DesiredHealthy: 2, ExpectedPods: 3},
+			expected: false,
+		},
+		{
+			name:     "no pod covered",
+			status:   policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+			expected: false,
+		},
+		{
+			name:       "status not observed for the latest generation",
+			generation: 2,
+			status:     policyv1.PodDisruptionBudgetStatus{ObservedGeneration: 1, DisruptionsAllowed: 0, CurrentHealthy: 3, DesiredHealthy: 3, ExpectedPods: 3},
+			expected:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdb := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: "pdb", Generation: tt.generation}, Status: tt.status}
+			assert.Equal(t, tt.expected, IsPDBPermanentlyBlocked(pdb))
+		})
+	}
+}
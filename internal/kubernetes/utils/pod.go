@@ -21,3 +21,14 @@ func GetPodNames(pods []*corev1.Pod) []string {
 	}
 	return result
 }
+
+// HasPreStopHook returns true if one of the containers of the pod has a PreStop hook.
+// The hook runs during the termination grace period of the pod, before the containers receive SIGTERM.
+func HasPreStopHook(pod *corev1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Lifecycle != nil && container.Lifecycle.PreStop != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestHasPreStopHook(t *testing.T) {
+	preStop := &corev1.Lifecycle{PreStop: &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"sleep", "60"}}}}
+	tests := []struct {
+		name       string
+		containers []corev1.Container
+		expected   bool
+	}{
+		{
+			name:       "no lifecycle",
+			containers: []corev1.Container{{Name: "app"}},
+			expected:   false,
+		},
+		{
+			name:       "PostStart hook only",
+			containers: []corev1.Container{{Name: "app", Lifecycle: &corev1.Lifecycle{PostStart: preStop.PreStop}}},
+			expected:   false,
+		},
+		{
+			name:       "PreStop hook on one of the containers",
+			containers: []corev1.Container{{Name: "app"}, {Name: "proxy", Lifecycle: preStop}},
+			expected:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, HasPreStopHook(&corev1.Pod{Spec: corev1.PodSpec{Containers: tt.containers}}))
+		})
+	}
+}
@@ -7,6 +7,11 @@ func DurationPtr(dur time.Duration) *time.Duration {
 	return &dur
 }
 
+// BoolPtr returns a pointer to the given bool
+func BoolPtr(b bool) *bool {
+	return &b
+}
+
 // StrPtr returns a pointer to the given string
 func StrPtr(str string) *string {
 	return &str
@@ -159,6 +159,8 @@ type PodStore interface {
 	ListPodsByStatus(podStatus string) ([]*core.Pod, error)
 	GetPodCount() (int, error)
 	ListPodsForClaim(namespace, claimName string) ([]*core.Pod, error)
+	// List all the pods of a namespace controlled by the given controller
+	ListPodsForController(namespace, kind, name string) ([]*core.Pod, error)
 }
 
 // A PodWatch is a cache of pod resources that notifies registered
@@ -172,6 +174,7 @@ var _ PodStore = &PodWatch{}
 const podNodeNameIndexField = ".spec.nodeName"
 const podStatusIndexField = ".status.phase"
 const podClaimIndexField = ".spec.phase"
+const podControllerIndexField = ".metadata.ownerReferences.controller"
 
 // NewPodWatch creates a watch on pod resources. Pods are cached and the
 // provided ResourceEventHandlers are called when the cache changes.
@@ -209,6 +212,16 @@ func NewPodWatch(ctx context.Context, c kubernetes.Interface) *PodWatch {
 			}
 			return claims, nil
 		},
+		podControllerIndexField: func(obj interface{}) ([]string, error) {
+			p, ok := obj.(*core.Pod)
+			if !ok {
+				return []string{""}, nil
+			}
+			if ctrl := meta.GetControllerOf(p); ctrl != nil {
+				return []string{p.Namespace + "/" + ctrl.Kind + "/" + ctrl.Name}, nil
+			}
+			return nil, nil
+		},
 	})
 	return &PodWatch{i}
 }
@@ -285,6 +298,26 @@ func (w *PodWatch) ListPodsForClaim(namespace, claimName string) ([]*core.Pod, e
 	return pods, nil
 }
 
+func (w *PodWatch) ListPodsForController(namespace, kind, name string) ([]*core.Pod, error) {
+	if !w.HasSynced() {
+		return nil, errors.New("pod informer not yet synced")
+	}
+	objs, err := w.GetIndexer().ByIndex(podControllerIndexField, namespace+"/"+kind+"/"+name)
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]*core.Pod, len(objs))
+	for i := range objs {
+		p, ok := objs[i].(*core.Pod)
+		if !ok {
+			return nil, errors.New("unexpected object type in Pod store")
+		}
+		pods[i] = p
+	}
+	sort.Sort(PodsSortedByName(pods))
+	return pods, nil
+}
+
 type PodsSortedByName []*core.Pod
 
 func (a PodsSortedByName) Len() int           { return len(a) }
@@ -0,0 +1,135 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	core "k8s.io/api/core/v1"
+)
+
+// WorkloadUnavailabilityCoordinator tracks the evictions in flight per workload across all the concurrent drains, so that draino never makes
+// more than a percentage of the pods of a workload unavailable at once. It is a soft cap on top of the PDBs: only the evictions of this draino
+// instance are accounted for, from the reservation of the eviction until the pod is deleted.
+// The workload of a pod is the top of its chain of owners, so that the ReplicaSets of a Deployment share the same cap during a rollout.
+type WorkloadUnavailabilityCoordinator struct {
+	sync.Mutex
+	store                 RuntimeObjectStore
+	maxUnavailablePercent int
+	// inFlight is keyed by namespace/kind/name of the workloads
+	inFlight map[string]int
+	// released is closed and replaced each time an eviction is released, to wake up the waiting evictions
+	released chan struct{}
+}
+
+// NewWorkloadUnavailabilityCoordinator returns a coordinator allowing at most maxUnavailablePercent of the pods of a workload to be evicted at once.
+// The store is used to get the workloads and their pods.
+func NewWorkloadUnavailabilityCoordinator(store RuntimeObjectStore, maxUnavailablePercent int) *WorkloadUnavailabilityCoordinator {
+	return &WorkloadUnavailabilityCoordinator{
+		store:                 store,
+		maxUnavailablePercent: maxUnavailablePercent,
+		inFlight:              map[string]int{},
+		released:              make(chan struct{}),
+	}
+}
+
+// MaxInFlight returns the number of evictions allowed at once for a workload of the given size.
+// At least one eviction is always allowed, so that the pods of the small workloads can be drained.
+func (c *WorkloadUnavailabilityCoordinator) MaxInFlight(size int) int {
+	max := size * c.maxUnavailablePercent / 100
+	if max < 1 {
+		return 1
+	}
+	return max
+}
+
+// Reserve waits until the eviction of the pod does not breach the cap of its workload. It returns a release function to call once the eviction is over.
+// Pods without controller are not coordinated. A WorkloadUnavailabilityCapError is returned if the wait times out.
+func (c *WorkloadUnavailabilityCoordinator) Reserve(ctx context.Context, pod *core.Pod, abort <-chan struct{}, timeout time.Duration) (func(), error) {
+	chain := GetOwnerChain(pod, c.store)
+	if len(chain) == 0 {
+		return func() {}, nil
+	}
+	top := chain[len(chain)-1]
+	workload := top.Namespace + "/" + top.Kind + "/" + top.Name
+	size, err := c.getWorkloadSize(top, chain[0])
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the size of %s %s/%s: %w", top.Kind, top.Namespace, top.Name, err)
+	}
+	max := c.MaxInFlight(size)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		c.Lock()
+		if c.inFlight[workload] < max {
+			c.inFlight[workload]++
+			c.Unlock()
+			return func() { c.release(workload) }, nil
+		}
+		released := c.released
+		c.Unlock()
+
+		select {
+		case <-released:
+			continue
+		case <-abort:
+			return nil, errors.New("pod eviction aborted")
+		case <-ctx.Done():
+			return nil, fmt.Errorf("pod eviction aborted while waiting for the unavailability cap of %s: %w", workload, ctx.Err())
+		case <-timer.C:
+		}
+		return nil, WorkloadUnavailabilityCapError{PodName: pod.Namespace + "/" + pod.Name, Workload: workload, Max: max}
+	}
+}
+
+func (c *WorkloadUnavailabilityCoordinator) release(workload string) {
+	c.Lock()
+	defer c.Unlock()
+	if c.inFlight[workload] <= 1 {
+		delete(c.inFlight, workload)
+	} else {
+		c.inFlight[workload]--
+	}
+	close(c.released)
+	c.released = make(chan struct{})
+}
+
+// getWorkloadSize returns the desired replicas of the Deployments and StatefulSets of the store, else the number of pods of the controller
+func (c *WorkloadUnavailabilityCoordinator) getWorkloadSize(workload, controller ObjectRef) (int, error) {
+	switch workload.Kind {
+	case "Deployment":
+		if deployment, err := c.store.Deployments().Get(workload.Namespace, workload.Name); err == nil {
+			return desiredReplicas(deployment.Spec.Replicas), nil
+		}
+	case "StatefulSet":
+		if sts, err := c.store.StatefulSets().Get(workload.Namespace, workload.Name); err == nil {
+			return desiredReplicas(sts.Spec.Replicas), nil
+		}
+	}
+	pods, err := c.store.Pods().ListPodsForController(controller.Namespace, controller.Kind, controller.Name)
+	if err != nil {
+		return 0, err
+	}
+	return len(pods), nil
+}
+
+// desiredReplicas returns the replicas of a workload spec, 1 if they are not set as for the API server defaults
+func desiredReplicas(replicas *int32) int {
+	if replicas == nil {
+		return 1
+	}
+	return int(*replicas)
+}
+
+type WorkloadUnavailabilityCapError struct {
+	PodName  string
+	Workload string
+	Max      int
+}
+
+func (e WorkloadUnavailabilityCapError) Error() string {
+	return fmt.Sprintf("cannot evict pod %s, %d pod(s) of %s are already being evicted", e.PodName, e.Max, e.Workload)
+}
@@ -0,0 +1,117 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// workloadPods returns count pods controlled by the ReplicaSet name, spread over the nodes
+func workloadPods(name string, count int, nodes ...string) []*core.Pod {
+	pods := make([]*core.Pod, count)
+	for i := range pods {
+		pods[i] = &core.Pod{
+			ObjectMeta: meta.ObjectMeta{
+				Name:            fmt.Sprintf("%s-%d", name, i),
+				Namespace:       "ns",
+				OwnerReferences: []meta.OwnerReference{{Controller: &isController, Kind: kindReplicaSet, Name: name}},
+			},
+			Spec: core.PodSpec{NodeName: nodes[i%len(nodes)], TerminationGracePeriodSeconds: &podGracePeriodSeconds},
+		}
+	}
+	return pods
+}
+
+func TestWorkloadUnavailabilityCoordinator_MaxInFlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		percent  int
+		size     int
+		expected int
+	}{
+		{name: "percentage of the pods", percent: 25, size: 8, expected: 2},
+		{name: "rounded down", percent: 50, size: 5, expected: 2},
+		{name: "at least one", percent: 10, size: 3, expected: 1},
+		{name: "empty workload", percent: 50, size: 0, expected: 1},
+		{name: "all the pods", percent: 100, size: 4, expected: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewWorkloadUnavailabilityCoordinator(nil, tt.percent).MaxInFlight(tt.size))
+		})
+	}
+}
+
+func TestWorkloadUnavailabilityCoordinator_Reserve(t *testing.T) {
+	pods := workloadPods("web-5d8f7c", 4, nodeName)
+	objects := make([]runtime.Object, len(pods))
+	for i := range pods {
+		objects[i] = pods[i]
+	}
+	ctx := context.Background()
+	store, closeFunc := RunStoreForTest(ctx, fake.NewSimpleClientset(objects...))
+	defer closeFunc()
+	c := NewWorkloadUnavailabilityCoordinator(store, 50)
+
+	release0, err := c.Reserve(ctx, pods[0], nil, time.Second)
+	assert.NoError(t, err)
+	release1, err := c.Reserve(ctx, pods[1], nil, time.Second)
+	assert.NoError(t, err)
+
+	// the cap is reached, the third eviction times out
+	_, err = c.Reserve(ctx, pods[2], nil, 10*time.Millisecond)
+	assert.Equal(t, WorkloadUnavailabilityCapError{PodName: "ns/web-5d8f7c-2", Workload: "ns/ReplicaSet/web-5d8f7c", Max: 2}, err)
+	assert.Equal(t, WorkloadUnavailabilityCap, GetFailureCause(fmt.Errorf("cannot evict pod: %w", err)))
+
+	// the third eviction starts as soon as one of the others is released
+	reserved := make(chan error)
+	go func() {
+		_, err := c.Reserve(ctx, pods[2], nil, time.Minute)
+		reserved <- err
+	}()
+	release0()
+	assert.NoError(t, <-reserved)
+
+	// the evictions waiting for the cap are stopped with the drain
+	abort := make(chan struct{})
+	close(abort)
+	_, err = c.Reserve(ctx, pods[3], abort, time.Minute)
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &WorkloadUnavailabilityCapError{}), "an aborted wait is not a cap failure")
+
+	// pods without controller are not coordinated
+	release, err := c.Reserve(ctx, &core.Pod{ObjectMeta: meta.ObjectMeta{Name: "standalone", Namespace: "ns"}}, abort, time.Minute)
+	assert.NoError(t, err)
+	release()
+
+	release1()
+	assert.Equal(t, map[string]int{"ns/ReplicaSet/web-5d8f7c": 1}, c.inFlight)
+}
+
+func TestWorkloadUnavailabilityCoordinator_Reserve_Rollout(t *testing.T) {
+	replicas := int32(4)
+	deployment := &appsv1.Deployment{ObjectMeta: meta.ObjectMeta{Name: "web", Namespace: "ns"}, Spec: appsv1.DeploymentSpec{Replicas: &replicas}}
+	oldPods, newPods := workloadPods("web-5d8f7c", 2, nodeName), workloadPods("web-7b9c4d", 2, nodeName)
+	ctx := context.Background()
+	store, closeFunc := RunStoreForTest(ctx, fake.NewSimpleClientset(deployment, oldPods[0], oldPods[1], newPods[0], newPods[1]))
+	defer closeFunc()
+	c := NewWorkloadUnavailabilityCoordinator(store, 50)
+
+	// the ReplicaSets of the deployment share the cap
+	_, err := c.Reserve(ctx, oldPods[0], nil, time.Second)
+	assert.NoError(t, err)
+	_, err = c.Reserve(ctx, newPods[0], nil, time.Second)
+	assert.NoError(t, err)
+	_, err = c.Reserve(ctx, newPods[1], nil, 10*time.Millisecond)
+	assert.Equal(t, WorkloadUnavailabilityCapError{PodName: "ns/web-7b9c4d-1", Workload: "ns/Deployment/web", Max: 2}, err)
+	assert.Equal(t, map[string]int{"ns/Deployment/web": 2}, c.inFlight)
+}
@@ -2,6 +2,9 @@ package limit
 
 import (
 	"context"
+	"sync"
+
+	"golang.org/x/time/rate"
 	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/utils/clock"
 )
@@ -10,20 +13,34 @@ type RateLimiter interface {
 	// TryAccept returns true if a token is taken immediately. Otherwise,
 	// it returns false.
 	TryAccept() bool
+	// TryAcceptN returns true if n tokens are taken immediately. Otherwise,
+	// it returns false and no token is taken.
+	// n is capped to the burst, so that a reservation bigger than the bucket can still be accepted once the bucket is full.
+	TryAcceptN(n int) bool
 }
 
+// rateLimiterImpl is a token bucket, like the one of flowcontrol, that can take several tokens at once
 type rateLimiterImpl struct {
-	limiter flowcontrol.RateLimiter
+	clock   clock.Clock
+	limiter *rate.Limiter
 }
 
 func NewRateLimiter(clock clock.Clock, qps float32, burst int) RateLimiter {
 	return &rateLimiterImpl{
-		limiter: flowcontrol.NewTokenBucketRateLimiterWithClock(qps, burst, clock),
+		clock:   clock,
+		limiter: rate.NewLimiter(rate.Limit(qps), burst),
 	}
 }
 
 func (limiter *rateLimiterImpl) TryAccept() bool {
-	return limiter.limiter.TryAccept()
+	return limiter.TryAcceptN(1)
+}
+
+func (limiter *rateLimiterImpl) TryAcceptN(n int) bool {
+	if burst := limiter.limiter.Burst(); n > burst {
+		n = burst
+	}
+	return limiter.limiter.AllowN(limiter.clock.Now(), n)
 }
 
 // TypedRateLimiter is a rate limiter that implements different rate limiters based on the given type t.
@@ -33,6 +50,9 @@ type TypedRateLimiter interface {
 	TryAccept(t string) bool
 	// Wait takes a type t and returns nil if a token is taken before the Context is done.
 	Wait(ctx context.Context, t string) error
+	// SetConfigurations replaces the configurations of the types. The rate limiters of the types whose QPS or burst changed
+	// are recreated with the new configurations, the others keep their tokens.
+	SetConfigurations(configurations map[string]RateLimiterConfiguration)
 }
 
 type RateLimiterConfiguration struct {
@@ -42,6 +62,7 @@ type RateLimiterConfiguration struct {
 
 // typedRateLimiterImpl is a wrapper to abstract the flowcontrol rate limiter to the other interal parts of the code
 type typedRateLimiterImpl struct {
+	sync.Mutex
 	clock        clock.Clock
 	defaultQPS   float32
 	defaultBurst int
@@ -69,20 +90,38 @@ func (limit *typedRateLimiterImpl) TryAccept(t string) bool {
 	return rateLimiter.TryAccept()
 }
 
+func (limit *typedRateLimiterImpl) SetConfigurations(configurations map[string]RateLimiterConfiguration) {
+	limit.Lock()
+	defer limit.Unlock()
+	previousConfigs := limit.rlConfigs
+	limit.rlConfigs = configurations
+	for t := range limit.rateLimiters {
+		previousQPS, previousBurst := limit.resolveConfiguration(previousConfigs[t])
+		qps, burst := limit.resolveConfiguration(configurations[t])
+		if qps != previousQPS || burst != previousBurst {
+			delete(limit.rateLimiters, t)
+		}
+	}
+}
+
+// resolveConfiguration returns the QPS and burst of the configuration, using the defaults for the unset values
+func (limit *typedRateLimiterImpl) resolveConfiguration(cfg RateLimiterConfiguration) (float32, int) {
+	qps, burst := limit.defaultQPS, limit.defaultBurst
+	if cfg.QPS != nil {
+		qps = *cfg.QPS
+	}
+	if cfg.Burst != nil {
+		burst = *cfg.Burst
+	}
+	return qps, burst
+}
+
 func (limit *typedRateLimiterImpl) getRateLimiter(t string) flowcontrol.RateLimiter {
+	limit.Lock()
+	defer limit.Unlock()
 	if _, exist := limit.rateLimiters[t]; !exist {
-		cfg, ok := limit.rlConfigs[t]
-		if !ok {
-			cfg.QPS = &limit.defaultQPS
-			cfg.Burst = &limit.defaultBurst
-		}
-		if cfg.QPS == nil {
-			cfg.QPS = &limit.defaultQPS
-		}
-		if cfg.Burst == nil {
-			cfg.Burst = &limit.defaultBurst
-		}
-		limit.rateLimiters[t] = flowcontrol.NewTokenBucketRateLimiterWithClock(*cfg.QPS, *cfg.Burst, limit.clock)
+		qps, burst := limit.resolveConfiguration(limit.rlConfigs[t])
+		limit.rateLimiters[t] = flowcontrol.NewTokenBucketRateLimiterWithClock(qps, burst, limit.clock)
 	}
 	return limit.rateLimiters[t]
 }
@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"go.opencensus.io/stats"
@@ -35,6 +36,9 @@ const (
 	OutOfScopeLabelValue     = "out-of-scope"
 	nodeOptionsMetricName    = "node_options_nodes_total"
 	nodeOptionsCPUMetricName = "node_options_cpu_total"
+
+	// reconcileBackoffCap is the maximum delay between two reconciles after consecutive failures
+	reconcileBackoffCap = 10 * time.Minute
 )
 
 type DrainoConfigurationObserver interface {
@@ -46,6 +50,7 @@ type DrainoConfigurationObserver interface {
 var (
 	MeasureNodeLabelPatchRateLimited = stats.Int64("draino/node_patch_ratelimited", "Number of rate limited patch label on nodes", stats.UnitDimensionless)
 	MeasureNodeLabelPatchFailed      = stats.Int64("draino/node_patch_failed", "Number of failure while patching label on nodes", stats.UnitDimensionless)
+	MeasureReconcileErrors           = stats.Int64("draino/observer_reconcile_errors", "Number of failed reconciles of the observer", stats.UnitDimensionless)
 )
 
 // metricsObjectsForObserver groups all the object required to serve the metrics
@@ -58,6 +63,7 @@ type metricsObjectsForObserver struct {
 
 	nodePatchRatelimitView *view.View
 	nodePatchFailureView   *view.View
+	reconcileErrorsView    *view.View
 }
 
 // initializeQueueMetrics initialize the metrics that are used to count internal retries and rateLimit
@@ -82,6 +88,16 @@ func (g *metricsObjectsForObserver) initializeQueueMetrics() {
 		}
 		view.Register(g.nodePatchFailureView)
 	}
+	if g.reconcileErrorsView == nil {
+		g.reconcileErrorsView = &view.View{
+			Name:        "observer_reconcile_errors_total",
+			Measure:     MeasureReconcileErrors,
+			Description: "Number of reconciles of the observer that have failed.",
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{},
+		}
+		view.Register(g.reconcileErrorsView)
+	}
 }
 
 // reset: replace existing gauges to eliminate obsolete series
@@ -154,6 +170,9 @@ type DrainoConfigurationObserverImpl struct {
 	// The consequence is that the metric is not 100% accurate when the controller starts. It converges after couple ou cycles.
 	queueNodeToBeUpdated workqueue.RateLimitingInterface
 	nodePatchLimiter     flowcontrol.RateLimiter // client side protection for APIServer
+	// nodeUpdateWorkers is the number of workers patching the labels of the queued nodes concurrently, at least one.
+	// The queue never hands the same node to two workers, and nodePatchLimiter is shared by all the workers.
+	nodeUpdateWorkers int
 
 	globalConfig        kubernetes.GlobalConfig
 	nodeFilterFunc      func(obj interface{}) bool
@@ -167,12 +186,54 @@ type DrainoConfigurationObserverImpl struct {
 
 	candidateFilter filters.Filter
 
+	// reconcileBackoff delays the next reconcile after consecutive failures
+	reconcileBackoff *reconcileBackoff
+	// nodePatchFailures counts the failed patches of the node labels since the last reconcile, accessed atomically
+	nodePatchFailures int32
+
 	metricsObjects metricsObjectsForObserver
 }
 
+// reconcileBackoff computes the delay before the next reconcile. The delay doubles with each consecutive failure, up to the cap, and is reset on success.
+type reconcileBackoff struct {
+	base     time.Duration
+	maxDelay time.Duration
+	failures int
+	next     time.Time
+}
+
+func newReconcileBackoff(base, maxDelay time.Duration) *reconcileBackoff {
+	return &reconcileBackoff{base: base, maxDelay: maxDelay}
+}
+
+// ready tells if a reconcile can run at the given time
+func (b *reconcileBackoff) ready(now time.Time) bool {
+	return !now.Before(b.next)
+}
+
+// failure registers a failed reconcile and returns the delay before the next one
+func (b *reconcileBackoff) failure(now time.Time) time.Duration {
+	b.failures++
+	delay := b.base
+	for i := 1; i < b.failures && delay < b.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > b.maxDelay {
+		delay = b.maxDelay
+	}
+	b.next = now.Add(delay)
+	return delay
+}
+
+// success resets the backoff
+func (b *reconcileBackoff) success() {
+	b.failures = 0
+	b.next = time.Time{}
+}
+
 var _ DrainoConfigurationObserver = &DrainoConfigurationObserverImpl{}
 
-func NewScopeObserver(client client.Interface, globalConfig kubernetes.GlobalConfig, runtimeObjectStore kubernetes.RuntimeObjectStore, analysisPeriod time.Duration, podFilterFunc, userOptInPodFilter, userOptOutPodFilter kubernetes.PodFilterFunc, nodeFilterFunc func(obj interface{}) bool, log *zap.Logger, retryWall drain.RetryWall, groupKeyGetter groups.GroupKeyGetter, runnerInfoGetter groups.RunnerInfoGetter, candidateFilter filters.Filter) DrainoConfigurationObserver {
+func NewScopeObserver(client client.Interface, globalConfig kubernetes.GlobalConfig, runtimeObjectStore kubernetes.RuntimeObjectStore, analysisPeriod time.Duration, nodeUpdateWorkers int, podFilterFunc, userOptInPodFilter, userOptOutPodFilter kubernetes.PodFilterFunc, nodeFilterFunc func(obj interface{}) bool, log *zap.Logger, retryWall drain.RetryWall, groupKeyGetter groups.GroupKeyGetter, runnerInfoGetter groups.RunnerInfoGetter, candidateFilter filters.Filter) DrainoConfigurationObserver {
 
 	// We are not adding a BucketRateLimiter to that list because the same nodes are going to be appended periodically if the update fails
 	// Failing nodes will already be in the queue with a retry. Added a BucketRL proved to be a problem here is the client side is not able to dequeue
@@ -197,10 +258,12 @@ func NewScopeObserver(client client.Interface, globalConfig kubernetes.GlobalCon
 		globalConfig:         globalConfig,
 		queueNodeToBeUpdated: workqueue.NewNamedRateLimitingQueue(workqueue.NewMaxOfRateLimiter(rateLimiters...), "nodeUpdater"),
 		nodePatchLimiter:     flowcontrol.NewTokenBucketRateLimiter(50, 10), // client side protection
+		nodeUpdateWorkers:    nodeUpdateWorkers,
 		retryWall:            retryWall,
 		groupKeyGetter:       groupKeyGetter,
 		runnerInfoGetter:     runnerInfoGetter,
 		candidateFilter:      candidateFilter,
+		reconcileBackoff:     newReconcileBackoff(2*analysisPeriod, reconcileBackoffCap), // the first delay must skip at least one tick
 	}
 	scopeObserver.metricsObjects.initializeQueueMetrics()
 
@@ -250,7 +313,7 @@ func (s *DrainoConfigurationObserverImpl) Run(stop <-chan struct{}) {
 		return s.runtimeObjectStore.Pods().HasSynced(), nil
 	})
 
-	go s.processQueueForNodeUpdates()
+	s.startNodeUpdateWorkers()
 	defer ticker.Stop()
 	for {
 		select {
@@ -258,102 +321,139 @@ func (s *DrainoConfigurationObserverImpl) Run(stop <-chan struct{}) {
 			s.queueNodeToBeUpdated.ShutDown()
 			return
 		case <-ticker.C:
-			// Let's print the queue size
-			s.logger.Info("queueNodeToBeUpdated", zap.Int("len", s.queueNodeToBeUpdated.Len()))
+			now := time.Now()
+			if !s.reconcileBackoff.ready(now) {
+				continue
+			}
+			if err := s.reconcile(); err != nil {
+				stats.Record(context.Background(), MeasureReconcileErrors.M(1))
+				delay := s.reconcileBackoff.failure(now)
+				s.logger.Error("Observer reconcile failed", zap.Error(err), zap.Int("consecutive_failures", s.reconcileBackoff.failures), zap.Duration("next_reconcile_in", delay))
+			} else {
+				s.reconcileBackoff.success()
+			}
+		}
+	}
+}
 
-			s.ProduceGroupRunnerMetrics()
+// reconcile updates the node labels and the metrics. It returns an error if some of the node labels could not be patched since the previous reconcile.
+// The nodes that could not be checked are only logged: the checks rely on the cache and the filters, backing off would not fix them.
+func (s *DrainoConfigurationObserverImpl) reconcile() error {
+	// Let's print the queue size
+	s.logger.Info("queueNodeToBeUpdated", zap.Int("len", s.queueNodeToBeUpdated.Len()))
 
-			// Let's update the nodes metadata
-			for _, node := range s.runtimeObjectStore.Nodes().ListNodes() {
-				_, outOfDate, err := s.getLabelUpdate(node)
-				if err != nil {
-					s.logger.Error("Failed to check if config annotation was out of date", zap.Error(err), zap.String("node", node.Name))
-				} else if outOfDate {
-					s.addNodeToQueue(node)
-				}
-			}
-			newMetricsFilterValue := filteredNodeMetrics{}
-			newMetricsValue := inScopeMetrics{}
-			newMetricsCPUValue := inScopeCPUMetrics{}
-			// Let's update the metrics
-			for _, node := range s.runtimeObjectStore.Nodes().ListNodes() {
-				// skip the node if it is too recent... it does not have all the required labels/annotations yet to have relevant metrics
-				if time.Now().Sub(node.CreationTimestamp.Time) < time.Minute {
-					continue
-				}
+	s.ProduceGroupRunnerMetrics()
 
-				s.ProduceNodeMetrics(node)
-				group := s.groupKeyGetter.GetGroupKey(node) // TODO once we have cleanup legacy code, check how to integrate 'group' directly in GetNodeTagsValues
-				nodeTags := kubernetes.GetNodeTagsValues(node)
-				conditions := kubernetes.GetNodeOffendingConditions(node, s.globalConfig.SuppliedConditions)
-				if node.Annotations == nil {
-					node.Annotations = map[string]string{}
-				}
+	// Let's update the nodes metadata
+	errorCount, err := s.reconcileNodeLabels()
+	s.produceNodesMetrics()
+	if errorCount > 0 {
+		s.logger.Warn("Failed to check some nodes", zap.Int("count", errorCount), zap.Error(err))
+	}
+	if failures := atomic.SwapInt32(&s.nodePatchFailures, 0); failures > 0 {
+		return fmt.Errorf("failed to patch the labels of %d nodes", failures)
+	}
+	return nil
+}
 
-				_, useDefaultRetryMaxAttempt, _ := kubernetes.GetNodeRetryMaxAttempt(node)
-
-				t := inScopeTags{
-					NodeTagsValues:                  nodeTags,
-					DrainStatus:                     getDrainStatusStr(node),
-					InScope:                         NodeInScopeWithConditionCheck(conditions, node),
-					PreprovisioningEnabled:          node.Annotations[pre_processor.PreprovisioningAnnotationKey] == pre_processor.PreprovisioningAnnotationValue,
-					PVCManagementEnabled:            s.HasPodWithPVCManagementEnabled(node),
-					DrainRetryCustomMaxAttempts:     !useDefaultRetryMaxAttempt,
-					UserOptOutViaPodAnnotation:      s.HasPodWithUserOptOutAnnotation(node),
-					UserOptInViaPodAnnotation:       s.HasPodWithUserOptInAnnotation(node),
-					UserAllowedConditionsAnnotation: kubernetes.HasAllowConditionList(node),
-					TagUserEvictionURLViaAnnotation: s.HasEvictionUrlViaAnnotation(node),
-				}
+// reconcileNodeLabels queues the nodes that have an out of date configuration label. It returns the number of nodes that could not be checked and the last error.
+func (s *DrainoConfigurationObserverImpl) reconcileNodeLabels() (int, error) {
+	var errorCount int
+	var lastErr error
+	for _, node := range s.runtimeObjectStore.Nodes().ListNodes() {
+		_, outOfDate, err := s.getLabelUpdate(node)
+		if err != nil {
+			s.logger.Error("Failed to check if config annotation was out of date", zap.Error(err), zap.String("node", node.Name))
+			errorCount++
+			lastErr = err
+		} else if outOfDate {
+			s.addNodeToQueue(node)
+		}
+	}
+	return errorCount, lastErr
+}
 
-				tCPU := inScopeCPUTags{
-					NodeTagsValues: nodeTags,
-					InScope:        NodeInScopeWithConditionCheck(conditions, node),
-				}
+func (s *DrainoConfigurationObserverImpl) produceNodesMetrics() {
+	newMetricsFilterValue := filteredNodeMetrics{}
+	newMetricsValue := inScopeMetrics{}
+	newMetricsCPUValue := inScopeCPUMetrics{}
+	// Let's update the metrics
+	for _, node := range s.runtimeObjectStore.Nodes().ListNodes() {
+		// skip the node if it is too recent... it does not have all the required labels/annotations yet to have relevant metrics
+		if time.Now().Sub(node.CreationTimestamp.Time) < time.Minute {
+			continue
+		}
 
-				overdue := map[string]bool{}
-				for _, c := range conditions {
-					isOverdue := kubernetes.IsOverdue(node, c)
-					overdue[string(c.Type)] = isOverdue
-					// If one of the conditions is overdue, we want to count the node as overdue in the "any" condition as well.
-					// With this we are able to get a count of all unique nodes that have an overdue condition.
-					if isOverdue {
-						overdue["any"] = isOverdue
-					}
-				}
+		s.ProduceNodeMetrics(node)
+		group := s.groupKeyGetter.GetGroupKey(node) // TODO once we have cleanup legacy code, check how to integrate 'group' directly in GetNodeTagsValues
+		nodeTags := kubernetes.GetNodeTagsValues(node)
+		conditions := kubernetes.GetNodeOffendingConditions(node, s.globalConfig.GetSuppliedConditions())
+		if node.Annotations == nil {
+			node.Annotations = map[string]string{}
+		}
 
-				// adding a virtual condition 'any' to be able to count the nodes whatever the condition(s) or absence of condition.
-				conditionsWithAll := append(kubernetes.GetConditionsTypes(conditions), "any")
-				for _, c := range conditionsWithAll {
-					t.Condition = c
-					t.Overdue = overdue[c]
-					newMetricsValue[t] = newMetricsValue[t] + 1
+		_, useDefaultRetryMaxAttempt, _ := kubernetes.GetNodeRetryMaxAttempt(node)
+
+		t := inScopeTags{
+			NodeTagsValues:                  nodeTags,
+			DrainStatus:                     getDrainStatusStr(node),
+			InScope:                         NodeInScopeWithConditionCheck(conditions, node),
+			PreprovisioningEnabled:          node.Annotations[pre_processor.PreprovisioningAnnotationKey] == pre_processor.PreprovisioningAnnotationValue,
+			PVCManagementEnabled:            s.HasPodWithPVCManagementEnabled(node),
+			DrainRetryCustomMaxAttempts:     !useDefaultRetryMaxAttempt,
+			UserOptOutViaPodAnnotation:      s.HasPodWithUserOptOutAnnotation(node),
+			UserOptInViaPodAnnotation:       s.HasPodWithUserOptInAnnotation(node),
+			UserAllowedConditionsAnnotation: kubernetes.HasAllowConditionList(node),
+			TagUserEvictionURLViaAnnotation: s.HasEvictionUrlViaAnnotation(node),
+		}
 
-					tCPU.Condition = c
-					newMetricsCPUValue[tCPU] = newMetricsCPUValue[tCPU] + node.Status.Capacity.Cpu().Value()
-				}
+		tCPU := inScopeCPUTags{
+			NodeTagsValues: nodeTags,
+			InScope:        NodeInScopeWithConditionCheck(conditions, node),
+		}
 
-				//filter tags
-				filterTags := filteredNodeTags{
-					NodeTagsValues: nodeTags,
-					group:          string(group),
-				}
-				filterOutputs := s.candidateFilter.FilterNode(context.Background(), node)
-				if !filterOutputs.Keep {
-					for _, check := range filterOutputs.Checks {
-						if check.Keep {
-							continue
-						}
-						ftags := filterTags
-						ftags.filter = check.FilterName
-						newMetricsFilterValue[ftags] = newMetricsFilterValue[ftags] + 1
-					}
-				}
+		overdue := map[string]bool{}
+		for _, c := range conditions {
+			isOverdue := kubernetes.IsOverdue(node, c)
+			overdue[string(c.Type)] = isOverdue
+			// If one of the conditions is overdue, we want to count the node as overdue in the "any" condition as well.
+			// With this we are able to get a count of all unique nodes that have an overdue condition.
+			if isOverdue {
+				overdue["any"] = isOverdue
+			}
+		}
+
+		// adding a virtual condition 'any' to be able to count the nodes whatever the condition(s) or absence of condition.
+		conditionsWithAll := append(kubernetes.GetConditionsTypes(conditions), "any")
+		for _, c := range conditionsWithAll {
+			t.Condition = c
+			t.Overdue = overdue[c]
+			newMetricsValue[t] = newMetricsValue[t] + 1
+
+			tCPU.Condition = c
+			newMetricsCPUValue[tCPU] = newMetricsCPUValue[tCPU] + node.Status.Capacity.Cpu().Value()
+		}
 
+		//filter tags
+		filterTags := filteredNodeTags{
+			NodeTagsValues: nodeTags,
+			group:          string(group),
+		}
+		filterOutputs := s.candidateFilter.FilterNode(context.Background(), node)
+		if !filterOutputs.Keep {
+			for _, check := range filterOutputs.Checks {
+				if check.Keep {
+					continue
+				}
+				ftags := filterTags
+				ftags.filter = check.FilterName
+				newMetricsFilterValue[ftags] = newMetricsFilterValue[ftags] + 1
 			}
-			s.updateGauges(newMetricsValue, newMetricsCPUValue)
-			s.updateAutoCleanupGauges(newMetricsFilterValue)
 		}
+
 	}
+	s.updateGauges(newMetricsValue, newMetricsCPUValue)
+	s.updateAutoCleanupGauges(newMetricsFilterValue)
 }
 
 func (s *DrainoConfigurationObserverImpl) ProduceGroupRunnerMetrics() {
@@ -543,11 +643,22 @@ func (s *DrainoConfigurationObserverImpl) IsInScope(node *v1.Node) (inScope bool
 	return true, "", nil
 }
 
-func (s *DrainoConfigurationObserverImpl) processQueueForNodeUpdates() {
+// startNodeUpdateWorkers starts the workers processing the queue of the nodes to update, they stop when the queue is shut down
+func (s *DrainoConfigurationObserverImpl) startNodeUpdateWorkers() {
+	workers := s.nodeUpdateWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		go s.processQueueForNodeUpdates(i)
+	}
+}
+
+func (s *DrainoConfigurationObserverImpl) processQueueForNodeUpdates(worker int) {
 	for {
 		obj, shutdown := s.queueNodeToBeUpdated.Get()
 		if shutdown {
-			s.logger.Info("Queue shutdown")
+			s.logger.Info("Queue shutdown", zap.Int("worker", worker))
 			break
 		}
 
@@ -564,7 +675,7 @@ func (s *DrainoConfigurationObserverImpl) processQueueForNodeUpdates() {
 						return // the node was deleted, no more need for update.
 					}
 					requeueCount := s.queueNodeToBeUpdated.NumRequeues(nodeName)
-					s.logger.Error("Failed to update label", zap.String("node", nodeName), zap.Int("retry", requeueCount), zap.Error(err))
+					s.logger.Error("Failed to update label", zap.String("node", nodeName), zap.Int("worker", worker), zap.Int("retry", requeueCount), zap.Error(err))
 					if requeueCount > 10 {
 						s.queueNodeToBeUpdated.Forget(nodeName)
 						return
@@ -603,6 +714,7 @@ func (s *DrainoConfigurationObserverImpl) patchNodeLabels(nodeName string) error
 			if apierrors.IsNotFound(err) {
 				return nil
 			}
+			atomic.AddInt32(&s.nodePatchFailures, 1)
 			return err
 		}
 	}
@@ -654,7 +766,7 @@ func (s *DrainoConfigurationObserverImpl) HasPodWithPVCManagementEnabled(node *v
 		return false
 	}
 	for _, p := range pods {
-		if kubernetes.PVCStorageClassCleanupEnabled(p, s.runtimeObjectStore, s.globalConfig.PVCManagementEnableIfNoEvictionUrl) {
+		if s.globalConfig.IsPVCCleanupEnabled(p, s.runtimeObjectStore) {
 			return true
 		}
 	}
@@ -671,7 +783,7 @@ func (s *DrainoConfigurationObserverImpl) HasEvictionUrlViaAnnotation(node *v1.N
 		return false
 	}
 	for _, p := range pods {
-		if _, ok := kubernetes.GetAnnotationFromPodOrController(kubernetes.EvictionAPIURLAnnotationKey, p, s.runtimeObjectStore); ok {
+		if kubernetes.HasEvictionEndpoint(p, s.runtimeObjectStore, s.globalConfig.EvictionEndpointResolver) {
 			return true
 		}
 	}
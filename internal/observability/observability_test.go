@@ -3,10 +3,12 @@ package observability
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/planetlabs/draino/internal/kubernetes"
+	"github.com/planetlabs/draino/internal/kubernetes/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -16,6 +18,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/workqueue"
 )
 
 func TestScopeObserverImpl_GetLabelUpdate(t *testing.T) {
@@ -299,6 +304,7 @@ func TestPVCStorageClassCleanupEnabled(t *testing.T) {
 		name                       string
 		p                          *v1.Pod
 		defaultTrueIfNoEvictionUrl bool
+		defaultCleanup             *bool
 		want                       bool
 	}{
 		{
@@ -353,6 +359,43 @@ func TestPVCStorageClassCleanupEnabled(t *testing.T) {
 			defaultTrueIfNoEvictionUrl: true,
 			want:                       true,
 		},
+		{
+			name: "explicit default true, with evictionURL",
+			p: &v1.Pod{
+				ObjectMeta: meta.ObjectMeta{
+					Annotations: map[string]string{kubernetes.EvictionAPIURLAnnotationKey: "url"},
+				},
+			},
+			defaultCleanup: utils.BoolPtr(true),
+			want:           true,
+		},
+		{
+			name:                       "explicit default false, no annotation",
+			p:                          &v1.Pod{},
+			defaultTrueIfNoEvictionUrl: true,
+			defaultCleanup:             utils.BoolPtr(false),
+			want:                       false,
+		},
+		{
+			name: "explicit default false, but explicit opt-in",
+			p: &v1.Pod{
+				ObjectMeta: meta.ObjectMeta{
+					Annotations: map[string]string{kubernetes.PVCStorageClassCleanupAnnotationKey: kubernetes.PVCStorageClassCleanupAnnotationTrueValue},
+				},
+			},
+			defaultCleanup: utils.BoolPtr(false),
+			want:           true,
+		},
+		{
+			name: "explicit default true, but explicit opt-out",
+			p: &v1.Pod{
+				ObjectMeta: meta.ObjectMeta{
+					Annotations: map[string]string{kubernetes.PVCStorageClassCleanupAnnotationKey: kubernetes.PVCStorageClassCleanupAnnotationFalseValue},
+				},
+			},
+			defaultCleanup: utils.BoolPtr(true),
+			want:           false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -360,7 +403,126 @@ func TestPVCStorageClassCleanupEnabled(t *testing.T) {
 			store, closingFunc := kubernetes.RunStoreForTest(context.Background(), kclient)
 			defer closingFunc()
 
-			assert.Equalf(t, tt.want, kubernetes.PVCStorageClassCleanupEnabled(tt.p, store, tt.defaultTrueIfNoEvictionUrl), "PVCStorageClassCleanupEnabled test=%s", tt.name)
+			config := kubernetes.GlobalConfig{PVCManagementEnableIfNoEvictionUrl: tt.defaultTrueIfNoEvictionUrl, DefaultPVCCleanup: tt.defaultCleanup}
+			assert.Equalf(t, tt.want, config.IsPVCCleanupEnabled(tt.p, store), "PVCStorageClassCleanupEnabled test=%s", tt.name)
 		})
 	}
 }
+
+func TestReconcileBackoff(t *testing.T) {
+	now := time.Now()
+	b := newReconcileBackoff(time.Minute, 5*time.Minute)
+	assert.True(t, b.ready(now))
+
+	// consecutive failures
+	for _, expected := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute} {
+		assert.Equal(t, expected, b.failure(now))
+		assert.False(t, b.ready(now.Add(expected-time.Second)))
+		assert.True(t, b.ready(now.Add(expected)))
+	}
+
+	// recovery
+	b.success()
+	assert.True(t, b.ready(now))
+	assert.Equal(t, time.Minute, b.failure(now))
+}
+
+func TestScopeObserverImpl_Reconcile(t *testing.T) {
+	node := &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "node1"}}
+	pod := &v1.Pod{ObjectMeta: meta.ObjectMeta{Name: "pod1", Namespace: "ns"}, Spec: v1.PodSpec{NodeName: "node1"}}
+	kclient := fake.NewSimpleClientset(node, pod)
+	runtimeObjectStore, closeFunc := kubernetes.RunStoreForTest(context.Background(), kclient)
+	defer closeFunc()
+
+	failing := true
+	s := &DrainoConfigurationObserverImpl{
+		kclient:            kclient,
+		runtimeObjectStore: runtimeObjectStore,
+		globalConfig:       kubernetes.GlobalConfig{ConfigName: "draino"},
+		nodeFilterFunc:     func(obj interface{}) bool { return true },
+		podFilterFunc: func(p v1.Pod) (bool, string, error) {
+			if failing {
+				return false, "", apierrors.NewServiceUnavailable("api server unavailable")
+			}
+			return true, "", nil
+		},
+		logger:               zap.NewNop(),
+		queueNodeToBeUpdated: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+	defer s.queueNodeToBeUpdated.ShutDown()
+
+	errorCount, err := s.reconcileNodeLabels()
+	assert.Equal(t, 1, errorCount)
+	assert.True(t, apierrors.IsServiceUnavailable(err))
+
+	failing = false
+	errorCount, err = s.reconcileNodeLabels()
+	assert.Equal(t, 0, errorCount)
+	assert.NoError(t, err)
+}
+
+func TestScopeObserverImpl_NodeUpdateWorkers(t *testing.T) {
+	const workers = 3
+	var objects []runtime.Object
+	for i := 0; i < 9; i++ {
+		objects = append(objects, &v1.Node{ObjectMeta: meta.ObjectMeta{Name: fmt.Sprintf("node%d", i)}})
+	}
+	objects = append(objects, &v1.Node{ObjectMeta: meta.ObjectMeta{Name: "failing-node"}})
+	kclient := fake.NewSimpleClientset(objects...)
+	kclient.PrependReactor("patch", "nodes", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.(clienttesting.PatchAction).GetName() == "failing-node" {
+			return true, nil, apierrors.NewServiceUnavailable("api server unavailable")
+		}
+		return false, nil, nil
+	})
+	// the fake client serializes the calls, the concurrency is observed in the node filter evaluated for each update
+	var inFlight, maxInFlight int32
+	nodeFilter := func(obj interface{}) bool {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			previous := atomic.LoadInt32(&maxInFlight)
+			if current <= previous || atomic.CompareAndSwapInt32(&maxInFlight, previous, current) {
+				break
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+		return true
+	}
+	runtimeObjectStore, closeFunc := kubernetes.RunStoreForTest(context.Background(), kclient)
+	defer closeFunc()
+
+	s := &DrainoConfigurationObserverImpl{
+		kclient:              kclient,
+		runtimeObjectStore:   runtimeObjectStore,
+		globalConfig:         kubernetes.GlobalConfig{Context: context.Background(), ConfigName: "draino"},
+		nodeFilterFunc:       nodeFilter,
+		podFilterFunc:        kubernetes.NewPodFilters(),
+		logger:               zap.NewNop(),
+		queueNodeToBeUpdated: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		nodePatchLimiter:     flowcontrol.NewFakeAlwaysRateLimiter(),
+		nodeUpdateWorkers:    workers,
+	}
+	defer s.queueNodeToBeUpdated.ShutDown()
+	for _, obj := range objects {
+		s.queueNodeToBeUpdated.Add(obj.(*v1.Node).Name)
+	}
+	s.startNodeUpdateWorkers()
+
+	// the failing node does not prevent the workers from updating the other nodes
+	assert.NoError(t, wait.PollImmediate(50*time.Millisecond, 10*time.Second, func() (bool, error) {
+		for i := 0; i < 9; i++ {
+			n, err := kclient.CoreV1().Nodes().Get(context.Background(), fmt.Sprintf("node%d", i), meta.GetOptions{})
+			if err != nil || n.Labels[ConfigurationLabelKey] != "draino" {
+				return false, nil
+			}
+		}
+		return true, nil
+	}))
+	assert.Equal(t, int32(workers), atomic.LoadInt32(&maxInFlight))
+
+	// only the failed patches count for the reconcile backoff
+	assert.NoError(t, wait.PollImmediate(50*time.Millisecond, 10*time.Second, func() (bool, error) {
+		return atomic.LoadInt32(&s.nodePatchFailures) > 0, nil
+	}))
+}
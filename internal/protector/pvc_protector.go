@@ -14,19 +14,19 @@ type PVCProtector interface {
 
 // legacyPVCProtectorImpl is an implementation of the PVCProtector interface that uses the legacy system with the runtime object store
 type legacyPVCProtectorImpl struct {
-	store                                   kubernetes.RuntimeObjectStore
-	logger                                  *zap.Logger
-	pvcManagementDefaultTrueIfNoEvictionURL bool
+	store             kubernetes.RuntimeObjectStore
+	logger            *zap.Logger
+	pvcCleanupDefault kubernetes.PVCCleanupDefaultFunc
 }
 
-func NewPVCProtector(store kubernetes.RuntimeObjectStore, logger *zap.Logger, pvcManagementDefaultTrueIfNoEvictionURL bool) PVCProtector {
+func NewPVCProtector(store kubernetes.RuntimeObjectStore, logger *zap.Logger, pvcCleanupDefault kubernetes.PVCCleanupDefaultFunc) PVCProtector {
 	return &legacyPVCProtectorImpl{
-		store:                                   store,
-		logger:                                  logger,
-		pvcManagementDefaultTrueIfNoEvictionURL: pvcManagementDefaultTrueIfNoEvictionURL,
+		store:             store,
+		logger:            logger,
+		pvcCleanupDefault: pvcCleanupDefault,
 	}
 }
 
 func (protector *legacyPVCProtectorImpl) GetUnscheduledPodsBoundToNodeByPV(node *corev1.Node) ([]*corev1.Pod, error) {
-	return kubernetes.GetUnscheduledPodsBoundToNodeByPV(node, protector.store, protector.pvcManagementDefaultTrueIfNoEvictionURL, protector.logger)
+	return kubernetes.GetUnscheduledPodsBoundToNodeByPV(node, protector.store, protector.pvcCleanupDefault, protector.logger)
 }
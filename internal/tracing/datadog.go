@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	kubernetestrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/k8s.io/client-go/kubernetes"
+	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+type datadogTracer struct{}
+
+// NewDatadogTracer returns a Tracer sending the spans to the Datadog tracer, that must be started with tracer.Start
+func NewDatadogTracer() Tracer {
+	return datadogTracer{}
+}
+
+func (datadogTracer) StartSpanFromContext(ctx context.Context, operationName string) (Span, context.Context) {
+	span, ctx := tracer.StartSpanFromContext(ctx, operationName)
+	return datadogSpan{Span: span}, ctx
+}
+
+func (datadogTracer) WrapRoundTripper(rt http.RoundTripper) http.RoundTripper {
+	return httptrace.WrapRoundTripper(rt)
+}
+
+func (datadogTracer) WrapKubernetesRoundTripper(rt http.RoundTripper) http.RoundTripper {
+	return kubernetestrace.WrapRoundTripper(rt)
+}
+
+// datadogSpan hides the finish options of the Datadog span
+type datadogSpan struct {
+	ddtrace.Span
+}
+
+func (s datadogSpan) Finish() {
+	s.Span.Finish()
+}
@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the OpenTelemetry tracer created by draino
+const InstrumentationName = "github.com/planetlabs/draino"
+
+type openTelemetryTracer struct {
+	tracer trace.Tracer
+}
+
+// NewOpenTelemetryTracer returns a Tracer creating the spans with the given OpenTelemetry provider
+func NewOpenTelemetryTracer(provider trace.TracerProvider) Tracer {
+	return &openTelemetryTracer{tracer: provider.Tracer(InstrumentationName)}
+}
+
+func (t *openTelemetryTracer) StartSpanFromContext(ctx context.Context, operationName string) (Span, context.Context) {
+	ctx, span := t.tracer.Start(ctx, operationName)
+	return openTelemetrySpan{span: span}, ctx
+}
+
+func (t *openTelemetryTracer) WrapRoundTripper(rt http.RoundTripper) http.RoundTripper {
+	return openTelemetryRoundTripper{tracer: t.tracer, operationName: "http.request", base: rt}
+}
+
+func (t *openTelemetryTracer) WrapKubernetesRoundTripper(rt http.RoundTripper) http.RoundTripper {
+	return openTelemetryRoundTripper{tracer: t.tracer, operationName: "kubernetes.request", base: rt}
+}
+
+// openTelemetryRoundTripper creates a client span for each request and propagates it in the headers of the request
+type openTelemetryRoundTripper struct {
+	tracer        trace.Tracer
+	operationName string
+	base          http.RoundTripper
+}
+
+func (rt openTelemetryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := rt.tracer.Start(req.Context(), rt.operationName, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("http.method", req.Method),
+		attribute.String("http.url", req.URL.String()),
+	))
+	defer span.End()
+
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp, err := rt.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+type openTelemetrySpan struct {
+	span trace.Span
+}
+
+func (s openTelemetrySpan) SetTag(key string, value interface{}) {
+	s.span.SetAttributes(toAttribute(key, value))
+}
+
+func (s openTelemetrySpan) Finish() {
+	s.span.End()
+}
+
+// toAttribute keeps the type of the tag when OpenTelemetry has a matching attribute type, other values are formatted as strings
+func toAttribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int32:
+		return attribute.Int64(key, int64(v))
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case time.Duration:
+		return attribute.String(key, v.String())
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	}
+	return attribute.String(key, fmt.Sprintf("%v", value))
+}
@@ -0,0 +1,99 @@
+// Package tracing is a thin abstraction on top of the tracing libraries, so that the spans of draino can be sent to Datadog or to OpenTelemetry.
+// The span names and tags are the same whatever the backend.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Span is the part of a tracing span used by draino
+type Span interface {
+	// SetTag sets a tag (an attribute for OpenTelemetry) on the span
+	SetTag(key string, value interface{})
+	// Finish ends the span, it must be called exactly once
+	Finish()
+}
+
+// Tracer creates the spans
+type Tracer interface {
+	// StartSpanFromContext starts a span, child of the span found in the context if any, and returns it with a context holding it
+	StartSpanFromContext(ctx context.Context, operationName string) (Span, context.Context)
+	// WrapRoundTripper returns a round tripper creating a span for each HTTP request sent with rt
+	WrapRoundTripper(rt http.RoundTripper) http.RoundTripper
+	// WrapKubernetesRoundTripper returns a round tripper creating a span for each request sent to the Kubernetes API server with rt
+	WrapKubernetesRoundTripper(rt http.RoundTripper) http.RoundTripper
+}
+
+const (
+	BackendDatadog       = "datadog"
+	BackendOpenTelemetry = "opentelemetry"
+)
+
+// ParseBackend validates the name of a tracing backend
+func ParseBackend(backend string) (string, error) {
+	switch b := strings.ToLower(backend); b {
+	case BackendDatadog, BackendOpenTelemetry:
+		return b, nil
+	}
+	return "", fmt.Errorf("unknown tracing backend %q, expected %q or %q", backend, BackendDatadog, BackendOpenTelemetry)
+}
+
+var (
+	tracerMutex   sync.RWMutex
+	currentTracer Tracer = NewDatadogTracer()
+)
+
+// SetTracer replaces the tracer used by StartSpanFromContext and returns the previous one.
+// The Datadog tracer is used until SetTracer is called.
+func SetTracer(t Tracer) Tracer {
+	tracerMutex.Lock()
+	defer tracerMutex.Unlock()
+	previous := currentTracer
+	currentTracer = t
+	return previous
+}
+
+// GetTracer returns the tracer used by StartSpanFromContext
+func GetTracer() Tracer {
+	tracerMutex.RLock()
+	defer tracerMutex.RUnlock()
+	return currentTracer
+}
+
+// StartSpanFromContext starts a span with the current tracer
+func StartSpanFromContext(ctx context.Context, operationName string) (Span, context.Context) {
+	return GetTracer().StartSpanFromContext(ctx, operationName)
+}
+
+// roundTripperFunc is an http.RoundTripper calling the function
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// WrapRoundTripper traces the HTTP requests sent with rt, http.DefaultTransport if nil. The tracer is the current one when each request is sent,
+// so that the round trippers created before SetTracer use the configured backend.
+func WrapRoundTripper(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return GetTracer().WrapRoundTripper(rt).RoundTrip(req)
+	})
+}
+
+// WrapKubernetesRoundTripper traces the requests sent to the Kubernetes API server with rt, it can be used as the WrapTransport of a rest.Config.
+// Like WrapRoundTripper, the tracer is the current one when each request is sent.
+func WrapKubernetesRoundTripper(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return GetTracer().WrapKubernetesRoundTripper(rt).RoundTrip(req)
+	})
+}
@@ -0,0 +1,155 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+)
+
+// recordedSpan is the part of a finished span checked by the tests, whatever the backend
+type recordedSpan struct {
+	name   string
+	parent string
+	tags   map[string]interface{}
+}
+
+func TestStartSpanFromContext(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup configures the tracer and returns the function listing the finished spans
+		setup func(t *testing.T) func() []recordedSpan
+	}{
+		{
+			name: "datadog",
+			setup: func(t *testing.T) func() []recordedSpan {
+				mt := mocktracer.Start()
+				t.Cleanup(mt.Stop)
+				SetTracer(NewDatadogTracer())
+				return func() []recordedSpan {
+					names := map[uint64]string{}
+					for _, s := range mt.FinishedSpans() {
+						names[s.SpanID()] = s.OperationName()
+					}
+					var spans []recordedSpan
+					for _, s := range mt.FinishedSpans() {
+						tags := map[string]interface{}{}
+						for _, key := range []string{"node", "failed"} {
+							if v := s.Tag(key); v != nil {
+								tags[key] = v
+							}
+						}
+						spans = append(spans, recordedSpan{name: s.OperationName(), parent: names[s.ParentID()], tags: tags})
+					}
+					return spans
+				}
+			},
+		},
+		{
+			name: "opentelemetry",
+			setup: func(t *testing.T) func() []recordedSpan {
+				recorder := tracetest.NewSpanRecorder()
+				SetTracer(NewOpenTelemetryTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))))
+				return func() []recordedSpan {
+					names := map[string]string{}
+					for _, s := range recorder.Ended() {
+						names[s.SpanContext().SpanID().String()] = s.Name()
+					}
+					var spans []recordedSpan
+					for _, s := range recorder.Ended() {
+						tags := map[string]interface{}{}
+						for _, kv := range s.Attributes() {
+							tags[string(kv.Key)] = kv.Value.AsInterface()
+						}
+						parent := ""
+						if s.Parent().IsValid() {
+							parent = names[s.Parent().SpanID().String()]
+						}
+						spans = append(spans, recordedSpan{name: s.Name(), parent: parent, tags: tags})
+					}
+					return spans
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := GetTracer()
+			defer SetTracer(previous)
+			finishedSpans := tt.setup(t)
+
+			parent, ctx := StartSpanFromContext(context.Background(), "Drain")
+			parent.SetTag("node", "node-1")
+			child, _ := StartSpanFromContext(ctx, "evictionSequence")
+			child.SetTag("failed", true)
+			child.Finish()
+			parent.Finish()
+
+			spans := finishedSpans()
+			assert.ElementsMatch(t, []recordedSpan{
+				{name: "evictionSequence", parent: "Drain", tags: map[string]interface{}{"failed": true}},
+				{name: "Drain", tags: map[string]interface{}{"node": "node-1"}},
+			}, spans)
+		})
+	}
+}
+
+func TestWrapRoundTripper_OpenTelemetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	// the round tripper is created before the tracer is configured, like the Kubernetes client config
+	client := &http.Client{Transport: WrapRoundTripper(nil)}
+	previous := GetTracer()
+	defer SetTracer(previous)
+	recorder := tracetest.NewSpanRecorder()
+	SetTracer(NewOpenTelemetryTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))))
+
+	resp, err := client.Get(server.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+	}
+	if spans := recorder.Ended(); assert.Len(t, spans, 1) {
+		assert.Equal(t, "http.request", spans[0].Name())
+		assert.Contains(t, spans[0].Attributes(), attribute.Int("http.status_code", http.StatusTooManyRequests))
+	}
+}
+
+func TestToAttribute(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected attribute.KeyValue
+	}{
+		{name: "string", value: "v", expected: attribute.String("key", "v")},
+		{name: "bool", value: true, expected: attribute.Bool("key", true)},
+		{name: "int", value: 3, expected: attribute.Int("key", 3)},
+		{name: "int32", value: int32(3), expected: attribute.Int64("key", 3)},
+		{name: "float", value: 1.5, expected: attribute.Float64("key", 1.5)},
+		{name: "duration", value: time.Minute, expected: attribute.String("key", "1m0s")},
+		{name: "other", value: []int{1, 2}, expected: attribute.String("key", "[1 2]")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, toAttribute("key", tt.value))
+		})
+	}
+}
+
+func TestParseBackend(t *testing.T) {
+	backend, err := ParseBackend("OpenTelemetry")
+	assert.NoError(t, err)
+	assert.Equal(t, BackendOpenTelemetry, backend)
+
+	_, err = ParseBackend("jaeger")
+	assert.Error(t, err)
+}